@@ -68,14 +68,30 @@ type CasbinCnf struct {
 
 type EtherumConfig struct {
 	BlockchainRPC string
+	FallbackRPCs  []string // tried in order whenever BlockchainRPC fails
+	WebsocketRPC  string   // optional ws endpoint, listeners subscribe to new heads through it
 	BlockTime     uint64
 	BlockOffSet   int64
+	Confirmations uint64 // blocks a deposit must be buried under, unless overridden per token
+	Gas           EthGasConf
+}
+
+// EthGasConf bounds the fees of outgoing EIP-1559 transactions, caps are in gwei
+type EthGasConf struct {
+	MaxFeePerGasGwei   uint64
+	MaxPriorityFeeGwei uint64
+	GasLimitMultiplier float64 // safety margin applied to estimated gas
+	TxFeeCeilingGwei   uint64  // transactions expected to cost more are refused
+
+	StuckTxTimeout         time.Duration // pending transactions are re-broadcast past it
+	ReplacementBumpPercent uint64        // fee increase of a re-broadcast, at least 10
 }
 
 type WelupsConfig struct {
 	Nodes         []string
 	BlockTime     uint64
 	BlockOffSet   int64
+	Confirmations uint64 // blocks a deposit must be buried under, unless overridden per token
 	ClientTimeout int64
 }
 
@@ -0,0 +1,17 @@
+package consts
+
+// ScanCursor is the last block a consumer of a chain listener has scanned
+type ScanCursor struct {
+	Chain            string `db:"chain"`
+	Consumer         string `db:"consumer"`
+	LastScannedBlock int64  `db:"last_scan_block"`
+}
+
+type IScanCursorRepo interface {
+	GetCursors(chain string) ([]ScanCursor, error)
+	// creates the cursor unless the consumer has one already
+	CreateCursor(cursor *ScanCursor) error
+	UpdateCursor(cursor *ScanCursor) error
+	// moves the cursors of the chain past block back to it
+	RollbackCursors(chain string, block int64) error
+}
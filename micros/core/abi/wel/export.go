@@ -1,12 +1,12 @@
 package wel
 
 import (
+	welListener "bridge/service-managers/listener/wel"
 	"bridge/service-managers/logger"
 	"crypto/sha256"
 	"fmt"
 	"math/big"
 
-	welclient "github.com/Paven-Org/gotron-sdk/pkg/client"
 	"github.com/Paven-Org/gotron-sdk/pkg/proto/api"
 	"github.com/ethereum/go-ethereum/crypto"
 	"google.golang.org/protobuf/proto"
@@ -16,10 +16,10 @@ import (
 
 type WelExport struct {
 	address string
-	cli     *welclient.GrpcClient
+	cli     welListener.IWelClient
 }
 
-func MkWelExport(welcli *welclient.GrpcClient, contract string) *WelExport {
+func MkWelExport(welcli welListener.IWelClient, contract string) *WelExport {
 	return &WelExport{cli: welcli, address: contract}
 }
 
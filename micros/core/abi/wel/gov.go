@@ -1,12 +1,12 @@
 package wel
 
 import (
+	welListener "bridge/service-managers/listener/wel"
 	"bridge/service-managers/logger"
 	"crypto/sha256"
 	"fmt"
 	"math/big"
 
-	welclient "github.com/Paven-Org/gotron-sdk/pkg/client"
 	"github.com/Paven-Org/gotron-sdk/pkg/proto/api"
 	"github.com/ethereum/go-ethereum/crypto"
 	"google.golang.org/protobuf/proto"
@@ -14,11 +14,11 @@ import (
 
 type WelGov struct {
 	address string
-	cli     *welclient.GrpcClient
+	cli     welListener.IWelClient
 }
 
 // NewWelGov creates a new instance of WelGov, bound to a specific deployed contract.
-func MkWelGov(address string, welcli *welclient.GrpcClient) *WelGov {
+func MkWelGov(address string, welcli welListener.IWelClient) *WelGov {
 	return &WelGov{address: address, cli: welcli}
 }
 
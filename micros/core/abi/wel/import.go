@@ -2,13 +2,13 @@ package wel
 
 import (
 	"bridge/libs"
+	welListener "bridge/service-managers/listener/wel"
 	"bridge/service-managers/logger"
 	"crypto/sha256"
 	"fmt"
 	"math/big"
 	"strings"
 
-	welclient "github.com/Paven-Org/gotron-sdk/pkg/client"
 	"github.com/Paven-Org/gotron-sdk/pkg/proto/api"
 	"github.com/ethereum/go-ethereum/crypto"
 	"google.golang.org/protobuf/proto"
@@ -18,10 +18,10 @@ import (
 
 type WelImport struct {
 	address string
-	cli     *welclient.GrpcClient
+	cli     welListener.IWelClient
 }
 
-func MkWelImport(welcli *welclient.GrpcClient, contract string) *WelImport {
+func MkWelImport(welcli welListener.IWelClient, contract string) *WelImport {
 	return &WelImport{cli: welcli, address: contract}
 }
 
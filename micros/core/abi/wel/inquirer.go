@@ -3,11 +3,11 @@ package wel
 import (
 	"bridge/libs"
 	"bridge/micros/core/model"
+	welListener "bridge/service-managers/listener/wel"
 	"bridge/service-managers/logger"
 	"fmt"
 	"math/big"
 
-	welclient "github.com/Paven-Org/gotron-sdk/pkg/client"
 	"github.com/Paven-Org/gotron-sdk/pkg/proto/api"
 	"github.com/Paven-Org/gotron-sdk/pkg/proto/core"
 	"google.golang.org/protobuf/proto"
@@ -16,10 +16,10 @@ import (
 // Contract caller for the ERC20 interface
 
 type WelInquirer struct {
-	cli *welclient.GrpcClient
+	cli welListener.IWelClient
 }
 
-func MkWelInquirer(welcli *welclient.GrpcClient) *WelInquirer {
+func MkWelInquirer(welcli welListener.IWelClient) *WelInquirer {
 	return &WelInquirer{cli: welcli}
 }
 
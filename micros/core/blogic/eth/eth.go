@@ -1,9 +1,7 @@
 package ethLogic
 
 import (
-	"bridge/common/consts"
 	"bridge/libs"
-	"bridge/micros/core/config"
 	msweleth "bridge/micros/core/microservices/weleth"
 	"bridge/micros/core/model"
 	ethService "bridge/micros/core/service/eth"
@@ -17,6 +15,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"go.temporal.io/sdk/client"
 )
@@ -302,7 +301,7 @@ func UnsetCurrentAuthenticator() error {
 }
 
 // Claim cashin = get wrapped tokens equivalent to another chain's original tokens
-func ClaimWel2EthCashin(cashinTxId string, userAddr string, contractVersion string) (inTokenAddr string, amount string, requestID []byte, signature []byte, claimExpireTime int64, err error) {
+func ClaimWel2EthCashin(cashinTxId string, logIndex int64, userAddr string, contractVersion string) (inTokenAddr string, amount string, requestID []byte, signature []byte, claimExpireTime int64, err error) {
 	// Get tx info from weleth microservice
 	// tmpCli.ExecuteWorkflow
 	ctx := context.Background()
@@ -311,7 +310,7 @@ func ClaimWel2EthCashin(cashinTxId string, userAddr string, contractVersion stri
 	wo := client.StartWorkflowOptions{
 		TaskQueue: msweleth.WFQueue,
 	}
-	we, err := tempcli.ExecuteWorkflow(ctx, wo, msweleth.CreateW2ECashinClaimRequestWF, cashinTxId, userAddr)
+	we, err := tempcli.ExecuteWorkflow(ctx, wo, msweleth.CreateW2ECashinClaimRequestWF, cashinTxId, userAddr, logIndex)
 	if err != nil {
 		log.Err(err).Msg("[Eth logic internal] Unable to call CreateW2ECashinClaimRequest workflow")
 		return
@@ -323,7 +322,7 @@ func ClaimWel2EthCashin(cashinTxId string, userAddr string, contractVersion stri
 		log.Err(err).Msg("[Eth logic internal] CreateW2ECashinClaimRequest workflow failed")
 		return
 	}
-	tempcli.ExecuteWorkflow(ctx, wo, msweleth.WaitForPendingW2ECashinClaimRequestWF, cashinTxId)
+	tempcli.ExecuteWorkflow(ctx, wo, msweleth.WaitForPendingW2ECashinClaimRequestWF, cashinTxId, logIndex)
 	claimExpireTime = time.Now().Add(3 * time.Minute).Unix()
 
 	// process
@@ -356,14 +355,15 @@ func ClaimWel2EthCashin(cashinTxId string, userAddr string, contractVersion stri
 
 	inTokenAddr = tx.EthTokenAddr
 	toAddress := tx.EthWalletAddr
-	amount = tx.Amount
+	// claimed in eth token units
+	amount = tx.DestAmount
 
 	_requestID := &big.Int{}
 	_requestID.SetString(tx.ReqID, 10)
 	requestID = _requestID.Bytes()
 
 	_amount := &big.Int{}
-	_amount.SetString(tx.Amount, 10)
+	_amount.SetString(amount, 10)
 
 	signature, err = libs.StdSignedMessageHash(inTokenAddr, toAddress, _amount, _requestID, contractVersion, prikey)
 	if err != nil {
@@ -437,35 +437,19 @@ func InvalidateRequestClaim(inTokenAddr, amount, reqID, contractVersion string)
 
 	log.Info().Msg("[Eth logic internal] Invalidating request ID " + reqID)
 
-	nonce, err := importC.cli.PendingNonceAt(ctx, caller)
-	if err != nil {
-		logger.Get().Err(err).Msgf("Unale to get last nonce of address %s", address)
-		return err
-	}
-	gasPrice, err := importC.cli.SuggestGasPrice(context.Background())
-	if err != nil {
-		logger.Get().Err(err).Msgf("Unable to get recommended gas price, set to default")
-		gasPrice = importC.lastGasPrice
-	}
-	importC.lastGasPrice = gasPrice
-
-	env := config.Get().Environment
-	opts, err := bind.NewKeyedTransactorWithChainID(pkey, consts.EthChainFromEnv[env])
-	if err != nil {
-		logger.Get().Err(err).Msg("Unale to create call opts for Disperse method")
-		return err
-	}
-	opts.GasLimit = uint64(300000)
-	opts.Value = big.NewInt(0)
-	opts.GasPrice = gasPrice
-	opts.Nonce = big.NewInt(int64(nonce))
-
 	tokenAddr := common.HexToAddress(inTokenAddr)
-	tx, err := importC.impC.EthImportCTransactor.Claim(opts, tokenAddr, _requestID, _amount, signature)
+	tx, err := importC.transactor.Transact(ctx, pkey, nil, false, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return importC.impC.EthImportCTransactor.Claim(opts, tokenAddr, _requestID, _amount, signature)
+	})
 	//if err != nil {
 	logger.Get().Err(err).Msgf("[Eth logic internal] failed tx: %v", tx)
 	//	return err
 	//}
+	if err == nil {
+		if err := importC.transactor.Track(tx); err != nil {
+			log.Err(err).Msgf("[Eth logic internal] Claim tx %s sent but not tracked", tx.Hash().Hex())
+		}
+	}
 
 	return nil
 }
@@ -605,12 +589,14 @@ func WatchTx2TreasuryRequest(from, to, treasury, netid, token, amount string) er
 	return nil
 }
 
-func WatchTx2TreasuryRequestByTxhash(txhash, to, netid, token string) error {
+// WatchTx2TreasuryRequestByTxhash requests the cashin of the deposit of txhash at logIndex,
+// nil for the only deposit of the tx
+func WatchTx2TreasuryRequestByTxhash(txhash string, logIndex *int64, to, netid, token string) error {
 	wo := client.StartWorkflowOptions{
 		TaskQueue: welService.ImportContractQueue,
 	}
 
-	we, err := tempcli.ExecuteWorkflow(context.Background(), wo, welService.WatchForTx2TreasuryByTxHashWF, txhash, to, netid, token)
+	we, err := tempcli.ExecuteWorkflow(context.Background(), wo, welService.WatchForTx2TreasuryByTxHashWF, txhash, to, netid, token, logIndex)
 	if err != nil {
 		log.Err(err).Msgf("[Eth logic internal] Failed to request BE to watch for transaction to treasury with txhash %s", txhash)
 		return err
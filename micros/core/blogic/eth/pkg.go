@@ -4,12 +4,14 @@ import (
 	"bridge/micros/core/config"
 	"bridge/micros/core/dao"
 	ethdao "bridge/micros/core/dao/eth-account"
+	ethtxdao "bridge/micros/core/dao/eth-tx"
 	userdao "bridge/micros/core/dao/user"
 	"bridge/micros/core/model"
+	"bridge/micros/core/service/eth/transactor"
 	"bridge/micros/core/service/notifier"
+	ethListener "bridge/service-managers/listener/eth"
 	"bridge/service-managers/logger"
 	"context"
-	"math/big"
 	"regexp"
 	"strings"
 	"sync"
@@ -18,14 +20,14 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/rs/zerolog"
 	"go.temporal.io/sdk/client"
 )
 
 var (
-	ethDAO  ethdao.IEthDAO
-	userDAO userdao.IUserDAO
+	ethDAO   ethdao.IEthDAO
+	ethTxDAO ethtxdao.IEthTxDAO
+	userDAO  userdao.IUserDAO
 	//mailer  *manager.Mailer
 	tempcli client.Client
 	log     *zerolog.Logger
@@ -33,14 +35,15 @@ var (
 )
 
 type importContract struct {
-	impC         *eth.EthImportC
-	cli          *ethclient.Client
-	lastGasPrice *big.Int
+	impC       *eth.EthImportC
+	cli        ethListener.IEthClient
+	transactor *transactor.Transactor
 }
 
-func Init(d *dao.DAOs, tmpcli client.Client, ethcli *ethclient.Client) {
+func Init(d *dao.DAOs, tmpcli client.Client, ethcli ethListener.IEthClient, txr *transactor.Transactor) {
 	log = logger.Get()
 	ethDAO = d.Eth
+	ethTxDAO = d.EthTx
 	userDAO = d.User
 
 	importContractAddress := common.HexToAddress(config.Get().EthImportContract)
@@ -50,9 +53,9 @@ func Init(d *dao.DAOs, tmpcli client.Client, ethcli *ethclient.Client) {
 		panic(err)
 	}
 	importC = &importContract{
-		impC:         impC,
-		cli:          ethcli,
-		lastGasPrice: big.NewInt(1000000000),
+		impC:       impC,
+		cli:        ethcli,
+		transactor: txr,
 	}
 	//	mailer = m
 	tempcli = tmpcli
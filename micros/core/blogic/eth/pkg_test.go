@@ -2,9 +2,11 @@ package ethLogic
 
 import (
 	"bridge/common"
+	"bridge/common/consts"
 	"bridge/micros/core/config"
 	"bridge/micros/core/dao"
 	ethService "bridge/micros/core/service/eth"
+	"bridge/micros/core/service/eth/transactor"
 	manager "bridge/service-managers"
 	"bridge/service-managers/logger"
 	"fmt"
@@ -65,7 +67,7 @@ func TestMain(m *testing.M) {
 	}
 	defer ethCli.Close()
 
-	GovService, err = ethService.MkGovContractService(ethCli, tempcli, daos, cnf.EthGovContract)
+	GovService, err = ethService.MkGovContractService(ethCli, tempcli, daos, cnf.EthGovContract, transactor.MkTransactor(ethCli, consts.EthChainFromEnv[cnf.Environment], cnf.EthereumConfig.Gas, nil, nil, nil))
 	if err != nil {
 		logger.Get().Err(err).Msgf("Unable to initialize GovContractService")
 		return
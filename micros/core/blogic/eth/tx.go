@@ -0,0 +1,28 @@
+package ethLogic
+
+import (
+	"bridge/micros/core/model"
+)
+
+// GetEthTxs lists the bridge transactions sent to ethereum, of every status when status is
+// empty
+func GetEthTxs(status string, offset uint, size uint) ([]model.EthOutgoingTx, error) {
+	ts, err := ethTxDAO.GetEthTxs(status, offset, size)
+	if err != nil {
+		log.Err(err).Msg("[Eth logic internal] Unable to get outgoing txs")
+		return nil, err
+	}
+	return ts, nil
+}
+
+// CancelEthTx has the stuck transaction monitor replace a pending transaction by a zero
+// value self transfer
+func CancelEthTx(id int64) (*model.EthOutgoingTx, error) {
+	t, err := ethTxDAO.RequestCancel(id)
+	if err != nil {
+		log.Err(err).Msgf("[Eth logic internal] Unable to cancel outgoing tx %d", id)
+		return nil, err
+	}
+	log.Info().Msgf("[Eth logic internal] Cancellation of tx %s requested", t.TxHash)
+	return t, nil
+}
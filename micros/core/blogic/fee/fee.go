@@ -0,0 +1,96 @@
+package feeLogic
+
+import (
+	msweleth "bridge/micros/core/microservices/weleth"
+	welethModel "bridge/micros/weleth/model"
+	"context"
+
+	"go.temporal.io/sdk/client"
+)
+
+// GetFeeRules lists the fee rules, an empty token or direction matches every rule
+func GetFeeRules(token, direction string) ([]welethModel.FeeRule, error) {
+	wo := client.StartWorkflowOptions{
+		TaskQueue: msweleth.WFQueue,
+	}
+
+	var rules []welethModel.FeeRule
+	ctx := context.Background()
+
+	we, err := tempcli.ExecuteWorkflow(ctx, wo, msweleth.GetFeeRulesWF, token, direction)
+	if err != nil {
+		log.Err(err).Msgf("[Fee logic internal] Failed to execute get fee rules workflow")
+		return nil, err
+	}
+	if err = we.Get(ctx, &rules); err != nil {
+		log.Err(err).Msgf("[Fee logic internal] Failed to get fee rules")
+		return nil, err
+	}
+	log.Info().Msgf("[Fee logic internal] Retrieved fee rules")
+	return rules, nil
+}
+
+func CreateFeeRule(rule welethModel.FeeRule) (int64, error) {
+	if err := rule.Validate(); err != nil {
+		return -1, err
+	}
+	wo := client.StartWorkflowOptions{
+		TaskQueue: msweleth.WFQueue,
+	}
+
+	var id int64
+	ctx := context.Background()
+
+	we, err := tempcli.ExecuteWorkflow(ctx, wo, msweleth.CreateFeeRuleWF, rule)
+	if err != nil {
+		log.Err(err).Msgf("[Fee logic internal] Failed to execute create fee rule workflow")
+		return -1, err
+	}
+	if err = we.Get(ctx, &id); err != nil {
+		log.Err(err).Msgf("[Fee logic internal] Failed to create fee rule")
+		return -1, err
+	}
+	log.Info().Msgf("[Fee logic internal] Created fee rule %d", id)
+	return id, nil
+}
+
+func UpdateFeeRule(rule welethModel.FeeRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	wo := client.StartWorkflowOptions{
+		TaskQueue: msweleth.WFQueue,
+	}
+	ctx := context.Background()
+
+	we, err := tempcli.ExecuteWorkflow(ctx, wo, msweleth.UpdateFeeRuleWF, rule)
+	if err != nil {
+		log.Err(err).Msgf("[Fee logic internal] Failed to execute update fee rule workflow")
+		return err
+	}
+	if err = we.Get(ctx, nil); err != nil {
+		log.Err(err).Msgf("[Fee logic internal] Failed to update fee rule %d", rule.ID)
+		return err
+	}
+	log.Info().Msgf("[Fee logic internal] Updated fee rule %d", rule.ID)
+	return nil
+}
+
+func DeleteFeeRule(id int64) error {
+	wo := client.StartWorkflowOptions{
+		TaskQueue: msweleth.WFQueue,
+	}
+	ctx := context.Background()
+
+	we, err := tempcli.ExecuteWorkflow(ctx, wo, msweleth.DeleteFeeRuleWF, id)
+	if err != nil {
+		log.Err(err).Msgf("[Fee logic internal] Failed to execute delete fee rule workflow")
+		return err
+	}
+	if err = we.Get(ctx, nil); err != nil {
+		log.Err(err).Msgf("[Fee logic internal] Failed to delete fee rule %d", id)
+		return err
+	}
+	log.Info().Msgf("[Fee logic internal] Deleted fee rule %d", id)
+	return nil
+}
@@ -0,0 +1,53 @@
+package healthLogic
+
+import (
+	msweleth "bridge/micros/core/microservices/weleth"
+	welethService "bridge/micros/weleth/temporal"
+	"context"
+
+	"go.temporal.io/sdk/client"
+)
+
+// GetListenerLeases tells which weleth replica leads each chain's listener
+func GetListenerLeases() ([]welethService.ListenerLease, error) {
+	wo := client.StartWorkflowOptions{
+		TaskQueue: msweleth.WFQueue,
+	}
+
+	var leases []welethService.ListenerLease
+	ctx := context.Background()
+
+	we, err := tempcli.ExecuteWorkflow(ctx, wo, msweleth.GetListenerLeasesWF)
+	if err != nil {
+		log.Err(err).Msgf("[Health logic internal] Failed to execute get listener leases workflow")
+		return nil, err
+	}
+	if err = we.Get(ctx, &leases); err != nil {
+		log.Err(err).Msgf("[Health logic internal] Failed to get listener leases")
+		return nil, err
+	}
+	log.Info().Msgf("[Health logic internal] Retrieved listener leases")
+	return leases, nil
+}
+
+// GetDaemonStates tells how the daemons of the weleth replica serving the call are faring
+func GetDaemonStates() ([]welethService.DaemonState, error) {
+	wo := client.StartWorkflowOptions{
+		TaskQueue: msweleth.WFQueue,
+	}
+
+	var states []welethService.DaemonState
+	ctx := context.Background()
+
+	we, err := tempcli.ExecuteWorkflow(ctx, wo, msweleth.GetDaemonStatesWF)
+	if err != nil {
+		log.Err(err).Msgf("[Health logic internal] Failed to execute get daemon states workflow")
+		return nil, err
+	}
+	if err = we.Get(ctx, &states); err != nil {
+		log.Err(err).Msgf("[Health logic internal] Failed to get daemon states")
+		return nil, err
+	}
+	log.Info().Msgf("[Health logic internal] Retrieved daemon states")
+	return states, nil
+}
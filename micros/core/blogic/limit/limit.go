@@ -0,0 +1,54 @@
+package limitLogic
+
+import (
+	msweleth "bridge/micros/core/microservices/weleth"
+	welethModel "bridge/micros/weleth/model"
+	"context"
+
+	"go.temporal.io/sdk/client"
+)
+
+// GetHeldTransfers lists the transfers held by the bridge limits, an empty status lists
+// released ones too
+func GetHeldTransfers(status string, offset, size uint64) ([]welethModel.HeldTransfer, error) {
+	wo := client.StartWorkflowOptions{
+		TaskQueue: msweleth.WFQueue,
+	}
+
+	var ts []welethModel.HeldTransfer
+	ctx := context.Background()
+
+	we, err := tempcli.ExecuteWorkflow(ctx, wo, msweleth.GetHeldTransfersWF, status, offset, size)
+	if err != nil {
+		log.Err(err).Msgf("[Limit logic internal] Failed to execute get held transfers workflow")
+		return nil, err
+	}
+	if err = we.Get(ctx, &ts); err != nil {
+		log.Err(err).Msgf("[Limit logic internal] Failed to get held transfers")
+		return nil, err
+	}
+	log.Info().Msgf("[Limit logic internal] Retrieved held transfers")
+	return ts, nil
+}
+
+// ReleaseHeldTransfer lets a held transfer through the bridge limits
+func ReleaseHeldTransfer(id int64) (welethModel.HeldTransfer, error) {
+	wo := client.StartWorkflowOptions{
+		TaskQueue: msweleth.WFQueue,
+	}
+
+	var t welethModel.HeldTransfer
+	ctx := context.Background()
+
+	we, err := tempcli.ExecuteWorkflow(ctx, wo, msweleth.ReleaseHeldTransferWF, id)
+	if err != nil {
+		log.Err(err).Msgf("[Limit logic internal] Failed to execute release held transfer workflow")
+		return t, err
+	}
+	if err = we.Get(ctx, &t); err != nil {
+		log.Err(err).Msgf("[Limit logic internal] Failed to release held transfer %d", id)
+		return t, err
+	}
+	log.Info().Msgf("[Limit logic internal] Released held transfer %d", id)
+	return t, nil
+}
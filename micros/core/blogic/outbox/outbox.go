@@ -0,0 +1,53 @@
+package outboxLogic
+
+import (
+	msweleth "bridge/micros/core/microservices/weleth"
+	welethModel "bridge/micros/weleth/model"
+	"context"
+
+	"go.temporal.io/sdk/client"
+)
+
+// GetDeadLetters lists the contract events weleth gave up consuming, an empty chain lists
+// both chains
+func GetDeadLetters(chain string, offset, size uint64) ([]welethModel.OutboxEvent, error) {
+	wo := client.StartWorkflowOptions{
+		TaskQueue: msweleth.WFQueue,
+	}
+
+	var es []welethModel.OutboxEvent
+	ctx := context.Background()
+
+	we, err := tempcli.ExecuteWorkflow(ctx, wo, msweleth.GetDeadLettersWF, chain, offset, size)
+	if err != nil {
+		log.Err(err).Msgf("[Outbox logic internal] Failed to execute get dead letters workflow")
+		return nil, err
+	}
+	if err = we.Get(ctx, &es); err != nil {
+		log.Err(err).Msgf("[Outbox logic internal] Failed to get dead letters")
+		return nil, err
+	}
+	log.Info().Msgf("[Outbox logic internal] Retrieved dead letters")
+	return es, nil
+}
+
+// RequeueDeadLetter sends a dead letter back to be consumed again
+func RequeueDeadLetter(id int64) error {
+	wo := client.StartWorkflowOptions{
+		TaskQueue: msweleth.WFQueue,
+	}
+
+	ctx := context.Background()
+
+	we, err := tempcli.ExecuteWorkflow(ctx, wo, msweleth.RequeueDeadLetterWF, id)
+	if err != nil {
+		log.Err(err).Msgf("[Outbox logic internal] Failed to execute requeue dead letter workflow")
+		return err
+	}
+	if err = we.Get(ctx, nil); err != nil {
+		log.Err(err).Msgf("[Outbox logic internal] Failed to requeue dead letter %d", id)
+		return err
+	}
+	log.Info().Msgf("[Outbox logic internal] Requeued dead letter %d", id)
+	return nil
+}
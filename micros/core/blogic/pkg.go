@@ -3,30 +3,45 @@ package blogic
 import (
 	"bridge/libs"
 	ethLogic "bridge/micros/core/blogic/eth"
+	feeLogic "bridge/micros/core/blogic/fee"
+	healthLogic "bridge/micros/core/blogic/health"
+	limitLogic "bridge/micros/core/blogic/limit"
+	outboxLogic "bridge/micros/core/blogic/outbox"
+	reconcileLogic "bridge/micros/core/blogic/reconcile"
+	refundLogic "bridge/micros/core/blogic/refund"
+	tokenLogic "bridge/micros/core/blogic/token"
 	userLogic "bridge/micros/core/blogic/user"
 	welLogic "bridge/micros/core/blogic/wel"
 	"bridge/micros/core/dao"
+	"bridge/micros/core/service/eth/transactor"
 	manager "bridge/service-managers"
-
-	welclient "github.com/Paven-Org/gotron-sdk/pkg/client"
-	"github.com/ethereum/go-ethereum/ethclient"
+	ethListener "bridge/service-managers/listener/eth"
+	welListener "bridge/service-managers/listener/wel"
 
 	"go.temporal.io/sdk/client"
 )
 
 type InitV struct {
-	DAOs         *dao.DAOs
-	RedisManager *manager.RedisManager
-	Mailer       *manager.Mailer
-	Httpcli      *manager.HttpClient
-	TokenService libs.ITokenService
-	TemporalCli  client.Client
-	WelCli       *welclient.GrpcClient
-	EthCli       *ethclient.Client
+	DAOs          *dao.DAOs
+	RedisManager  *manager.RedisManager
+	Mailer        *manager.Mailer
+	Httpcli       *manager.HttpClient
+	TokenService  libs.ITokenService
+	TemporalCli   client.Client
+	WelCli        welListener.IWelClient
+	EthCli        ethListener.IEthClient
+	EthTransactor *transactor.Transactor
 }
 
 func Init(iv InitV) {
 	userLogic.Init(iv.DAOs, iv.RedisManager, iv.TokenService)
-	ethLogic.Init(iv.DAOs, iv.TemporalCli, iv.EthCli)
+	ethLogic.Init(iv.DAOs, iv.TemporalCli, iv.EthCli, iv.EthTransactor)
 	welLogic.Init(iv.DAOs, iv.TemporalCli, iv.WelCli)
+	reconcileLogic.Init(iv.TemporalCli)
+	feeLogic.Init(iv.TemporalCli)
+	limitLogic.Init(iv.TemporalCli)
+	tokenLogic.Init(iv.TemporalCli)
+	outboxLogic.Init(iv.TemporalCli)
+	refundLogic.Init(iv.TemporalCli)
+	healthLogic.Init(iv.TemporalCli)
 }
@@ -0,0 +1,18 @@
+package reconcileLogic
+
+import (
+	"bridge/service-managers/logger"
+
+	"github.com/rs/zerolog"
+	"go.temporal.io/sdk/client"
+)
+
+var (
+	tempcli client.Client
+	log     *zerolog.Logger
+)
+
+func Init(tmpcli client.Client) {
+	log = logger.Get()
+	tempcli = tmpcli
+}
@@ -0,0 +1,55 @@
+package reconcileLogic
+
+import (
+	"bridge/micros/core/model"
+	welethModel "bridge/micros/weleth/model"
+	welethService "bridge/micros/weleth/temporal"
+	"context"
+	"fmt"
+	"time"
+
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/client"
+)
+
+// StartReconciliation dispatches the weleth reconciliation workflow over the given block
+// ranges and returns its ID, a chain is skipped when its range is empty
+func StartReconciliation(ethFrom, ethTo, welFrom, welTo int64) (string, error) {
+	if (ethFrom > ethTo && welFrom > welTo) || ethFrom < 0 || welFrom < 0 {
+		return "", model.ErrReconcileInvalidRange
+	}
+	wo := client.StartWorkflowOptions{
+		ID:        fmt.Sprintf("reconcile-%d", time.Now().UnixNano()),
+		TaskQueue: welethService.WelethServiceQueue,
+	}
+
+	we, err := tempcli.ExecuteWorkflow(context.Background(), wo, welethService.ReconcileWF, ethFrom, ethTo, welFrom, welTo)
+	if err != nil {
+		log.Err(err).Msg("[Reconcile logic internal] Failed to execute reconciliation workflow")
+		return "", err
+	}
+	log.Info().Str("Workflow", we.GetID()).Str("runID=", we.GetRunID()).Msg("[Reconcile logic internal] reconciliation dispatched")
+	return we.GetID(), nil
+}
+
+// GetReconciliationReport returns the report of a finished reconciliation, or
+// ErrReconcileRunning while it's still going
+func GetReconciliationReport(id string) (*welethModel.ReconcileReport, error) {
+	ctx := context.Background()
+	desc, err := tempcli.DescribeWorkflowExecution(ctx, id, "")
+	if err != nil {
+		log.Err(err).Msgf("[Reconcile logic internal] Failed to get reconciliation %s", id)
+		return nil, err
+	}
+	if desc.GetWorkflowExecutionInfo().GetStatus() == enums.WORKFLOW_EXECUTION_STATUS_RUNNING {
+		return nil, model.ErrReconcileRunning
+	}
+
+	var report welethModel.ReconcileReport
+	if err := tempcli.GetWorkflow(ctx, id, "").Get(ctx, &report); err != nil {
+		log.Err(err).Msgf("[Reconcile logic internal] Reconciliation %s failed", id)
+		return nil, err
+	}
+	log.Info().Msgf("[Reconcile logic internal] Retrieved reconciliation report %s", id)
+	return &report, nil
+}
@@ -0,0 +1,75 @@
+package refundLogic
+
+import (
+	msweleth "bridge/micros/core/microservices/weleth"
+	ethService "bridge/micros/core/service/eth"
+	welethModel "bridge/micros/weleth/model"
+	"context"
+
+	"go.temporal.io/sdk/client"
+)
+
+// GetRefunds lists the refunds of deposits to treasury, an empty status lists them all
+func GetRefunds(status string, offset, size uint64) ([]welethModel.Refund, error) {
+	wo := client.StartWorkflowOptions{
+		TaskQueue: msweleth.WFQueue,
+	}
+
+	var rs []welethModel.Refund
+	ctx := context.Background()
+
+	we, err := tempcli.ExecuteWorkflow(ctx, wo, msweleth.GetRefundsWF, status, offset, size)
+	if err != nil {
+		log.Err(err).Msgf("[Refund logic internal] Failed to execute get refunds workflow")
+		return nil, err
+	}
+	if err = we.Get(ctx, &rs); err != nil {
+		log.Err(err).Msgf("[Refund logic internal] Failed to get refunds")
+		return nil, err
+	}
+	log.Info().Msgf("[Refund logic internal] Retrieved refunds")
+	return rs, nil
+}
+
+// ApproveRefund disperses a refund back to the depositor and waits for its tx to be sent
+func ApproveRefund(id int64) (welethModel.Refund, error) {
+	wo := client.StartWorkflowOptions{
+		TaskQueue: ethService.MulsendContractQueue,
+	}
+
+	var r welethModel.Refund
+	ctx := context.Background()
+
+	we, err := tempcli.ExecuteWorkflow(ctx, wo, ethService.RefundWF, id)
+	if err != nil {
+		log.Err(err).Msgf("[Refund logic internal] Failed to execute refund workflow")
+		return r, err
+	}
+	if err = we.Get(ctx, &r); err != nil {
+		log.Err(err).Msgf("[Refund logic internal] Failed to refund %d", id)
+		return r, err
+	}
+	log.Info().Msgf("[Refund logic internal] Refund %d sent in %s", id, r.RefundTxHash)
+	return r, nil
+}
+
+func RejectRefund(id int64, note string) (welethModel.Refund, error) {
+	wo := client.StartWorkflowOptions{
+		TaskQueue: msweleth.WFQueue,
+	}
+
+	var r welethModel.Refund
+	ctx := context.Background()
+
+	we, err := tempcli.ExecuteWorkflow(ctx, wo, msweleth.RejectRefundWF, id, note)
+	if err != nil {
+		log.Err(err).Msgf("[Refund logic internal] Failed to execute reject refund workflow")
+		return r, err
+	}
+	if err = we.Get(ctx, &r); err != nil {
+		log.Err(err).Msgf("[Refund logic internal] Failed to reject refund %d", id)
+		return r, err
+	}
+	log.Info().Msgf("[Refund logic internal] Rejected refund %d", id)
+	return r, nil
+}
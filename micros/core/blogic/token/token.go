@@ -0,0 +1,96 @@
+package tokenLogic
+
+import (
+	msweleth "bridge/micros/core/microservices/weleth"
+	welethModel "bridge/micros/weleth/model"
+	"context"
+
+	"go.temporal.io/sdk/client"
+)
+
+// GetBridgeTokens lists the registered token pairs, disabled ones included
+func GetBridgeTokens() ([]welethModel.BridgeToken, error) {
+	wo := client.StartWorkflowOptions{
+		TaskQueue: msweleth.WFQueue,
+	}
+
+	var tokens []welethModel.BridgeToken
+	ctx := context.Background()
+
+	we, err := tempcli.ExecuteWorkflow(ctx, wo, msweleth.GetBridgeTokensWF)
+	if err != nil {
+		log.Err(err).Msgf("[Token logic internal] Failed to execute get bridge tokens workflow")
+		return nil, err
+	}
+	if err = we.Get(ctx, &tokens); err != nil {
+		log.Err(err).Msgf("[Token logic internal] Failed to get bridge tokens")
+		return nil, err
+	}
+	log.Info().Msgf("[Token logic internal] Retrieved bridge tokens")
+	return tokens, nil
+}
+
+func CreateBridgeToken(token welethModel.BridgeToken) (int64, error) {
+	if err := token.Validate(); err != nil {
+		return -1, err
+	}
+	wo := client.StartWorkflowOptions{
+		TaskQueue: msweleth.WFQueue,
+	}
+
+	var id int64
+	ctx := context.Background()
+
+	we, err := tempcli.ExecuteWorkflow(ctx, wo, msweleth.CreateBridgeTokenWF, token)
+	if err != nil {
+		log.Err(err).Msgf("[Token logic internal] Failed to execute create bridge token workflow")
+		return -1, err
+	}
+	if err = we.Get(ctx, &id); err != nil {
+		log.Err(err).Msgf("[Token logic internal] Failed to create bridge token")
+		return -1, err
+	}
+	log.Info().Msgf("[Token logic internal] Created bridge token %d", id)
+	return id, nil
+}
+
+func UpdateBridgeToken(token welethModel.BridgeToken) error {
+	if err := token.Validate(); err != nil {
+		return err
+	}
+	wo := client.StartWorkflowOptions{
+		TaskQueue: msweleth.WFQueue,
+	}
+	ctx := context.Background()
+
+	we, err := tempcli.ExecuteWorkflow(ctx, wo, msweleth.UpdateBridgeTokenWF, token)
+	if err != nil {
+		log.Err(err).Msgf("[Token logic internal] Failed to execute update bridge token workflow")
+		return err
+	}
+	if err = we.Get(ctx, nil); err != nil {
+		log.Err(err).Msgf("[Token logic internal] Failed to update bridge token %d", token.ID)
+		return err
+	}
+	log.Info().Msgf("[Token logic internal] Updated bridge token %d", token.ID)
+	return nil
+}
+
+func DeleteBridgeToken(id int64) error {
+	wo := client.StartWorkflowOptions{
+		TaskQueue: msweleth.WFQueue,
+	}
+	ctx := context.Background()
+
+	we, err := tempcli.ExecuteWorkflow(ctx, wo, msweleth.DeleteBridgeTokenWF, id)
+	if err != nil {
+		log.Err(err).Msgf("[Token logic internal] Failed to execute delete bridge token workflow")
+		return err
+	}
+	if err = we.Get(ctx, nil); err != nil {
+		log.Err(err).Msgf("[Token logic internal] Failed to delete bridge token %d", id)
+		return err
+	}
+	log.Info().Msgf("[Token logic internal] Deleted bridge token %d", id)
+	return nil
+}
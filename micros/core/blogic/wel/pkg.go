@@ -9,6 +9,7 @@ import (
 	weldao "bridge/micros/core/dao/wel-account"
 	"bridge/micros/core/model"
 	"bridge/micros/core/service/notifier"
+	welListener "bridge/service-managers/listener/wel"
 	"bridge/service-managers/logger"
 	"context"
 	"fmt"
@@ -16,7 +17,6 @@ import (
 	"strings"
 	"sync"
 
-	welclient "github.com/Paven-Org/gotron-sdk/pkg/client"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/rs/zerolog"
 	"go.temporal.io/sdk/client"
@@ -29,7 +29,7 @@ var (
 	tempcli client.Client
 	welInq  *welABI.WelInquirer
 	welExp  *welABI.WelExport
-	welcli  *welclient.GrpcClient
+	welcli  welListener.IWelClient
 	log     *zerolog.Logger
 )
 
@@ -37,7 +37,7 @@ const (
 	defaultFeeLimit = 8000000
 )
 
-func Init(d *dao.DAOs, tmpcli client.Client, wcli *welclient.GrpcClient) {
+func Init(d *dao.DAOs, tmpcli client.Client, wcli welListener.IWelClient) {
 	log = logger.Get()
 	exportContr := config.Get().WelExportContract
 	welDAO = d.Wel
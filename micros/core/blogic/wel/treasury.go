@@ -0,0 +1,38 @@
+package welLogic
+
+import (
+	ethService "bridge/micros/core/service/eth"
+	"context"
+
+	"go.temporal.io/sdk/client"
+)
+
+// WatchWelTx2TreasuryRequest has BE cash the transfer to the Welups treasury out to the eth
+// address to, once the transfer is confirmed
+func WatchWelTx2TreasuryRequest(from, to, treasury, netid, token, amount string) error {
+	wo := client.StartWorkflowOptions{
+		TaskQueue: ethService.MulsendContractQueue,
+	}
+
+	we, err := tempcli.ExecuteWorkflow(context.Background(), wo, ethService.WatchForWelTx2TreasuryWF, from, to, treasury, netid, token, amount)
+	if err != nil {
+		log.Err(err).Msgf("[Wel logic internal] Failed to request BE to watch for transaction to treasury from %s", from)
+		return err
+	}
+	log.Info().Msgf("[Wel logic internal] Request BE to watch for transaction to treasury, WF ID: %s, run ID: %s", we.GetID(), we.GetRunID())
+	return nil
+}
+
+func WatchWelTx2TreasuryRequestByTxhash(txhash string, logIndex int64, to, netid string) error {
+	wo := client.StartWorkflowOptions{
+		TaskQueue: ethService.MulsendContractQueue,
+	}
+
+	we, err := tempcli.ExecuteWorkflow(context.Background(), wo, ethService.WatchForWelTx2TreasuryByTxHashWF, txhash, to, netid, logIndex)
+	if err != nil {
+		log.Err(err).Msgf("[Wel logic internal] Failed to request BE to watch for transaction to treasury with txhash %s", txhash)
+		return err
+	}
+	log.Info().Msgf("[Wel logic internal] Request BE to watch for transaction to treasury, WF ID: %s, run ID: %s", we.GetID(), we.GetRunID())
+	return nil
+}
@@ -309,7 +309,7 @@ func UnsetCurrentAuthenticator() error {
 }
 
 // Claim cashout = get original tokens back from another chain's equivalent wrapped tokens
-func ClaimEth2WelCashout(cashoutTxId string, userAddr string, contractVersion string) (outTokenAddr string, amount string, requestID []byte, signature []byte, claimExpireTime int64, err error) {
+func ClaimEth2WelCashout(cashoutTxId string, logIndex int64, userAddr string, contractVersion string) (outTokenAddr string, amount string, requestID []byte, signature []byte, claimExpireTime int64, err error) {
 	// Check receiving account and activate if needed
 	activators, err := GetWelAccountsWithRole("operator", 0, 1000)
 	if err != nil {
@@ -335,7 +335,7 @@ func ClaimEth2WelCashout(cashoutTxId string, userAddr string, contractVersion st
 	wo := client.StartWorkflowOptions{
 		TaskQueue: msweleth.WFQueue,
 	}
-	we, err := tempcli.ExecuteWorkflow(ctx, wo, msweleth.CreateE2WCashoutClaimRequestWF, cashoutTxId, userAddr)
+	we, err := tempcli.ExecuteWorkflow(ctx, wo, msweleth.CreateE2WCashoutClaimRequestWF, cashoutTxId, userAddr, logIndex)
 	if err != nil {
 		log.Err(err).Msg("[Wel logic internal] Unable to call CreateE2WCashoutClaimRequest workflow")
 		return
@@ -347,7 +347,7 @@ func ClaimEth2WelCashout(cashoutTxId string, userAddr string, contractVersion st
 		log.Err(err).Msg("[Wel logic internal] CreateE2WCashoutClaimRequest workflow failed")
 		return
 	}
-	tempcli.ExecuteWorkflow(ctx, wo, msweleth.WaitForPendingE2WCashoutClaimRequestWF, cashoutTxId)
+	tempcli.ExecuteWorkflow(ctx, wo, msweleth.WaitForPendingE2WCashoutClaimRequestWF, cashoutTxId, logIndex)
 	claimExpireTime = time.Now().Add(3 * time.Minute).Unix()
 
 	// process
@@ -381,7 +381,8 @@ func ClaimEth2WelCashout(cashoutTxId string, userAddr string, contractVersion st
 	outTokenAddr = tx.WelTokenAddr
 	_token, _ := libs.B58toStdHex(outTokenAddr)
 	toAddress, _ := libs.B58toStdHex(tx.WelWalletAddr)
-	amount = tx.Amount
+	// claimed in wel token units
+	amount = tx.DestAmount
 
 	_requestID := &big.Int{}
 	_requestID.SetString(tx.ReqID, 10)
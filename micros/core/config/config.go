@@ -108,8 +108,19 @@ func parseEnv() Env {
 
 		EthereumConfig: common.EtherumConfig{
 			BlockchainRPC: common.WithDefault("ETH_BLOCKCHAIN_RPC", "https://eth-goerli.alchemyapi.io/v2/Ls_mnBZPnKr6Ndt5bYUzZB034n6lM23Z"),
+			FallbackRPCs:  common.WithDefault("ETH_FALLBACK_RPCS", []string{}),
+			WebsocketRPC:  common.WithDefault("ETH_WEBSOCKET_RPC", ""),
 			BlockTime:     common.WithDefault("ETH_BLOCK_TIME", uint64(14)),
 			BlockOffSet:   common.WithDefault("ETH_BLOCK_OFFSET", int64(5)),
+			Gas: common.EthGasConf{
+				MaxFeePerGasGwei:   common.WithDefault("ETH_MAX_FEE_PER_GAS_GWEI", uint64(300)),
+				MaxPriorityFeeGwei: common.WithDefault("ETH_MAX_PRIORITY_FEE_GWEI", uint64(3)),
+				GasLimitMultiplier: common.WithDefault("ETH_GAS_LIMIT_MULTIPLIER", 1.2),
+				TxFeeCeilingGwei:   common.WithDefault("ETH_TX_FEE_CEILING_GWEI", uint64(50000000)),
+
+				StuckTxTimeout:         common.WithDefault("ETH_STUCK_TX_TIMEOUT", 10*time.Minute),
+				ReplacementBumpPercent: common.WithDefault("ETH_REPLACEMENT_BUMP_PERCENT", uint64(12)),
+			},
 		},
 		EthGovContract:     common.WithDefault("ETH_GOV_CONTRACT_ADDRESS", "0x45863E5eF99b33AFc7c3B47C77da50Ccddda5EF3"),
 		EthImportContract:  common.WithDefault("ETH_IMPORT_CONTRACT_ADDRESS", "0x47469dd8bb847df5bAe03A9E3644C4db9c7d779B"),
@@ -120,6 +131,7 @@ func parseEnv() Env {
 			BlockTime:     common.WithDefault("WEL_BLOCK_TIME", uint64(3)),
 			ClientTimeout: common.WithDefault("WEL_CLIENT_TIMEOUT", int64(5)),
 			BlockOffSet:   common.WithDefault("WEL_BLOCK_OFFSET", int64(20)),
+			Confirmations: common.WithDefault("WEL_CONFIRMATIONS", uint64(20)),
 		},
 		WelGovContract:    common.WithDefault("WEL_GOV_CONTRACT_ADDRESS", "WE8RFVk1GA5NhK8yLxHWkpuP1E5UVqX9tQ"),
 		WelExportContract: common.WithDefault("WEL_EXPORT_CONTRACT_ADDRESS", "WUbnXM9M4QYEkksG3ADmSan2kY5xiHTr1E"),
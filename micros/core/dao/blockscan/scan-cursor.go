@@ -0,0 +1,45 @@
+package blockscan
+
+import (
+	"bridge/common/consts"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// sort of a locator for DAOs
+type ScanCursorDAO struct {
+	db *sqlx.DB
+}
+
+func (c *ScanCursorDAO) GetCursors(chain string) ([]consts.ScanCursor, error) {
+	var res = []consts.ScanCursor{}
+	err := c.db.Select(&res, "SELECT chain, consumer, last_scan_block FROM scan_cursors WHERE chain = $1 ORDER BY consumer", chain)
+	return res, err
+}
+
+func (c *ScanCursorDAO) CreateCursor(cursor *consts.ScanCursor) error {
+	_, err := c.db.NamedExec(
+		`INSERT INTO scan_cursors(chain, consumer, last_scan_block) VALUES (:chain, :consumer, :last_scan_block)
+			ON CONFLICT (chain, consumer) DO NOTHING`, cursor)
+	return err
+}
+
+func (c *ScanCursorDAO) UpdateCursor(cursor *consts.ScanCursor) error {
+	_, err := c.db.NamedExec(
+		`UPDATE scan_cursors SET last_scan_block = :last_scan_block, updated_at = NOW()
+			WHERE chain = :chain AND consumer = :consumer`, cursor)
+	return err
+}
+
+func (c *ScanCursorDAO) RollbackCursors(chain string, block int64) error {
+	_, err := c.db.Exec(
+		"UPDATE scan_cursors SET last_scan_block = $2, updated_at = NOW() WHERE chain = $1 AND last_scan_block > $2",
+		chain, block)
+	return err
+}
+
+func MkScanCursorDao(db *sqlx.DB) *ScanCursorDAO {
+	return &ScanCursorDAO{
+		db: db,
+	}
+}
@@ -0,0 +1,90 @@
+package ethNonceDAO
+
+import (
+	"bridge/service-managers/logger"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IEthNonceDAO interface {
+	LockNonce(address string) (*NonceLock, error)
+	GetNonceAddresses() ([]string, error)
+}
+
+// NonceLock holds the nonce row of an address, other senders from the address wait until
+// it's committed, forgotten or released
+type NonceLock struct {
+	tx      *sqlx.Tx
+	Address string
+	Next    sql.NullInt64
+	Age     time.Duration // since the next nonce was stored
+}
+
+// Commit stores the next nonce and releases the lock
+func (l *NonceLock) Commit(next uint64) error {
+	if _, err := l.tx.Exec("UPDATE eth_nonces SET next_nonce = $2, updated_at = NOW() WHERE address = $1", l.Address, next); err != nil {
+		logger.Get().Err(err).Msgf("Error while storing next nonce of %s", l.Address)
+		l.tx.Rollback()
+		return err
+	}
+	return l.tx.Commit()
+}
+
+// Forget releases the lock, the next nonce being taken from the chain again
+func (l *NonceLock) Forget() error {
+	if _, err := l.tx.Exec("UPDATE eth_nonces SET next_nonce = NULL, updated_at = NOW() WHERE address = $1", l.Address); err != nil {
+		logger.Get().Err(err).Msgf("Error while forgetting next nonce of %s", l.Address)
+		l.tx.Rollback()
+		return err
+	}
+	return l.tx.Commit()
+}
+
+// Release releases the lock, leaving the next nonce as it was
+func (l *NonceLock) Release() error {
+	return l.tx.Rollback()
+}
+
+type ethNonceDAO struct {
+	db *sqlx.DB
+}
+
+func MkEthNonceDAO(db *sqlx.DB) IEthNonceDAO {
+	return &ethNonceDAO{db: db}
+}
+
+func (dao *ethNonceDAO) LockNonce(address string) (*NonceLock, error) {
+	log := logger.Get()
+	tx, err := dao.db.Beginx()
+	if err != nil {
+		log.Err(err).Msg("Can't start transaction")
+		return nil, err
+	}
+
+	if _, err := tx.Exec("INSERT INTO eth_nonces(address) VALUES ($1) ON CONFLICT (address) DO NOTHING", address); err != nil {
+		log.Err(err).Msgf("Error while adding nonce of %s", address)
+		tx.Rollback()
+		return nil, err
+	}
+	l := &NonceLock{tx: tx, Address: address}
+	var age float64
+	err = tx.QueryRowx("SELECT next_nonce, EXTRACT(EPOCH FROM NOW() - updated_at) FROM eth_nonces WHERE address = $1 FOR UPDATE", address).Scan(&l.Next, &age)
+	if err != nil {
+		log.Err(err).Msgf("Error while locking nonce of %s", address)
+		tx.Rollback()
+		return nil, err
+	}
+	l.Age = time.Duration(age * float64(time.Second))
+	return l, nil
+}
+
+func (dao *ethNonceDAO) GetNonceAddresses() ([]string, error) {
+	addresses := []string{}
+	if err := dao.db.Select(&addresses, "SELECT address FROM eth_nonces ORDER BY address"); err != nil {
+		logger.Get().Err(err).Msg("Error while querying for nonce addresses")
+		return nil, err
+	}
+	return addresses, nil
+}
@@ -0,0 +1,138 @@
+package ethTxDAO
+
+import (
+	"bridge/micros/core/model"
+	"bridge/service-managers/logger"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IEthTxDAO interface {
+	TrackEthTx(t *model.EthOutgoingTx) error
+	GetEthTx(id int64) (*model.EthOutgoingTx, error)
+	GetEthTxs(status string, offset uint, size uint) ([]model.EthOutgoingTx, error)
+	ReplaceEthTx(id int64, txHash string, payload bool, rawTx string) (*model.EthOutgoingTx, error)
+	SettleEthTx(id int64, status string, minedTxHash string) error
+	RequestCancel(id int64) (*model.EthOutgoingTx, error)
+}
+
+type ethTxDAO struct {
+	db *sqlx.DB
+}
+
+func MkEthTxDAO(db *sqlx.DB) IEthTxDAO {
+	return &ethTxDAO{db: db}
+}
+
+// TrackEthTx starts watching a sent transaction, a nonce used again after its previous
+// transaction got dropped is watched anew
+func (dao *ethTxDAO) TrackEthTx(t *model.EthOutgoingTx) error {
+	err := dao.db.Get(t,
+		`INSERT INTO eth_outgoing_txs(from_address, nonce, tx_hash, tx_hashes, raw_tx)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (from_address, nonce) DO UPDATE SET
+				tx_hash = EXCLUDED.tx_hash,
+				tx_hashes = EXCLUDED.tx_hashes,
+				raw_tx = EXCLUDED.raw_tx,
+				replacements = 0,
+				cancel_requested = FALSE,
+				mined_tx_hash = '',
+				status = 'pending',
+				sent_at = NOW(),
+				updated_at = NOW()
+			RETURNING *`,
+		t.From, t.Nonce, t.TxHash, t.TxHashes, t.RawTx)
+	if err != nil {
+		logger.Get().Err(err).Msgf("Error while tracking tx %s", t.TxHash)
+	}
+	return err
+}
+
+func (dao *ethTxDAO) GetEthTx(id int64) (*model.EthOutgoingTx, error) {
+	var t model.EthOutgoingTx
+	err := dao.db.Get(&t, "SELECT * FROM eth_outgoing_txs WHERE id = $1", id)
+	if err == sql.ErrNoRows {
+		return nil, model.ErrEthTxNotFound
+	}
+	if err != nil {
+		logger.Get().Err(err).Msgf("Error while querying for outgoing tx %d", id)
+		return nil, err
+	}
+	return &t, nil
+}
+
+// GetEthTxs lists outgoing transactions, of every status when status is empty
+func (dao *ethTxDAO) GetEthTxs(status string, offset uint, size uint) ([]model.EthOutgoingTx, error) {
+	ts := []model.EthOutgoingTx{}
+	err := dao.db.Select(&ts,
+		`SELECT * FROM eth_outgoing_txs WHERE ($1 = '' OR status = $1)
+			ORDER BY id DESC OFFSET $2 LIMIT $3`,
+		status, offset, size)
+	if err != nil {
+		logger.Get().Err(err).Msg("Error while querying for outgoing txs")
+		return nil, err
+	}
+	return ts, nil
+}
+
+// ReplaceEthTx records a new broadcast of a pending transaction, payload telling whether it
+// carries the payload or cancels it
+func (dao *ethTxDAO) ReplaceEthTx(id int64, txHash string, payload bool, rawTx string) (*model.EthOutgoingTx, error) {
+	var t model.EthOutgoingTx
+	err := dao.db.Get(&t,
+		`UPDATE eth_outgoing_txs SET
+				tx_hash = CASE WHEN $3 THEN $2 ELSE tx_hash END,
+				tx_hashes = array_append(tx_hashes, $2),
+				raw_tx = $4,
+				replacements = replacements + 1,
+				sent_at = NOW(),
+				updated_at = NOW()
+			WHERE id = $1 AND status = 'pending'
+			RETURNING *`,
+		id, txHash, payload, rawTx)
+	if err == sql.ErrNoRows {
+		return nil, model.ErrEthTxSettled
+	}
+	if err != nil {
+		logger.Get().Err(err).Msgf("Error while replacing outgoing tx %d by %s", id, txHash)
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (dao *ethTxDAO) SettleEthTx(id int64, status string, minedTxHash string) error {
+	res, err := dao.db.Exec(
+		`UPDATE eth_outgoing_txs SET status = $2, mined_tx_hash = $3, updated_at = NOW()
+			WHERE id = $1 AND status = 'pending'`,
+		id, status, minedTxHash)
+	if err != nil {
+		logger.Get().Err(err).Msgf("Error while settling outgoing tx %d", id)
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return model.ErrEthTxSettled
+	}
+	return nil
+}
+
+// RequestCancel flags a pending transaction so that its next broadcast cancels it
+func (dao *ethTxDAO) RequestCancel(id int64) (*model.EthOutgoingTx, error) {
+	var t model.EthOutgoingTx
+	err := dao.db.Get(&t,
+		`UPDATE eth_outgoing_txs SET cancel_requested = TRUE, updated_at = NOW()
+			WHERE id = $1 AND status = 'pending'
+			RETURNING *`,
+		id)
+	if err == sql.ErrNoRows {
+		if _, err := dao.GetEthTx(id); err != nil {
+			return nil, err
+		}
+		return nil, model.ErrEthTxSettled
+	}
+	if err != nil {
+		logger.Get().Err(err).Msgf("Error while requesting cancellation of outgoing tx %d", id)
+		return nil, err
+	}
+	return &t, nil
+}
@@ -3,6 +3,8 @@ package dao
 import (
 	"bridge/micros/core/dao/blockscan"
 	ethDAO "bridge/micros/core/dao/eth-account"
+	ethNonceDAO "bridge/micros/core/dao/eth-nonce"
+	ethTxDAO "bridge/micros/core/dao/eth-tx"
 	userDAO "bridge/micros/core/dao/user"
 	welDAO "bridge/micros/core/dao/wel-account"
 
@@ -13,17 +15,23 @@ import (
 type DAOs struct {
 	User        userDAO.IUserDAO
 	Eth         ethDAO.IEthDAO
+	EthTx       ethTxDAO.IEthTxDAO
+	EthNonce    ethNonceDAO.IEthNonceDAO
 	Wel         welDAO.IWelDAO
 	EthBlockDAO *blockscan.EthSysDAO
 	WelBlockDAO *blockscan.WelSysDAO
+	ScanCursors *blockscan.ScanCursorDAO
 }
 
 func MkDAOs(db *sqlx.DB) *DAOs {
 	return &DAOs{
 		User:        userDAO.MkUserDAO(db),
 		Eth:         ethDAO.MkEthDAO(db),
+		EthTx:       ethTxDAO.MkEthTxDAO(db),
+		EthNonce:    ethNonceDAO.MkEthNonceDAO(db),
 		Wel:         welDAO.MkWelDAO(db),
 		EthBlockDAO: blockscan.MkEthSysDao(db),
 		WelBlockDAO: blockscan.MkWelSysDao(db),
+		ScanCursors: blockscan.MkScanCursorDao(db),
 	}
 }
@@ -0,0 +1,84 @@
+package ethTxRouter
+
+import (
+	ethLogic "bridge/micros/core/blogic/eth"
+	"bridge/micros/core/model"
+	log "bridge/service-managers/logger"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+var logger *zerolog.Logger
+
+func Config(router gin.IRouter, mw ...gin.HandlerFunc) {
+	initialize()
+
+	gr := router.Group("/m/eth-txs", mw... /*,middlewares.Author*/)
+	gr.GET("", getEthTxs)
+	gr.POST("/:id/cancel", cancelEthTx)
+}
+
+func initialize() {
+	logger = log.Get()
+	logger.Info().Msg("outgoing ethereum tx handlers initialized")
+}
+
+func getEthTxs(c *gin.Context) {
+	// request
+	type ethTxQuery struct {
+		Status string `form:"status"`
+		Offset uint   `form:"offset"`
+		Size   uint   `form:"size"`
+	}
+	var q ethTxQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		logger.Err(err).Msgf("[get eth txs handler] Invalid request query")
+		c.JSON(http.StatusBadRequest, "Invalid request query")
+		return
+	}
+	if q.Size == 0 {
+		q.Size = 15 // default
+	}
+
+	// process
+	ts, err := ethLogic.GetEthTxs(q.Status, q.Offset, q.Size)
+	if err != nil {
+		logger.Err(err).Msgf("[get eth txs handler] Unable to get outgoing ethereum txs")
+		c.JSON(http.StatusInternalServerError, "Unable to get outgoing ethereum txs")
+		return
+	}
+
+	// response
+	logger.Info().Msgf("[get eth txs handler] Get outgoing ethereum txs successfully")
+	c.JSON(http.StatusOK, ts)
+}
+
+func cancelEthTx(c *gin.Context) {
+	// request
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid tx ID")
+		return
+	}
+
+	// process
+	t, err := ethLogic.CancelEthTx(id)
+	if err != nil {
+		logger.Err(err).Msgf("[cancel eth tx handler] Unable to cancel tx %d", id)
+		status := http.StatusInternalServerError
+		if err == model.ErrEthTxNotFound {
+			status = http.StatusNotFound
+		} else if err == model.ErrEthTxSettled {
+			status = http.StatusConflict
+		}
+		c.JSON(status, "Unable to cancel tx")
+		return
+	}
+
+	// response
+	logger.Info().Msgf("[cancel eth tx handler] Cancellation of tx %d requested", id)
+	c.JSON(http.StatusOK, t)
+}
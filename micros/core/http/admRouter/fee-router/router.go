@@ -0,0 +1,125 @@
+package feeRouter
+
+import (
+	feeLogic "bridge/micros/core/blogic/fee"
+	welethModel "bridge/micros/weleth/model"
+	log "bridge/service-managers/logger"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+var logger *zerolog.Logger
+
+func Config(router gin.IRouter, mw ...gin.HandlerFunc) {
+	initialize()
+
+	gr := router.Group("/m/fee", mw... /*,middlewares.Author*/)
+	gr.GET("/rules", getFeeRules)
+	gr.POST("/rules", createFeeRule)
+	gr.PUT("/rules/:id", updateFeeRule)
+	gr.DELETE("/rules/:id", deleteFeeRule)
+}
+
+func initialize() {
+	logger = log.Get()
+	logger.Info().Msg("fee schedule handlers initialized")
+}
+
+func getFeeRules(c *gin.Context) {
+	// request
+	token := c.Query("token")
+	direction := c.Query("direction")
+
+	// process
+	rules, err := feeLogic.GetFeeRules(token, direction)
+	if err != nil {
+		logger.Err(err).Msgf("[get fee rules handler] Unable to get fee rules")
+		c.JSON(http.StatusInternalServerError, "Unable to get fee rules")
+		return
+	}
+
+	// response
+	logger.Info().Msgf("[get fee rules handler] Get fee rules successfully")
+	c.JSON(http.StatusOK, rules)
+}
+
+func createFeeRule(c *gin.Context) {
+	// request
+	var rule welethModel.FeeRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		logger.Err(err).Msgf("[create fee rule handler] Invalid request payload")
+		c.JSON(http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// process
+	id, err := feeLogic.CreateFeeRule(rule)
+	if err == welethModel.ErrInvalidFeeRule {
+		c.JSON(http.StatusBadRequest, "Invalid fee rule")
+		return
+	}
+	if err != nil {
+		logger.Err(err).Msgf("[create fee rule handler] Unable to create fee rule")
+		c.JSON(http.StatusInternalServerError, "Unable to create fee rule")
+		return
+	}
+
+	// response
+	logger.Info().Msgf("[create fee rule handler] Fee rule %d created", id)
+	c.JSON(http.StatusOK, gin.H{"id": id})
+}
+
+func updateFeeRule(c *gin.Context) {
+	// request
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid fee rule ID")
+		return
+	}
+	var rule welethModel.FeeRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		logger.Err(err).Msgf("[update fee rule handler] Invalid request payload")
+		c.JSON(http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	rule.ID = id
+
+	// process
+	err = feeLogic.UpdateFeeRule(rule)
+	if err == welethModel.ErrInvalidFeeRule {
+		c.JSON(http.StatusBadRequest, "Invalid fee rule")
+		return
+	}
+	if err != nil {
+		logger.Err(err).Msgf("[update fee rule handler] Unable to update fee rule %d", id)
+		c.JSON(http.StatusInternalServerError, "Unable to update fee rule")
+		return
+	}
+
+	// response
+	logger.Info().Msgf("[update fee rule handler] Fee rule %d updated", id)
+	c.JSON(http.StatusOK, "Fee rule updated")
+}
+
+func deleteFeeRule(c *gin.Context) {
+	// request
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid fee rule ID")
+		return
+	}
+
+	// process
+	if err := feeLogic.DeleteFeeRule(id); err != nil {
+		logger.Err(err).Msgf("[delete fee rule handler] Unable to delete fee rule %d", id)
+		c.JSON(http.StatusInternalServerError, "Unable to delete fee rule")
+		return
+	}
+
+	// response
+	logger.Info().Msgf("[delete fee rule handler] Fee rule %d deleted", id)
+	c.JSON(http.StatusOK, "Fee rule deleted")
+}
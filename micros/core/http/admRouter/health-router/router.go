@@ -0,0 +1,54 @@
+package healthRouter
+
+import (
+	healthLogic "bridge/micros/core/blogic/health"
+	"bridge/service-managers/daemon"
+	log "bridge/service-managers/logger"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+var logger *zerolog.Logger
+
+func Config(router gin.IRouter, mw ...gin.HandlerFunc) {
+	initialize()
+
+	gr := router.Group("/m/health", mw... /*,middlewares.Author*/)
+	gr.GET("", getHealth)
+}
+
+func initialize() {
+	logger = log.Get()
+	logger.Info().Msg("health handlers initialized")
+}
+
+// getHealth reports the weleth replica leading each chain's listener, and how the daemons
+// of core and weleth are faring
+func getHealth(c *gin.Context) {
+	// process
+	leases, err := healthLogic.GetListenerLeases()
+	if err != nil {
+		logger.Err(err).Msgf("[get health handler] Unable to get listener leases")
+		c.JSON(http.StatusInternalServerError, "Unable to get listener leases")
+		return
+	}
+
+	welethDaemons, err := healthLogic.GetDaemonStates()
+	if err != nil {
+		logger.Err(err).Msgf("[get health handler] Unable to get weleth daemon states")
+		c.JSON(http.StatusInternalServerError, "Unable to get weleth daemon states")
+		return
+	}
+
+	// response
+	logger.Info().Msgf("[get health handler] Get health successfully")
+	c.JSON(http.StatusOK, gin.H{
+		"listeners": leases,
+		"daemons": gin.H{
+			"core":   daemon.States(),
+			"weleth": welethDaemons,
+		},
+	})
+}
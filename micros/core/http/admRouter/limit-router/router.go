@@ -0,0 +1,74 @@
+package limitRouter
+
+import (
+	limitLogic "bridge/micros/core/blogic/limit"
+	log "bridge/service-managers/logger"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+var logger *zerolog.Logger
+
+func Config(router gin.IRouter, mw ...gin.HandlerFunc) {
+	initialize()
+
+	gr := router.Group("/m/limits", mw... /*,middlewares.Author*/)
+	gr.GET("/held", getHeldTransfers)
+	gr.POST("/held/:id/release", releaseHeldTransfer)
+}
+
+func initialize() {
+	logger = log.Get()
+	logger.Info().Msg("bridge limits handlers initialized")
+}
+
+func getHeldTransfers(c *gin.Context) {
+	// request
+	type heldQuery struct {
+		Status string `form:"status"`
+		Offset uint64 `form:"offset"`
+		Size   uint64 `form:"size"`
+	}
+	q := heldQuery{Status: "held"}
+	if err := c.ShouldBindQuery(&q); err != nil {
+		logger.Err(err).Msgf("[get held transfers handler] Invalid request query")
+		c.JSON(http.StatusBadRequest, "Invalid request query")
+		return
+	}
+
+	// process
+	ts, err := limitLogic.GetHeldTransfers(q.Status, q.Offset, q.Size)
+	if err != nil {
+		logger.Err(err).Msgf("[get held transfers handler] Unable to get held transfers")
+		c.JSON(http.StatusInternalServerError, "Unable to get held transfers")
+		return
+	}
+
+	// response
+	logger.Info().Msgf("[get held transfers handler] Get held transfers successfully")
+	c.JSON(http.StatusOK, ts)
+}
+
+func releaseHeldTransfer(c *gin.Context) {
+	// request
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid held transfer ID")
+		return
+	}
+
+	// process
+	t, err := limitLogic.ReleaseHeldTransfer(id)
+	if err != nil {
+		logger.Err(err).Msgf("[release held transfer handler] Unable to release held transfer %d", id)
+		c.JSON(http.StatusInternalServerError, "Unable to release held transfer")
+		return
+	}
+
+	// response
+	logger.Info().Msgf("[release held transfer handler] Held transfer %d released", id)
+	c.JSON(http.StatusOK, t)
+}
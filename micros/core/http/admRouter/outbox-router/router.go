@@ -0,0 +1,73 @@
+package outboxRouter
+
+import (
+	outboxLogic "bridge/micros/core/blogic/outbox"
+	log "bridge/service-managers/logger"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+var logger *zerolog.Logger
+
+func Config(router gin.IRouter, mw ...gin.HandlerFunc) {
+	initialize()
+
+	gr := router.Group("/m/outbox", mw... /*,middlewares.Author*/)
+	gr.GET("/dead", getDeadLetters)
+	gr.POST("/dead/:id/requeue", requeueDeadLetter)
+}
+
+func initialize() {
+	logger = log.Get()
+	logger.Info().Msg("event outbox handlers initialized")
+}
+
+func getDeadLetters(c *gin.Context) {
+	// request
+	type deadQuery struct {
+		Chain  string `form:"chain"`
+		Offset uint64 `form:"offset"`
+		Size   uint64 `form:"size"`
+	}
+	var q deadQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		logger.Err(err).Msgf("[get dead letters handler] Invalid request query")
+		c.JSON(http.StatusBadRequest, "Invalid request query")
+		return
+	}
+
+	// process
+	es, err := outboxLogic.GetDeadLetters(q.Chain, q.Offset, q.Size)
+	if err != nil {
+		logger.Err(err).Msgf("[get dead letters handler] Unable to get dead letters")
+		c.JSON(http.StatusInternalServerError, "Unable to get dead letters")
+		return
+	}
+
+	// response
+	logger.Info().Msgf("[get dead letters handler] Get dead letters successfully")
+	c.JSON(http.StatusOK, es)
+}
+
+func requeueDeadLetter(c *gin.Context) {
+	// request
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid dead letter ID")
+		return
+	}
+
+	// process
+	if err := outboxLogic.RequeueDeadLetter(id); err != nil {
+		logger.Err(err).Msgf("[requeue dead letter handler] Unable to requeue dead letter %d", id)
+		c.JSON(http.StatusInternalServerError, "Unable to requeue dead letter")
+		return
+	}
+
+	// response
+	logger.Info().Msgf("[requeue dead letter handler] Dead letter %d requeued", id)
+	c.JSON(http.StatusOK, "Dead letter requeued")
+}
@@ -0,0 +1,80 @@
+package reconcileRouter
+
+import (
+	reconcileLogic "bridge/micros/core/blogic/reconcile"
+	"bridge/micros/core/model"
+	log "bridge/service-managers/logger"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+var logger *zerolog.Logger
+
+func Config(router gin.IRouter, mw ...gin.HandlerFunc) {
+	initialize()
+
+	gr := router.Group("/m/reconcile", mw... /*,middlewares.Author*/)
+	gr.POST("/start", startReconciliation)
+	gr.GET("/report/:id", getReconciliationReport)
+}
+
+func initialize() {
+	logger = log.Get()
+	logger.Info().Msg("reconciliation handlers initialized")
+}
+
+func startReconciliation(c *gin.Context) {
+	// request
+	type reconcileReq struct {
+		EthFromBlock int64 `json:"eth_from_block"`
+		EthToBlock   int64 `json:"eth_to_block"`
+		WelFromBlock int64 `json:"wel_from_block"`
+		WelToBlock   int64 `json:"wel_to_block"`
+	}
+	// a chain whose range is left out isn't reconciled
+	req := reconcileReq{EthFromBlock: 1, WelFromBlock: 1}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Err(err).Msgf("[start reconciliation handler] Invalid request payload")
+		c.JSON(http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// process
+	id, err := reconcileLogic.StartReconciliation(req.EthFromBlock, req.EthToBlock, req.WelFromBlock, req.WelToBlock)
+	if err != nil {
+		logger.Err(err).Msgf("[start reconciliation handler] Unable to start reconciliation")
+		status := http.StatusInternalServerError
+		if err == model.ErrReconcileInvalidRange {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, "Unable to start reconciliation")
+		return
+	}
+
+	// response
+	logger.Info().Msgf("[start reconciliation handler] Reconciliation %s started", id)
+	c.JSON(http.StatusOK, gin.H{"id": id})
+}
+
+func getReconciliationReport(c *gin.Context) {
+	// request
+	id := c.Param("id")
+
+	// process
+	report, err := reconcileLogic.GetReconciliationReport(id)
+	if err == model.ErrReconcileRunning {
+		c.JSON(http.StatusAccepted, "Reconciliation still running")
+		return
+	}
+	if err != nil {
+		logger.Err(err).Msgf("[get reconciliation report handler] Unable to get reconciliation report %s", id)
+		c.JSON(http.StatusInternalServerError, "Unable to get reconciliation report "+id)
+		return
+	}
+
+	// response
+	logger.Info().Msgf("[get reconciliation report handler] Get reconciliation report %s successfully", id)
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,105 @@
+package refundRouter
+
+import (
+	refundLogic "bridge/micros/core/blogic/refund"
+	log "bridge/service-managers/logger"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+var logger *zerolog.Logger
+
+func Config(router gin.IRouter, mw ...gin.HandlerFunc) {
+	initialize()
+
+	gr := router.Group("/m/refunds", mw... /*,middlewares.Author*/)
+	gr.GET("", getRefunds)
+	gr.POST("/:id/approve", approveRefund)
+	gr.POST("/:id/reject", rejectRefund)
+}
+
+func initialize() {
+	logger = log.Get()
+	logger.Info().Msg("refund handlers initialized")
+}
+
+func getRefunds(c *gin.Context) {
+	// request
+	type refundQuery struct {
+		Status string `form:"status"`
+		Offset uint64 `form:"offset"`
+		Size   uint64 `form:"size"`
+	}
+	var q refundQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		logger.Err(err).Msgf("[get refunds handler] Invalid request query")
+		c.JSON(http.StatusBadRequest, "Invalid request query")
+		return
+	}
+
+	// process
+	rs, err := refundLogic.GetRefunds(q.Status, q.Offset, q.Size)
+	if err != nil {
+		logger.Err(err).Msgf("[get refunds handler] Unable to get refunds")
+		c.JSON(http.StatusInternalServerError, "Unable to get refunds")
+		return
+	}
+
+	// response
+	logger.Info().Msgf("[get refunds handler] Get refunds successfully")
+	c.JSON(http.StatusOK, rs)
+}
+
+func approveRefund(c *gin.Context) {
+	// request
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid refund ID")
+		return
+	}
+
+	// process
+	r, err := refundLogic.ApproveRefund(id)
+	if err != nil {
+		logger.Err(err).Msgf("[approve refund handler] Unable to refund %d", id)
+		c.JSON(http.StatusInternalServerError, "Unable to refund")
+		return
+	}
+
+	// response
+	logger.Info().Msgf("[approve refund handler] Refund %d sent", id)
+	c.JSON(http.StatusOK, r)
+}
+
+func rejectRefund(c *gin.Context) {
+	// request
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid refund ID")
+		return
+	}
+	type rejectBody struct {
+		Note string `json:"note"`
+	}
+	var body rejectBody
+	if err := c.ShouldBindJSON(&body); err != nil && c.Request.ContentLength > 0 {
+		logger.Err(err).Msgf("[reject refund handler] Invalid request body")
+		c.JSON(http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// process
+	r, err := refundLogic.RejectRefund(id, body.Note)
+	if err != nil {
+		logger.Err(err).Msgf("[reject refund handler] Unable to reject refund %d", id)
+		c.JSON(http.StatusInternalServerError, "Unable to reject refund")
+		return
+	}
+
+	// response
+	logger.Info().Msgf("[reject refund handler] Refund %d rejected", id)
+	c.JSON(http.StatusOK, r)
+}
@@ -2,7 +2,15 @@ package admRouter
 
 import (
 	ethRouter "bridge/micros/core/http/admRouter/eth-router"
+	ethTxRouter "bridge/micros/core/http/admRouter/eth-tx-router"
+	feeRouter "bridge/micros/core/http/admRouter/fee-router"
+	healthRouter "bridge/micros/core/http/admRouter/health-router"
+	limitRouter "bridge/micros/core/http/admRouter/limit-router"
 	"bridge/micros/core/http/admRouter/manageUserRouter"
+	outboxRouter "bridge/micros/core/http/admRouter/outbox-router"
+	reconcileRouter "bridge/micros/core/http/admRouter/reconcile-router"
+	refundRouter "bridge/micros/core/http/admRouter/refund-router"
+	tokenRouter "bridge/micros/core/http/admRouter/token-router"
 	welRouter "bridge/micros/core/http/admRouter/wel-router"
 	"net/http"
 
@@ -21,4 +29,12 @@ func Config(router gin.IRouter, mw ...gin.HandlerFunc) {
 	manageUserRouter.Config(gr)
 	ethRouter.Config(gr)
 	welRouter.Config(gr)
+	reconcileRouter.Config(gr)
+	feeRouter.Config(gr)
+	limitRouter.Config(gr)
+	tokenRouter.Config(gr)
+	outboxRouter.Config(gr)
+	refundRouter.Config(gr)
+	ethTxRouter.Config(gr)
+	healthRouter.Config(gr)
 }
@@ -0,0 +1,121 @@
+package tokenRouter
+
+import (
+	tokenLogic "bridge/micros/core/blogic/token"
+	welethModel "bridge/micros/weleth/model"
+	log "bridge/service-managers/logger"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+var logger *zerolog.Logger
+
+func Config(router gin.IRouter, mw ...gin.HandlerFunc) {
+	initialize()
+
+	gr := router.Group("/m/tokens", mw... /*,middlewares.Author*/)
+	gr.GET("", getBridgeTokens)
+	gr.POST("", createBridgeToken)
+	gr.PUT("/:id", updateBridgeToken)
+	gr.DELETE("/:id", deleteBridgeToken)
+}
+
+func initialize() {
+	logger = log.Get()
+	logger.Info().Msg("token registry handlers initialized")
+}
+
+func getBridgeTokens(c *gin.Context) {
+	// process
+	tokens, err := tokenLogic.GetBridgeTokens()
+	if err != nil {
+		logger.Err(err).Msgf("[get bridge tokens handler] Unable to get bridge tokens")
+		c.JSON(http.StatusInternalServerError, "Unable to get bridge tokens")
+		return
+	}
+
+	// response
+	logger.Info().Msgf("[get bridge tokens handler] Get bridge tokens successfully")
+	c.JSON(http.StatusOK, tokens)
+}
+
+func createBridgeToken(c *gin.Context) {
+	// request
+	var token welethModel.BridgeToken
+	if err := c.ShouldBindJSON(&token); err != nil {
+		logger.Err(err).Msgf("[create bridge token handler] Invalid request payload")
+		c.JSON(http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// process
+	id, err := tokenLogic.CreateBridgeToken(token)
+	if err == welethModel.ErrInvalidToken {
+		c.JSON(http.StatusBadRequest, "Invalid bridge token")
+		return
+	}
+	if err != nil {
+		logger.Err(err).Msgf("[create bridge token handler] Unable to create bridge token")
+		c.JSON(http.StatusInternalServerError, "Unable to create bridge token")
+		return
+	}
+
+	// response
+	logger.Info().Msgf("[create bridge token handler] Bridge token %d created", id)
+	c.JSON(http.StatusOK, gin.H{"id": id})
+}
+
+func updateBridgeToken(c *gin.Context) {
+	// request
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid bridge token ID")
+		return
+	}
+	var token welethModel.BridgeToken
+	if err := c.ShouldBindJSON(&token); err != nil {
+		logger.Err(err).Msgf("[update bridge token handler] Invalid request payload")
+		c.JSON(http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	token.ID = id
+
+	// process
+	err = tokenLogic.UpdateBridgeToken(token)
+	if err == welethModel.ErrInvalidToken {
+		c.JSON(http.StatusBadRequest, "Invalid bridge token")
+		return
+	}
+	if err != nil {
+		logger.Err(err).Msgf("[update bridge token handler] Unable to update bridge token %d", id)
+		c.JSON(http.StatusInternalServerError, "Unable to update bridge token")
+		return
+	}
+
+	// response
+	logger.Info().Msgf("[update bridge token handler] Bridge token %d updated", id)
+	c.JSON(http.StatusOK, "Bridge token updated")
+}
+
+func deleteBridgeToken(c *gin.Context) {
+	// request
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid bridge token ID")
+		return
+	}
+
+	// process
+	if err := tokenLogic.DeleteBridgeToken(id); err != nil {
+		logger.Err(err).Msgf("[delete bridge token handler] Unable to delete bridge token %d", id)
+		c.JSON(http.StatusInternalServerError, "Unable to delete bridge token")
+		return
+	}
+
+	// response
+	logger.Info().Msgf("[delete bridge token handler] Bridge token %d deleted", id)
+	c.JSON(http.StatusOK, "Bridge token deleted")
+}
@@ -27,6 +27,8 @@ func Config(router gin.IRouter, mw ...gin.HandlerFunc) {
 
 	gr.POST("/request/eth/cashin-to/wel", eth2welCashin)
 	gr.POST("/request/eth/cashin-to/wel/:txid", eth2welCashinByTxId)
+	gr.POST("/request/wel/cashout-to/eth", wel2ethCashout)
+	gr.POST("/request/wel/cashout-to/eth/:txid", wel2ethCashoutByTxId)
 	//gr.POST("/claim/wel/cashout-to/eth", wel2ethCashout)
 
 	gr.GET("/transaction/eth/cashin/wel/:eth_txid", getE2WCashinTxByEthTxId)
@@ -51,6 +53,7 @@ func wel2ethCashin(c *gin.Context) {
 	// request
 	type request struct {
 		TxHash           string `json:"txhash"`
+		LogIndex         int64  `json:"log_index"` // position of the deposit event in the tx
 		ToAccountAddress string `json:"to_account_address"`
 	}
 	var req request
@@ -62,7 +65,7 @@ func wel2ethCashin(c *gin.Context) {
 	}
 
 	// process
-	tkAddr, amount, reqIDraw, signature, claimExpireTime, err := ethLogic.ClaimWel2EthCashin(req.TxHash, req.ToAccountAddress, contractVersion)
+	tkAddr, amount, reqIDraw, signature, claimExpireTime, err := ethLogic.ClaimWel2EthCashin(req.TxHash, req.LogIndex, req.ToAccountAddress, contractVersion)
 	if err != nil {
 		logger.Err(err).Msgf("[Claim W2E cashin] Unable to generate request ID and signature")
 		c.JSON(http.StatusInternalServerError, "Unable to generate request ID and signature")
@@ -102,6 +105,7 @@ func eth2welCashout(c *gin.Context) {
 	// request
 	type request struct {
 		TxHash           string `json:"txhash"`
+		LogIndex         int64  `json:"log_index"` // position of the deposit event in the tx
 		ToAccountAddress string `json:"to_account_address"`
 	}
 	var req request
@@ -113,7 +117,7 @@ func eth2welCashout(c *gin.Context) {
 	}
 
 	// process
-	tkAddr, amount, reqIDraw, signature, claimExpireTime, err := welLogic.ClaimEth2WelCashout(req.TxHash, req.ToAccountAddress, contractVersion)
+	tkAddr, amount, reqIDraw, signature, claimExpireTime, err := welLogic.ClaimEth2WelCashout(req.TxHash, req.LogIndex, req.ToAccountAddress, contractVersion)
 	if err != nil {
 		logger.Err(err).Msgf("[Claim E2W cashout] Unable to generate request ID and signature")
 		c.JSON(http.StatusInternalServerError, "Unable to generate request ID and signature")
@@ -187,9 +191,10 @@ func eth2welCashin(c *gin.Context) {
 func eth2welCashinByTxId(c *gin.Context) {
 	//request
 	type request struct {
-		To    string `json:"to_wel"`
-		NetId string `json:"netid"`
-		Token string `json:"token"`
+		To       string `json:"to_wel"`
+		NetId    string `json:"netid"`
+		Token    string `json:"token"`
+		LogIndex *int64 `json:"log_index"` // index of the Transfer log, -1 for ether, optional for a single deposit
 	}
 	var req request
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -210,6 +215,7 @@ func eth2welCashinByTxId(c *gin.Context) {
 	if err := ethLogic.
 		WatchTx2TreasuryRequestByTxhash(
 			txhash,
+			req.LogIndex,
 			req.To,
 			req.NetId,
 			req.Token); err != nil {
@@ -222,6 +228,91 @@ func eth2welCashinByTxId(c *gin.Context) {
 	c.JSON(http.StatusOK, fmt.Sprintf("BE is confirming transaction to treasury with transaction id %s", txhash))
 }
 
+func wel2ethCashout(c *gin.Context) {
+	//request
+	type request struct {
+		From     string `json:"from_wel"`
+		To       string `json:"to_eth"`
+		Treasury string `json:"wel_treasury"`
+		NetId    string `json:"netid"`
+		Token    string `json:"token"`
+		Amount   string `json:"amount"`
+	}
+	var req request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Err(err).Msgf("[W2E cashout] Invalid request payload")
+		c.JSON(http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if !common.IsHexAddress(req.To) {
+		err := fmt.Errorf("Invalid eth address")
+		logger.Err(err).Msgf("[W2E cashout] Invalid receiver " + req.To)
+		c.JSON(http.StatusBadRequest, "Invalid eth address")
+		return
+	}
+
+	// process
+	if err := welLogic.
+		WatchWelTx2TreasuryRequest(
+			req.From,
+			req.To,
+			req.Treasury,
+			req.NetId,
+			req.Token,
+			req.Amount); err != nil {
+		logger.Err(err).Msgf("[W2E cashout] Failed to request backend to watch for transaction to treasury")
+		c.JSON(http.StatusBadRequest, "Failed to request backend to watch for transaction to treasury")
+		return
+	}
+
+	// response
+	c.JSON(http.StatusOK, fmt.Sprintf("BE is watching for transaction to %s from %s", req.Treasury, req.From))
+}
+
+func wel2ethCashoutByTxId(c *gin.Context) {
+	//request
+	type request struct {
+		To       string `json:"to_eth"`
+		NetId    string `json:"netid"`
+		LogIndex int64  `json:"log_index"` // position of the TRC20 transfer in the tx
+	}
+	var req request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Err(err).Msgf("[W2E cashout] Invalid request payload")
+		c.JSON(http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if !common.IsHexAddress(req.To) {
+		err := fmt.Errorf("Invalid eth address")
+		logger.Err(err).Msgf("[W2E cashout] Invalid receiver " + req.To)
+		c.JSON(http.StatusBadRequest, "Invalid eth address")
+		return
+	}
+
+	txhash := c.Param("txid")
+	if len(txhash) <= 0 {
+		err := fmt.Errorf("Invalid request payload")
+		logger.Err(err).Msgf("[W2E cashout] Invalid request payload")
+		c.JSON(http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// process
+	if err := welLogic.
+		WatchWelTx2TreasuryRequestByTxhash(
+			txhash,
+			req.LogIndex,
+			req.To,
+			req.NetId); err != nil {
+		logger.Err(err).Msgf("[W2E cashout] Failed to request backend to watch for transaction to treasury")
+		c.JSON(http.StatusBadRequest, "Failed to request backend to watch for transaction to treasury")
+		return
+	}
+
+	// response
+	c.JSON(http.StatusOK, fmt.Sprintf("BE is confirming transaction to treasury with transaction id %s", txhash))
+}
+
 func getE2WCashinTxByEthTxId(c *gin.Context) {
 	//request
 	txhash := c.Param("eth_txid")
@@ -364,10 +455,6 @@ func getW2ECashoutTx(c *gin.Context) {
 	c.JSON(http.StatusOK, txs)
 }
 
-func wel2ethCashout(c *gin.Context) {
-
-}
-
 func getW2ECashinRequest(c *gin.Context) {
 	requestID := c.Param("request_id")
 	if len(requestID) <= 0 {
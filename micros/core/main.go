@@ -11,10 +11,12 @@ import (
 	"bridge/micros/core/middlewares"
 	ethService "bridge/micros/core/service/eth"
 	ethMulsend "bridge/micros/core/service/eth/mulsend"
+	"bridge/micros/core/service/eth/transactor"
 	"bridge/micros/core/service/notifier"
 	welService "bridge/micros/core/service/wel"
 	importcontract "bridge/micros/core/service/wel/import-contract"
 	manager "bridge/service-managers"
+	"bridge/service-managers/daemon"
 	ethListener "bridge/service-managers/listener/eth"
 	welListener "bridge/service-managers/listener/wel"
 	"bridge/service-managers/logger"
@@ -25,12 +27,10 @@ import (
 	"syscall"
 	"time"
 
-	patchedWelclient "github.com/Paven-Org/gotron-sdk/pkg/client"
-	welclient "github.com/Paven-Org/gotron-sdk/pkg/client"
-
 	"github.com/casbin/casbin/v2"
 	"github.com/ethereum/go-ethereum/ethclient"
 	_ "github.com/lib/pq"
+	"go.temporal.io/sdk/client"
 	//"https://github.com/rs/zerolog/log"
 )
 
@@ -101,14 +101,29 @@ func main() {
 	defer tempCli.Close()
 
 	// ETH chain stuff: contract address, prkey, contract event watcher...
-	ethCli, err := ethclient.Dial(cnf.EthereumConfig.BlockchainRPC)
+	ethCli, err := ethListener.NewMultiClient(append([]string{cnf.EthereumConfig.BlockchainRPC}, cnf.EthereumConfig.FallbackRPCs...), logger)
 	if err != nil {
 		logger.Err(err).Msgf("Unable to connect to ethereum RPC server")
 		return
 	}
 	defer ethCli.Close()
 
-	ethGovService, err := ethService.MkGovContractService(ethCli, tempCli, daos, cnf.EthGovContract)
+	ethNonces := transactor.MkNonceManager(ethCli, daos.EthNonce)
+	if err := ethNonces.Reconcile(context.Background()); err != nil {
+		logger.Err(err).Msg("[main] Unable to reconcile ethereum nonces with the chain")
+	}
+
+	// every outgoing ethereum transaction is signed through it, refused ones are notified to admins
+	ethTransactor := transactor.MkTransactor(ethCli, consts.EthChainFromEnv[cnf.Environment], cnf.EthereumConfig.Gas, daos.EthTx, ethNonces, func(problem string) {
+		wo := client.StartWorkflowOptions{
+			TaskQueue: notifier.NotifierQueue,
+		}
+		if _, err := tempCli.ExecuteWorkflow(context.Background(), wo, notifier.NotifyProblemWF, problem, "admin"); err != nil {
+			logger.Err(err).Msg("[main] Failed to notify admins of problem: " + problem)
+		}
+	})
+
+	ethGovService, err := ethService.MkGovContractService(ethCli, tempCli, daos, cnf.EthGovContract, ethTransactor)
 	if err != nil {
 		logger.Err(err).Msgf("Unable to initialize ethererum GovContractService")
 		return
@@ -117,7 +132,7 @@ func main() {
 	ethGovService.StartService()
 	defer ethGovService.StopService()
 
-	ethMulsendService, err := ethMulsend.MkMulsendContractService(ethCli, tempCli, daos, cnf.EthMulsendContract)
+	ethMulsendService, err := ethMulsend.MkMulsendContractService(ethCli, tempCli, daos, cnf.EthMulsendContract, ethTransactor)
 	if err != nil {
 		logger.Err(err).Msgf("Unable to initialize ethererum MulsendContractService")
 		return
@@ -127,12 +142,12 @@ func main() {
 	defer ethMulsendService.StopService()
 
 	// WEL chain stuff
-	welCli := welclient.NewGrpcClient(cnf.WelupsConfig.Nodes[0])
-	defer welCli.Stop()
-	if err := welCli.Start(); err != nil {
-		logger.Err(err).Msgf("Unable to start welCli's GRPC connection")
+	welCli, err := welListener.NewNodePool(cnf.WelupsConfig.Nodes, time.Minute*time.Duration(cnf.WelupsConfig.ClientTimeout), logger)
+	if err != nil {
+		logger.Err(err).Msgf("Unable to start welCli's GRPC connections")
 		return
 	}
+	defer welCli.Stop()
 
 	welGovService, err := welService.MkGovContractService(welCli, tempCli, daos, cnf.WelGovContract)
 	if err != nil {
@@ -143,13 +158,7 @@ func main() {
 	welGovService.StartService()
 	defer welGovService.StopService()
 
-	patchedWelCli := patchedWelclient.NewGrpcClient(cnf.WelupsConfig.Nodes[0])
-	defer patchedWelCli.Stop()
-	if err := patchedWelCli.Start(); err != nil {
-		logger.Err(err).Msgf("Unable to start patchedWelCli's GRPC connection")
-		return
-	}
-	welImportService, err := importcontract.MkImportContractService(patchedWelCli, tempCli, daos, cnf.WelImportContract)
+	welImportService, err := importcontract.MkImportContractService(welCli, tempCli, daos, cnf.WelImportContract)
 	if err != nil {
 		logger.Err(err).Msgf("Unable to initialize welups WelImportService")
 		return
@@ -173,10 +182,11 @@ func main() {
 		RedisManager: rm,
 		Mailer:       mailer,
 		//Httpcli: nil,
-		TokenService: ts,
-		TemporalCli:  tempCli,
-		WelCli:       welCli,
-		EthCli:       ethCli,
+		TokenService:  ts,
+		TemporalCli:   tempCli,
+		WelCli:        welCli,
+		EthCli:        ethCli,
+		EthTransactor: ethTransactor,
 	}
 
 	blogic.Init(initVector)
@@ -191,7 +201,17 @@ func main() {
 	ethListen := ethListener.NewEthListener(ethblockdao, ethCli, cnf.EthereumConfig.BlockTime, cnf.EthereumConfig.BlockOffSet, logger)
 
 	ethGovEvConsumer := ethService.NewGovEvConsumer(cnf.EthGovContract, daos, tempCli)
-	ethListen.RegisterConsumer(ethGovEvConsumer)
+	ethListen.RegisterConsumer("gov", 0, ethGovEvConsumer)
+	ethListen.ScanCursors = daos.ScanCursors
+	if cnf.EthereumConfig.WebsocketRPC != "" {
+		wsClient, err := ethclient.Dial(cnf.EthereumConfig.WebsocketRPC)
+		if err != nil {
+			logger.Err(err).Msg("[main] Ethereum ws client initialization failed, polling instead")
+		} else {
+			defer wsClient.Close()
+			ethListen.Subscriber = wsClient
+		}
+	}
 
 	wg.Add(1)
 	go func() {
@@ -199,14 +219,20 @@ func main() {
 		wg.Done()
 	}()
 
+	wg.Add(1)
+	go func() {
+		daemon.BootstrapDaemons(ctx, ethCli.HealthCheck, welCli.HealthCheck)
+		wg.Done()
+	}()
+
 	// welups side
-	welExtcli := welListener.NewExtNodeClientFromCli(welCli, time.Minute*time.Duration(cnf.WelupsConfig.ClientTimeout))
-	welTransHandler := welListener.NewTransHandler(welExtcli, cnf.WelupsConfig.BlockOffSet)
+	welTransHandler := welListener.NewTransHandler(welCli, cnf.WelupsConfig.BlockOffSet)
 	welblockdao := daos.WelBlockDAO
 	welListen := welListener.NewWelListener(welblockdao, welTransHandler, cnf.WelupsConfig.BlockTime, cnf.WelupsConfig.BlockOffSet, logger)
 
 	welEvtConsumer := welService.NewGovEvConsumer(cnf.WelGovContract, daos, tempCli)
-	welListen.RegisterConsumer(welEvtConsumer)
+	welListen.RegisterConsumer("gov", 0, welEvtConsumer)
+	welListen.ScanCursors = daos.ScanCursors
 
 	wg.Add(1)
 	go func() {
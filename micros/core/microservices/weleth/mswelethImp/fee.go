@@ -0,0 +1,97 @@
+package mswelethImp
+
+import (
+	welethService "bridge/micros/weleth/temporal"
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// admin requests fail fast instead of retrying for minutes
+func withAdminActivityOptions(ctx workflow.Context) workflow.Context {
+	ao := workflow.ActivityOptions{
+		TaskQueue:              welethService.WelethServiceQueue,
+		ScheduleToCloseTimeout: time.Second * 60,
+		ScheduleToStartTimeout: time.Second * 60,
+		StartToCloseTimeout:    time.Second * 60,
+		HeartbeatTimeout:       time.Second * 10,
+		WaitForCancellation:    false,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumInterval: time.Second * 10,
+			MaximumAttempts: 3,
+		},
+	}
+	return workflow.WithActivityOptions(ctx, ao)
+}
+
+func (cli *Weleth) GetFeeRulesWF(ctx workflow.Context, token, direction string) ([]welethService.FeeRule, error) {
+	log := workflow.GetLogger(ctx)
+	log.Info("[Core MSWeleth] Getting fee rules of token " + token + ", direction " + direction)
+	ctx = withAdminActivityOptions(ctx)
+
+	// call weleth
+	log.Info("[Core MSWeleth] Call weleth...")
+	var rules []welethService.FeeRule
+	res := workflow.ExecuteActivity(ctx, welethService.GetFeeRules, token, direction)
+	if err := res.Get(ctx, &rules); err != nil {
+		log.Error("[Core MSWeleth] Error while executing activity GetFeeRules in weleth microservice", err.Error())
+		return nil, err
+	}
+
+	log.Info("[Core MSWeleth] Call weleth successfully, result: ", rules)
+	return rules, nil
+}
+
+func (cli *Weleth) CreateFeeRuleWF(ctx workflow.Context, rule welethService.FeeRule) (int64, error) {
+	log := workflow.GetLogger(ctx)
+	log.Info("[Core MSWeleth] Creating fee rule for token " + rule.TokenAddr + ", direction " + rule.Direction)
+	ctx = withAdminActivityOptions(ctx)
+
+	// call weleth
+	log.Info("[Core MSWeleth] Call weleth...")
+	var id int64
+	res := workflow.ExecuteActivity(ctx, welethService.CreateFeeRule, rule)
+	if err := res.Get(ctx, &id); err != nil {
+		log.Error("[Core MSWeleth] Error while executing activity CreateFeeRule in weleth microservice", err.Error())
+		return -1, err
+	}
+
+	log.Info("[Core MSWeleth] Call weleth successfully, result: ", id)
+	return id, nil
+}
+
+func (cli *Weleth) UpdateFeeRuleWF(ctx workflow.Context, rule welethService.FeeRule) error {
+	log := workflow.GetLogger(ctx)
+	log.Info(fmt.Sprintf("[Core MSWeleth] Updating fee rule %d", rule.ID))
+	ctx = withAdminActivityOptions(ctx)
+
+	// call weleth
+	log.Info("[Core MSWeleth] Call weleth...")
+	res := workflow.ExecuteActivity(ctx, welethService.UpdateFeeRule, rule)
+	if err := res.Get(ctx, nil); err != nil {
+		log.Error("[Core MSWeleth] Error while executing activity UpdateFeeRule in weleth microservice", err.Error())
+		return err
+	}
+
+	log.Info("[Core MSWeleth] Call weleth successfully")
+	return nil
+}
+
+func (cli *Weleth) DeleteFeeRuleWF(ctx workflow.Context, id int64) error {
+	log := workflow.GetLogger(ctx)
+	log.Info(fmt.Sprintf("[Core MSWeleth] Deleting fee rule %d", id))
+	ctx = withAdminActivityOptions(ctx)
+
+	// call weleth
+	log.Info("[Core MSWeleth] Call weleth...")
+	res := workflow.ExecuteActivity(ctx, welethService.DeleteFeeRule, id)
+	if err := res.Get(ctx, nil); err != nil {
+		log.Error("[Core MSWeleth] Error while executing activity DeleteFeeRule in weleth microservice", err.Error())
+		return err
+	}
+
+	log.Info("[Core MSWeleth] Call weleth successfully")
+	return nil
+}
@@ -0,0 +1,43 @@
+package mswelethImp
+
+import (
+	welethService "bridge/micros/weleth/temporal"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+func (cli *Weleth) GetListenerLeasesWF(ctx workflow.Context) ([]welethService.ListenerLease, error) {
+	log := workflow.GetLogger(ctx)
+	log.Info("[Core MSWeleth] Getting listener leases")
+	ctx = withAdminActivityOptions(ctx)
+
+	// call weleth
+	log.Info("[Core MSWeleth] Call weleth...")
+	var leases []welethService.ListenerLease
+	res := workflow.ExecuteActivity(ctx, welethService.GetListenerLeases)
+	if err := res.Get(ctx, &leases); err != nil {
+		log.Error("[Core MSWeleth] Error while executing activity GetListenerLeases in weleth microservice", err.Error())
+		return nil, err
+	}
+
+	log.Info("[Core MSWeleth] Call weleth successfully, result: ", leases)
+	return leases, nil
+}
+
+func (cli *Weleth) GetDaemonStatesWF(ctx workflow.Context) ([]welethService.DaemonState, error) {
+	log := workflow.GetLogger(ctx)
+	log.Info("[Core MSWeleth] Getting daemon states")
+	ctx = withAdminActivityOptions(ctx)
+
+	// call weleth
+	log.Info("[Core MSWeleth] Call weleth...")
+	var states []welethService.DaemonState
+	res := workflow.ExecuteActivity(ctx, welethService.GetDaemonStates)
+	if err := res.Get(ctx, &states); err != nil {
+		log.Error("[Core MSWeleth] Error while executing activity GetDaemonStates in weleth microservice", err.Error())
+		return nil, err
+	}
+
+	log.Info("[Core MSWeleth] Call weleth successfully, result: ", states)
+	return states, nil
+}
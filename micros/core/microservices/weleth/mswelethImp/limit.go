@@ -0,0 +1,44 @@
+package mswelethImp
+
+import (
+	welethService "bridge/micros/weleth/temporal"
+	"fmt"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+func (cli *Weleth) GetHeldTransfersWF(ctx workflow.Context, status string, offset, size uint64) ([]welethService.HeldTransfer, error) {
+	log := workflow.GetLogger(ctx)
+	log.Info("[Core MSWeleth] Getting transfers held for review, status: " + status)
+	ctx = withAdminActivityOptions(ctx)
+
+	// call weleth
+	log.Info("[Core MSWeleth] Call weleth...")
+	var ts []welethService.HeldTransfer
+	res := workflow.ExecuteActivity(ctx, welethService.GetHeldTransfers, status, offset, size)
+	if err := res.Get(ctx, &ts); err != nil {
+		log.Error("[Core MSWeleth] Error while executing activity GetHeldTransfers in weleth microservice", err.Error())
+		return nil, err
+	}
+
+	log.Info("[Core MSWeleth] Call weleth successfully, result: ", ts)
+	return ts, nil
+}
+
+func (cli *Weleth) ReleaseHeldTransferWF(ctx workflow.Context, id int64) (welethService.HeldTransfer, error) {
+	log := workflow.GetLogger(ctx)
+	log.Info(fmt.Sprintf("[Core MSWeleth] Releasing held transfer %d", id))
+	ctx = withAdminActivityOptions(ctx)
+
+	// call weleth
+	log.Info("[Core MSWeleth] Call weleth...")
+	var t welethService.HeldTransfer
+	res := workflow.ExecuteActivity(ctx, welethService.ReleaseHeldTransfer, id)
+	if err := res.Get(ctx, &t); err != nil {
+		log.Error("[Core MSWeleth] Error while executing activity ReleaseHeldTransfer in weleth microservice", err.Error())
+		return t, err
+	}
+
+	log.Info("[Core MSWeleth] Call weleth successfully, result: ", t)
+	return t, nil
+}
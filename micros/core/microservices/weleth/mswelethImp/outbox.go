@@ -0,0 +1,43 @@
+package mswelethImp
+
+import (
+	welethService "bridge/micros/weleth/temporal"
+	"fmt"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+func (cli *Weleth) GetDeadLettersWF(ctx workflow.Context, chain string, offset, size uint64) ([]welethService.OutboxEvent, error) {
+	log := workflow.GetLogger(ctx)
+	log.Info("[Core MSWeleth] Getting dead letters, chain: " + chain)
+	ctx = withAdminActivityOptions(ctx)
+
+	// call weleth
+	log.Info("[Core MSWeleth] Call weleth...")
+	var es []welethService.OutboxEvent
+	res := workflow.ExecuteActivity(ctx, welethService.GetDeadLetters, chain, offset, size)
+	if err := res.Get(ctx, &es); err != nil {
+		log.Error("[Core MSWeleth] Error while executing activity GetDeadLetters in weleth microservice", err.Error())
+		return nil, err
+	}
+
+	log.Info("[Core MSWeleth] Call weleth successfully, result: ", es)
+	return es, nil
+}
+
+func (cli *Weleth) RequeueDeadLetterWF(ctx workflow.Context, id int64) error {
+	log := workflow.GetLogger(ctx)
+	log.Info(fmt.Sprintf("[Core MSWeleth] Requeuing dead letter %d", id))
+	ctx = withAdminActivityOptions(ctx)
+
+	// call weleth
+	log.Info("[Core MSWeleth] Call weleth...")
+	res := workflow.ExecuteActivity(ctx, welethService.RequeueDeadLetter, id)
+	if err := res.Get(ctx, nil); err != nil {
+		log.Error("[Core MSWeleth] Error while executing activity RequeueDeadLetter in weleth microservice", err.Error())
+		return err
+	}
+
+	log.Info("[Core MSWeleth] Call weleth successfully")
+	return nil
+}
@@ -40,6 +40,28 @@ const (
 
 	WaitForPendingW2ECashinClaimRequestWF  = msweleth.WaitForPendingW2ECashinClaimRequestWF
 	WaitForPendingE2WCashoutClaimRequestWF = msweleth.WaitForPendingE2WCashoutClaimRequestWF
+
+	GetFeeRulesWF   = msweleth.GetFeeRulesWF
+	CreateFeeRuleWF = msweleth.CreateFeeRuleWF
+	UpdateFeeRuleWF = msweleth.UpdateFeeRuleWF
+	DeleteFeeRuleWF = msweleth.DeleteFeeRuleWF
+
+	GetHeldTransfersWF    = msweleth.GetHeldTransfersWF
+	ReleaseHeldTransferWF = msweleth.ReleaseHeldTransferWF
+
+	GetBridgeTokensWF   = msweleth.GetBridgeTokensWF
+	CreateBridgeTokenWF = msweleth.CreateBridgeTokenWF
+	UpdateBridgeTokenWF = msweleth.UpdateBridgeTokenWF
+	DeleteBridgeTokenWF = msweleth.DeleteBridgeTokenWF
+
+	GetDeadLettersWF    = msweleth.GetDeadLettersWF
+	RequeueDeadLetterWF = msweleth.RequeueDeadLetterWF
+
+	GetRefundsWF   = msweleth.GetRefundsWF
+	RejectRefundWF = msweleth.RejectRefundWF
+
+	GetListenerLeasesWF = msweleth.GetListenerLeasesWF
+	GetDaemonStatesWF   = msweleth.GetDaemonStatesWF
 )
 
 type Weleth struct {
@@ -53,7 +75,7 @@ func MkWeleth(cli client.Client) *Weleth {
 	}
 }
 
-func (cli *Weleth) CreateW2ECashinClaimRequestWF(ctx workflow.Context, txhash string, userAddr string) (tx model.WelCashinEthTrans, err error) {
+func (cli *Weleth) CreateW2ECashinClaimRequestWF(ctx workflow.Context, txhash string, userAddr string, logIndex int64) (tx model.WelCashinEthTrans, err error) {
 	log := workflow.GetLogger(ctx)
 	log.Info("[Core MSWeleth] Creating cashin claim request from wel to eth with wel's side txhash: " + txhash)
 
@@ -74,7 +96,7 @@ func (cli *Weleth) CreateW2ECashinClaimRequestWF(ctx workflow.Context, txhash st
 
 	// call weleth
 	log.Info("[Core MSWeleth] Call weleth...")
-	res := workflow.ExecuteActivity(ctx, welethService.CreateW2ECashinClaimRequest, txhash, userAddr)
+	res := workflow.ExecuteActivity(ctx, welethService.CreateW2ECashinClaimRequest, txhash, userAddr, logIndex)
 	if err = res.Get(ctx, &tx); err != nil {
 		log.Error("[Core MSWeleth] Error while executing activity CreateW2ECashinClaimRequest in weleth microservice", err.Error())
 		return
@@ -89,7 +111,7 @@ func (cli *Weleth) CreateW2ECashinClaimRequestWF(ctx workflow.Context, txhash st
 func (cli *Weleth) InvalidateW2ECashinClaim(ctx context.Context, tokenAddr, reqid string) error {
 	return ethLogic.InvalidateRequestClaim(tokenAddr, "0", reqid, "EXPORT_WELUPS_v1")
 }
-func (cli *Weleth) WaitForPendingW2ECashinClaimRequestWF(ctx workflow.Context, txhash string) error {
+func (cli *Weleth) WaitForPendingW2ECashinClaimRequestWF(ctx workflow.Context, txhash string, logIndex int64) error {
 	log := workflow.GetLogger(ctx)
 
 	log.Info("[Core MSWeleth] Waiting for claim request...")
@@ -111,7 +133,7 @@ func (cli *Weleth) WaitForPendingW2ECashinClaimRequestWF(ctx workflow.Context, t
 	ctx = workflow.WithActivityOptions(ctx, ao)
 
 	var tx model.WelCashinEthTrans
-	res := workflow.ExecuteActivity(ctx, welethService.GetWelToEthCashinByTxHash, txhash)
+	res := workflow.ExecuteActivity(ctx, welethService.GetWelToEthCashinByTxHash, txhash, logIndex)
 	if err := res.Get(ctx, &tx); err != nil {
 		log.Info("[Temporal BG] Error while processing pending claim request: ", err.Error())
 		return err
@@ -131,7 +153,7 @@ func (cli *Weleth) WaitForPendingW2ECashinClaimRequestWF(ctx workflow.Context, t
 	log.Info("[Temporal] nothing to do")
 	return nil
 }
-func (cli *Weleth) CreateE2WCashoutClaimRequestWF(ctx workflow.Context, txhash string, userAddr string) (tx model.EthCashoutWelTrans, err error) {
+func (cli *Weleth) CreateE2WCashoutClaimRequestWF(ctx workflow.Context, txhash string, userAddr string, logIndex int64) (tx model.EthCashoutWelTrans, err error) {
 	log := workflow.GetLogger(ctx)
 	log.Info("[Core MSWeleth] Creating cashout claim request from eth to wel with eth's side txhash: " + txhash)
 
@@ -152,7 +174,7 @@ func (cli *Weleth) CreateE2WCashoutClaimRequestWF(ctx workflow.Context, txhash s
 
 	// call weleth
 	log.Info("[Core MSWeleth] Call weleth...")
-	res := workflow.ExecuteActivity(ctx, welethService.CreateE2WCashoutClaimRequest, txhash, userAddr)
+	res := workflow.ExecuteActivity(ctx, welethService.CreateE2WCashoutClaimRequest, txhash, userAddr, logIndex)
 	if err = res.Get(ctx, &tx); err != nil {
 		log.Error("[Core MSWeleth] Error while executing activity CreateE2WCashoutClaimRequest in weleth microservice", err.Error())
 		return
@@ -168,7 +190,7 @@ func (cli *Weleth) InvalidateE2WCashoutClaim(ctx context.Context, tokenAddr, req
 	return welLogic.InvalidateRequestClaim(tokenAddr, "0", reqid, "EXPORT_WELUPS_v1")
 }
 
-func (cli *Weleth) WaitForPendingE2WCashoutClaimRequestWF(ctx workflow.Context, txhash string) error {
+func (cli *Weleth) WaitForPendingE2WCashoutClaimRequestWF(ctx workflow.Context, txhash string, logIndex int64) error {
 	log := workflow.GetLogger(ctx)
 
 	log.Info("[Core MSWeleth] Waiting for claim request...")
@@ -190,7 +212,7 @@ func (cli *Weleth) WaitForPendingE2WCashoutClaimRequestWF(ctx workflow.Context,
 	ctx = workflow.WithActivityOptions(ctx, ao)
 
 	var tx model.EthCashoutWelTrans
-	res := workflow.ExecuteActivity(ctx, welethService.GetEthToWelCashoutByTxHash, txhash)
+	res := workflow.ExecuteActivity(ctx, welethService.GetEthToWelCashoutByTxHash, txhash, logIndex)
 	if err := res.Get(ctx, &tx); err != nil {
 		log.Info("[Temporal BG] Error while processing pending claim request: ", err.Error())
 		return err
@@ -201,7 +223,7 @@ func (cli *Weleth) WaitForPendingE2WCashoutClaimRequestWF(ctx workflow.Context,
 			log.Info("[Temporal BG] Error while processing pending claim request: ", err.Error())
 			return err
 		}
-		if err := workflow.ExecuteActivity(ctx, welethService.UpdateClaimEthCashoutWel, tx.ID, tx.ReqID, model.RequestExpired, tx.ClaimTxHash, tx.Amount, tx.Fee, model.StatusUnknown).Get(ctx, nil); err != nil {
+		if err := workflow.ExecuteActivity(ctx, welethService.UpdateClaimEthCashoutWel, tx.ID, tx.ReqID, model.RequestExpired, tx.ClaimTxHash, tx.DestAmount, tx.Fee, model.StatusUnknown).Get(ctx, nil); err != nil {
 			log.Info("[Temporal BG] Error while processing pending claim request: ", err.Error())
 			return err
 		}
@@ -209,7 +231,7 @@ func (cli *Weleth) WaitForPendingE2WCashoutClaimRequestWF(ctx workflow.Context,
 	log.Info("[Temporal] nothing to do")
 	return nil
 }
-func (cli *Weleth) GetWelToEthCashinByTxHashWF(ctx workflow.Context, txhash string) (tx welethService.WelCashinEthTrans, err error) {
+func (cli *Weleth) GetWelToEthCashinByTxHashWF(ctx workflow.Context, txhash string, logIndex int64) (tx welethService.WelCashinEthTrans, err error) {
 	log := workflow.GetLogger(ctx)
 	log.Info("[Core MSWeleth] Getting cashin transaction from wel to eth with wel's side txhash: " + txhash)
 
@@ -230,7 +252,7 @@ func (cli *Weleth) GetWelToEthCashinByTxHashWF(ctx workflow.Context, txhash stri
 
 	// call weleth
 	log.Info("[Core MSWeleth] Call weleth...")
-	res := workflow.ExecuteActivity(ctx, welethService.GetWelToEthCashinByTxHash, txhash)
+	res := workflow.ExecuteActivity(ctx, welethService.GetWelToEthCashinByTxHash, txhash, logIndex)
 	if err = res.Get(ctx, &tx); err != nil {
 		log.Error("[Core MSWeleth] Error while executing activity GetWelToEthCashinByTxHash in weleth microservice", err.Error())
 		return
@@ -302,7 +324,7 @@ func (cli *Weleth) GetWelToEthCashinClaimRequestWF(ctx workflow.Context, request
 	return claimRequest, nil
 }
 
-func (cli *Weleth) GetEthToWelCashoutByTxHashWF(ctx workflow.Context, txhash string) (tx welethService.EthCashoutWelTrans, err error) {
+func (cli *Weleth) GetEthToWelCashoutByTxHashWF(ctx workflow.Context, txhash string, logIndex int64) (tx welethService.EthCashoutWelTrans, err error) {
 	log := workflow.GetLogger(ctx)
 	log.Info("[Core MSWeleth] Getting cashout transaction from eth to wel with eth's side txhash: " + txhash)
 
@@ -323,7 +345,7 @@ func (cli *Weleth) GetEthToWelCashoutByTxHashWF(ctx workflow.Context, txhash str
 
 	// call weleth
 	log.Info("[Core MSWeleth] Call weleth...")
-	res := workflow.ExecuteActivity(ctx, welethService.GetEthToWelCashoutByTxHash, txhash)
+	res := workflow.ExecuteActivity(ctx, welethService.GetEthToWelCashoutByTxHash, txhash, logIndex)
 	if err = res.Get(ctx, &tx); err != nil {
 		log.Error("[Core MSWeleth] Error while executing activity GetEthToWelCashoutByTxHash in weleth microservice", err.Error())
 		return
@@ -488,7 +510,7 @@ func (cli *Weleth) GetEthToWelCashinWithTx2TreasuryWF(ctx workflow.Context, send
 	return tx, nil
 }
 
-func (cli *Weleth) GetWelToEthCashoutByTxHashWF(ctx workflow.Context, txhash string) (tx welethService.WelCashoutEthTrans, err error) {
+func (cli *Weleth) GetWelToEthCashoutByTxHashWF(ctx workflow.Context, txhash string, logIndex int64) (tx welethService.WelCashoutEthTrans, err error) {
 	log := workflow.GetLogger(ctx)
 	log.Info("[Core MSWeleth] Getting cashout transaction from wel to eth with wel's side txhash: " + txhash)
 
@@ -509,7 +531,7 @@ func (cli *Weleth) GetWelToEthCashoutByTxHashWF(ctx workflow.Context, txhash str
 
 	// call weleth
 	log.Info("[Core MSWeleth] Call weleth...")
-	res := workflow.ExecuteActivity(ctx, welethService.GetWelToEthCashoutByTxHash, txhash)
+	res := workflow.ExecuteActivity(ctx, welethService.GetWelToEthCashoutByTxHash, txhash, logIndex)
 	if err = res.Get(ctx, &tx); err != nil {
 		log.Error("[Core MSWeleth] Error while executing activity GetWelToEthCashoutByTxHash in weleth microservice", err.Error())
 		return
@@ -609,6 +631,28 @@ func (cli *Weleth) registerService(w worker.Worker) {
 	w.RegisterActivity(cli.InvalidateW2ECashinClaim)
 	w.RegisterWorkflowWithOptions(cli.WaitForPendingE2WCashoutClaimRequestWF, workflow.RegisterOptions{Name: WaitForPendingE2WCashoutClaimRequestWF})
 
+	w.RegisterWorkflowWithOptions(cli.GetFeeRulesWF, workflow.RegisterOptions{Name: GetFeeRulesWF})
+	w.RegisterWorkflowWithOptions(cli.CreateFeeRuleWF, workflow.RegisterOptions{Name: CreateFeeRuleWF})
+	w.RegisterWorkflowWithOptions(cli.UpdateFeeRuleWF, workflow.RegisterOptions{Name: UpdateFeeRuleWF})
+	w.RegisterWorkflowWithOptions(cli.DeleteFeeRuleWF, workflow.RegisterOptions{Name: DeleteFeeRuleWF})
+
+	w.RegisterWorkflowWithOptions(cli.GetHeldTransfersWF, workflow.RegisterOptions{Name: GetHeldTransfersWF})
+	w.RegisterWorkflowWithOptions(cli.ReleaseHeldTransferWF, workflow.RegisterOptions{Name: ReleaseHeldTransferWF})
+
+	w.RegisterWorkflowWithOptions(cli.GetBridgeTokensWF, workflow.RegisterOptions{Name: GetBridgeTokensWF})
+	w.RegisterWorkflowWithOptions(cli.CreateBridgeTokenWF, workflow.RegisterOptions{Name: CreateBridgeTokenWF})
+	w.RegisterWorkflowWithOptions(cli.UpdateBridgeTokenWF, workflow.RegisterOptions{Name: UpdateBridgeTokenWF})
+	w.RegisterWorkflowWithOptions(cli.DeleteBridgeTokenWF, workflow.RegisterOptions{Name: DeleteBridgeTokenWF})
+
+	w.RegisterWorkflowWithOptions(cli.GetDeadLettersWF, workflow.RegisterOptions{Name: GetDeadLettersWF})
+	w.RegisterWorkflowWithOptions(cli.RequeueDeadLetterWF, workflow.RegisterOptions{Name: RequeueDeadLetterWF})
+
+	w.RegisterWorkflowWithOptions(cli.GetRefundsWF, workflow.RegisterOptions{Name: GetRefundsWF})
+	w.RegisterWorkflowWithOptions(cli.RejectRefundWF, workflow.RegisterOptions{Name: RejectRefundWF})
+
+	w.RegisterWorkflowWithOptions(cli.GetListenerLeasesWF, workflow.RegisterOptions{Name: GetListenerLeasesWF})
+	w.RegisterWorkflowWithOptions(cli.GetDaemonStatesWF, workflow.RegisterOptions{Name: GetDaemonStatesWF})
+
 }
 
 func (cli *Weleth) StartService() error {
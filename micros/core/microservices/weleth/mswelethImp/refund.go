@@ -0,0 +1,44 @@
+package mswelethImp
+
+import (
+	welethService "bridge/micros/weleth/temporal"
+	"fmt"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+func (cli *Weleth) GetRefundsWF(ctx workflow.Context, status string, offset, size uint64) ([]welethService.Refund, error) {
+	log := workflow.GetLogger(ctx)
+	log.Info("[Core MSWeleth] Getting refunds, status: " + status)
+	ctx = withAdminActivityOptions(ctx)
+
+	// call weleth
+	log.Info("[Core MSWeleth] Call weleth...")
+	var rs []welethService.Refund
+	res := workflow.ExecuteActivity(ctx, welethService.GetRefunds, status, offset, size)
+	if err := res.Get(ctx, &rs); err != nil {
+		log.Error("[Core MSWeleth] Error while executing activity GetRefunds in weleth microservice", err.Error())
+		return nil, err
+	}
+
+	log.Info("[Core MSWeleth] Call weleth successfully, result: ", rs)
+	return rs, nil
+}
+
+func (cli *Weleth) RejectRefundWF(ctx workflow.Context, id int64, note string) (welethService.Refund, error) {
+	log := workflow.GetLogger(ctx)
+	log.Info(fmt.Sprintf("[Core MSWeleth] Rejecting refund %d", id))
+	ctx = withAdminActivityOptions(ctx)
+
+	// call weleth
+	log.Info("[Core MSWeleth] Call weleth...")
+	var r welethService.Refund
+	res := workflow.ExecuteActivity(ctx, welethService.RejectRefund, id, note)
+	if err := res.Get(ctx, &r); err != nil {
+		log.Error("[Core MSWeleth] Error while executing activity RejectRefund in weleth microservice", err.Error())
+		return r, err
+	}
+
+	log.Info("[Core MSWeleth] Call weleth successfully, result: ", r)
+	return r, nil
+}
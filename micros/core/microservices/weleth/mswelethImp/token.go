@@ -0,0 +1,78 @@
+package mswelethImp
+
+import (
+	welethService "bridge/micros/weleth/temporal"
+	"fmt"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+func (cli *Weleth) GetBridgeTokensWF(ctx workflow.Context) ([]welethService.BridgeToken, error) {
+	log := workflow.GetLogger(ctx)
+	log.Info("[Core MSWeleth] Getting bridge tokens")
+	ctx = withAdminActivityOptions(ctx)
+
+	// call weleth
+	log.Info("[Core MSWeleth] Call weleth...")
+	var tokens []welethService.BridgeToken
+	res := workflow.ExecuteActivity(ctx, welethService.GetBridgeTokens)
+	if err := res.Get(ctx, &tokens); err != nil {
+		log.Error("[Core MSWeleth] Error while executing activity GetBridgeTokens in weleth microservice", err.Error())
+		return nil, err
+	}
+
+	log.Info("[Core MSWeleth] Call weleth successfully, result: ", tokens)
+	return tokens, nil
+}
+
+func (cli *Weleth) CreateBridgeTokenWF(ctx workflow.Context, token welethService.BridgeToken) (int64, error) {
+	log := workflow.GetLogger(ctx)
+	log.Info("[Core MSWeleth] Creating bridge token " + token.EthAddr + " <-> " + token.WelAddr)
+	ctx = withAdminActivityOptions(ctx)
+
+	// call weleth
+	log.Info("[Core MSWeleth] Call weleth...")
+	var id int64
+	res := workflow.ExecuteActivity(ctx, welethService.CreateBridgeToken, token)
+	if err := res.Get(ctx, &id); err != nil {
+		log.Error("[Core MSWeleth] Error while executing activity CreateBridgeToken in weleth microservice", err.Error())
+		return -1, err
+	}
+
+	log.Info("[Core MSWeleth] Call weleth successfully, result: ", id)
+	return id, nil
+}
+
+func (cli *Weleth) UpdateBridgeTokenWF(ctx workflow.Context, token welethService.BridgeToken) error {
+	log := workflow.GetLogger(ctx)
+	log.Info(fmt.Sprintf("[Core MSWeleth] Updating bridge token %d", token.ID))
+	ctx = withAdminActivityOptions(ctx)
+
+	// call weleth
+	log.Info("[Core MSWeleth] Call weleth...")
+	res := workflow.ExecuteActivity(ctx, welethService.UpdateBridgeToken, token)
+	if err := res.Get(ctx, nil); err != nil {
+		log.Error("[Core MSWeleth] Error while executing activity UpdateBridgeToken in weleth microservice", err.Error())
+		return err
+	}
+
+	log.Info("[Core MSWeleth] Call weleth successfully")
+	return nil
+}
+
+func (cli *Weleth) DeleteBridgeTokenWF(ctx workflow.Context, id int64) error {
+	log := workflow.GetLogger(ctx)
+	log.Info(fmt.Sprintf("[Core MSWeleth] Deleting bridge token %d", id))
+	ctx = withAdminActivityOptions(ctx)
+
+	// call weleth
+	log.Info("[Core MSWeleth] Call weleth...")
+	res := workflow.ExecuteActivity(ctx, welethService.DeleteBridgeToken, id)
+	if err := res.Get(ctx, nil); err != nil {
+		log.Error("[Core MSWeleth] Error while executing activity DeleteBridgeToken in weleth microservice", err.Error())
+		return err
+	}
+
+	log.Info("[Core MSWeleth] Call weleth successfully")
+	return nil
+}
@@ -24,4 +24,26 @@ const (
 
 	WaitForPendingW2ECashinClaimRequestWF  = "WaitForPendingW2ECashinClaimRequestWF"
 	WaitForPendingE2WCashoutClaimRequestWF = "WaitForPendingE2WCashoutClaimRequestWF"
+
+	GetFeeRulesWF   = "GetFeeRulesWF"
+	CreateFeeRuleWF = "CreateFeeRuleWF"
+	UpdateFeeRuleWF = "UpdateFeeRuleWF"
+	DeleteFeeRuleWF = "DeleteFeeRuleWF"
+
+	GetHeldTransfersWF    = "GetHeldTransfersWF"
+	ReleaseHeldTransferWF = "ReleaseHeldTransferWF"
+
+	GetBridgeTokensWF   = "GetBridgeTokensWF"
+	CreateBridgeTokenWF = "CreateBridgeTokenWF"
+	UpdateBridgeTokenWF = "UpdateBridgeTokenWF"
+	DeleteBridgeTokenWF = "DeleteBridgeTokenWF"
+
+	GetDeadLettersWF    = "GetDeadLettersWF"
+	RequeueDeadLetterWF = "RequeueDeadLetterWF"
+
+	GetRefundsWF   = "GetRefundsWF"
+	RejectRefundWF = "RejectRefundWF"
+
+	GetListenerLeasesWF = "GetListenerLeasesWF"
+	GetDaemonStatesWF   = "GetDaemonStatesWF"
 )
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- bridge transactions sent to ethereum, watched until mined. tx_hash is the current
+-- broadcast of the payload, tx_hashes every broadcast made for the nonce (cancellations
+-- included) and raw_tx the latest one, signed
+CREATE TABLE IF NOT EXISTS eth_outgoing_txs (
+  id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  from_address varchar(256) NOT NULL,
+  nonce bigint NOT NULL,
+  tx_hash varchar(100) NOT NULL,
+  tx_hashes varchar(100)[] NOT NULL,
+  raw_tx text NOT NULL,
+  replacements integer NOT NULL DEFAULT 0,
+  cancel_requested boolean NOT NULL DEFAULT FALSE,
+  mined_tx_hash varchar(100) NOT NULL DEFAULT '',
+  status varchar(20) NOT NULL DEFAULT 'pending',
+  sent_at timestamp NOT NULL DEFAULT NOW(),
+  created_at timestamp NOT NULL DEFAULT NOW(),
+  updated_at timestamp NOT NULL DEFAULT NOW(),
+
+  UNIQUE (from_address, nonce),
+  CHECK (status IN ('pending', 'mined', 'reverted', 'cancelled', 'dropped'))
+);
+
+CREATE INDEX IF NOT EXISTS eth_outgoing_txs_status_index ON eth_outgoing_txs(status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE eth_outgoing_txs CASCADE;
+-- +goose StatementEnd
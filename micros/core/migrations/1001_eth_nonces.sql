@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- next nonce of each signing address, the row is locked while a transaction is being sent.
+-- NULL until reconciled with the chain
+CREATE TABLE IF NOT EXISTS eth_nonces (
+  address varchar(256) PRIMARY KEY,
+  next_nonce bigint,
+  updated_at timestamp NOT NULL DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE eth_nonces;
+-- +goose StatementEnd
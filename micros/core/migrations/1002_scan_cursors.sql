@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+-- last block scanned by each consumer of the chain listeners, next to the listeners' own
+-- eth_last_scan_block/wel_last_scan_block
+CREATE TABLE IF NOT EXISTS scan_cursors (
+  chain varchar(8) NOT NULL,
+  consumer varchar(64) NOT NULL,
+  last_scan_block bigint NOT NULL DEFAULT 0,
+  updated_at timestamp NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (chain, consumer)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE scan_cursors CASCADE;
+-- +goose StatementEnd
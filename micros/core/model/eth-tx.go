@@ -0,0 +1,59 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	EthTxPending   = "pending"
+	EthTxMined     = "mined"
+	EthTxReverted  = "reverted"
+	EthTxCancelled = "cancelled"
+	EthTxDropped   = "dropped" // its nonce got used by a transaction we don't know of
+)
+
+var (
+	ErrEthTxNotFound           = fmt.Errorf("Ethereum transaction not found in internal system")
+	ErrEthTxSettled            = fmt.Errorf("Ethereum transaction no longer pending")
+	ErrEthSignerKeyUnavailable = fmt.Errorf("Key of the ethereum transaction's signer unavailable")
+)
+
+// EthOutgoingTx is a bridge transaction sent to ethereum, watched until its nonce is mined.
+// TxHash is the latest broadcast of its payload, TxHashes every broadcast made for the
+// nonce, RawTx the latest one.
+type EthOutgoingTx struct {
+	ID              int64          `json:"id" db:"id"`
+	From            string         `json:"from_address" db:"from_address"`
+	Nonce           uint64         `json:"nonce" db:"nonce"`
+	TxHash          string         `json:"tx_hash" db:"tx_hash"`
+	TxHashes        pq.StringArray `json:"tx_hashes" db:"tx_hashes"`
+	RawTx           string         `json:"-" db:"raw_tx"`
+	Replacements    int            `json:"replacements" db:"replacements"`
+	CancelRequested bool           `json:"cancel_requested" db:"cancel_requested"`
+	MinedTxHash     string         `json:"mined_tx_hash" db:"mined_tx_hash"`
+	Status          string         `json:"status" db:"status"`
+
+	SentAt    time.Time `json:"sent_at" db:"sent_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Cancelling tells whether the latest broadcast is a cancellation rather than the payload
+func (t *EthOutgoingTx) Cancelling() bool {
+	return len(t.TxHashes) > 0 && t.TxHashes[len(t.TxHashes)-1] != t.TxHash
+}
+
+// DueReplacement tells whether the transaction should be re-broadcast: it's been waiting
+// for longer than stuckAfter, or its cancellation was asked for and not broadcast yet
+func (t *EthOutgoingTx) DueReplacement(now time.Time, stuckAfter time.Duration) bool {
+	if t.Status != EthTxPending {
+		return false
+	}
+	if t.CancelRequested && !t.Cancelling() {
+		return true
+	}
+	return now.Sub(t.SentAt) >= stuckAfter
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEthOutgoingTxDueReplacement(t *testing.T) {
+	now := time.Now()
+	tx := EthOutgoingTx{TxHash: "0xa", TxHashes: []string{"0xa"}, Status: EthTxPending, SentAt: now.Add(-5 * time.Minute)}
+
+	if tx.DueReplacement(now, 10*time.Minute) {
+		t.Fatal("fresh transaction due replacement")
+	}
+	if !tx.DueReplacement(now, 5*time.Minute) {
+		t.Fatal("stuck transaction not due replacement")
+	}
+
+	tx.CancelRequested = true
+	if !tx.DueReplacement(now, 10*time.Minute) {
+		t.Fatal("requested cancellation not due")
+	}
+
+	tx.TxHashes = append(tx.TxHashes, "0xb")
+	if !tx.Cancelling() {
+		t.Fatal("cancellation broadcast not detected")
+	}
+	if tx.DueReplacement(now, 10*time.Minute) {
+		t.Fatal("broadcast cancellation due again before being stuck")
+	}
+
+	tx.Status = EthTxMined
+	if tx.DueReplacement(now, time.Minute) {
+		t.Fatal("mined transaction due replacement")
+	}
+}
@@ -34,4 +34,8 @@ var (
 	ErrEthAccountNotFound             = fmt.Errorf("Account not found in internal system")
 	ErrEthRoleNotFound                = fmt.Errorf("Role not found in internal system")
 	ErrEthAuthenticatorKeyUnavailable = fmt.Errorf("Ethereum authenticator key unavailable")
+	// followed by the cashout given up when notified
+	ErrEthDisperseRetriesExhausted = fmt.Errorf("Ethereum disperse retries exhausted")
+	// followed by the refused transaction when notified
+	ErrEthTxFeeAboveCeiling = fmt.Errorf("Ethereum transaction fee above ceiling")
 )
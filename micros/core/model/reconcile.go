@@ -0,0 +1,8 @@
+package model
+
+import "fmt"
+
+var (
+	ErrReconcileInvalidRange = fmt.Errorf("Invalid block range to reconcile")
+	ErrReconcileRunning      = fmt.Errorf("Reconciliation still running")
+)
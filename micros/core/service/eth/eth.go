@@ -5,16 +5,17 @@ import (
 	"bridge/micros/core/dao"
 	ethDAO "bridge/micros/core/dao/eth-account"
 	"bridge/micros/core/model"
+	"bridge/micros/core/service/eth/transactor"
+	ethListener "bridge/service-managers/listener/eth"
 	"bridge/service-managers/logger"
 	"context"
 	"fmt"
-	"math/big"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"go.temporal.io/sdk/client"
 	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/worker"
@@ -33,15 +34,15 @@ const (
 )
 
 type GovContractService struct {
-	gov          *ethGov.EthGov
-	dao          ethDAO.IEthDAO
-	cli          *ethclient.Client
-	tempCli      client.Client
-	worker       worker.Worker
-	lastGasPrice *big.Int
+	gov        *ethGov.EthGov
+	dao        ethDAO.IEthDAO
+	cli        ethListener.IEthClient
+	tempCli    client.Client
+	worker     worker.Worker
+	transactor *transactor.Transactor
 }
 
-func MkGovContractService(client *ethclient.Client, tempCli client.Client, daos *dao.DAOs, contractAddr string) (*GovContractService, error) {
+func MkGovContractService(client ethListener.IEthClient, tempCli client.Client, daos *dao.DAOs, contractAddr string, txr *transactor.Transactor) (*GovContractService, error) {
 	contractAddress := common.HexToAddress(contractAddr)
 	gov, err := ethGov.NewEthGov(contractAddress, client)
 	if err != nil {
@@ -49,38 +50,18 @@ func MkGovContractService(client *ethclient.Client, tempCli client.Client, daos
 		return nil, err
 	}
 
-	return &GovContractService{cli: client, tempCli: tempCli, gov: gov, dao: daos.Eth, lastGasPrice: big.NewInt(1000000000)}, nil
+	return &GovContractService{cli: client, tempCli: tempCli, gov: gov, dao: daos.Eth, transactor: txr}, nil
 }
 
 func (ctr *GovContractService) GrantRoleOnContract(ctx context.Context, target string, role string) (string, error) {
 	targetAddress := common.HexToAddress(target)
 
-	gasPrice, err := ctr.cli.SuggestGasPrice(context.Background())
-	if err != nil {
-		logger.Get().Err(err).Msgf("Unable to get recommended gas price, set to default")
-		gasPrice = ctr.lastGasPrice
-	}
-	ctr.lastGasPrice = gasPrice
-
 	callerkey := ctx.Value("callerkey").(string)
 	pkey, err := crypto.HexToECDSA(callerkey)
 	if err != nil {
 		logger.Get().Err(err).Msgf("Unable to parse hexstring to ECDSA key")
 		return "", err
 	}
-	caller := crypto.PubkeyToAddress(pkey.PublicKey)
-
-	nonce, err := ctr.cli.PendingNonceAt(ctx, caller)
-	if err != nil {
-		logger.Get().Err(err).Msgf("Unale to get last nonce of address %s", caller.Hex())
-		return "", err
-	}
-
-	opts := bind.NewKeyedTransactor(pkey)
-	opts.GasLimit = uint64(300000)
-	opts.Value = big.NewInt(0)
-	opts.GasPrice = gasPrice
-	opts.Nonce = big.NewInt(int64(nonce))
 
 	var brole [32]byte
 	if role == model.EthAccountRoleSuperAdmin {
@@ -89,7 +70,9 @@ func (ctr *GovContractService) GrantRoleOnContract(ctx context.Context, target s
 		copy(brole[:], crypto.Keccak256([]byte(role)))
 	}
 
-	tx, err := ctr.gov.GrantRole(opts, brole, targetAddress)
+	tx, err := ctr.transactor.Transact(ctx, pkey, nil, false, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return ctr.gov.GrantRole(opts, brole, targetAddress)
+	})
 	if err != nil {
 		logger.Get().Err(err).Msgf("Unable to trigger governance contract")
 		return "", err
@@ -168,32 +151,12 @@ func (ctr *GovContractService) GrantRoleWorkflow(ctx workflow.Context, target st
 func (ctr *GovContractService) RevokeRoleOnContract(ctx context.Context, target string, role string) (string, error) {
 	targetAddress := common.HexToAddress(target)
 
-	gasPrice, err := ctr.cli.SuggestGasPrice(context.Background())
-	if err != nil {
-		logger.Get().Err(err).Msgf("Unable to get recommended gas price, set to default")
-		gasPrice = ctr.lastGasPrice
-	}
-	ctr.lastGasPrice = gasPrice
-
 	callerkey := ctx.Value("callerkey").(string)
 	pkey, err := crypto.HexToECDSA(callerkey)
 	if err != nil {
 		logger.Get().Err(err).Msgf("Unable to parse hexstring to ECDSA key")
 		return "", err
 	}
-	caller := crypto.PubkeyToAddress(pkey.PublicKey)
-
-	nonce, err := ctr.cli.PendingNonceAt(ctx, caller)
-	if err != nil {
-		logger.Get().Err(err).Msgf("Unale to get last nonce of address %s", caller.Hex())
-		return "", err
-	}
-
-	opts := bind.NewKeyedTransactor(pkey)
-	opts.GasLimit = uint64(300000)
-	opts.Value = big.NewInt(0)
-	opts.GasPrice = gasPrice
-	opts.Nonce = big.NewInt(int64(nonce))
 
 	var brole [32]byte
 	if role == model.EthAccountRoleSuperAdmin {
@@ -202,7 +165,9 @@ func (ctr *GovContractService) RevokeRoleOnContract(ctx context.Context, target
 		copy(brole[:], crypto.Keccak256([]byte(role)))
 	}
 
-	tx, err := ctr.gov.RevokeRole(opts, brole, targetAddress)
+	tx, err := ctr.transactor.Transact(ctx, pkey, nil, false, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return ctr.gov.RevokeRole(opts, brole, targetAddress)
+	})
 	if err != nil {
 		logger.Get().Err(err).Msgf("Unable to trigger governance contract")
 		return "", err
@@ -3,12 +3,26 @@ package ethService
 const (
 	MulsendContractQueue = "EthMulsendContractService"
 
-	Disperse = "Disperse"
+	Disperse            = "Disperse"
+	EstimateDisperseFee = "EstimateDisperseFee"
+
+	// refunds of deposits to treasury, approved by an admin
+	RefundWF = "RefundWF"
+
+	// W2E cashouts of transfers to the Welups treasury
+	WatchForWelTx2TreasuryWF         = "WatchForWelTx2TreasuryWF"
+	WatchForWelTx2TreasuryByTxHashWF = "WatchForWelTx2TreasuryByTxHashWF"
 
 	// signal
 	BatchDisperseSignal = "BatchedDisperseSignal"
 
 	BatchDisperseID = "BatchDisperseWFOnlyInstance"
+
+	// re-submits declined disperses to BatchDisperse
+	DisperseRetryID = "DisperseRetryWFOnlyInstance"
+
+	// re-broadcasts stuck outgoing txs
+	EthTxMonitorID = "EthTxMonitorWFOnlyInstance"
 )
 
 //const (
@@ -0,0 +1,280 @@
+package mulsend
+
+import (
+	ethLogic "bridge/micros/core/blogic/eth"
+	"bridge/micros/core/model"
+	welethService "bridge/micros/weleth/temporal"
+	"bridge/service-managers/logger"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+var EthTxMonitorInterval = 30 * time.Second
+
+// GetPendingEthTxs lists the tracked transactions not mined yet
+func (ctr *MulsendContractService) GetPendingEthTxs(ctx context.Context) ([]model.EthOutgoingTx, error) {
+	return ctr.txs.GetEthTxs(model.EthTxPending, 0, 1000)
+}
+
+// PollEthTx looks for a receipt of any broadcast of t, settling t once one is found or its
+// nonce got used by something else
+func (ctr *MulsendContractService) PollEthTx(ctx context.Context, t model.EthOutgoingTx) (model.EthOutgoingTx, error) {
+	log := logger.Get()
+	from := common.HexToAddress(t.From)
+	// before receipts: a nonce used while they were looked for must not pass for dropped
+	nonce, err := ctr.cli.NonceAt(ctx, from, nil)
+	if err != nil {
+		log.Err(err).Msgf("[Tx monitor] Unable to get nonce of address %s", t.From)
+		return t, err
+	}
+
+	for _, h := range t.TxHashes {
+		receipt, err := ctr.cli.TransactionReceipt(ctx, common.HexToHash(h))
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if err != nil {
+			log.Err(err).Msgf("[Tx monitor] Unable to get receipt of tx %s", h)
+			return t, err
+		}
+
+		t.Status = model.EthTxMined
+		t.MinedTxHash = h
+		if receipt.Status == types.ReceiptStatusFailed {
+			t.Status = model.EthTxReverted
+		} else if h != t.TxHash {
+			tx, _, err := ctr.cli.TransactionByHash(ctx, receipt.TxHash)
+			if err != nil {
+				log.Err(err).Msgf("[Tx monitor] Unable to get tx %s", h)
+				return t, err
+			}
+			if isCancellation(tx, from) {
+				t.Status = model.EthTxCancelled
+			}
+		}
+		break
+	}
+	if t.Status == model.EthTxPending && nonce > t.Nonce {
+		t.Status = model.EthTxDropped
+	}
+
+	if t.Status == model.EthTxPending {
+		return t, nil
+	}
+	if err := ctr.txs.SettleEthTx(t.ID, t.Status, t.MinedTxHash); err != nil {
+		log.Err(err).Msgf("[Tx monitor] Unable to settle tx %s", t.TxHash)
+		return t, err
+	}
+	log.Info().Msgf("[Tx monitor] Tx %s of %s nonce %d settled as %s", t.TxHash, t.From, t.Nonce, t.Status)
+	return t, nil
+}
+
+// ReplaceEthTx signs and records a re-broadcast of a pending transaction with bumped fees,
+// cancelling it when asked. It's sent by SendEthTx.
+func (ctr *MulsendContractService) ReplaceEthTx(ctx context.Context, id int64) (model.EthOutgoingTx, error) {
+	log := logger.Get()
+	t, err := ctr.txs.GetEthTx(id)
+	if err != nil {
+		return model.EthOutgoingTx{}, err
+	}
+	old, err := decodeTx(t.RawTx)
+	if err != nil {
+		log.Err(err).Msgf("[Tx monitor] Unable to decode tx %s", t.TxHash)
+		return *t, err
+	}
+
+	callerkey, err := ethLogic.GetAuthenticatorKey()
+	if err != nil {
+		log.Err(err).Msgf("[Tx monitor] Unable to get authenticator's key")
+		return *t, err
+	}
+	pkey, err := crypto.HexToECDSA(callerkey)
+	if err != nil {
+		log.Err(err).Msgf("[Tx monitor] Unable to parse hexstring to ECDSA key")
+		return *t, err
+	}
+	if !strings.EqualFold(crypto.PubkeyToAddress(pkey.PublicKey).Hex(), t.From) {
+		return *t, model.ErrEthSignerKeyUnavailable
+	}
+
+	tx, err := ctr.transactor.Replace(ctx, pkey, old, t.CancelRequested)
+	if err != nil {
+		log.Err(err).Msgf("[Tx monitor] Unable to replace tx %s", t.TxHash)
+		return *t, err
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return *t, err
+	}
+	replaced, err := ctr.txs.ReplaceEthTx(id, tx.Hash().Hex(), !t.CancelRequested, hexutil.Encode(raw))
+	if err != nil {
+		return *t, err
+	}
+	log.Info().Msgf("[Tx monitor] Tx %s of %s nonce %d replaced by %s", t.TxHash, t.From, t.Nonce, tx.Hash().Hex())
+	return *replaced, nil
+}
+
+// SendEthTx broadcasts the latest signed transaction of a pending nonce
+func (ctr *MulsendContractService) SendEthTx(ctx context.Context, id int64) error {
+	log := logger.Get()
+	t, err := ctr.txs.GetEthTx(id)
+	if err != nil {
+		return err
+	}
+	if t.Status != model.EthTxPending {
+		return nil
+	}
+	tx, err := decodeTx(t.RawTx)
+	if err != nil {
+		log.Err(err).Msgf("[Tx monitor] Unable to decode tx of %s nonce %d", t.From, t.Nonce)
+		return err
+	}
+	return ctr.sendTx(ctx, tx)
+}
+
+// SendSignedEthTx hands a transaction signed but not sent yet over to the monitor, then
+// broadcasts it. Once tracked, the monitor broadcasts it again if this fails.
+func (ctr *MulsendContractService) SendSignedEthTx(ctx context.Context, rawTx string) error {
+	tx, err := decodeTx(rawTx)
+	if err != nil {
+		logger.Get().Err(err).Msgf("[Tx monitor] Unable to decode tx")
+		return err
+	}
+	if err := ctr.transactor.Track(tx); err != nil {
+		return err
+	}
+	return ctr.sendTx(ctx, tx)
+}
+
+func (ctr *MulsendContractService) sendTx(ctx context.Context, tx *types.Transaction) error {
+	if err := ctr.cli.SendTransaction(ctx, tx); err != nil {
+		// already broadcast, or its nonce got mined in the meantime: left to PollEthTx
+		if msg := err.Error(); strings.Contains(msg, "already known") || strings.Contains(msg, "nonce too low") {
+			logger.Get().Warn().Msgf("[Tx monitor] Tx %s not sent: %s", tx.Hash().Hex(), msg)
+			return nil
+		}
+		logger.Get().Err(err).Msgf("[Tx monitor] Unable to send tx %s", tx.Hash().Hex())
+		return err
+	}
+	return nil
+}
+
+// EthTxMonitorWF watches the bridge transactions sent to ethereum until they're mined,
+// re-broadcasting the ones pending for longer than stuckAfter with bumped fees. Weleth's
+// records are kept pointing to the transaction that makes it on chain.
+func (ctr *MulsendContractService) EthTxMonitorWF(ctx workflow.Context, stuckAfter time.Duration) error {
+	for iterN := 1; iterN <= 6000; iterN++ {
+		if err := workflow.Sleep(ctx, EthTxMonitorInterval); err != nil {
+			// canceled
+			return nil
+		}
+		ctr.monitorTxs(ctx, stuckAfter)
+	}
+
+	workflow.GetLogger(ctx).Info("[EthTxMonitorWF] iteration number passed 6000, continuing as new WF insance...")
+	return workflow.NewContinueAsNewError(ctx, ctr.EthTxMonitorWF, stuckAfter)
+}
+
+func (ctr *MulsendContractService) monitorTxs(ctx workflow.Context, stuckAfter time.Duration) {
+	log := workflow.GetLogger(ctx)
+	ao := workflow.ActivityOptions{
+		TaskQueue:              MulsendContractQueue,
+		ScheduleToCloseTimeout: time.Second * 60,
+		ScheduleToStartTimeout: time.Second * 60,
+		StartToCloseTimeout:    time.Second * 60,
+		HeartbeatTimeout:       time.Second * 10,
+		WaitForCancellation:    false,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumInterval: time.Second * 100,
+			MaximumAttempts: 10,
+		},
+	}
+	mctx := workflow.WithActivityOptions(ctx, ao)
+	wctx := workflow.WithTaskQueue(mctx, welethService.WelethServiceQueue)
+	// a replacement refused by the fee caps is only tried again on the next round
+	once := ao
+	once.RetryPolicy = &temporal.RetryPolicy{MaximumAttempts: 1}
+	octx := workflow.WithActivityOptions(ctx, once)
+
+	var pending []model.EthOutgoingTx
+	if err := workflow.ExecuteActivity(mctx, ctr.GetPendingEthTxs).Get(mctx, &pending); err != nil {
+		log.Error("Failed to get pending ethereum txs", "error", err)
+		return
+	}
+
+	for _, t := range pending {
+		var polled model.EthOutgoingTx
+		if err := workflow.ExecuteActivity(mctx, ctr.PollEthTx, t).Get(mctx, &polled); err != nil {
+			log.Error(fmt.Sprintf("Failed to poll tx %s", t.TxHash), "error", err)
+			continue
+		}
+
+		switch polled.Status {
+		case model.EthTxMined:
+			if polled.MinedTxHash != polled.TxHash {
+				// an earlier broadcast made it
+				if err := workflow.ExecuteActivity(wctx, welethService.ReplaceEthTxHash, polled.TxHash, polled.MinedTxHash).Get(wctx, nil); err != nil {
+					log.Error(fmt.Sprintf("Failed to point tx %s records to %s", polled.TxHash, polled.MinedTxHash), "error", err)
+				}
+			}
+			if workflow.GetVersion(ctx, "confirm-mined-txs", workflow.DefaultVersion, 1) == workflow.DefaultVersion {
+				continue
+			}
+			if err := workflow.ExecuteActivity(wctx, welethService.ConfirmEthTx, polled.MinedTxHash).Get(wctx, nil); err != nil {
+				log.Error(fmt.Sprintf("Failed to confirm tx %s records", polled.MinedTxHash), "error", err)
+			}
+		case model.EthTxReverted, model.EthTxCancelled, model.EthTxDropped:
+			note := fmt.Sprintf("ethereum tx %s %s", polled.TxHash, polled.Status)
+			if err := workflow.ExecuteActivity(wctx, welethService.AbandonEthTx, polled.TxHash, note).Get(wctx, nil); err != nil {
+				log.Error(fmt.Sprintf("Failed to abandon tx %s records", polled.TxHash), "error", err)
+			}
+		case model.EthTxPending:
+			if polled.DueReplacement(workflow.Now(ctx), stuckAfter) {
+				ctr.replaceTx(mctx, wctx, octx, polled)
+			}
+		}
+	}
+}
+
+func (ctr *MulsendContractService) replaceTx(mctx, wctx, octx workflow.Context, t model.EthOutgoingTx) {
+	log := workflow.GetLogger(mctx)
+	var replaced model.EthOutgoingTx
+	if err := workflow.ExecuteActivity(octx, ctr.ReplaceEthTx, t.ID).Get(octx, &replaced); err != nil {
+		log.Error(fmt.Sprintf("Failed to replace tx %s", t.TxHash), "error", err)
+		return
+	}
+	// records are moved to the replacement before it's sent, so that they never miss it
+	if replaced.TxHash != t.TxHash {
+		if err := workflow.ExecuteActivity(wctx, welethService.ReplaceEthTxHash, t.TxHash, replaced.TxHash).Get(wctx, nil); err != nil {
+			log.Error(fmt.Sprintf("Failed to point tx %s records to %s", t.TxHash, replaced.TxHash), "error", err)
+		}
+	}
+	if err := workflow.ExecuteActivity(mctx, ctr.SendEthTx, t.ID).Get(mctx, nil); err != nil {
+		log.Error(fmt.Sprintf("Failed to send replacement of tx %s", t.TxHash), "error", err)
+	}
+}
+
+func decodeTx(raw string) (*types.Transaction, error) {
+	b, err := hexutil.Decode(raw)
+	if err != nil {
+		return nil, err
+	}
+	tx := new(types.Transaction)
+	return tx, tx.UnmarshalBinary(b)
+}
+
+// isCancellation tells a cancellation, a zero value self transfer, from a payload
+func isCancellation(tx *types.Transaction, from common.Address) bool {
+	return tx.To() != nil && *tx.To() == from && tx.Value().Sign() == 0 && len(tx.Data()) == 0
+}
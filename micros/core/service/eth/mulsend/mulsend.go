@@ -8,9 +8,12 @@ import (
 	"bridge/micros/core/config"
 	"bridge/micros/core/dao"
 	ethDAO "bridge/micros/core/dao/eth-account"
+	ethTxDAO "bridge/micros/core/dao/eth-tx"
 	ethService "bridge/micros/core/service/eth"
+	"bridge/micros/core/service/eth/transactor"
 	welethModel "bridge/micros/weleth/model"
 	welethService "bridge/micros/weleth/temporal"
+	ethListener "bridge/service-managers/listener/eth"
 	"bridge/service-managers/logger"
 	"context"
 	"fmt"
@@ -20,8 +23,8 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"go.temporal.io/sdk/client"
 	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/worker"
@@ -43,10 +46,11 @@ import (
 type MulsendContractService struct {
 	mulsend            *ethMulsend.EthMultiSenderC
 	dao                ethDAO.IEthDAO
-	cli                *ethclient.Client
+	cli                ethListener.IEthClient
 	tempCli            client.Client
 	worker             worker.Worker
-	lastGasPrice       *big.Int
+	transactor         *transactor.Transactor
+	txs                ethTxDAO.IEthTxDAO
 	batchDisperseID    string
 	batchDisperseRunID string
 }
@@ -54,84 +58,97 @@ type MulsendContractService struct {
 const (
 	MulsendContractQueue = ethService.MulsendContractQueue
 
-	Disperse = ethService.Disperse
+	Disperse            = ethService.Disperse
+	EstimateDisperseFee = ethService.EstimateDisperseFee
+
+	RefundWF = ethService.RefundWF
+
+	WatchForWelTx2TreasuryWF         = ethService.WatchForWelTx2TreasuryWF
+	WatchForWelTx2TreasuryByTxHashWF = ethService.WatchForWelTx2TreasuryByTxHashWF
 
 	// signal
 	BatchDisperseSignal = ethService.BatchDisperseSignal
 
 	BatchDisperseID = ethService.BatchDisperseID
+	DisperseRetryID = ethService.DisperseRetryID
+	EthTxMonitorID  = ethService.EthTxMonitorID
 )
 
-func MkMulsendContractService(client *ethclient.Client, tempCli client.Client, daos *dao.DAOs, contractAddr string) (*MulsendContractService, error) {
+func MkMulsendContractService(client ethListener.IEthClient, tempCli client.Client, daos *dao.DAOs, contractAddr string, txr *transactor.Transactor) (*MulsendContractService, error) {
 	mulsend, err := ethMulsend.NewEthMultiSenderC(common.HexToAddress(contractAddr), client)
 	if err != nil {
 		logger.Get().Err(err).Msgf("Unable to create multisender contract interface at address %s", contractAddr)
 		return nil, err
 	}
 
-	return &MulsendContractService{cli: client, tempCli: tempCli, mulsend: mulsend, dao: daos.Eth, lastGasPrice: big.NewInt(1000000000)}, nil
+	return &MulsendContractService{cli: client, tempCli: tempCli, mulsend: mulsend, dao: daos.Eth, transactor: txr, txs: daos.EthTx}, nil
 }
 
 func (ctr *MulsendContractService) Disperse(ctx context.Context, tokenAddr string, receivers []string, values []*big.Int) (string, error) {
-	callerkey, err := ethLogic.GetAuthenticatorKey()
+	tx, err := ctr.disperseTx(ctx, tokenAddr, receivers, values, disperseSend)
 	if err != nil {
-		logger.Get().Err(err).Msgf("Unable to get authenticator's key")
 		return "", err
 	}
-	pkey, err := crypto.HexToECDSA(callerkey)
-	if err != nil {
-		logger.Get().Err(err).Msgf("Unable to parse hexstring to ECDSA key")
-		return "", err
+	logger.Get().Info().Msgf("Contract call done with tx: %+v", tx)
+	if err := ctr.transactor.Track(tx); err != nil {
+		logger.Get().Err(err).Msgf("Disperse tx %s sent but not tracked", tx.Hash().Hex())
 	}
-	caller := crypto.PubkeyToAddress(pkey.PublicKey)
+	return tx.Hash().Hex(), nil
+}
 
-	gasPrice, err := ctr.cli.SuggestGasPrice(context.Background())
-	if err != nil {
-		logger.Get().Err(err).Msgf("Unable to get recommended gas price, set to default")
-		gasPrice = ctr.lastGasPrice
-	}
-	ctr.lastGasPrice = gasPrice
+// how disperseTx signs a disperse call
+type disperseMode int
+
+const (
+	disperseSend     disperseMode = iota // sent right away
+	disperseEstimate                     // never sent, no nonce is allocated to it
+	disperseSign                         // sent later on, its nonce is allocated
+)
 
-	nonce, err := ctr.cli.PendingNonceAt(ctx, caller)
+// disperseTx signs a disperse call, sending it or not depending on mode
+func (ctr *MulsendContractService) disperseTx(ctx context.Context, tokenAddr string, receivers []string, values []*big.Int, mode disperseMode) (*types.Transaction, error) {
+	callerkey, err := ethLogic.GetAuthenticatorKey()
 	if err != nil {
-		logger.Get().Err(err).Msgf("Unale to get last nonce of address %s", caller.Hex())
-		return "", err
+		logger.Get().Err(err).Msgf("Unable to get authenticator's key")
+		return nil, err
 	}
-
-	env := config.Get().Environment
-	opts, err := bind.NewKeyedTransactorWithChainID(pkey, consts.EthChainFromEnv[env])
+	pkey, err := crypto.HexToECDSA(callerkey)
 	if err != nil {
-		logger.Get().Err(err).Msg("Unale to create call opts for Disperse method")
-		return "", err
+		logger.Get().Err(err).Msgf("Unable to parse hexstring to ECDSA key")
+		return nil, err
 	}
-	opts.GasLimit = 0
-	opts.Value = big.NewInt(0)
+
+	value := big.NewInt(0)
 	if tokenAddr == consts.EthereumTk {
-		sumValues := libs.Reduce(
+		value = libs.Reduce(
 			func(accum *big.Int, this *big.Int) *big.Int {
 				accum = accum.Add(accum, this)
 				return accum
 			},
 			big.NewInt(0),
 			values)
-		opts.Value = sumValues
 	}
-	logger.Get().Info().Msgf("Total values: %s", opts.Value.String())
-	opts.GasPrice = gasPrice
-	opts.Nonce = big.NewInt(int64(nonce))
+	logger.Get().Info().Msgf("Total values: %s", value.String())
 
 	tokenAddress := common.HexToAddress(tokenAddr)
 	receiversAddress := libs.Map(
 		func(addr string) common.Address {
 			return common.HexToAddress(addr)
 		}, receivers)
-	tx, err := ctr.mulsend.Disperse(opts, tokenAddress, receiversAddress, values)
+	call := func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return ctr.mulsend.Disperse(opts, tokenAddress, receiversAddress, values)
+	}
+	var tx *types.Transaction
+	if mode == disperseSign {
+		tx, err = ctr.transactor.Sign(ctx, pkey, value, call)
+	} else {
+		tx, err = ctr.transactor.Transact(ctx, pkey, value, mode == disperseEstimate, call)
+	}
 	if err != nil {
 		logger.Get().Err(err).Msgf("Unable to trigger mulsend contract")
-		return "", err
+		return nil, err
 	}
-	logger.Get().Info().Msgf("Contract call done with tx: %+v", tx)
-	return tx.Hash().Hex(), nil
+	return tx, nil
 }
 
 //type txQueue = []welethModel.EthCashoutEthTrans
@@ -176,6 +193,21 @@ func (ctr *MulsendContractService) BatchDisperseWF(ctx workflow.Context) error {
 			var tx = welethModel.WelCashoutEthTrans{}
 			channel.Receive(ctx, &tx)
 			log.Info(fmt.Sprintf("BatchDisperse received tx: %+v", tx))
+
+			// over-limit cashouts are held for review, releasing one sends it back here
+			if workflow.GetVersion(ctx, "bridge-limits", workflow.DefaultVersion, 1) != workflow.DefaultVersion {
+				var held bool
+				lctx := workflow.WithTaskQueue(ctx, welethService.WelethServiceQueue)
+				limited := welethModel.BridgeTransfer{Kind: welethModel.TransferW2ECashoutDisperse, Ref: tx.WelWithdrawTxHash, LogIndex: tx.LogIndex, TokenAddr: tx.WelTokenAddr, Address: tx.WelWalletAddr, Amount: tx.Total}
+				if err := workflow.ExecuteActivity(lctx, welethService.CheckBridgeLimit, limited).Get(lctx, &held); err != nil {
+					log.Error("Failed to check bridge limits, cashout left out of BatchDisperse: "+tx.WelWithdrawTxHash, "error", err)
+					return
+				}
+				if held {
+					log.Info("Cashout held for review: " + tx.WelWithdrawTxHash)
+					return
+				}
+			}
 			ethToken := tx.EthTokenAddr
 			_, ok := allTxQueues[ethToken]
 			if !ok {
@@ -197,7 +229,7 @@ func (ctr *MulsendContractService) BatchDisperseWF(ctx workflow.Context) error {
 				values := libs.Map(
 					func(tx welethModel.WelCashoutEthTrans) *big.Int {
 						ret := &big.Int{}
-						ret.SetString(tx.Total, 10)
+						ret.SetString(tx.DestAmount, 10)
 						return ret
 					}, allTxQueues[ethToken].queue)
 				log.Info(fmt.Sprintf("BatchDisperse values: %+v", values))
@@ -264,7 +296,7 @@ func (ctr *MulsendContractService) BatchDisperseWF(ctx workflow.Context) error {
 					values := libs.Map(
 						func(tx welethModel.WelCashoutEthTrans) *big.Int {
 							ret := &big.Int{}
-							ret.SetString(tx.Total, 10)
+							ret.SetString(tx.DestAmount, 10)
 							return ret
 						}, allTxQueues[ethToken].queue)
 					// issue
@@ -328,8 +360,20 @@ func (ctr *MulsendContractService) BatchDisperseWF(ctx workflow.Context) error {
 func (ctr *MulsendContractService) registerService(w worker.Worker) {
 	//w.RegisterActivity(ctr.Withdraw)
 	w.RegisterActivity(ctr.Disperse)
+	w.RegisterActivity(ctr.EstimateDisperseFee)
+	w.RegisterActivity(ctr.SignDisperse)
+	w.RegisterActivity(ctr.SendSignedEthTx)
+	w.RegisterActivity(ctr.GetPendingEthTxs)
+	w.RegisterActivity(ctr.PollEthTx)
+	w.RegisterActivity(ctr.ReplaceEthTx)
+	w.RegisterActivity(ctr.SendEthTx)
 
 	w.RegisterWorkflow(ctr.BatchDisperseWF)
+	w.RegisterWorkflow(ctr.RefundWF)
+	w.RegisterWorkflow(ctr.DisperseRetryWF)
+	w.RegisterWorkflow(ctr.EthTxMonitorWF)
+	w.RegisterWorkflowWithOptions(ctr.WatchForWelTx2Treasury, workflow.RegisterOptions{Name: WatchForWelTx2TreasuryWF})
+	w.RegisterWorkflowWithOptions(ctr.WatchForWelTx2TreasuryByTxHash, workflow.RegisterOptions{Name: WatchForWelTx2TreasuryByTxHashWF})
 }
 
 func (ctr *MulsendContractService) StartService() error {
@@ -359,6 +403,26 @@ func (ctr *MulsendContractService) StartService() error {
 	ctr.batchDisperseID = we.GetID()
 	ctr.batchDisperseRunID = we.GetRunID()
 
+	// start declined disperses retry WF
+	wo = client.StartWorkflowOptions{
+		TaskQueue: MulsendContractQueue,
+		ID:        DisperseRetryID, // only one workflow ID allowed at all time
+	}
+	if _, err := ctr.tempCli.ExecuteWorkflow(ctx, wo, ctr.DisperseRetryWF); err != nil {
+		logger.Get().Err(err).Msgf("Error while starting long-running workflow DisperseRetry")
+		return err
+	}
+
+	// start stuck txs monitor WF
+	wo = client.StartWorkflowOptions{
+		TaskQueue: MulsendContractQueue,
+		ID:        EthTxMonitorID, // only one workflow ID allowed at all time
+	}
+	if _, err := ctr.tempCli.ExecuteWorkflow(ctx, wo, ctr.EthTxMonitorWF, config.Get().EthereumConfig.Gas.StuckTxTimeout); err != nil {
+		logger.Get().Err(err).Msgf("Error while starting long-running workflow EthTxMonitor")
+		return err
+	}
+
 	return nil
 }
 
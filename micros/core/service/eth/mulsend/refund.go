@@ -0,0 +1,127 @@
+package mulsend
+
+import (
+	"bridge/common/consts"
+	welethModel "bridge/micros/weleth/model"
+	welethService "bridge/micros/weleth/temporal"
+	"bridge/service-managers/logger"
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// EstimateDisperseFee returns the most a disperse call would cost in gas, in wei, at the
+// current fees: its gas limit at its max fee per gas
+func (ctr *MulsendContractService) EstimateDisperseFee(ctx context.Context, tokenAddr string, receivers []string, values []*big.Int) (string, error) {
+	tx, err := ctr.disperseTx(ctx, tokenAddr, receivers, values, disperseEstimate)
+	if err != nil {
+		logger.Get().Err(err).Msgf("Unable to estimate disperse fee")
+		return "", err
+	}
+	fee := (&big.Int{}).Mul(tx.GasPrice(), big.NewInt(int64(tx.Gas())))
+	return fee.String(), nil
+}
+
+// RefundWF sends an approved refund back to the depositor. The gas of the refund is
+// deducted from refunded ether, the treasury bears it for tokens. Only a refund failing
+// before it's signed can be approved again.
+func (ctr *MulsendContractService) RefundWF(ctx workflow.Context, id int64) (welethModel.Refund, error) {
+	log := workflow.GetLogger(ctx)
+	retried := workflow.ActivityOptions{
+		TaskQueue:              MulsendContractQueue,
+		ScheduleToCloseTimeout: time.Second * 60,
+		ScheduleToStartTimeout: time.Second * 60,
+		StartToCloseTimeout:    time.Second * 60,
+		HeartbeatTimeout:       time.Second * 10,
+		WaitForCancellation:    false,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumInterval: time.Second * 100,
+			MaximumAttempts: 10,
+		},
+	}
+	wctx := workflow.WithActivityOptions(ctx, retried)
+	wctx = workflow.WithTaskQueue(wctx, welethService.WelethServiceQueue)
+	mctx := workflow.WithActivityOptions(ctx, retried)
+
+	var r welethModel.Refund
+	if err := workflow.ExecuteActivity(wctx, welethService.StartRefund, id).Get(wctx, &r); err != nil {
+		log.Error(fmt.Sprintf("Unable to start refund %d", id), "error", err)
+		return r, err
+	}
+
+	fail := func(err error) (welethModel.Refund, error) {
+		log.Error(fmt.Sprintf("Refund %d of tx to treasury %s failed", r.ID, r.TxID), "error", err)
+		r.Status = welethModel.RefundFailed
+		r.Note = err.Error()
+		if uerr := workflow.ExecuteActivity(wctx, welethService.UpdateRefund, r).Get(wctx, nil); uerr != nil {
+			log.Error(fmt.Sprintf("Unable to update refund %d", r.ID), "error", uerr)
+		}
+		return r, err
+	}
+
+	amount, ok := (&big.Int{}).SetString(r.Amount, 10)
+	if !ok {
+		return fail(welethModel.ErrInvalidAmount)
+	}
+	receivers := []string{r.ToAddress}
+
+	gasFee := "0"
+	if r.TokenAddr == consts.EthereumTk {
+		err := workflow.ExecuteActivity(mctx, ctr.EstimateDisperseFee, r.TokenAddr, receivers, []*big.Int{amount}).Get(mctx, &gasFee)
+		if err != nil {
+			return fail(err)
+		}
+	}
+	if err := r.Deduct(gasFee); err != nil {
+		return fail(err)
+	}
+
+	// the refund is signed once and its tx recorded before it's sent: from then on it's only
+	// settled by the tx monitor, once the tx is mined or known never to be
+	once := retried
+	once.RetryPolicy = &temporal.RetryPolicy{MaximumAttempts: 1}
+	octx := workflow.WithActivityOptions(ctx, once)
+	refunded, _ := (&big.Int{}).SetString(r.RefundAmount, 10)
+	var signed SignedTx
+	if err := workflow.ExecuteActivity(octx, ctr.SignDisperse, r.TokenAddr, receivers, []*big.Int{refunded}).Get(octx, &signed); err != nil {
+		return fail(err)
+	}
+
+	r.RefundTxHash = signed.TxHash
+	r.Status = welethModel.RefundSent
+	if err := workflow.ExecuteActivity(wctx, welethService.UpdateRefund, r).Get(wctx, nil); err != nil {
+		log.Error(fmt.Sprintf("Refund %d signed in %s but not recorded, not sending it", r.ID, signed.TxHash), "error", err)
+		return r, err
+	}
+	if err := workflow.ExecuteActivity(mctx, ctr.SendSignedEthTx, signed.RawTx).Get(mctx, nil); err != nil {
+		log.Error(fmt.Sprintf("Refund %d tx %s may not be sent, left to the tx monitor", r.ID, signed.TxHash), "error", err)
+		return r, err
+	}
+	log.Info(fmt.Sprintf("Refund %d of tx to treasury %s sent in %s", r.ID, r.TxID, signed.TxHash))
+	return r, nil
+}
+
+// SignedTx is a transaction signed but not sent yet
+type SignedTx struct {
+	TxHash string
+	RawTx  string
+}
+
+// SignDisperse signs a disperse call without sending it, see SendSignedEthTx
+func (ctr *MulsendContractService) SignDisperse(ctx context.Context, tokenAddr string, receivers []string, values []*big.Int) (SignedTx, error) {
+	tx, err := ctr.disperseTx(ctx, tokenAddr, receivers, values, disperseSign)
+	if err != nil {
+		return SignedTx{}, err
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		logger.Get().Err(err).Msgf("Unable to encode disperse tx %s", tx.Hash().Hex())
+		return SignedTx{}, err
+	}
+	return SignedTx{TxHash: tx.Hash().Hex(), RawTx: hexutil.Encode(raw)}, nil
+}
@@ -0,0 +1,93 @@
+package mulsend
+
+import (
+	"bridge/micros/core/model"
+	"bridge/micros/core/service/notifier"
+	welethModel "bridge/micros/weleth/model"
+	welethService "bridge/micros/weleth/temporal"
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+var DisperseRetryInterval = time.Minute
+
+// DisperseRetryWF periodically sends the cashouts whose disperse got declined back to
+// BatchDisperse, with backoff, and escalates to admins the ones out of attempts
+func (ctr *MulsendContractService) DisperseRetryWF(ctx workflow.Context) error {
+	ao := workflow.ActivityOptions{
+		TaskQueue:              welethService.WelethServiceQueue,
+		ScheduleToCloseTimeout: time.Second * 60,
+		ScheduleToStartTimeout: time.Second * 60,
+		StartToCloseTimeout:    time.Second * 60,
+		HeartbeatTimeout:       time.Second * 10,
+		WaitForCancellation:    false,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumInterval: time.Second * 100,
+			MaximumAttempts: 10,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	for iterN := 1; iterN <= 6000; iterN++ {
+		if err := workflow.Sleep(ctx, DisperseRetryInterval); err != nil {
+			// canceled
+			return nil
+		}
+		ctr.retryDeclined(ctx)
+	}
+
+	workflow.GetLogger(ctx).Info("[DisperseRetryWF] iteration number passed 6000, continuing as new WF insance...")
+	return workflow.NewContinueAsNewError(ctx, ctr.DisperseRetryWF)
+}
+
+func (ctr *MulsendContractService) retryDeclined(ctx workflow.Context) {
+	log := workflow.GetLogger(ctx)
+
+	var due []welethModel.DisperseRetry
+	if err := workflow.ExecuteActivity(ctx, welethService.GetDueDisperseRetries).Get(ctx, &due); err != nil {
+		log.Error("Failed to get declined disperses to retry", "error", err)
+		return
+	}
+
+	for _, r := range due {
+		if r.Exhausted() {
+			ctr.giveUpDisperse(ctx, r)
+			continue
+		}
+
+		// an attempt recorded but never making it to BatchDisperse is retried once stalled,
+		// see GetDueDisperseRetries
+		var attempt welethModel.DisperseAttempt
+		if err := workflow.ExecuteActivity(ctx, welethService.StartDisperseRetry, r.ID).Get(ctx, &attempt); err != nil {
+			log.Error(fmt.Sprintf("Failed to start disperse attempt of W2E cashout %d", r.ID), "error", err)
+			continue
+		}
+		tran := r.WelCashoutEthTrans
+		tran.DisperseStatus = welethModel.WelCashoutEthUnconfirmed
+		if err := workflow.SignalExternalWorkflow(ctx, BatchDisperseID, "", BatchDisperseSignal, tran).Get(ctx, nil); err != nil {
+			log.Error(fmt.Sprintf("Failed to send W2E cashout %d to BatchDisperse", r.ID), "error", err)
+			continue
+		}
+		log.Info(fmt.Sprintf("W2E cashout %d sent to BatchDisperse, attempt %d", r.ID, attempt.Attempt))
+	}
+}
+
+func (ctr *MulsendContractService) giveUpDisperse(ctx workflow.Context, r welethModel.DisperseRetry) {
+	log := workflow.GetLogger(ctx)
+	if err := workflow.ExecuteActivity(ctx, welethService.GiveUpDisperseRetry, r.ID).Get(ctx, nil); err != nil {
+		log.Error(fmt.Sprintf("Failed to give up disperse of W2E cashout %d", r.ID), "error", err)
+		return
+	}
+
+	problem := fmt.Sprintf("%s: W2E cashout %d, withdraw tx %s, %s of eth token %s to %s, last declined disperse tx %s after %d attempts",
+		model.ErrEthDisperseRetriesExhausted.Error(), r.ID, r.WelWithdrawTxHash, r.DestAmount, r.EthTokenAddr, r.EthWalletAddr, r.EthDisperseTxHash, r.Attempts)
+	cctx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{TaskQueue: notifier.NotifierQueue})
+	if err := workflow.ExecuteChildWorkflow(cctx, notifier.NotifyProblemWF, problem, "admin").Get(cctx, nil); err != nil {
+		log.Error("Failed to notify admins of problem: "+problem, "error", err)
+		return
+	}
+	log.Info(fmt.Sprintf("Disperse of W2E cashout %d given up, admins notified", r.ID))
+}
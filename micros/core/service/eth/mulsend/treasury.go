@@ -0,0 +1,136 @@
+package mulsend
+
+import (
+	welethModel "bridge/micros/weleth/model"
+	welethService "bridge/micros/weleth/temporal"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// WatchForWelTx2Treasury turns a confirmed transfer to the Welups treasury into a W2E
+// cashout to the eth address to, looking the transfer up again in 2 minutes if it's not
+// confirmed yet
+func (ctr *MulsendContractService) WatchForWelTx2Treasury(ctx workflow.Context, from, to, treasury, netid, token, amount string) error {
+	ctx = withWelTx2TreasuryOptions(ctx)
+	return watchForWelTx2Treasury(ctx, "[WatchForWelTx2Treasury]", func() (welethModel.TxToTreasury, error) {
+		var tx welethModel.TxToTreasury
+		err := workflow.ExecuteActivity(ctx, welethService.GetWelTx2Treasury, from, treasury, token, amount).Get(ctx, &tx)
+		return tx, err
+	}, to, netid)
+}
+
+// WatchForWelTx2TreasuryByTxHash is WatchForWelTx2Treasury for the transfer of txhash at
+// logIndex
+func (ctr *MulsendContractService) WatchForWelTx2TreasuryByTxHash(ctx workflow.Context, txhash, to, netid string, logIndex int64) error {
+	ctx = withWelTx2TreasuryOptions(ctx)
+	return watchForWelTx2Treasury(ctx, "[WatchForWelTx2TreasuryByTxHash]", func() (welethModel.TxToTreasury, error) {
+		var tx welethModel.TxToTreasury
+		err := workflow.ExecuteActivity(ctx, welethService.GetWelTx2TreasuryByTxHash, txhash, logIndex).Get(ctx, &tx)
+		return tx, err
+	}, to, netid)
+}
+
+func withWelTx2TreasuryOptions(ctx workflow.Context) workflow.Context {
+	ao := workflow.ActivityOptions{
+		TaskQueue:              welethService.WelethServiceQueue,
+		ScheduleToCloseTimeout: time.Second * 60,
+		ScheduleToStartTimeout: time.Second * 60,
+		StartToCloseTimeout:    time.Second * 60,
+		HeartbeatTimeout:       time.Second * 10,
+		WaitForCancellation:    false,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumInterval: time.Second * 30,
+		},
+	}
+	return workflow.WithActivityOptions(ctx, ao)
+}
+
+func watchForWelTx2Treasury(ctx workflow.Context, tag string, getTx func() (welethModel.TxToTreasury, error), to, netid string) error {
+	log := workflow.GetLogger(ctx)
+
+	cashout := func() error {
+		tx, err := getTx()
+		if err != nil {
+			log.Error(tag+" error while getting wel tx2treasury", err)
+			return err
+		}
+
+		var ethToken string
+		res := workflow.ExecuteActivity(ctx, welethService.MapWelTokenToEth, tx.TokenAddr)
+		err = res.Get(ctx, &ethToken)
+		if err != nil {
+			log.Error(tag+" error while getting corresponding eth token", err)
+			return err
+		}
+
+		// already confirmed by the confirmation tracker, straight to BatchDisperse
+		cashoutTx := welethModel.WelCashoutEthTrans{
+			WelWithdrawTxHash: tx.TxID,
+			LogIndex:          tx.LogIndex,
+
+			EthTokenAddr: ethToken,
+			WelTokenAddr: tx.TokenAddr,
+
+			EthWalletAddr: to,
+			WelWalletAddr: tx.FromAddress,
+
+			NetworkID: netid,
+			Total:     tx.Amount,
+
+			CashoutStatus:       welethModel.WelCashoutEthConfirmed,
+			DisperseStatus:      welethModel.WelCashoutEthUnconfirmed,
+			WithdrawBlockNumber: tx.BlockNumber,
+			Confirmations:       tx.Confirmations,
+		}
+
+		var quote welethModel.FeeQuote
+		res = workflow.ExecuteActivity(ctx, welethService.ComputeCommissionFee, tx.TokenAddr, welethModel.FeeWelToEth, cashoutTx.Total)
+		err = res.Get(ctx, &quote)
+		if err != nil {
+			log.Error(tag+" error while computing commission fee", err)
+			return err
+		}
+		cashoutTx.Amount = quote.Amount
+		cashoutTx.CommissionFee = quote.CommissionFee
+		cashoutTx.DestAmount = quote.DestAmount
+		cashoutTx.Dust = quote.Dust
+
+		res = workflow.ExecuteActivity(ctx, welethService.CreateWelCashoutEthTrans, cashoutTx)
+		err = res.Get(ctx, &(cashoutTx.ID))
+		if err != nil {
+			log.Error(tag+" error while creating W2ECashout trans", err)
+			return err
+		}
+
+		se := workflow.SignalExternalWorkflow(ctx, BatchDisperseID, "", BatchDisperseSignal, cashoutTx)
+		err = se.Get(ctx, nil)
+		if err != nil {
+			log.Error(tag+" error while sending tx to BatchDisperse", err)
+			return err
+		}
+		return nil
+	}
+
+	timer := workflow.NewTimer(ctx, 2*time.Minute)
+	selector := workflow.NewSelector(ctx)
+
+	selector.AddReceive(ctx.Done(), func(channel workflow.ReceiveChannel, more bool) {})
+	selector.AddFuture(timer, func(f workflow.Future) {
+		if err := cashout(); err == welethModel.ErrTx2TreasuryNotFound {
+			log.Error(tag + " wel tx2treasury not found")
+			return
+		}
+		log.Info(tag + " tx enqueued for BatchDisperseWF")
+	})
+
+	// main
+	if err := cashout(); err == welethModel.ErrTx2TreasuryNotFound {
+		selector.Select(ctx)
+	} else if err != nil {
+		return err
+	}
+
+	return nil
+}
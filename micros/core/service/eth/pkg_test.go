@@ -1,10 +1,12 @@
 package ethService
 
 import (
+	"bridge/common/consts"
 	"bridge/micros/core/config"
 	"bridge/micros/core/dao"
 	userdao "bridge/micros/core/dao/user"
 	"bridge/micros/core/model"
+	"bridge/micros/core/service/eth/transactor"
 	manager "bridge/service-managers"
 	"bridge/service-managers/logger"
 	"context"
@@ -66,7 +68,7 @@ func TestMain(m *testing.M) {
 	}
 	defer ethCli.Close()
 
-	GovService, err = MkGovContractService(ethCli, tempcli, daos, cnf.EthGovContract)
+	GovService, err = MkGovContractService(ethCli, tempcli, daos, cnf.EthGovContract, transactor.MkTransactor(ethCli, consts.EthChainFromEnv[cnf.Environment], cnf.EthereumConfig.Gas, nil, nil, nil))
 	if err != nil {
 		logger.Get().Err(err).Msgf("Unable to initialize GovContractService")
 		return
@@ -0,0 +1,115 @@
+package transactor
+
+import (
+	ethNonceDAO "bridge/micros/core/dao/eth-nonce"
+	ethListener "bridge/service-managers/listener/eth"
+	"bridge/service-managers/logger"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// a stored nonce ahead of the chain's pending one for longer than this is a gap: the
+// transactions it was handed out to never reached the nodes. Until then nodes are assumed
+// to lag behind.
+var NonceGapGrace = 2 * time.Minute
+
+// NonceManager hands out sequential nonces per signing address. The nonce of an address is
+// locked in Postgres from allocation until its transaction is sent, so that concurrent
+// senders, across workers, never reuse one.
+type NonceManager struct {
+	cli    ethListener.IEthClient
+	nonces ethNonceDAO.IEthNonceDAO
+}
+
+func MkNonceManager(cli ethListener.IEthClient, nonces ethNonceDAO.IEthNonceDAO) *NonceManager {
+	return &NonceManager{cli: cli, nonces: nonces}
+}
+
+// NonceLease is a nonce allocated to a transaction being sent
+type NonceLease struct {
+	lock  *ethNonceDAO.NonceLock
+	Nonce uint64
+}
+
+// Acquire locks the nonce of address until the returned lease is done with
+func (m *NonceManager) Acquire(ctx context.Context, address common.Address) (*NonceLease, error) {
+	log := logger.Get()
+	lock, err := m.nonces.LockNonce(address.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := m.cli.PendingNonceAt(ctx, address)
+	if err != nil {
+		log.Err(err).Msgf("[Nonce manager] Unable to get pending nonce of address %s", address.Hex())
+		lock.Release()
+		return nil, err
+	}
+
+	stored := uint64(lock.Next.Int64)
+	next, gap := reconcileNonce(stored, lock.Next.Valid, pending, lock.Age)
+	if gap {
+		log.Warn().Msgf("[Nonce manager] Nonce gap of address %s: nonces %d to %d never reached the chain, handing out %d again",
+			address.Hex(), pending, stored-1, pending)
+	}
+	return &NonceLease{lock: lock, Nonce: next}, nil
+}
+
+// Done releases the lease once its transaction is sent, or failed to be with err. The nonce
+// is consumed on success, and taken from the chain again after nonce errors.
+func (l *NonceLease) Done(err error) error {
+	if err == nil {
+		return l.lock.Commit(l.Nonce + 1)
+	}
+	if isNonceError(err) {
+		logger.Get().Warn().Msgf("[Nonce manager] Nonce %d of %s rejected, reconciling with the chain: %s", l.Nonce, l.lock.Address, err.Error())
+		return l.lock.Forget()
+	}
+	return l.lock.Release()
+}
+
+// Reconcile checks every known address' nonce against the chain, on startup
+func (m *NonceManager) Reconcile(ctx context.Context) error {
+	addresses, err := m.nonces.GetNonceAddresses()
+	if err != nil {
+		return err
+	}
+	for _, address := range addresses {
+		lease, err := m.Acquire(ctx, common.HexToAddress(address))
+		if err != nil {
+			return err
+		}
+		if err := lease.lock.Commit(lease.Nonce); err != nil {
+			return err
+		}
+		logger.Get().Info().Msgf("[Nonce manager] Next nonce of %s: %d", address, lease.Nonce)
+	}
+	return nil
+}
+
+// reconcileNonce picks the next nonce from the stored one and the chain's pending one,
+// stored for since, telling whether the stored one is past a gap
+func reconcileNonce(stored uint64, known bool, pending uint64, since time.Duration) (next uint64, gap bool) {
+	switch {
+	case !known || stored < pending:
+		// never reconciled, or sent from outside of the manager
+		return pending, false
+	case stored > pending && since >= NonceGapGrace:
+		return pending, true
+	}
+	return stored, false
+}
+
+// isNonceError tells the errors of nodes refusing a transaction for its nonce
+func isNonceError(err error) bool {
+	msg := err.Error()
+	for _, e := range []string{"nonce too low", "nonce too high", "already known", "replacement transaction underpriced"} {
+		if strings.Contains(msg, e) {
+			return true
+		}
+	}
+	return false
+}
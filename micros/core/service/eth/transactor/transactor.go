@@ -0,0 +1,314 @@
+package transactor
+
+import (
+	"bridge/common"
+	ethTxDAO "bridge/micros/core/dao/eth-tx"
+	"bridge/micros/core/model"
+	ethListener "bridge/service-managers/listener/eth"
+	"bridge/service-managers/logger"
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var gwei = big.NewInt(1000000000)
+
+// a transaction refused again, e.g. each time the monitor tries replacing it, is only
+// alerted about once in a while
+var RefusalAlertInterval = time.Hour
+
+// Call is a contract method bound to the opts it's signed with
+type Call func(opts *bind.TransactOpts) (*types.Transaction, error)
+
+// Transactor signs every outgoing ethereum transaction of the core: fees are EIP-1559
+// dynamic fees bounded by EthGasConf, gas is estimated with a safety margin, and
+// transactions expected to cost above the ceiling are refused and alerted about.
+type Transactor struct {
+	cli     ethListener.IEthClient
+	chainID *big.Int
+	conf    common.EthGasConf
+	txs     ethTxDAO.IEthTxDAO
+	nonces  *NonceManager
+	alert   func(problem string)
+
+	mu      sync.Mutex
+	alerted map[string]time.Time // by sender and nonce
+}
+
+// nonces may be nil, nonces being then taken from the chain's pending state
+func MkTransactor(cli ethListener.IEthClient, chainID *big.Int, conf common.EthGasConf, txs ethTxDAO.IEthTxDAO, nonces *NonceManager, alert func(problem string)) *Transactor {
+	return &Transactor{cli: cli, chainID: chainID, conf: conf, txs: txs, nonces: nonces, alert: alert, alerted: make(map[string]time.Time)}
+}
+
+// Transact signs call with pkey, sending it unless noSend. call is invoked twice: once to
+// estimate its gas, then to sign it with the final gas limit.
+func (t *Transactor) Transact(ctx context.Context, pkey *ecdsa.PrivateKey, value *big.Int, noSend bool, call Call) (*types.Transaction, error) {
+	return t.transact(ctx, pkey, value, noSend, !noSend, call)
+}
+
+// Sign signs call with pkey without sending it, its nonce being allocated all the same so
+// that the transaction can be recorded before it's sent
+func (t *Transactor) Sign(ctx context.Context, pkey *ecdsa.PrivateKey, value *big.Int, call Call) (*types.Transaction, error) {
+	return t.transact(ctx, pkey, value, true, true, call)
+}
+
+func (t *Transactor) transact(ctx context.Context, pkey *ecdsa.PrivateKey, value *big.Int, noSend, allocate bool, call Call) (tx *types.Transaction, err error) {
+	caller := crypto.PubkeyToAddress(pkey.PublicKey)
+	opts, err := bind.NewKeyedTransactorWithChainID(pkey, t.chainID)
+	if err != nil {
+		logger.Get().Err(err).Msg("[Transactor] Unable to create call opts")
+		return nil, err
+	}
+
+	var nonce uint64
+	if t.nonces != nil && allocate {
+		lease, err := t.nonces.Acquire(ctx, caller)
+		if err != nil {
+			logger.Get().Err(err).Msgf("[Transactor] Unable to allocate nonce of address %s", caller.Hex())
+			return nil, err
+		}
+		// held until sent, or signed only
+		defer func() {
+			if derr := lease.Done(err); derr != nil {
+				logger.Get().Err(derr).Msgf("[Transactor] Unable to release nonce %d of address %s", lease.Nonce, caller.Hex())
+			}
+		}()
+		nonce = lease.Nonce
+	} else {
+		nonce, err = t.cli.PendingNonceAt(ctx, caller)
+		if err != nil {
+			logger.Get().Err(err).Msgf("[Transactor] Unable to get last nonce of address %s", caller.Hex())
+			return nil, err
+		}
+	}
+	opts.Context = ctx
+	opts.Nonce = new(big.Int).SetUint64(nonce)
+	opts.Value = value
+	if opts.Value == nil {
+		opts.Value = big.NewInt(0)
+	}
+
+	perGas, err := t.fees(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// gas limit 0 makes bind estimate it
+	opts.GasLimit = 0
+	opts.NoSend = true
+	estimated, err := call(opts)
+	if err != nil {
+		logger.Get().Err(err).Msg("[Transactor] Unable to estimate gas")
+		return nil, err
+	}
+
+	opts.GasLimit = withMargin(estimated.Gas(), t.conf.GasLimitMultiplier)
+	if err := checkCeiling(opts.GasLimit, perGas, t.conf); err != nil {
+		t.refuse(opts, perGas, err)
+		return nil, err
+	}
+
+	opts.NoSend = noSend
+	return call(opts)
+}
+
+// fees sets the dynamic fees of opts and returns the expected price per gas, chains
+// without a base fee get a capped legacy gas price instead
+func (t *Transactor) fees(ctx context.Context, opts *bind.TransactOpts) (*big.Int, error) {
+	head, err := t.cli.HeaderByNumber(ctx, nil)
+	if err != nil {
+		logger.Get().Err(err).Msg("[Transactor] Unable to get chain head")
+		return nil, err
+	}
+
+	if head.BaseFee == nil {
+		gasPrice, err := t.cli.SuggestGasPrice(ctx)
+		if err != nil {
+			logger.Get().Err(err).Msg("[Transactor] Unable to get recommended gas price")
+			return nil, err
+		}
+		gasPrice = capped(gasPrice, t.conf.MaxFeePerGasGwei)
+		opts.GasPrice = gasPrice
+		return gasPrice, nil
+	}
+
+	suggested, err := t.cli.SuggestGasTipCap(ctx)
+	if err != nil {
+		logger.Get().Err(err).Msg("[Transactor] Unable to get recommended priority fee, using its cap")
+		suggested = new(big.Int).Mul(new(big.Int).SetUint64(t.conf.MaxPriorityFeeGwei), gwei)
+	}
+	tip, feeCap, err := dynamicFees(head.BaseFee, suggested, t.conf)
+	opts.GasTipCap, opts.GasFeeCap = tip, feeCap
+	perGas := new(big.Int).Add(head.BaseFee, tip)
+	if err != nil {
+		t.refuse(opts, perGas, err)
+		return nil, err
+	}
+	return perGas, nil
+}
+
+// Track hands a sent bridge transaction over to the stuck transaction monitor
+func (t *Transactor) Track(tx *types.Transaction) error {
+	if t.txs == nil {
+		return nil
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(t.chainID), tx)
+	if err != nil {
+		logger.Get().Err(err).Msgf("[Transactor] Unable to get sender of tx %s", tx.Hash().Hex())
+		return err
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		logger.Get().Err(err).Msgf("[Transactor] Unable to encode tx %s", tx.Hash().Hex())
+		return err
+	}
+	return t.txs.TrackEthTx(&model.EthOutgoingTx{
+		From:     from.Hex(),
+		Nonce:    tx.Nonce(),
+		TxHash:   tx.Hash().Hex(),
+		TxHashes: []string{tx.Hash().Hex()},
+		RawTx:    hexutil.Encode(raw),
+	})
+}
+
+// Replace signs a re-broadcast of old with the same nonce, its fees bumped enough to be
+// accepted by nodes in place of it. A cancellation is a zero value transfer to the signer.
+// The replacement isn't sent.
+func (t *Transactor) Replace(ctx context.Context, pkey *ecdsa.PrivateKey, old *types.Transaction, cancel bool) (*types.Transaction, error) {
+	from := crypto.PubkeyToAddress(pkey.PublicKey)
+	opts := &bind.TransactOpts{From: from, Nonce: new(big.Int).SetUint64(old.Nonce()), GasLimit: old.Gas()}
+	to, value, data := old.To(), old.Value(), old.Data()
+	if cancel {
+		opts.GasLimit = 21000
+		to, value, data = &from, big.NewInt(0), nil
+	}
+
+	perGas, err := t.fees(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var txdata types.TxData
+	if opts.GasPrice != nil {
+		opts.GasPrice = replacementFee(opts.GasPrice, old.GasPrice(), t.conf.ReplacementBumpPercent)
+		perGas = opts.GasPrice
+		txdata = &types.LegacyTx{Nonce: old.Nonce(), GasPrice: opts.GasPrice, Gas: opts.GasLimit, To: to, Value: value, Data: data}
+	} else {
+		baseFee := new(big.Int).Sub(perGas, opts.GasTipCap)
+		opts.GasTipCap = replacementFee(opts.GasTipCap, old.GasTipCap(), t.conf.ReplacementBumpPercent)
+		opts.GasFeeCap = replacementFee(opts.GasFeeCap, old.GasFeeCap(), t.conf.ReplacementBumpPercent)
+		perGas = new(big.Int).Add(baseFee, opts.GasTipCap)
+		if opts.GasFeeCap.Cmp(perGas) < 0 {
+			opts.GasFeeCap = perGas
+		}
+		txdata = &types.DynamicFeeTx{ChainID: t.chainID, Nonce: old.Nonce(), GasTipCap: opts.GasTipCap, GasFeeCap: opts.GasFeeCap,
+			Gas: opts.GasLimit, To: to, Value: value, Data: data}
+	}
+
+	maxFee := perGas
+	if opts.GasFeeCap != nil {
+		maxFee = opts.GasFeeCap
+	}
+	if err := checkReplacement(maxFee, opts.GasLimit, perGas, t.conf); err != nil {
+		t.refuse(opts, perGas, err)
+		return nil, err
+	}
+
+	return types.SignNewTx(pkey, types.LatestSignerForChainID(t.chainID), txdata)
+}
+
+func (t *Transactor) refuse(opts *bind.TransactOpts, perGas *big.Int, err error) {
+	problem := fmt.Sprintf("%s, from %s, nonce %s, gas limit %d, price per gas %s wei",
+		err.Error(), opts.From.Hex(), opts.Nonce.String(), opts.GasLimit, perGas.String())
+	logger.Get().Warn().Msgf("[Transactor] Refused transaction: %s", problem)
+	if t.alert == nil {
+		return
+	}
+
+	key := opts.From.Hex() + "/" + opts.Nonce.String()
+	now := time.Now()
+	t.mu.Lock()
+	last, seen := t.alerted[key]
+	due := !seen || now.Sub(last) >= RefusalAlertInterval
+	if due {
+		t.alerted[key] = now
+	}
+	t.mu.Unlock()
+	if due {
+		t.alert(problem)
+	}
+}
+
+// dynamicFees bounds the tip by its cap, and leaves room for the base fee to double
+// within the max fee per gas
+func dynamicFees(baseFee, suggestedTip *big.Int, conf common.EthGasConf) (tip, feeCap *big.Int, err error) {
+	tip = capped(suggestedTip, conf.MaxPriorityFeeGwei)
+	feeCap = new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), tip)
+	feeCap = capped(feeCap, conf.MaxFeePerGasGwei)
+	if feeCap.Cmp(new(big.Int).Add(baseFee, tip)) < 0 {
+		return tip, feeCap, fmt.Errorf("%w: base fee %s wei over max fee per gas", model.ErrEthTxFeeAboveCeiling, baseFee.String())
+	}
+	return tip, feeCap, nil
+}
+
+// checkCeiling refuses transactions expected to cost more than the configured ceiling
+func checkCeiling(gasLimit uint64, perGas *big.Int, conf common.EthGasConf) error {
+	if conf.TxFeeCeilingGwei == 0 {
+		return nil
+	}
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(gasLimit), perGas)
+	ceiling := new(big.Int).Mul(new(big.Int).SetUint64(conf.TxFeeCeilingGwei), gwei)
+	if fee.Cmp(ceiling) > 0 {
+		return fmt.Errorf("%w: expected fee %s wei", model.ErrEthTxFeeAboveCeiling, fee.String())
+	}
+	return nil
+}
+
+// checkReplacement applies the caps to a replacement, whose bumped fees may exceed the max
+// fee per gas
+func checkReplacement(maxFee *big.Int, gasLimit uint64, perGas *big.Int, conf common.EthGasConf) error {
+	if conf.MaxFeePerGasGwei != 0 && maxFee.Cmp(new(big.Int).Mul(new(big.Int).SetUint64(conf.MaxFeePerGasGwei), gwei)) > 0 {
+		return fmt.Errorf("%w: replacement fee %s wei over max fee per gas", model.ErrEthTxFeeAboveCeiling, maxFee.String())
+	}
+	return checkCeiling(gasLimit, perGas, conf)
+}
+
+// replacementFee is the current fee, or the replaced one bumped by bumpPercent when higher
+func replacementFee(current, replaced *big.Int, bumpPercent uint64) *big.Int {
+	bumped := new(big.Int).Mul(replaced, new(big.Int).SetUint64(100+bumpPercent))
+	bumped.Add(bumped, big.NewInt(99)) // rounded up, nodes reject bumps short by a wei
+	bumped.Div(bumped, big.NewInt(100))
+	if current.Cmp(bumped) > 0 {
+		return new(big.Int).Set(current)
+	}
+	return bumped
+}
+
+// withMargin multiplies estimated gas by the safety multiplier, never lowering it
+func withMargin(gas uint64, multiplier float64) uint64 {
+	if multiplier <= 1 {
+		return gas
+	}
+	return uint64(math.Ceil(float64(gas) * multiplier))
+}
+
+// capped bounds wei by a cap in gwei, a zero cap meaning unbounded
+func capped(wei *big.Int, capGwei uint64) *big.Int {
+	if capGwei == 0 {
+		return new(big.Int).Set(wei)
+	}
+	max := new(big.Int).Mul(new(big.Int).SetUint64(capGwei), gwei)
+	if wei.Cmp(max) > 0 {
+		return max
+	}
+	return new(big.Int).Set(wei)
+}
@@ -0,0 +1,123 @@
+package transactor
+
+import (
+	"bridge/common"
+	"bridge/micros/core/model"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func gweis(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), gwei)
+}
+
+func TestDynamicFees(t *testing.T) {
+	conf := common.EthGasConf{MaxFeePerGasGwei: 100, MaxPriorityFeeGwei: 2}
+
+	tip, feeCap, err := dynamicFees(gweis(30), gweis(1), conf)
+	if err != nil || tip.Cmp(gweis(1)) != 0 || feeCap.Cmp(gweis(61)) != 0 {
+		t.Fatalf("got tip %s, fee cap %s, err %v", tip, feeCap, err)
+	}
+
+	tip, feeCap, err = dynamicFees(gweis(60), gweis(5), conf)
+	if err != nil || tip.Cmp(gweis(2)) != 0 || feeCap.Cmp(gweis(100)) != 0 {
+		t.Fatalf("caps not applied: tip %s, fee cap %s, err %v", tip, feeCap, err)
+	}
+
+	if _, _, err = dynamicFees(gweis(99), gweis(2), conf); !errors.Is(err, model.ErrEthTxFeeAboveCeiling) {
+		t.Fatalf("base fee over max fee per gas accepted: %v", err)
+	}
+
+	if _, feeCap, _ = dynamicFees(gweis(500), gweis(1), common.EthGasConf{}); feeCap.Cmp(gweis(1001)) != 0 {
+		t.Fatalf("zero caps should be unbounded, got fee cap %s", feeCap)
+	}
+}
+
+func TestCheckCeiling(t *testing.T) {
+	conf := common.EthGasConf{TxFeeCeilingGwei: 1000000}
+	if err := checkCeiling(20000, gweis(50), conf); err != nil {
+		t.Fatalf("fee at ceiling refused: %v", err)
+	}
+	if err := checkCeiling(20001, gweis(50), conf); !errors.Is(err, model.ErrEthTxFeeAboveCeiling) {
+		t.Fatalf("fee above ceiling accepted: %v", err)
+	}
+	if err := checkCeiling(1<<40, gweis(50), common.EthGasConf{}); err != nil {
+		t.Fatalf("zero ceiling should be unbounded: %v", err)
+	}
+}
+
+func TestWithMargin(t *testing.T) {
+	for _, c := range []struct {
+		gas        uint64
+		multiplier float64
+		want       uint64
+	}{
+		{100000, 1.2, 120000},
+		{21001, 1.5, 31502},
+		{50000, 1, 50000},
+		{50000, 0, 50000},
+	} {
+		if got := withMargin(c.gas, c.multiplier); got != c.want {
+			t.Errorf("withMargin(%d, %v) = %d, want %d", c.gas, c.multiplier, got, c.want)
+		}
+	}
+}
+
+func TestReplacementFee(t *testing.T) {
+	for _, c := range []struct {
+		current, replaced *big.Int
+		want              *big.Int
+	}{
+		{gweis(10), gweis(10), gweis(11)},
+		{gweis(20), gweis(10), gweis(20)},
+		{big.NewInt(101), big.NewInt(101), big.NewInt(112)},
+	} {
+		if got := replacementFee(c.current, c.replaced, 10); got.Cmp(c.want) != 0 {
+			t.Errorf("replacementFee(%s, %s, 10) = %s, want %s", c.current, c.replaced, got, c.want)
+		}
+	}
+}
+
+func TestCheckReplacement(t *testing.T) {
+	conf := common.EthGasConf{MaxFeePerGasGwei: 100}
+	if err := checkReplacement(gweis(100), 21000, gweis(50), conf); err != nil {
+		t.Fatalf("replacement at max fee per gas refused: %v", err)
+	}
+	if err := checkReplacement(gweis(101), 21000, gweis(50), conf); !errors.Is(err, model.ErrEthTxFeeAboveCeiling) {
+		t.Fatalf("replacement over max fee per gas accepted: %v", err)
+	}
+}
+
+func TestReconcileNonce(t *testing.T) {
+	for _, c := range []struct {
+		name    string
+		stored  uint64
+		known   bool
+		pending uint64
+		since   time.Duration
+		next    uint64
+		gap     bool
+	}{
+		{"unknown", 0, false, 7, 0, 7, false},
+		{"in sync", 7, true, 7, time.Hour, 7, false},
+		{"sent from elsewhere", 5, true, 7, 0, 7, false},
+		{"nodes lagging", 9, true, 7, time.Second, 9, false},
+		{"gap", 9, true, 7, NonceGapGrace, 7, true},
+	} {
+		next, gap := reconcileNonce(c.stored, c.known, c.pending, c.since)
+		if next != c.next || gap != c.gap {
+			t.Errorf("%s: got next %d, gap %v, want %d, %v", c.name, next, gap, c.next, c.gap)
+		}
+	}
+}
+
+func TestIsNonceError(t *testing.T) {
+	if !isNonceError(errors.New("nonce too low")) || !isNonceError(errors.New("replacement transaction underpriced")) {
+		t.Fatal("nonce errors not recognized")
+	}
+	if isNonceError(errors.New("insufficient funds for gas * price + value")) {
+		t.Fatal("funds error taken for a nonce one")
+	}
+}
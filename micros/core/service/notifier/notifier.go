@@ -9,6 +9,7 @@ import (
 	"bridge/service-managers/logger"
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"go.temporal.io/sdk/client"
@@ -36,6 +37,32 @@ const (
 	Authenticator key at %s.
 	`
 	notificationSubjectNoAuthKey = "[Welbridge system] No Authenticator key available for %s side"
+
+	notificationEmailDisperseGivenUp = `
+	[Timestamp=%s] This email is automatically sent to every admin of the Welbridge system
+	to notify them of a certain operation problem.
+
+
+	To all admins of Welbridge system: the Ethereum disperse of a Welups to Ethereum cashout
+	kept being declined by the MultiSender contract and was given up after every retry.
+	The user hasn't received their tokens and the cashout needs to be handled manually.
+
+	%s
+	`
+	notificationSubjectDisperseGivenUp = "[Welbridge system] Ethereum disperse given up"
+
+	notificationEmailTxFeeAboveCeiling = `
+	[Timestamp=%s] This email is automatically sent to every admin of the Welbridge system
+	to notify them of a certain operation problem.
+
+
+	To all admins of Welbridge system: an outgoing Ethereum transaction was refused because
+	its fee exceeded the configured caps (ETH_MAX_FEE_PER_GAS_GWEI, ETH_TX_FEE_CEILING_GWEI).
+	The operation will keep failing until gas prices come down or the caps are raised.
+
+	%s
+	`
+	notificationSubjectTxFeeAboveCeiling = "[Welbridge system] Ethereum transaction fee above ceiling"
 )
 
 type Notifier struct {
@@ -106,6 +133,16 @@ func (notifier *Notifier) handleProblem(prob string, role string) error {
 		subject := fmt.Sprintf(notificationSubjectNoAuthKey, chain)
 		return notifier.sendNotificationToRole(role, subject, mailBody)
 	default:
+		if givenUp := model.ErrEthDisperseRetriesExhausted.Error(); strings.HasPrefix(prob, givenUp) {
+			details := strings.TrimPrefix(strings.TrimPrefix(prob, givenUp), ": ")
+			mailBody := fmt.Sprintf(notificationEmailDisperseGivenUp, time.Now().String(), details)
+			return notifier.sendNotificationToRole(role, notificationSubjectDisperseGivenUp, mailBody)
+		}
+		if refused := model.ErrEthTxFeeAboveCeiling.Error(); strings.HasPrefix(prob, refused) {
+			details := strings.TrimPrefix(strings.TrimPrefix(prob, refused), ": ")
+			mailBody := fmt.Sprintf(notificationEmailTxFeeAboveCeiling, time.Now().String(), details)
+			return notifier.sendNotificationToRole(role, notificationSubjectTxFeeAboveCeiling, mailBody)
+		}
 		return nil
 	}
 	return nil
@@ -9,13 +9,13 @@ import (
 	welService "bridge/micros/core/service/wel"
 	welethModel "bridge/micros/weleth/model"
 	welethService "bridge/micros/weleth/temporal"
+	welListener "bridge/service-managers/listener/wel"
 	"bridge/service-managers/logger"
 	"context"
 	"math/big"
 	"sort"
 	"time"
 
-	welclient "github.com/Paven-Org/gotron-sdk/pkg/client"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"go.temporal.io/sdk/client"
@@ -39,7 +39,7 @@ import (
 type ImportContractService struct {
 	imp             *welImport.WelImport
 	dao             welDAO.IWelDAO
-	cli             *welclient.GrpcClient
+	cli             welListener.IWelClient
 	tempCli         client.Client
 	worker          worker.Worker
 	defaultFeelimit int64
@@ -60,7 +60,7 @@ const (
 	BatchIssueID = welService.BatchIssueID
 )
 
-func MkImportContractService(client *welclient.GrpcClient, tempCli client.Client, daos *dao.DAOs, contractAddr string) (*ImportContractService, error) {
+func MkImportContractService(client welListener.IWelClient, tempCli client.Client, daos *dao.DAOs, contractAddr string) (*ImportContractService, error) {
 	imp := welImport.MkWelImport(client, contractAddr)
 
 	return &ImportContractService{cli: client, tempCli: tempCli, imp: imp, dao: daos.Wel, defaultFeelimit: 8000000}, nil
@@ -142,6 +142,21 @@ func (ctr *ImportContractService) BatchIssueWF(ctx workflow.Context) error {
 			var tx = welethModel.EthCashinWelTrans{}
 			channel.Receive(ctx, &tx)
 			log.Info("BatchIssueWF received tx: ", tx)
+
+			// over-limit cashins are held for review, releasing one sends it back here
+			if workflow.GetVersion(ctx, "bridge-limits", workflow.DefaultVersion, 1) != workflow.DefaultVersion {
+				var held bool
+				lctx := workflow.WithTaskQueue(ctx, welethService.WelethServiceQueue)
+				limited := welethModel.BridgeTransfer{Kind: welethModel.TransferE2WCashinIssue, Ref: tx.EthTxHash, LogIndex: tx.LogIndex, TokenAddr: tx.EthTokenAddr, Address: tx.EthWalletAddr, Amount: tx.Total}
+				if err := workflow.ExecuteActivity(lctx, welethService.CheckBridgeLimit, limited).Get(lctx, &held); err != nil {
+					log.Error("Failed to check bridge limits, cashin left out of BatchIssueWF: "+tx.EthTxHash, "error", err)
+					return
+				}
+				if held {
+					log.Info("Cashin held for review: " + tx.EthTxHash)
+					return
+				}
+			}
 			welToken := tx.WelTokenAddr
 			_, ok := allTxQueues[welToken]
 			if !ok {
@@ -161,7 +176,7 @@ func (ctr *ImportContractService) BatchIssueWF(ctx workflow.Context) error {
 				values := libs.Map(
 					func(tx welethModel.EthCashinWelTrans) *big.Int {
 						ret := &big.Int{}
-						ret.SetString(tx.Total, 10)
+						ret.SetString(tx.DestAmount, 10)
 						return ret
 					}, allTxQueues[welToken].queue)
 				// issue
@@ -190,6 +205,10 @@ func (ctr *ImportContractService) BatchIssueWF(ctx workflow.Context) error {
 					ctx_welethService := workflow.WithActivityOptions(ctx, ao_welethService)
 
 					tran.WelIssueTxHash = txhash
+					if txhash == "" {
+						// the deposit gets refunded once an admin approves it
+						tran.Status = welethModel.EthCashinWelFailed
+					}
 					res = workflow.ExecuteActivity(ctx_welethService, welethService.UpdateEthCashinWelTrans, tran)
 					if err := res.Get(ctx_welethService, nil); err != nil {
 						log.Error("Failed to update E2W cashin trans")
@@ -198,6 +217,10 @@ func (ctr *ImportContractService) BatchIssueWF(ctx workflow.Context) error {
 					}
 				}
 
+				if txhash != "" {
+					ctr.verifyIssue(ctx, txhash, allTxQueues[welToken].queue)
+				}
+
 				// update lastIssuance
 				allTxQueues[welToken].lastIssuance = workflow.Now(ctx)
 				//lastIssuance = allTxQueues[welToken].lastIssuance
@@ -228,7 +251,7 @@ func (ctr *ImportContractService) BatchIssueWF(ctx workflow.Context) error {
 					values := libs.Map(
 						func(tx welethModel.EthCashinWelTrans) *big.Int {
 							ret := &big.Int{}
-							ret.SetString(tx.Total, 10)
+							ret.SetString(tx.DestAmount, 10)
 							return ret
 						}, allTxQueues[welToken].queue)
 					// issue
@@ -257,6 +280,10 @@ func (ctr *ImportContractService) BatchIssueWF(ctx workflow.Context) error {
 						ctx_welethService := workflow.WithActivityOptions(ctx, ao_welethService)
 
 						tran.WelIssueTxHash = txhash
+						if txhash == "" {
+							// the deposit gets refunded once an admin approves it
+							tran.Status = welethModel.EthCashinWelFailed
+						}
 						res = workflow.ExecuteActivity(ctx_welethService, welethService.UpdateEthCashinWelTrans, tran)
 						if err := res.Get(ctx_welethService, nil); err != nil {
 							log.Error("Failed to update E2W cashin trans")
@@ -265,6 +292,10 @@ func (ctr *ImportContractService) BatchIssueWF(ctx workflow.Context) error {
 						}
 					}
 
+					if txhash != "" {
+						ctr.verifyIssue(ctx, txhash, allTxQueues[welToken].queue)
+					}
+
 					// update lastIssuance
 					allTxQueues[welToken].lastIssuance = workflow.Now(ctx)
 					//lastIssuance = allTxQueues[welToken].lastIssuance
@@ -326,6 +357,7 @@ func (ctr *ImportContractService) WatchForTx2Treasury(ctx workflow.Context, from
 
 		cashinTx := welethModel.EthCashinWelTrans{
 			EthTxHash: tx.TxID,
+			LogIndex:  tx.LogIndex,
 
 			EthTokenAddr: token,
 			WelTokenAddr: welToken,
@@ -336,9 +368,21 @@ func (ctr *ImportContractService) WatchForTx2Treasury(ctx workflow.Context, from
 			NetworkID: netid,
 			Total:     tx.Amount,
 
-			//CommissionFee:
 			Status: welethModel.EthCashinWelUnconfirmed,
 		}
+
+		var quote welethModel.FeeQuote
+		res = workflow.ExecuteActivity(ctx, welethService.ComputeCommissionFee, token, welethModel.FeeEthToWel, cashinTx.Total)
+		err = res.Get(ctx, &quote)
+		if err != nil {
+			log.Error("[WatchForTx2Treasury] error while computing commission fee", err)
+			return err
+		}
+		cashinTx.Amount = quote.Amount
+		cashinTx.CommissionFee = quote.CommissionFee
+		cashinTx.DestAmount = quote.DestAmount
+		cashinTx.Dust = quote.Dust
+
 		res = workflow.ExecuteActivity(ctx, welethService.CreateEthCashinWelTrans, cashinTx)
 		err = res.Get(ctx, &(cashinTx.ID))
 		if err != nil {
@@ -378,7 +422,9 @@ func (ctr *ImportContractService) WatchForTx2Treasury(ctx workflow.Context, from
 	return nil
 }
 
-func (ctr *ImportContractService) WatchForTx2TreasuryByTxHash(ctx workflow.Context, txhash, to, netid, token string) error {
+// WatchForTx2TreasuryByTxHash cashes in the deposit of txhash at logIndex, logIndex may be
+// left nil for txs holding a single deposit
+func (ctr *ImportContractService) WatchForTx2TreasuryByTxHash(ctx workflow.Context, txhash, to, netid, token string, logIndex *int64) error {
 	log := workflow.GetLogger(ctx)
 
 	ao := workflow.ActivityOptions{
@@ -396,7 +442,7 @@ func (ctr *ImportContractService) WatchForTx2TreasuryByTxHash(ctx workflow.Conte
 
 	getTx2Treasury := func() error {
 		var tx welethModel.TxToTreasury
-		res := workflow.ExecuteActivity(ctx, welethService.GetTx2TreasuryByTxHash, txhash)
+		res := workflow.ExecuteActivity(ctx, welethService.GetTx2TreasuryByTxHash, txhash, logIndex)
 		err := res.Get(ctx, &tx)
 
 		if err != nil {
@@ -415,6 +461,7 @@ func (ctr *ImportContractService) WatchForTx2TreasuryByTxHash(ctx workflow.Conte
 
 		cashinTx := welethModel.EthCashinWelTrans{
 			EthTxHash: tx.TxID,
+			LogIndex:  tx.LogIndex,
 
 			EthTokenAddr: token,
 			WelTokenAddr: welToken,
@@ -425,9 +472,21 @@ func (ctr *ImportContractService) WatchForTx2TreasuryByTxHash(ctx workflow.Conte
 			NetworkID: netid,
 			Total:     tx.Amount,
 
-			//CommissionFee:
 			Status: welethModel.EthCashinWelUnconfirmed,
 		}
+
+		var quote welethModel.FeeQuote
+		res = workflow.ExecuteActivity(ctx, welethService.ComputeCommissionFee, token, welethModel.FeeEthToWel, cashinTx.Total)
+		err = res.Get(ctx, &quote)
+		if err != nil {
+			log.Error("[WatchForTx2Treasury] error while computing commission fee", err)
+			return err
+		}
+		cashinTx.Amount = quote.Amount
+		cashinTx.CommissionFee = quote.CommissionFee
+		cashinTx.DestAmount = quote.DestAmount
+		cashinTx.Dust = quote.Dust
+
 		res = workflow.ExecuteActivity(ctx, welethService.CreateEthCashinWelTrans, cashinTx)
 		err = res.Get(ctx, &(cashinTx.ID))
 		if err != nil {
@@ -471,10 +530,12 @@ func (ctr *ImportContractService) WatchForTx2TreasuryByTxHash(ctx workflow.Conte
 func (ctr *ImportContractService) registerService(w worker.Worker) {
 	//w.RegisterActivity(ctr.Withdraw)
 	w.RegisterActivity(ctr.Issue)
+	w.RegisterActivity(ctr.VerifyIssue)
 
 	w.RegisterWorkflowWithOptions(ctr.WatchForTx2Treasury, workflow.RegisterOptions{Name: WatchForTx2TreasuryWF})
 	w.RegisterWorkflowWithOptions(ctr.WatchForTx2TreasuryByTxHash, workflow.RegisterOptions{Name: WatchForTx2TreasuryByTxHashWF})
 	w.RegisterWorkflow(ctr.BatchIssueWF)
+	w.RegisterWorkflow(ctr.VerifyIssueWF)
 }
 
 func (ctr *ImportContractService) StartService() error {
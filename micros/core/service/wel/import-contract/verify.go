@@ -0,0 +1,175 @@
+package importcontract
+
+import (
+	"bridge/micros/core/config"
+	welethModel "bridge/micros/weleth/model"
+	welethService "bridge/micros/weleth/temporal"
+	"bridge/service-managers/logger"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Paven-Org/gotron-sdk/pkg/proto/core"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// an issue tx not yet buried under enough blocks, VerifyIssue is retried until it is
+var ErrIssueUnconfirmed = errors.New("issue tx not confirmed yet")
+
+// how long an issue tx is polled for before its cashins are left for admins to look into
+var IssueVerificationTimeout = time.Hour
+
+// IssueReceipt is the outcome of an issue tx once confirmed
+type IssueReceipt struct {
+	TxHash    string
+	Success   bool
+	Reason    string
+	Retryable bool
+}
+
+// VerifyIssue gets the receipt of an issue tx, failing with ErrIssueUnconfirmed until it's
+// mined and confirmed
+func (ctr *ImportContractService) VerifyIssue(ctx context.Context, txhash string) (IssueReceipt, error) {
+	log := logger.Get()
+	info, err := ctr.cli.GetTransactionInfoByID(txhash)
+	if err != nil {
+		log.Err(err).Msgf("[Issue verification] Unable to get info of issue tx %s", txhash)
+		return IssueReceipt{}, err
+	}
+	if info == nil || len(info.GetId()) == 0 || info.GetBlockNumber() == 0 {
+		return IssueReceipt{}, ErrIssueUnconfirmed
+	}
+
+	block, err := ctr.cli.GetNowBlock()
+	if err != nil {
+		log.Err(err).Msg("[Issue verification] Unable to get wel head")
+		return IssueReceipt{}, err
+	}
+	head := block.GetBlockHeader().GetRawData().GetNumber()
+	if head < info.GetBlockNumber() || uint64(head-info.GetBlockNumber()) < config.Get().WelupsConfig.Confirmations {
+		return IssueReceipt{}, ErrIssueUnconfirmed
+	}
+
+	ok, reason, retryable := issueOutcome(info)
+	if !ok {
+		log.Warn().Msgf("[Issue verification] Issue tx %s failed: %s", txhash, reason)
+	}
+	return IssueReceipt{TxHash: txhash, Success: ok, Reason: reason, Retryable: retryable}, nil
+}
+
+// issueOutcome tells whether an issue tx succeeded, and why it didn't otherwise. Running out
+// of energy or time is worth another try, reverts aren't.
+func issueOutcome(info *core.TransactionInfo) (ok bool, reason string, retryable bool) {
+	result := info.GetReceipt().GetResult()
+	if info.GetResult() != core.TransactionInfo_FAILED &&
+		(result == core.Transaction_Result_DEFAULT || result == core.Transaction_Result_SUCCESS) {
+		return true, "", false
+	}
+
+	reason = result.String()
+	if msg := string(info.GetResMessage()); msg != "" {
+		reason = fmt.Sprintf("%s: %s", reason, msg)
+	}
+	switch result {
+	case core.Transaction_Result_OUT_OF_ENERGY, core.Transaction_Result_OUT_OF_TIME:
+		retryable = true
+	}
+	return false, reason, retryable
+}
+
+// VerifyIssueWF waits for the issue tx of a batch of cashins to be confirmed, then marks
+// them confirmed, or failed with the reason. Cashins failing for a retryable reason are sent
+// back to BatchIssueWF.
+func (ctr *ImportContractService) VerifyIssueWF(ctx workflow.Context, txhash string, trans []welethModel.EthCashinWelTrans) error {
+	log := workflow.GetLogger(ctx)
+	ao := workflow.ActivityOptions{
+		TaskQueue:              ImportContractQueue,
+		ScheduleToCloseTimeout: IssueVerificationTimeout,
+		StartToCloseTimeout:    time.Second * 60,
+		WaitForCancellation:    false,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second * 10,
+			BackoffCoefficient: 1.5,
+			MaximumInterval:    time.Second * 100,
+		},
+	}
+	vctx := workflow.WithActivityOptions(ctx, ao)
+
+	var receipt IssueReceipt
+	if err := workflow.ExecuteActivity(vctx, ctr.VerifyIssue, txhash).Get(vctx, &receipt); err != nil {
+		// the tx may still make it, the cashins are kept pending with its hash
+		log.Error("[VerifyIssueWF] Failed to verify issue tx "+txhash, "error", err)
+		return err
+	}
+
+	wo := workflow.ActivityOptions{
+		TaskQueue:              welethService.WelethServiceQueue,
+		ScheduleToCloseTimeout: time.Second * 60,
+		ScheduleToStartTimeout: time.Second * 60,
+		StartToCloseTimeout:    time.Second * 60,
+		HeartbeatTimeout:       time.Second * 10,
+		WaitForCancellation:    false,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumInterval: time.Second * 100,
+			MaximumAttempts: 10,
+		},
+	}
+	wctx := workflow.WithActivityOptions(ctx, wo)
+	narrow := workflow.GetVersion(ctx, "settle-issue", workflow.DefaultVersion, 1) != workflow.DefaultVersion
+
+	for _, tran := range trans {
+		tran.WelIssueTxHash = txhash
+		requeue := false
+		if receipt.Success {
+			tran.Status = welethModel.EthCashinWelConfirmed
+			tran.IssuedAt = sql.NullTime{Time: workflow.Now(ctx), Valid: true}
+			tran.FailReason = ""
+		} else {
+			requeue = tran.IssueFailed(receipt.Reason, receipt.Retryable)
+		}
+
+		// only the outcome of the issue is written, the rest of tran may be stale by now
+		settled := true
+		if narrow {
+			if err := workflow.ExecuteActivity(wctx, welethService.SettleEthCashinWelIssue, tran, txhash).Get(wctx, &settled); err != nil {
+				log.Error("[VerifyIssueWF] Failed to update E2W cashin trans "+tran.EthTxHash, "error", err)
+				continue
+			}
+		} else if err := workflow.ExecuteActivity(wctx, welethService.UpdateEthCashinWelTrans, tran).Get(wctx, nil); err != nil {
+			log.Error("[VerifyIssueWF] Failed to update E2W cashin trans "+tran.EthTxHash, "error", err)
+			continue
+		}
+		if !settled || !requeue {
+			continue
+		}
+		if err := workflow.SignalExternalWorkflow(ctx, BatchIssueID, "", BatchIssueSignal, tran).Get(ctx, nil); err != nil {
+			log.Error("[VerifyIssueWF] Failed to re-queue E2W cashin "+tran.EthTxHash, "error", err)
+			continue
+		}
+		log.Info("[VerifyIssueWF] E2W cashin re-queued for another issue: " + tran.EthTxHash)
+	}
+	return nil
+}
+
+// verifyIssue starts VerifyIssueWF for a batch, without waiting for it
+func (ctr *ImportContractService) verifyIssue(ctx workflow.Context, txhash string, trans []welethModel.EthCashinWelTrans) {
+	// BatchIssueWF runs started before issues were verified don't start it
+	if workflow.GetVersion(ctx, "verify-issue", workflow.DefaultVersion, 1) == workflow.DefaultVersion {
+		return
+	}
+	cwo := workflow.ChildWorkflowOptions{
+		WorkflowID:        "VerifyIssue-" + txhash,
+		TaskQueue:         ImportContractQueue,
+		ParentClosePolicy: enums.PARENT_CLOSE_POLICY_ABANDON, // outlives BatchIssueWF continuing as new
+	}
+	cctx := workflow.WithChildOptions(ctx, cwo)
+	child := workflow.ExecuteChildWorkflow(cctx, ctr.VerifyIssueWF, txhash, trans)
+	// started, or not: either way the cashins keep their issue tx hash
+	if err := child.GetChildWorkflowExecution().Get(cctx, nil); err != nil {
+		workflow.GetLogger(ctx).Error("Failed to start issue verification of tx "+txhash, "error", err)
+	}
+}
@@ -0,0 +1,30 @@
+package importcontract
+
+import (
+	"testing"
+
+	"github.com/Paven-Org/gotron-sdk/pkg/proto/core"
+)
+
+func TestIssueOutcome(t *testing.T) {
+	cases := []struct {
+		name      string
+		info      *core.TransactionInfo
+		ok        bool
+		reason    string
+		retryable bool
+	}{
+		{"success", &core.TransactionInfo{Receipt: &core.ResourceReceipt{Result: core.Transaction_Result_SUCCESS}}, true, "", false},
+		{"no receipt result", &core.TransactionInfo{}, true, "", false},
+		{"out of energy", &core.TransactionInfo{Result: core.TransactionInfo_FAILED, Receipt: &core.ResourceReceipt{Result: core.Transaction_Result_OUT_OF_ENERGY}}, false, "OUT_OF_ENERGY", true},
+		{"out of time", &core.TransactionInfo{Result: core.TransactionInfo_FAILED, Receipt: &core.ResourceReceipt{Result: core.Transaction_Result_OUT_OF_TIME}}, false, "OUT_OF_TIME", true},
+		{"revert", &core.TransactionInfo{Result: core.TransactionInfo_FAILED, ResMessage: []byte("REVERT opcode executed"), Receipt: &core.ResourceReceipt{Result: core.Transaction_Result_REVERT}}, false, "REVERT: REVERT opcode executed", false},
+		{"failed without receipt", &core.TransactionInfo{Result: core.TransactionInfo_FAILED}, false, "DEFAULT", false},
+	}
+	for _, c := range cases {
+		ok, reason, retryable := issueOutcome(c.info)
+		if ok != c.ok || reason != c.reason || retryable != c.retryable {
+			t.Errorf("%s: got (%v, %q, %v), want (%v, %q, %v)", c.name, ok, reason, retryable, c.ok, c.reason, c.retryable)
+		}
+	}
+}
@@ -6,11 +6,11 @@ import (
 	"bridge/micros/core/dao"
 	welDAO "bridge/micros/core/dao/wel-account"
 	"bridge/micros/core/model"
+	welListener "bridge/service-managers/listener/wel"
 	"bridge/service-managers/logger"
 	"context"
 	"time"
 
-	welclient "github.com/Paven-Org/gotron-sdk/pkg/client"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"go.temporal.io/sdk/client"
@@ -33,13 +33,13 @@ const (
 type GovContractService struct {
 	gov             *welGov.WelGov
 	dao             welDAO.IWelDAO
-	cli             *welclient.GrpcClient
+	cli             welListener.IWelClient
 	tempCli         client.Client
 	worker          worker.Worker
 	defaultFeelimit int64
 }
 
-func MkGovContractService(client *welclient.GrpcClient, tempCli client.Client, daos *dao.DAOs, contractAddr string) (*GovContractService, error) {
+func MkGovContractService(client welListener.IWelClient, tempCli client.Client, daos *dao.DAOs, contractAddr string) (*GovContractService, error) {
 	gov := welGov.MkWelGov(contractAddr, client)
 
 	return &GovContractService{cli: client, tempCli: tempCli, gov: gov, dao: daos.Wel, defaultFeelimit: 8000000}, nil
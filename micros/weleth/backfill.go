@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bridge/micros/weleth/config"
+	"bridge/micros/weleth/dao"
+	"bridge/micros/weleth/service"
+	ethListener "bridge/service-managers/listener/eth"
+	welListener "bridge/service-managers/listener/wel"
+	"bridge/service-managers/logger"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"go.temporal.io/sdk/client"
+)
+
+// backfill replays a block range of one chain through the event consumers without moving
+// the live cursor, e.g.
+//
+//	weleth backfill -chain eth -from 15000000 -to 15001000 -dry-run
+func backfill(args []string, daos *dao.DAOs, tempCli client.Client) error {
+	log := logger.Get()
+
+	fs := flag.NewFlagSet("backfill", flag.ExitOnError)
+	chain := fs.String("chain", "", "chain to replay: eth or wel")
+	from := fs.Int64("from", -1, "first block to replay")
+	to := fs.Int64("to", -1, "last block to replay")
+	dryRun := fs.Bool("dry-run", false, "print the records that would be created instead of writing them")
+	fs.Parse(args)
+
+	if *from < 0 || *to < *from {
+		return fmt.Errorf("invalid block range [%d, %d]", *from, *to)
+	}
+
+	backfillDAOs, stats := dao.MkBackfillDAOs(daos, *dryRun, os.Stdout)
+	ctx := context.Background()
+
+	var replayed int
+	var backfillErr error
+	switch *chain {
+	case "eth":
+		ethConf := config.Get().EtherumConf
+		ethClient, err := ethListener.NewMultiClient(append([]string{ethConf.BlockchainRPC}, ethConf.FallbackRPCs...), log)
+		if err != nil {
+			return err
+		}
+		defer ethClient.Close()
+
+		ethListen := ethListener.NewEthListener(backfillDAOs.EthSysDAO, ethClient, ethConf.BlockTime, ethConf.BlockOffSet, log)
+		ethListen.RegisterConsumer("bridge", 0, service.NewEthConsumer(config.Get().EthContractAddress[0], config.Get().EthMultisenderAddress, tempCli, backfillDAOs))
+		replayed, backfillErr = ethListen.Backfill(ctx, *from, *to)
+
+	case "wel":
+		welConf := config.Get().WelupsConf
+		welClient, err := welListener.NewNodePool(welConf.Nodes, time.Duration(welConf.ClientTimeout)*time.Minute, log)
+		if err != nil {
+			return err
+		}
+		defer welClient.Stop()
+
+		welTransHandler := welListener.NewTransHandler(welClient, welConf.BlockOffSet)
+		welListen := welListener.NewWelListener(backfillDAOs.WelSysDAO, welTransHandler, welConf.BlockTime, welConf.BlockOffSet, log)
+		welListen.RegisterConsumer("bridge", 0, service.NewWelConsumer(config.Get().WelImportAddress, config.Get().WelContractAddress[0], tempCli, backfillDAOs))
+		replayed, backfillErr = welListen.Backfill(ctx, *from, *to)
+
+	default:
+		return fmt.Errorf("unknown chain %q, expected eth or wel", *chain)
+	}
+
+	verb := "created"
+	if *dryRun {
+		verb = "would be created"
+	}
+	fmt.Printf("[backfill] %s blocks %d to %d: %d events replayed, %d records %s, %d rows left untouched\n",
+		*chain, *from, *to, replayed, stats.Created, verb, stats.Untouched)
+	return backfillErr
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"bridge/common"
 	"bridge/libs"
+	"bridge/micros/weleth/model"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -25,7 +26,10 @@ type Env struct {
 	EthContractAddress    []string
 	EthTreasuryAddress    string
 	EthMultisenderAddress string
+	EthCashinExpiry       time.Duration // deposits to treasury not matched by a cashin request within it get refunded
+	ListenerLeaseTTL      time.Duration // a standby replica takes a dead one's listeners over within it
 	WelImportAddress      string
+	WelTreasuryAddress    string // transfers to it aren't monitored when empty
 	Mailerconf            common.Mailerconf
 	TemporalCliConfig     common.TemporalCliconf
 }
@@ -92,21 +96,28 @@ func parseEnv() Env {
 
 		EtherumConf: common.EtherumConfig{
 			BlockchainRPC: common.WithDefault("ETH_BLOCKCHAIN_RPC", "https://eth-goerli.alchemyapi.io/v2/fTsNANWphvAVnwh9ll2iKoDkUfmJ1pMy"),
+			FallbackRPCs:  common.WithDefault("ETH_FALLBACK_RPCS", []string{}),
+			WebsocketRPC:  common.WithDefault("ETH_WEBSOCKET_RPC", ""),
 			BlockTime:     common.WithDefault("ETH_BLOCK_TIME", uint64(14)),
 			BlockOffSet:   common.WithDefault("ETH_BLOCK_OFFSET", int64(5)),
+			Confirmations: common.WithDefault("ETH_CONFIRMATIONS", uint64(12)),
 		},
 		EthContractAddress:    common.WithDefault("ETH_CONTRACT_ADDRESS", []string{"0x47469dd8bb847df5bAe03A9E3644C4db9c7d779B"}),
 		EthTreasuryAddress:    common.WithDefault("ETH_TREASURY_ADDRESS", "0x25e8370E0e2cf3943Ad75e768335c892434bD090"),
 		EthMultisenderAddress: common.WithDefault("ETH_MULTISENDER_ADDRESS", "0x3a9c1A3D0DDa6a025794626Afd2A4C7B7e740712"),
+		EthCashinExpiry:       common.WithDefault("ETH_CASHIN_EXPIRY", 72*time.Hour),
+		ListenerLeaseTTL:      common.WithDefault("LISTENER_LEASE_TTL", 30*time.Second),
 
 		WelupsConf: common.WelupsConfig{
 			Nodes:         common.WithDefault("WEL_NODES", []string{"54.179.208.1:16669"}),
 			BlockTime:     common.WithDefault("WEL_BLOCK_TIME", uint64(3)),
 			ClientTimeout: common.WithDefault("WEL_CLIENT_TIMEOUT", int64(5)),
 			BlockOffSet:   common.WithDefault("WEL_BLOCK_OFFSET", int64(20)),
+			Confirmations: common.WithDefault("WEL_CONFIRMATIONS", uint64(20)),
 		},
 		WelContractAddress: common.WithDefault("WEL_CONTRACT_ADDRESS", []string{"WUbnXM9M4QYEkksG3ADmSan2kY5xiHTr1E"}),
 		WelImportAddress:   common.WithDefault("WEL_IMPORT_ADDRESS", "WKHhHJY7wszCjfdz5jKTytt2F3ULu1PyAS"),
+		WelTreasuryAddress: common.WithDefault("WEL_TREASURY_ADDRESS", ""),
 
 		Mailerconf: common.Mailerconf{
 			SmtpHost: common.WithDefault("APP_MAILER_SMTP_HOST", "smtp.gmail.com"),
@@ -134,15 +145,66 @@ func parseFlags() Flags {
 	}
 }
 
+// TokensMap seeds the bridge_tokens table on first start, the token pairs are then managed
+// through the admin API
 type TokensMap []struct {
-	Eth     string `json:"eth"`
-	Wel     string `json:"wel"`
-	EthName string `json:"eth_name"` // not actually used right now, but it's nice to have some clarity
-	WelName string `json:"wel_name"` // not actually used right now, but it's nice to have some clarity
+	Eth         string `json:"eth"`
+	Wel         string `json:"wel"`
+	EthName     string `json:"eth_name"`
+	WelName     string `json:"wel_name"`
+	EthDecimals *int   `json:"eth_decimals,omitempty"` // defaults to 18
+	WelDecimals *int   `json:"wel_decimals,omitempty"` // defaults to 18
+
+	// confirmation depths overriding the chain-wide ETH_CONFIRMATIONS/WEL_CONFIRMATIONS
+	EthConfirmations uint64 `json:"eth_confirmations,omitempty"`
+	WelConfirmations uint64 `json:"wel_confirmations,omitempty"`
+
+	// limits of the transfers out of each chain, in the units of the token on that chain
+	EthLimits model.BridgeLimits `json:"eth_limits,omitempty"`
+	WelLimits model.BridgeLimits `json:"wel_limits,omitempty"`
 }
 
+// BridgeTokens converts the map into the rows seeding bridge_tokens
+func (m TokensMap) BridgeTokens() []model.BridgeToken {
+	decimals := func(d *int) int {
+		if d == nil {
+			return 18
+		}
+		return *d
+	}
+	tokens := make([]model.BridgeToken, 0, len(m))
+	for _, pair := range m {
+		tokens = append(tokens, model.BridgeToken{
+			EthAddr:     pair.Eth,
+			WelAddr:     pair.Wel,
+			EthName:     pair.EthName,
+			WelName:     pair.WelName,
+			EthDecimals: decimals(pair.EthDecimals),
+			WelDecimals: decimals(pair.WelDecimals),
+			Enabled:     true,
+
+			EthMinAmount:       pair.EthLimits.MinTransfer,
+			WelMinAmount:       pair.WelLimits.MinTransfer,
+			EthMaxTransfer:     pair.EthLimits.MaxTransfer,
+			WelMaxTransfer:     pair.WelLimits.MaxTransfer,
+			EthAddressDailyCap: pair.EthLimits.AddressDailyCap,
+			WelAddressDailyCap: pair.WelLimits.AddressDailyCap,
+			EthDailyCap:        pair.EthLimits.DailyCap,
+			WelDailyCap:        pair.WelLimits.DailyCap,
+
+			EthConfirmations: int64(pair.EthConfirmations),
+			WelConfirmations: int64(pair.WelConfirmations),
+		})
+	}
+	return tokens
+}
+
+// ParseTokensMap reads tokens-map.json, it's optional once bridge_tokens is seeded
 func ParseTokensMap() TokensMap {
 	mapfile, err := os.Open("tokens-map.json")
+	if os.IsNotExist(err) {
+		return TokensMap{}
+	}
 	if err != nil {
 		fmt.Println("[config] Unable to load tokens map, error: ", err.Error())
 		panic(err)
@@ -0,0 +1,222 @@
+package dao
+
+import (
+	"bridge/micros/weleth/model"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrBackfillUntouched is returned instead of modifying a row while backfilling, it stops
+// consumers before any follow-up of the modification
+var ErrBackfillUntouched = fmt.Errorf("Row already present, left untouched")
+
+type BackfillStats struct {
+	Created   int
+	Untouched int
+}
+
+// backfiller is shared by the backfill DAOs: rows are only created when absent, and in
+// dry-run they are printed instead
+type backfiller struct {
+	dryRun bool
+	out    io.Writer
+	stats  *BackfillStats
+}
+
+// create runs insert unless the row exists already or it's a dry-run
+func (b *backfiller) create(table string, exists func() (bool, error), record interface{}, insert func() error) error {
+	present, err := exists()
+	if err != nil {
+		return err
+	}
+	if present {
+		return b.untouched()
+	}
+	if b.dryRun {
+		m, _ := json.Marshal(record)
+		fmt.Fprintf(b.out, "[dry-run] %s: %s\n", table, m)
+	} else if err := insert(); err != nil {
+		return err
+	}
+	b.stats.Created++
+	return nil
+}
+
+func (b *backfiller) untouched() error {
+	b.stats.Untouched++
+	return ErrBackfillUntouched
+}
+
+func found(err error) (bool, error) {
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// MkBackfillDAOs wraps the transaction DAOs for replaying past blocks: missing rows are
+// created, rows already present are left untouched, and nothing is written in dry-run.
+// Replayed events bypass the processed events ledger.
+func MkBackfillDAOs(daos *DAOs, dryRun bool, out io.Writer) (*DAOs, *BackfillStats) {
+	b := &backfiller{dryRun: dryRun, out: out, stats: &BackfillStats{}}
+	return &DAOs{
+		WelCashinEthTransDAO:  &backfillWelCashinEthTransDAO{daos.WelCashinEthTransDAO, b},
+		EthCashoutWelTransDAO: &backfillEthCashoutWelTransDAO{daos.EthCashoutWelTransDAO, b},
+		EthCashinWelTransDAO:  &backfillEthCashinWelTransDAO{daos.EthCashinWelTransDAO, b},
+		WelCashoutEthTransDAO: &backfillWelCashoutEthTransDAO{daos.WelCashoutEthTransDAO, b},
+		FeeRuleDAO:            daos.FeeRuleDAO,
+		BridgeLimitDAO:        daos.BridgeLimitDAO,
+		BridgeTokenDAO:        daos.BridgeTokenDAO,
+		Tokens:                daos.Tokens,
+		EthSysDAO:             daos.EthSysDAO,
+		WelSysDAO:             daos.WelSysDAO,
+	}, b.stats
+}
+
+type backfillWelCashinEthTransDAO struct {
+	IWelCashinEthTransDAO
+	b *backfiller
+}
+
+func (w *backfillWelCashinEthTransDAO) CreateWelCashinEthTrans(t *model.WelCashinEthTrans) error {
+	return w.b.create("wel_cashin_eth_trans", func() (bool, error) {
+		_, err := w.IWelCashinEthTransDAO.SelectTransByDepositEvent(t.DepositTxHash, t.LogIndex)
+		return found(err)
+	}, t, func() error {
+		return w.IWelCashinEthTransDAO.CreateWelCashinEthTrans(t)
+	})
+}
+
+func (w *backfillWelCashinEthTransDAO) UpdateDepositWelCashinEthConfirmed(depositTxHash, welWalletAddr, amount, fee string) error {
+	return w.b.untouched()
+}
+
+func (w *backfillWelCashinEthTransDAO) UpdateDepositConfirmations(id int64, confirmations int64, status string) error {
+	return w.b.untouched()
+}
+
+func (w *backfillWelCashinEthTransDAO) UpdateClaimWelCashinEth(id int64, reqID, reqStatus, claimTxHash, status string) error {
+	return w.b.untouched()
+}
+
+// also sets the request on the transaction row
+func (w *backfillWelCashinEthTransDAO) CreateClaimRequest(requestID string, txID int64, status string, expiredAt time.Time) error {
+	return w.b.untouched()
+}
+
+func (w *backfillWelCashinEthTransDAO) UpdateClaimRequest(reqID, status string) error {
+	return w.b.untouched()
+}
+
+type backfillEthCashoutWelTransDAO struct {
+	IEthCashoutWelTransDAO
+	b *backfiller
+}
+
+func (w *backfillEthCashoutWelTransDAO) CreateEthCashoutWelTrans(t *model.EthCashoutWelTrans) error {
+	return w.b.create("eth_cashout_wel_trans", func() (bool, error) {
+		_, err := w.IEthCashoutWelTransDAO.SelectTransByDepositEvent(t.DepositTxHash, t.LogIndex)
+		return found(err)
+	}, t, func() error {
+		return w.IEthCashoutWelTransDAO.CreateEthCashoutWelTrans(t)
+	})
+}
+
+func (w *backfillEthCashoutWelTransDAO) UpdateDepositEthCashoutWelBlock(depositTxHash string, logIndex int64, ethWalletAddr, amount, blockHash string, blockNumber int64) error {
+	return w.b.untouched()
+}
+
+func (w *backfillEthCashoutWelTransDAO) UpdateDepositConfirmations(id int64, confirmations int64, status string) error {
+	return w.b.untouched()
+}
+
+func (w *backfillEthCashoutWelTransDAO) MarkOrphanedByBlockHashes(blockHashes []string) (int64, error) {
+	return 0, w.b.untouched()
+}
+
+func (w *backfillEthCashoutWelTransDAO) UpdateClaimEthCashoutWel(id int64, reqID, reqStatus, claimTxHash, amount, fee, status string) error {
+	return w.b.untouched()
+}
+
+// also sets the request on the transaction row
+func (w *backfillEthCashoutWelTransDAO) CreateClaimRequest(requestID string, txID int64, status string, expiredAt time.Time) error {
+	return w.b.untouched()
+}
+
+func (w *backfillEthCashoutWelTransDAO) UpdateClaimRequest(reqID, status string) error {
+	return w.b.untouched()
+}
+
+type backfillEthCashinWelTransDAO struct {
+	IEthCashinWelTransDAO
+	b *backfiller
+}
+
+func (w *backfillEthCashinWelTransDAO) CreateEthCashinWelTrans(t *model.EthCashinWelTrans) (int64, error) {
+	var id int64
+	err := w.b.create("eth_cashin_wel_trans", func() (bool, error) {
+		_, err := w.IEthCashinWelTransDAO.SelectTransByDepositEvent(t.EthTxHash, t.LogIndex)
+		return found(err)
+	}, t, func() (err error) {
+		id, err = w.IEthCashinWelTransDAO.CreateEthCashinWelTrans(t)
+		return err
+	})
+	return id, err
+}
+
+func (w *backfillEthCashinWelTransDAO) CreateTx2Treasury(t *model.TxToTreasury) error {
+	return w.b.create("tx_to_treasury", func() (bool, error) {
+		_, err := w.IEthCashinWelTransDAO.GetTx2TreasuryByTxHash(t.TxID, t.LogIndex)
+		return found(err)
+	}, t, func() error {
+		return w.IEthCashinWelTransDAO.CreateTx2Treasury(t)
+	})
+}
+
+func (w *backfillEthCashinWelTransDAO) UpdateTx2TreasuryConfirmations(txID string, logIndex, blockNumber, confirmations int64, status string) error {
+	return w.b.untouched()
+}
+
+func (w *backfillEthCashinWelTransDAO) UpdateEthCashinWelTx(t *model.EthCashinWelTrans) error {
+	return w.b.untouched()
+}
+
+func (w *backfillEthCashinWelTransDAO) SettleEthCashinWelIssue(t *model.EthCashinWelTrans, issueTxHash string) (bool, error) {
+	return false, w.b.untouched()
+}
+
+type backfillWelCashoutEthTransDAO struct {
+	IWelCashoutEthTransDAO
+	b *backfiller
+}
+
+func (w *backfillWelCashoutEthTransDAO) CreateWelCashoutEthTrans(t *model.WelCashoutEthTrans) (int64, error) {
+	var id int64
+	err := w.b.create("wel_cashout_eth_trans", func() (bool, error) {
+		_, err := w.IWelCashoutEthTransDAO.SelectTransByWithdrawEvent(t.WelWithdrawTxHash, t.LogIndex)
+		return found(err)
+	}, t, func() (err error) {
+		id, err = w.IWelCashoutEthTransDAO.CreateWelCashoutEthTrans(t)
+		return err
+	})
+	return id, err
+}
+
+func (w *backfillWelCashoutEthTransDAO) UpdateWelCashoutEthTx(t *model.WelCashoutEthTrans) error {
+	return w.b.untouched()
+}
+
+func (w *backfillWelCashoutEthTransDAO) UpdateCashoutConfirmations(id int64, confirmations int64, status string) error {
+	return w.b.untouched()
+}
+
+func (w *backfillWelCashoutEthTransDAO) MarkOrphanedByBlockHashes(blockHashes []string) (int64, error) {
+	return 0, w.b.untouched()
+}
+
+func (w *backfillWelCashoutEthTransDAO) RetryDeclinedDisperse(disperseTxHash, ethWalletAddr, amount string) (int64, error) {
+	return 0, w.b.untouched()
+}
@@ -0,0 +1,60 @@
+package dao
+
+import (
+	"bridge/micros/weleth/model"
+	"bytes"
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+type fakeWelCashinEthTransDAO struct {
+	IWelCashinEthTransDAO
+	rows map[string]*model.WelCashinEthTrans
+}
+
+func (f *fakeWelCashinEthTransDAO) SelectTransByDepositEvent(txHash string, logIndex int64) (*model.WelCashinEthTrans, error) {
+	if t, ok := f.rows[txHash]; ok && t.LogIndex == logIndex {
+		return t, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeWelCashinEthTransDAO) CreateWelCashinEthTrans(t *model.WelCashinEthTrans) error {
+	f.rows[t.DepositTxHash] = t
+	return nil
+}
+
+func TestBackfillDAOs(t *testing.T) {
+	for _, dryRun := range []bool{false, true} {
+		fake := &fakeWelCashinEthTransDAO{rows: map[string]*model.WelCashinEthTrans{
+			"present": {DepositTxHash: "present", Amount: "1"},
+		}}
+		var out bytes.Buffer
+		daos, stats := MkBackfillDAOs(&DAOs{WelCashinEthTransDAO: fake}, dryRun, &out)
+
+		if err := daos.WelCashinEthTransDAO.CreateWelCashinEthTrans(&model.WelCashinEthTrans{DepositTxHash: "present", Amount: "2"}); err != ErrBackfillUntouched {
+			t.Errorf("dry-run %v: expected present row to be left untouched, got %v", dryRun, err)
+		}
+		if fake.rows["present"].Amount != "1" {
+			t.Errorf("dry-run %v: present row overwritten", dryRun)
+		}
+		if err := daos.WelCashinEthTransDAO.UpdateClaimWelCashinEth(1, "1", model.RequestSuccess, "claim", model.StatusSuccess); err != ErrBackfillUntouched {
+			t.Errorf("dry-run %v: expected update to be skipped, got %v", dryRun, err)
+		}
+
+		if err := daos.WelCashinEthTransDAO.CreateWelCashinEthTrans(&model.WelCashinEthTrans{DepositTxHash: "missing"}); err != nil {
+			t.Fatalf("dry-run %v: %v", dryRun, err)
+		}
+		_, created := fake.rows["missing"]
+		if created == dryRun {
+			t.Errorf("dry-run %v: missing row created: %v", dryRun, created)
+		}
+		if printed := strings.Contains(out.String(), `"deposit_tx_hash":"missing"`); printed != dryRun {
+			t.Errorf("dry-run %v: record printed: %v", dryRun, printed)
+		}
+		if stats.Created != 1 || stats.Untouched != 2 {
+			t.Errorf("dry-run %v: unexpected stats %+v", dryRun, *stats)
+		}
+	}
+}
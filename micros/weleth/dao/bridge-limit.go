@@ -0,0 +1,144 @@
+package dao
+
+import (
+	"bridge/micros/weleth/model"
+	"bridge/service-managers/logger"
+	"database/sql"
+	"math/big"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IBridgeLimitDAO interface {
+	// ReserveVolume adds the transfer to the daily volumes if check, given the address and
+	// token volumes over the last 24 hours, accepts it. A transfer is only counted once.
+	ReserveVolume(t *model.BridgeTransfer, check func(addressVolume, tokenVolume *big.Int) error) error
+
+	HoldTransfer(t *model.BridgeTransfer, reason string) error
+	SelectHeldTransfer(kind, ref string, logIndex int64) (*model.HeldTransfer, error)
+	SelectHeldTransferById(id int64) (*model.HeldTransfer, error)
+	SelectHeldTransfers(status string, offset, size uint64) ([]model.HeldTransfer, error)
+	UpdateHeldTransferStatus(id int64, status string) error
+}
+
+// sort of a locator for DAOs
+type bridgeLimitDAO struct {
+	db *sqlx.DB
+}
+
+func (b *bridgeLimitDAO) ReserveVolume(t *model.BridgeTransfer, check func(addressVolume, tokenVolume *big.Int) error) error {
+	log := logger.Get()
+	tx, err := b.db.Beginx()
+	if err != nil {
+		log.Err(err).Msg("Can't start transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	// transfers of the same token are checked one at a time so that concurrent ones can't
+	// both squeeze under a cap
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext($1))", t.TokenAddr); err != nil {
+		log.Err(err).Msgf("Error while locking volumes of token %s", t.TokenAddr)
+		return err
+	}
+
+	var counted bool
+	if err := tx.Get(&counted, "SELECT EXISTS(SELECT 1 FROM bridge_volumes WHERE kind = $1 AND ref = $2 AND log_index = $3)", t.Kind, t.Ref, t.LogIndex); err != nil {
+		return err
+	}
+	if counted {
+		return nil
+	}
+
+	var addressVolume, tokenVolume string
+	err = tx.Get(&addressVolume,
+		`SELECT COALESCE(SUM(amount), 0)::text FROM bridge_volumes
+			WHERE token_addr = $1 AND address = $2 AND created_at > NOW() - interval '24 hours'`,
+		t.TokenAddr, t.Address)
+	if err != nil {
+		log.Err(err).Msgf("Error while summing volume of address %s", t.Address)
+		return err
+	}
+	err = tx.Get(&tokenVolume,
+		`SELECT COALESCE(SUM(amount), 0)::text FROM bridge_volumes
+			WHERE token_addr = $1 AND created_at > NOW() - interval '24 hours'`,
+		t.TokenAddr)
+	if err != nil {
+		log.Err(err).Msgf("Error while summing volume of token %s", t.TokenAddr)
+		return err
+	}
+	addrVol, _ := (&big.Int{}).SetString(addressVolume, 10)
+	tokenVol, _ := (&big.Int{}).SetString(tokenVolume, 10)
+	if err := check(addrVol, tokenVol); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("INSERT INTO bridge_volumes(kind, ref, log_index, token_addr, address, amount) VALUES ($1,$2,$3,$4,$5,$6)",
+		t.Kind, t.Ref, t.LogIndex, t.TokenAddr, t.Address, t.Amount)
+	if err != nil {
+		log.Err(err).Msgf("Error while recording %s transfer %s", t.Kind, t.Ref)
+		return err
+	}
+	return tx.Commit()
+}
+
+func (b *bridgeLimitDAO) HoldTransfer(t *model.BridgeTransfer, reason string) error {
+	log := logger.Get()
+	_, err := b.db.Exec(
+		`INSERT INTO held_transfers(kind, ref, log_index, token_addr, address, amount, reason, status)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8) ON CONFLICT (kind, ref, log_index) DO NOTHING`,
+		t.Kind, t.Ref, t.LogIndex, t.TokenAddr, t.Address, t.Amount, reason, model.HeldForReview)
+	if err != nil {
+		log.Err(err).Msgf("Error while holding %s transfer %s", t.Kind, t.Ref)
+	}
+	return err
+}
+
+func (b *bridgeLimitDAO) SelectHeldTransfer(kind, ref string, logIndex int64) (*model.HeldTransfer, error) {
+	var t = &model.HeldTransfer{}
+	err := b.db.Get(t, "SELECT * FROM held_transfers WHERE kind = $1 AND ref = $2 AND log_index = $3", kind, ref, logIndex)
+	if err == sql.ErrNoRows {
+		return nil, model.ErrHeldTransferNotFound
+	}
+	return t, err
+}
+
+func (b *bridgeLimitDAO) SelectHeldTransferById(id int64) (*model.HeldTransfer, error) {
+	var t = &model.HeldTransfer{}
+	err := b.db.Get(t, "SELECT * FROM held_transfers WHERE id = $1", id)
+	if err == sql.ErrNoRows {
+		return nil, model.ErrHeldTransferNotFound
+	}
+	return t, err
+}
+
+func (b *bridgeLimitDAO) SelectHeldTransfers(status string, offset, size uint64) ([]model.HeldTransfer, error) {
+	var ts = []model.HeldTransfer{}
+	q := "SELECT * FROM held_transfers WHERE ($1 = '' OR status = $1) ORDER BY id OFFSET $2"
+	params := []interface{}{status, offset}
+	if size > 0 {
+		q += " LIMIT $3"
+		params = append(params, size)
+	}
+	err := b.db.Select(&ts, q, params...)
+	return ts, err
+}
+
+func (b *bridgeLimitDAO) UpdateHeldTransferStatus(id int64, status string) error {
+	log := logger.Get()
+	_, err := b.db.Exec(
+		`UPDATE held_transfers SET status = $1,
+			released_at = CASE WHEN $1 = 'released' THEN NOW() ELSE NULL END
+			WHERE id = $2`,
+		status, id)
+	if err != nil {
+		log.Err(err).Msgf("Error while updating held transfer %d", id)
+	}
+	return err
+}
+
+func MkBridgeLimitDao(db *sqlx.DB) *bridgeLimitDAO {
+	return &bridgeLimitDAO{
+		db: db,
+	}
+}
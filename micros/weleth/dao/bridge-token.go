@@ -0,0 +1,156 @@
+package dao
+
+import (
+	"bridge/micros/weleth/model"
+	"bridge/service-managers/logger"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IBridgeTokenDAO interface {
+	CreateBridgeToken(t *model.BridgeToken) (int64, error)
+	UpdateBridgeToken(t *model.BridgeToken) error
+	DeleteBridgeToken(id int64) error
+	// inserts the pairs not listed yet, listed ones are left untouched
+	SeedBridgeTokens(ts []model.BridgeToken) error
+
+	SelectBridgeTokenById(id int64) (*model.BridgeToken, error)
+	SelectBridgeTokens() ([]model.BridgeToken, error)
+}
+
+// sort of a locator for DAOs
+type bridgeTokenDAO struct {
+	db *sqlx.DB
+}
+
+const insertBridgeToken = `INSERT INTO bridge_tokens(
+	eth_addr,
+	wel_addr,
+	eth_name,
+	wel_name,
+	eth_decimals,
+	wel_decimals,
+	enabled,
+	paused,
+	eth_min_amount,
+	wel_min_amount,
+	eth_max_transfer,
+	wel_max_transfer,
+	eth_address_daily_cap,
+	wel_address_daily_cap,
+	eth_daily_cap,
+	wel_daily_cap,
+	eth_confirmations,
+	wel_confirmations) VALUES (
+	:eth_addr,
+	:wel_addr,
+	:eth_name,
+	:wel_name,
+	:eth_decimals,
+	:wel_decimals,
+	:enabled,
+	:paused,
+	:eth_min_amount,
+	:wel_min_amount,
+	:eth_max_transfer,
+	:wel_max_transfer,
+	:eth_address_daily_cap,
+	:wel_address_daily_cap,
+	:eth_daily_cap,
+	:wel_daily_cap,
+	:eth_confirmations,
+	:wel_confirmations)`
+
+func (b *bridgeTokenDAO) CreateBridgeToken(t *model.BridgeToken) (int64, error) {
+	log := logger.Get()
+	q, args, err := b.db.BindNamed(insertBridgeToken+" RETURNING id", t)
+	if err != nil {
+		return -1, err
+	}
+	var id int64
+	if err := b.db.Get(&id, q, args...); err != nil {
+		log.Err(err).Msgf("Error while inserting bridge token %s/%s", t.EthAddr, t.WelAddr)
+		return -1, err
+	}
+	return id, nil
+}
+
+func (b *bridgeTokenDAO) UpdateBridgeToken(t *model.BridgeToken) error {
+	log := logger.Get()
+	res, err := b.db.NamedExec(
+		`UPDATE bridge_tokens SET
+			eth_addr = :eth_addr,
+			wel_addr = :wel_addr,
+			eth_name = :eth_name,
+			wel_name = :wel_name,
+			eth_decimals = :eth_decimals,
+			wel_decimals = :wel_decimals,
+			enabled = :enabled,
+			paused = :paused,
+			eth_min_amount = :eth_min_amount,
+			wel_min_amount = :wel_min_amount,
+			eth_max_transfer = :eth_max_transfer,
+			wel_max_transfer = :wel_max_transfer,
+			eth_address_daily_cap = :eth_address_daily_cap,
+			wel_address_daily_cap = :wel_address_daily_cap,
+			eth_daily_cap = :eth_daily_cap,
+			wel_daily_cap = :wel_daily_cap,
+			eth_confirmations = :eth_confirmations,
+			wel_confirmations = :wel_confirmations,
+			updated_at = NOW()
+			WHERE id = :id`, t)
+	if err != nil {
+		log.Err(err).Msgf("Error while updating bridge token %d", t.ID)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return model.ErrTokenNotFound
+	}
+	return nil
+}
+
+func (b *bridgeTokenDAO) DeleteBridgeToken(id int64) error {
+	log := logger.Get()
+	res, err := b.db.Exec("DELETE FROM bridge_tokens WHERE id = $1", id)
+	if err != nil {
+		log.Err(err).Msgf("Error while deleting bridge token %d", id)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return model.ErrTokenNotFound
+	}
+	return nil
+}
+
+func (b *bridgeTokenDAO) SeedBridgeTokens(ts []model.BridgeToken) error {
+	log := logger.Get()
+	for i := range ts {
+		if _, err := b.db.NamedExec(insertBridgeToken+" ON CONFLICT DO NOTHING", &ts[i]); err != nil {
+			log.Err(err).Msgf("Error while seeding bridge token %s/%s", ts[i].EthAddr, ts[i].WelAddr)
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *bridgeTokenDAO) SelectBridgeTokenById(id int64) (*model.BridgeToken, error) {
+	var t = &model.BridgeToken{}
+	err := b.db.Get(t, "SELECT * FROM bridge_tokens WHERE id = $1", id)
+	if err == sql.ErrNoRows {
+		return nil, model.ErrTokenNotFound
+	}
+	return t, err
+}
+
+func (b *bridgeTokenDAO) SelectBridgeTokens() ([]model.BridgeToken, error) {
+	var ts = []model.BridgeToken{}
+	err := b.db.Select(&ts, "SELECT * FROM bridge_tokens ORDER BY id")
+	return ts, err
+}
+
+func MkBridgeTokenDao(db *sqlx.DB) *bridgeTokenDAO {
+	return &bridgeTokenDAO{
+		db: db,
+	}
+}
@@ -0,0 +1,124 @@
+package dao
+
+import (
+	"bridge/micros/weleth/model"
+	"bridge/service-managers/logger"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IDisperseRetryDAO interface {
+	SelectDisperseRetries() ([]model.DisperseRetry, error)
+	StartDisperseAttempt(cashoutID int64) (*model.DisperseAttempt, error)
+	RequeueStalledAttempts(before time.Time) (int64, error)
+	RecordAttemptTx(cashoutID int64, txHash string) error
+	GiveUpDisperse(cashoutID int64) error
+}
+
+// sort of a locator for DAOs
+type disperseRetryDAO struct {
+	db *sqlx.DB
+}
+
+// SelectDisperseRetries lists the declined cashouts waiting to be dispersed again, with
+// the attempts already made
+func (d *disperseRetryDAO) SelectDisperseRetries() ([]model.DisperseRetry, error) {
+	rs := []model.DisperseRetry{}
+	err := d.db.Select(&rs,
+		`SELECT t.*,
+			COUNT(a.id) AS attempts,
+			COALESCE(MAX(a.created_at), t.created_at) AS last_attempt_at
+			FROM wel_cashout_eth_trans t LEFT JOIN disperse_attempts a ON a.cashout_id = t.id
+			WHERE t.disperse_status = $1
+			GROUP BY t.id
+			ORDER BY t.id`,
+		model.WelCashoutEthRetry)
+	if err != nil {
+		logger.Get().Err(err).Msg("[SelectDisperseRetries] error while querying DB")
+		return nil, err
+	}
+	return rs, nil
+}
+
+// StartDisperseAttempt records a new attempt and takes the cashout out of the retries
+// until it's declined again
+func (d *disperseRetryDAO) StartDisperseAttempt(cashoutID int64) (*model.DisperseAttempt, error) {
+	log := logger.Get()
+	tx, err := d.db.Beginx()
+	if err != nil {
+		log.Err(err).Msg("Can't start transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec("UPDATE wel_cashout_eth_trans SET disperse_status = $1 WHERE id = $2 AND disperse_status = $3",
+		model.WelCashoutEthUnconfirmed, cashoutID, model.WelCashoutEthRetry)
+	if err != nil {
+		log.Err(err).Msgf("Error while re-queuing W2E cashout %d", cashoutID)
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return nil, model.ErrNotRetrying
+	}
+
+	a := &model.DisperseAttempt{}
+	err = tx.Get(a,
+		`INSERT INTO disperse_attempts(cashout_id, attempt)
+			SELECT $1, COUNT(*) + 1 FROM disperse_attempts WHERE cashout_id = $1
+			RETURNING *`,
+		cashoutID)
+	if err != nil {
+		log.Err(err).Msgf("Error while recording disperse attempt of W2E cashout %d", cashoutID)
+		return nil, err
+	}
+	return a, tx.Commit()
+}
+
+// RequeueStalledAttempts sends the cashouts whose last attempt started before before and
+// never got a tx back to the retries, the attempt counting as declined. Cashouts held by
+// the bridge limits are left alone.
+func (d *disperseRetryDAO) RequeueStalledAttempts(before time.Time) (int64, error) {
+	res, err := d.db.Exec(
+		`UPDATE wel_cashout_eth_trans t SET disperse_status = $1
+			WHERE t.disperse_status = $2
+			AND EXISTS (SELECT 1 FROM disperse_attempts a
+				WHERE a.cashout_id = t.id AND a.tx_hash = '' AND a.created_at < $3
+				AND a.attempt = (SELECT MAX(attempt) FROM disperse_attempts WHERE cashout_id = t.id))
+			AND NOT EXISTS (SELECT 1 FROM held_transfers h
+				WHERE h.kind = $4 AND h.ref = t.wel_withdraw_tx_hash AND h.log_index = t.log_index AND h.status = $5)`,
+		model.WelCashoutEthRetry, model.WelCashoutEthUnconfirmed, before, model.TransferW2ECashoutDisperse, model.HeldForReview)
+	if err != nil {
+		logger.Get().Err(err).Msg("Error while re-queuing stalled disperse attempts")
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// RecordAttemptTx sets the tx hash of the attempt in flight, if any
+func (d *disperseRetryDAO) RecordAttemptTx(cashoutID int64, txHash string) error {
+	_, err := d.db.Exec("UPDATE disperse_attempts SET tx_hash = $1 WHERE cashout_id = $2 AND tx_hash = ''", txHash, cashoutID)
+	if err != nil {
+		logger.Get().Err(err).Msgf("Error while recording disperse attempt tx %s of W2E cashout %d", txHash, cashoutID)
+	}
+	return err
+}
+
+func (d *disperseRetryDAO) GiveUpDisperse(cashoutID int64) error {
+	res, err := d.db.Exec("UPDATE wel_cashout_eth_trans SET disperse_status = $1 WHERE id = $2 AND disperse_status = $3",
+		model.WelCashoutEthFailed, cashoutID, model.WelCashoutEthRetry)
+	if err != nil {
+		logger.Get().Err(err).Msgf("Error while giving up disperse of W2E cashout %d", cashoutID)
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return model.ErrNotRetrying
+	}
+	return nil
+}
+
+func MkDisperseRetryDao(db *sqlx.DB) *disperseRetryDAO {
+	return &disperseRetryDAO{
+		db: db,
+	}
+}
@@ -13,15 +13,19 @@ import (
 type IEthCashinWelTransDAO interface {
 	CreateEthCashinWelTrans(t *model.EthCashinWelTrans) (int64, error)
 	GetUnconfirmedTx2Treasury(from, treasury, token, amount string) (*model.TxToTreasury, error)
-	GetUnconfirmedTx2TreasuryByTxHash(txhash string) (*model.TxToTreasury, error)
+	GetUnconfirmedTx2TreasuryByTxHash(txhash string, logIndex *int64) (*model.TxToTreasury, error)
 	GetTx2TreasuryFromSender(sender string) ([]model.TxToTreasury, error)
-	GetTx2TreasuryByTxHash(txhash string) (*model.TxToTreasury, error)
+	GetTx2TreasuryByTxHash(txhash string, logIndex int64) (*model.TxToTreasury, error)
 
 	CreateTx2Treasury(t *model.TxToTreasury) error
+	SelectTx2TreasuryPendingConfirmation() ([]model.TxToTreasury, error)
+	UpdateTx2TreasuryConfirmations(txID string, logIndex, blockNumber, confirmations int64, status string) error
 
 	UpdateEthCashinWelTx(t *model.EthCashinWelTrans) error
+	SettleEthCashinWelIssue(t *model.EthCashinWelTrans, issueTxHash string) (bool, error)
 
 	SelectTransByDepositTxHash(txHash string) (*model.EthCashinWelTrans, error)
+	SelectTransByDepositEvent(txHash string, logIndex int64) (*model.EthCashinWelTrans, error)
 	SelectTransByIssueTxHash(txHash string) ([]*model.EthCashinWelTrans, error)
 	SelectTransById(id string) (*model.EthCashinWelTrans, error)
 	SelectTrans(sender, receiver, status string, offset, size uint64) ([]model.EthCashinWelTrans, error)
@@ -38,16 +42,45 @@ func (w *ethCashinWelTransDAO) CreateTx2Treasury(t *model.TxToTreasury) error {
 
 	q := db.Rebind(`INSERT INTO tx_to_treasury(
 									tx_id,
+									log_index,
 									from_address,
 									treasury_address,
 									token_address,
 									amount,
 									tx_fee,
-									status) VALUES (?,?,?,?,?,?,?)`)
-	_, err := db.Exec(q, t.TxID, t.FromAddress, t.TreasuryAddr, t.TokenAddr, t.Amount, t.TxFee, t.Status)
+									status,
+									block_number,
+									confirmations) VALUES (?,?,?,?,?,?,?,?,?,?) ON CONFLICT (tx_id, log_index) DO NOTHING`)
+	_, err := db.Exec(q, t.TxID, t.LogIndex, t.FromAddress, t.TreasuryAddr, t.TokenAddr, t.Amount, t.TxFee, t.Status, t.BlockNumber, t.Confirmations)
 
 	if err != nil {
-		log.Err(err).Msgf("Error while inserting tx to treasury %s", t.TxID)
+		log.Err(err).Msgf("Error while inserting tx to treasury %s:%d", t.TxID, t.LogIndex)
+		return err
+	}
+	return nil
+}
+
+func (w *ethCashinWelTransDAO) SelectTx2TreasuryPendingConfirmation() ([]model.TxToTreasury, error) {
+	db := w.db
+	log := logger.Get()
+
+	res := []model.TxToTreasury{}
+	err := db.Select(&res, "SELECT * FROM tx_to_treasury WHERE status = $1", model.Tx2TrPendingConfirmation)
+	if err != nil {
+		log.Err(err).Msg("[SelectTx2TreasuryPendingConfirmation] error while querying DB")
+		return nil, err
+	}
+	return res, nil
+}
+
+func (w *ethCashinWelTransDAO) UpdateTx2TreasuryConfirmations(txID string, logIndex, blockNumber, confirmations int64, status string) error {
+	db := w.db
+	log := logger.Get()
+
+	q := db.Rebind(`UPDATE tx_to_treasury SET block_number = ?, confirmations = ?, status = ? WHERE tx_id = ? AND log_index = ?`)
+	_, err := db.Exec(q, blockNumber, confirmations, status, txID, logIndex)
+	if err != nil {
+		log.Err(err).Msgf("Error while updating confirmations of tx to treasury %s:%d", txID, logIndex)
 		return err
 	}
 	return nil
@@ -103,17 +136,16 @@ func (w *ethCashinWelTransDAO) GetUnconfirmedTx2Treasury(from, treasury, token,
 	return &res, nil
 }
 
-func (w *ethCashinWelTransDAO) GetTx2TreasuryByTxHash(txhash string) (*model.TxToTreasury, error) {
+func (w *ethCashinWelTransDAO) GetTx2TreasuryByTxHash(txhash string, logIndex int64) (*model.TxToTreasury, error) {
 	db := w.db
 	log := logger.Get()
 
 	var res model.TxToTreasury
 	q := db.Rebind(
 		`SELECT * FROM tx_to_treasury
-			WHERE tx_id = ?
-			ORDER BY created_at DESC`)
+			WHERE tx_id = ? AND log_index = ?`)
 
-	err := db.Get(&res, q, txhash)
+	err := db.Get(&res, q, txhash, logIndex)
 	if err == sql.ErrNoRows {
 		log.Info().Msg("[GetUnconfirmedTx2Treasury] no tx found")
 		return nil, nil
@@ -126,28 +158,38 @@ func (w *ethCashinWelTransDAO) GetTx2TreasuryByTxHash(txhash string) (*model.TxT
 	return &res, nil
 }
 
-func (w *ethCashinWelTransDAO) GetUnconfirmedTx2TreasuryByTxHash(txhash string) (*model.TxToTreasury, error) {
+// GetUnconfirmedTx2TreasuryByTxHash gets the deposit of txhash at logIndex, or its only
+// deposit when logIndex is nil
+func (w *ethCashinWelTransDAO) GetUnconfirmedTx2TreasuryByTxHash(txhash string, logIndex *int64) (*model.TxToTreasury, error) {
 	db := w.db
 	log := logger.Get()
 
-	var res model.TxToTreasury
+	var res []model.TxToTreasury
 	q := db.Rebind(
 		`SELECT * FROM tx_to_treasury
 			WHERE tx_id = ? AND
+						(? OR log_index = ?) AND
 						status = 'unconfirmed'
-			ORDER BY created_at DESC`)
+			ORDER BY log_index`)
 
-	err := db.Get(&res, q, txhash)
-	if err == sql.ErrNoRows {
-		log.Info().Msg("[GetUnconfirmedTx2Treasury] no tx found")
-		return nil, nil
+	var index int64
+	if logIndex != nil {
+		index = *logIndex
 	}
+	err := db.Select(&res, q, txhash, logIndex == nil, index)
 	if err != nil {
 		log.Err(err).Msg("[GetUnconfirmedTx2Treasury] error while querying DB")
 		return nil, err
 	}
+	if len(res) == 0 {
+		log.Info().Msg("[GetUnconfirmedTx2Treasury] no tx found")
+		return nil, nil
+	}
+	if len(res) > 1 {
+		return nil, model.ErrTx2TreasuryAmbiguous
+	}
 
-	return &res, nil
+	return &res[0], nil
 }
 
 func (w *ethCashinWelTransDAO) CreateEthCashinWelTrans(t *model.EthCashinWelTrans) (int64, error) {
@@ -162,28 +204,36 @@ func (w *ethCashinWelTransDAO) CreateEthCashinWelTrans(t *model.EthCashinWelTran
 		Rebind(
 			`INSERT INTO eth_cashin_wel_trans(
 				eth_tx_hash,
+				log_index,
 				wel_issue_tx_hash,
 				eth_token_addr,
 				wel_token_addr,
 				network_id,
 				eth_wallet_addr,
 				wel_wallet_addr,
+				total,
 				amount,
 				commission_fee,
-				status) VALUES (?,?,?,?,?,?,?,?,?,?) RETURNING id`)
+				dest_amount,
+				dust,
+				status) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?) RETURNING id`)
 	var id int64
 	err = tx.
 		Get(&id,
 			qCreate,
 			t.EthTxHash,
+			t.LogIndex,
 			t.WelIssueTxHash,
 			t.EthTokenAddr,
 			t.WelTokenAddr,
 			t.NetworkID,
 			t.EthWalletAddr,
 			t.WelWalletAddr,
+			t.Total,
 			t.Amount,
 			t.CommissionFee,
+			t.DestAmount,
+			t.Dust,
 			t.Status)
 
 	if err != nil {
@@ -192,13 +242,19 @@ func (w *ethCashinWelTransDAO) CreateEthCashinWelTrans(t *model.EthCashinWelTran
 		return id, err
 	}
 
-	qUpdateTx2Treasury := tx.Rebind(`UPDATE tx_to_treasury SET status='isCashin' WHERE tx_id = ?`)
-	_, err = tx.Exec(qUpdateTx2Treasury, t.EthTxHash)
+	// an expired deposit is being refunded, it can't be cashed in anymore
+	qUpdateTx2Treasury := tx.Rebind(`UPDATE tx_to_treasury SET status='isCashin' WHERE tx_id = ? AND log_index = ? AND status <> 'expired'`)
+	res, err := tx.Exec(qUpdateTx2Treasury, t.EthTxHash, t.LogIndex)
 	if err != nil {
 		log.Err(err).Msgf("Error while inserting EthCashinWel tx with eth tx hash %s", t.EthTxHash)
 		tx.Rollback()
 		return id, err
 	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		log.Warn().Msgf("Tx to treasury %s:%d expired, not creating EthCashinWel tx", t.EthTxHash, t.LogIndex)
+		tx.Rollback()
+		return id, model.ErrTx2TreasuryExpired
+	}
 	tx.Commit()
 
 	return id, nil
@@ -220,8 +276,11 @@ func (w *ethCashinWelTransDAO) UpdateEthCashinWelTx(t *model.EthCashinWelTrans)
 		    wel_wallet_addr = ?,
 				total = ?,
 		    commission_fee = ?,
+		    dest_amount = ?,
 		    status = ?,
-				issued_at = ?
+				issued_at = ?,
+		    fail_reason = ?,
+		    issue_attempts = ?
 		    WHERE id = ?`)
 	_, err := db.
 		Exec(q,
@@ -234,8 +293,11 @@ func (w *ethCashinWelTransDAO) UpdateEthCashinWelTx(t *model.EthCashinWelTrans)
 			t.WelWalletAddr,
 			t.Total,
 			t.CommissionFee,
+			t.DestAmount,
 			t.Status,
 			t.IssuedAt,
+			t.FailReason,
+			t.IssueAttempts,
 			t.ID)
 
 	if err != nil {
@@ -245,12 +307,38 @@ func (w *ethCashinWelTransDAO) UpdateEthCashinWelTx(t *model.EthCashinWelTrans)
 	return nil
 }
 
+// SettleEthCashinWelIssue records the outcome of the issue tx of a cashin, provided the
+// cashin is still waiting on issueTxHash. It tells whether the cashin was.
+func (w *ethCashinWelTransDAO) SettleEthCashinWelIssue(t *model.EthCashinWelTrans, issueTxHash string) (bool, error) {
+	res, err := w.db.Exec(
+		`UPDATE eth_cashin_wel_trans SET
+			wel_issue_tx_hash = $1,
+			status = $2,
+			issued_at = $3,
+			fail_reason = $4,
+			issue_attempts = $5
+			WHERE id = $6 AND wel_issue_tx_hash = $7`,
+		t.WelIssueTxHash, t.Status, t.IssuedAt, t.FailReason, t.IssueAttempts, t.ID, issueTxHash)
+	if err != nil {
+		logger.Get().Err(err).Msgf("Error while settling issue %s of EthCashinWel tx with eth tx hash %s", issueTxHash, t.EthTxHash)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
 func (w *ethCashinWelTransDAO) SelectTransByDepositTxHash(txHash string) (*model.EthCashinWelTrans, error) {
 	var t = &model.EthCashinWelTrans{}
 	err := w.db.Get(t, "SELECT * FROM eth_cashin_wel_trans WHERE eth_tx_hash = $1", txHash)
 	return t, err
 }
 
+func (w *ethCashinWelTransDAO) SelectTransByDepositEvent(txHash string, logIndex int64) (*model.EthCashinWelTrans, error) {
+	var t = &model.EthCashinWelTrans{}
+	err := w.db.Get(t, "SELECT * FROM eth_cashin_wel_trans WHERE eth_tx_hash = $1 AND log_index = $2", txHash, logIndex)
+	return t, err
+}
+
 func (w *ethCashinWelTransDAO) SelectTransByIssueTxHash(txHash string) ([]*model.EthCashinWelTrans, error) {
 	var txs = []*model.EthCashinWelTrans{}
 	err := w.db.Select(&txs, "SELECT * FROM eth_cashin_wel_trans WHERE wel_issue_tx_hash = $1", txHash)
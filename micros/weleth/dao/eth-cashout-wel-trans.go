@@ -7,16 +7,21 @@ import (
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type IEthCashoutWelTransDAO interface {
 	CreateEthCashoutWelTrans(t *model.EthCashoutWelTrans) error
 
-	UpdateDepositEthCashoutWelConfirmed(depositTxHash, ethWalletAddr, amount string) error
+	UpdateDepositEthCashoutWelBlock(depositTxHash string, logIndex int64, ethWalletAddr, amount, blockHash string, blockNumber int64) error
+	SelectTransPendingConfirmation() ([]*model.EthCashoutWelTrans, error)
+	UpdateDepositConfirmations(id int64, confirmations int64, status string) error
+	MarkOrphanedByBlockHashes(blockHashes []string) (int64, error)
 
 	UpdateClaimEthCashoutWel(id int64, reqID, reqStatus, claimTxHash, amount, fee, status string) error
 
 	SelectTransByDepositTxHash(txHash string) (*model.EthCashoutWelTrans, error)
+	SelectTransByDepositEvent(txHash string, logIndex int64) (*model.EthCashoutWelTrans, error)
 	SelectTransById(id string) (*model.EthCashoutWelTrans, error)
 	SelectTrans(sender, receiver, status string, offset, size uint64) ([]model.EthCashoutWelTrans, error)
 
@@ -32,40 +37,74 @@ type ethCashoutWelTransDAO struct {
 }
 
 func (w *ethCashoutWelTransDAO) CreateEthCashoutWelTrans(t *model.EthCashoutWelTrans) error {
-	_, err := w.db.NamedExec(`INSERT INTO eth_cashout_wel_trans(deposit_tx_hash, wel_token_addr, eth_token_addr, eth_wallet_addr, wel_wallet_addr, network_id, amount, fee, deposit_at, deposit_status) VALUES (:deposit_tx_hash, :wel_token_addr, :eth_token_addr, :eth_wallet_addr, :wel_wallet_addr, :network_id, :amount, :fee, :deposit_at, :deposit_status)`,
+	_, err := w.db.NamedExec(`INSERT INTO eth_cashout_wel_trans(deposit_tx_hash, log_index, wel_token_addr, eth_token_addr, eth_wallet_addr, wel_wallet_addr, network_id, amount, dest_amount, dust, fee, deposit_at, deposit_status, deposit_block_number, deposit_block_hash, confirmations) VALUES (:deposit_tx_hash, :log_index, :wel_token_addr, :eth_token_addr, :eth_wallet_addr, :wel_wallet_addr, :network_id, :amount, :dest_amount, :dust, :fee, :deposit_at, :deposit_status, :deposit_block_number, :deposit_block_hash, :confirmations) ON CONFLICT (deposit_tx_hash, log_index) DO NOTHING`,
 		map[string]interface{}{
 			"deposit_tx_hash": t.DepositTxHash,
+			"log_index":       t.LogIndex,
 			"wel_token_addr":  t.WelTokenAddr,
 			"eth_token_addr":  t.EthTokenAddr,
 			"eth_wallet_addr": t.EthWalletAddr,
 			"wel_wallet_addr": t.WelWalletAddr,
 			"network_id":      t.NetworkID,
 			"amount":          t.Amount,
+			"dest_amount":     t.DestAmount,
+			"dust":            t.Dust,
 			"fee":             t.Fee,
 			"deposit_at":      time.Now(),
 			"deposit_status":  t.DepositStatus,
+
+			"deposit_block_number": t.DepositBlockNumber,
+			"deposit_block_hash":   t.DepositBlockHash,
+			"confirmations":        t.Confirmations,
 		})
 
 	return err
 }
 
-func (w *ethCashoutWelTransDAO) UpdateDepositEthCashoutWelConfirmed(depositTxHash, ethWalletAddr, amount string) error {
-	_, err := w.db.NamedExec(`UPDATE eth_cashout_wel_trans SET deposit_status = :deposit_status, eth_wallet_addr = :eth_wallet_addr, amount = :amount WHERE deposit_tx_hash = :deposit_tx_hash`,
+// UpdateDepositEthCashoutWelBlock records the block a deposit got (re-)included in, its
+// confirmations start over
+func (w *ethCashoutWelTransDAO) UpdateDepositEthCashoutWelBlock(depositTxHash string, logIndex int64, ethWalletAddr, amount, blockHash string, blockNumber int64) error {
+	_, err := w.db.NamedExec(`UPDATE eth_cashout_wel_trans SET deposit_status = :deposit_status, eth_wallet_addr = :eth_wallet_addr, amount = :amount, deposit_block_number = :deposit_block_number, deposit_block_hash = :deposit_block_hash, confirmations = 0 WHERE deposit_tx_hash = :deposit_tx_hash AND log_index = :log_index`,
 		map[string]interface{}{
-			"deposit_status":  model.StatusSuccess,
-			"eth_wallet_addr": ethWalletAddr,
-			"amount":          amount,
-			"deposit_tx_hash": depositTxHash,
+			"deposit_status":       model.StatusPendingConfirmation,
+			"eth_wallet_addr":      ethWalletAddr,
+			"amount":               amount,
+			"deposit_block_number": blockNumber,
+			"deposit_block_hash":   blockHash,
+			"deposit_tx_hash":      depositTxHash,
+			"log_index":            logIndex,
 		})
 	return err
 }
 
+func (w *ethCashoutWelTransDAO) SelectTransPendingConfirmation() ([]*model.EthCashoutWelTrans, error) {
+	var txs = []*model.EthCashoutWelTrans{}
+	err := w.db.Select(&txs, "SELECT * FROM eth_cashout_wel_trans WHERE deposit_status = $1", model.StatusPendingConfirmation)
+	return txs, err
+}
+
+func (w *ethCashoutWelTransDAO) UpdateDepositConfirmations(id int64, confirmations int64, status string) error {
+	_, err := w.db.Exec("UPDATE eth_cashout_wel_trans SET confirmations = $1, deposit_status = $2 WHERE id = $3", confirmations, status, id)
+	return err
+}
+
+// marks deposits included in blocks no longer part of the canonical chain as orphaned
+func (w *ethCashoutWelTransDAO) MarkOrphanedByBlockHashes(blockHashes []string) (int64, error) {
+	res, err := w.db.Exec("UPDATE eth_cashout_wel_trans SET deposit_status = $1 WHERE deposit_block_hash = ANY($2)", model.StatusOrphaned, pq.Array(blockHashes))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// UpdateClaimEthCashoutWel records a claim on wel, amount and fee being what the claim
+// delivered and charged, in wel token units
 func (w *ethCashoutWelTransDAO) UpdateClaimEthCashoutWel(id int64, reqID, reqStatus, claimTxHash, amount, fee, status string) error {
 	tx, err := w.db.Beginx()
 	if err != nil {
 		return err
 	}
-	_, err = tx.NamedExec(`UPDATE eth_cashout_wel_trans SET claim_tx_hash = :claim_tx_hash, claim_status = :claim_status, amount = :amount, fee = :fee WHERE id= :id`,
+	_, err = tx.NamedExec(`UPDATE eth_cashout_wel_trans SET claim_tx_hash = :claim_tx_hash, claim_status = :claim_status, dest_amount = :amount, fee = :fee WHERE id= :id`,
 		map[string]interface{}{
 			"claim_tx_hash": claimTxHash,
 			"claim_status":  status,
@@ -97,6 +136,12 @@ func (w *ethCashoutWelTransDAO) SelectTransByDepositTxHash(txHash string) (*mode
 	return t, err
 }
 
+func (w *ethCashoutWelTransDAO) SelectTransByDepositEvent(txHash string, logIndex int64) (*model.EthCashoutWelTrans, error) {
+	var t = &model.EthCashoutWelTrans{}
+	err := w.db.Get(t, "SELECT * FROM eth_cashout_wel_trans WHERE deposit_tx_hash = $1 AND log_index = $2", txHash, logIndex)
+	return t, err
+}
+
 func (w *ethCashoutWelTransDAO) SelectTransById(id string) (*model.EthCashoutWelTrans, error) {
 	var t = &model.EthCashoutWelTrans{}
 	err := w.db.Get(t, "SELECT * FROM eth_cashout_wel_trans WHERE id = $1", id)
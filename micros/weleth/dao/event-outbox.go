@@ -0,0 +1,111 @@
+package dao
+
+import (
+	"bridge/micros/weleth/model"
+	"bridge/service-managers/logger"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IEventOutboxDAO interface {
+	// queues the events not queued yet, queued ones are left untouched
+	EnqueueEvents(es []model.OutboxEvent) error
+	SelectDueEvents(chain string, limit int) ([]model.OutboxEvent, error)
+	AckEvent(id int64) error
+	// records the attempts, status and next attempt of a failed event
+	UpdateFailedEvent(e *model.OutboxEvent) error
+
+	SelectDeadLetters(chain string, offset, size uint64) ([]model.OutboxEvent, error)
+	// sends a dead letter back to the queue with a fresh set of attempts
+	RequeueDeadLetter(id int64) error
+}
+
+// sort of a locator for DAOs
+type eventOutboxDAO struct {
+	db *sqlx.DB
+}
+
+func (o *eventOutboxDAO) EnqueueEvents(es []model.OutboxEvent) error {
+	log := logger.Get()
+	tx, err := o.db.Beginx()
+	if err != nil {
+		log.Err(err).Msg("Can't start transaction")
+		return err
+	}
+	for i := range es {
+		_, err := tx.NamedExec(
+			`INSERT INTO event_outbox(chain, tx_hash, log_index, revision, payload)
+				VALUES (:chain, :tx_hash, :log_index, :revision, :payload)
+				ON CONFLICT (chain, tx_hash, log_index, revision) DO NOTHING`, &es[i])
+		if err != nil {
+			log.Err(err).Msgf("Error while queuing %s event %s:%d", es[i].Chain, es[i].TxHash, es[i].LogIndex)
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (o *eventOutboxDAO) SelectDueEvents(chain string, limit int) ([]model.OutboxEvent, error) {
+	var es = []model.OutboxEvent{}
+	err := o.db.Select(&es,
+		`SELECT * FROM event_outbox WHERE chain = $1 AND status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY id LIMIT $2`, chain, limit)
+	return es, err
+}
+
+func (o *eventOutboxDAO) AckEvent(id int64) error {
+	_, err := o.db.Exec("UPDATE event_outbox SET status = 'done', last_error = '', updated_at = NOW() WHERE id = $1", id)
+	if err != nil {
+		logger.Get().Err(err).Msgf("Error while acknowledging queued event %d", id)
+	}
+	return err
+}
+
+func (o *eventOutboxDAO) UpdateFailedEvent(e *model.OutboxEvent) error {
+	_, err := o.db.NamedExec(
+		`UPDATE event_outbox SET
+			status = :status,
+			attempts = :attempts,
+			last_error = :last_error,
+			next_attempt_at = :next_attempt_at,
+			updated_at = NOW()
+			WHERE id = :id`, e)
+	if err != nil {
+		logger.Get().Err(err).Msgf("Error while updating failed queued event %d", e.ID)
+	}
+	return err
+}
+
+func (o *eventOutboxDAO) SelectDeadLetters(chain string, offset, size uint64) ([]model.OutboxEvent, error) {
+	var es = []model.OutboxEvent{}
+	q := "SELECT * FROM event_outbox WHERE status = 'dead' AND ($1 = '' OR chain = $1) ORDER BY id OFFSET $2"
+	params := []interface{}{chain, offset}
+	if size > 0 {
+		q += " LIMIT $3"
+		params = append(params, size)
+	}
+	err := o.db.Select(&es, q, params...)
+	return es, err
+}
+
+func (o *eventOutboxDAO) RequeueDeadLetter(id int64) error {
+	log := logger.Get()
+	res, err := o.db.Exec(
+		`UPDATE event_outbox SET status = 'pending', attempts = 0, next_attempt_at = NOW(), updated_at = NOW()
+			WHERE id = $1 AND status = 'dead'`, id)
+	if err != nil {
+		log.Err(err).Msgf("Error while requeuing dead letter %d", id)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return model.ErrDeadLetterNotFound
+	}
+	return nil
+}
+
+func MkEventOutboxDao(db *sqlx.DB) *eventOutboxDAO {
+	return &eventOutboxDAO{
+		db: db,
+	}
+}
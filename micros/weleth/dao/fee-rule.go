@@ -0,0 +1,127 @@
+package dao
+
+import (
+	"bridge/micros/weleth/model"
+	"bridge/service-managers/logger"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IFeeRuleDAO interface {
+	CreateFeeRule(r *model.FeeRule) (int64, error)
+	UpdateFeeRule(r *model.FeeRule) error
+	DeleteFeeRule(id int64) error
+
+	SelectFeeRuleById(id int64) (*model.FeeRule, error)
+	// empty token or direction matches every rule
+	SelectFeeRules(token, direction string) ([]model.FeeRule, error)
+	SelectFeeSchedule(token, direction string) (model.FeeSchedule, error)
+}
+
+// sort of a locator for DAOs
+type feeRuleDAO struct {
+	db *sqlx.DB
+}
+
+func (f *feeRuleDAO) CreateFeeRule(r *model.FeeRule) (int64, error) {
+	log := logger.Get()
+	var id int64
+	err := f.db.Get(&id,
+		`INSERT INTO fee_rules(
+			token_addr,
+			direction,
+			min_amount,
+			flat_fee,
+			percent_bps,
+			min_fee,
+			max_fee) VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING id`,
+		r.TokenAddr,
+		r.Direction,
+		r.MinAmount,
+		r.FlatFee,
+		r.PercentBps,
+		r.MinFee,
+		r.MaxFee)
+	if err != nil {
+		log.Err(err).Msgf("Error while inserting fee rule for token %s direction %s", r.TokenAddr, r.Direction)
+		return -1, err
+	}
+	return id, nil
+}
+
+func (f *feeRuleDAO) UpdateFeeRule(r *model.FeeRule) error {
+	log := logger.Get()
+	res, err := f.db.Exec(
+		`UPDATE fee_rules SET
+			token_addr = $1,
+			direction = $2,
+			min_amount = $3,
+			flat_fee = $4,
+			percent_bps = $5,
+			min_fee = $6,
+			max_fee = $7,
+			updated_at = NOW()
+			WHERE id = $8`,
+		r.TokenAddr,
+		r.Direction,
+		r.MinAmount,
+		r.FlatFee,
+		r.PercentBps,
+		r.MinFee,
+		r.MaxFee,
+		r.ID)
+	if err != nil {
+		log.Err(err).Msgf("Error while updating fee rule %d", r.ID)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return model.ErrFeeRuleNotFound
+	}
+	return nil
+}
+
+func (f *feeRuleDAO) DeleteFeeRule(id int64) error {
+	log := logger.Get()
+	res, err := f.db.Exec("DELETE FROM fee_rules WHERE id = $1", id)
+	if err != nil {
+		log.Err(err).Msgf("Error while deleting fee rule %d", id)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return model.ErrFeeRuleNotFound
+	}
+	return nil
+}
+
+func (f *feeRuleDAO) SelectFeeRuleById(id int64) (*model.FeeRule, error) {
+	var r = &model.FeeRule{}
+	err := f.db.Get(r, "SELECT * FROM fee_rules WHERE id = $1", id)
+	if err == sql.ErrNoRows {
+		return nil, model.ErrFeeRuleNotFound
+	}
+	return r, err
+}
+
+func (f *feeRuleDAO) SelectFeeRules(token, direction string) ([]model.FeeRule, error) {
+	var rules = []model.FeeRule{}
+	err := f.db.Select(&rules,
+		`SELECT * FROM fee_rules
+			WHERE ($1 = '' OR token_addr = $1) AND ($2 = '' OR direction = $2)
+			ORDER BY token_addr, direction, id`,
+		token, direction)
+	return rules, err
+}
+
+func (f *feeRuleDAO) SelectFeeSchedule(token, direction string) (model.FeeSchedule, error) {
+	if token == "" || direction == "" {
+		return nil, model.ErrInvalidFeeRule
+	}
+	return f.SelectFeeRules(token, direction)
+}
+
+func MkFeeRuleDao(db *sqlx.DB) *feeRuleDAO {
+	return &feeRuleDAO{
+		db: db,
+	}
+}
@@ -0,0 +1,52 @@
+package dao
+
+import (
+	"bridge/service-managers/leader"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// sort of a locator for DAOs
+type listenerLeaseDAO struct {
+	db *sqlx.DB
+}
+
+const selectLease = `SELECT name, holder, acquired_at, renewed_at, expires_at, expires_at > NOW() AS active
+	FROM listener_leases`
+
+func (l *listenerLeaseDAO) AcquireLease(name, holder string, ttl time.Duration) (*leader.Lease, error) {
+	_, err := l.db.Exec(
+		`INSERT INTO listener_leases(name, holder, acquired_at, renewed_at, expires_at)
+			VALUES ($1, $2, NOW(), NOW(), NOW() + $3 * interval '1 millisecond')
+			ON CONFLICT (name) DO UPDATE SET
+				holder = EXCLUDED.holder,
+				acquired_at = CASE WHEN listener_leases.holder = EXCLUDED.holder AND listener_leases.expires_at > NOW() THEN listener_leases.acquired_at ELSE NOW() END,
+				renewed_at = NOW(),
+				expires_at = EXCLUDED.expires_at
+			WHERE listener_leases.holder = EXCLUDED.holder OR listener_leases.expires_at <= NOW()`,
+		name, holder, ttl.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	var lease leader.Lease
+	err = l.db.Get(&lease, selectLease+" WHERE name = $1", name)
+	return &lease, err
+}
+
+func (l *listenerLeaseDAO) ReleaseLease(name, holder string) error {
+	_, err := l.db.Exec("UPDATE listener_leases SET expires_at = NOW() WHERE name = $1 AND holder = $2", name, holder)
+	return err
+}
+
+func (l *listenerLeaseDAO) GetLeases() ([]leader.Lease, error) {
+	var leases = []leader.Lease{}
+	err := l.db.Select(&leases, selectLease+" ORDER BY name")
+	return leases, err
+}
+
+func MkListenerLeaseDao(db *sqlx.DB) *listenerLeaseDAO {
+	return &listenerLeaseDAO{
+		db: db,
+	}
+}
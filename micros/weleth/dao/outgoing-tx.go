@@ -0,0 +1,89 @@
+package dao
+
+import (
+	"bridge/micros/weleth/model"
+	"bridge/service-managers/logger"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// IOutgoingTxDAO follows the ethereum transactions sent by the core once they are
+// replaced or abandoned, so that records keep pointing to what's actually on chain
+type IOutgoingTxDAO interface {
+	ReplaceTxHash(oldHash, newHash string) error
+	ConfirmTx(txHash string) error
+	AbandonTx(txHash, note string) error
+}
+
+// sort of a locator for DAOs
+type outgoingTxDAO struct {
+	db *sqlx.DB
+}
+
+// ReplaceTxHash points every disperse and refund sent in oldHash to newHash
+func (d *outgoingTxDAO) ReplaceTxHash(oldHash, newHash string) error {
+	log := logger.Get()
+	tx, err := d.db.Beginx()
+	if err != nil {
+		log.Err(err).Msg("Can't start transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, q := range []string{
+		"UPDATE wel_cashout_eth_trans SET eth_disperse_tx_hash = $2 WHERE eth_disperse_tx_hash = $1",
+		"UPDATE disperse_attempts SET tx_hash = $2 WHERE tx_hash = $1",
+		"UPDATE refunds SET refund_tx_hash = $2, updated_at = NOW() WHERE refund_tx_hash = $1",
+	} {
+		if _, err := tx.Exec(q, oldHash, newHash); err != nil {
+			log.Err(err).Msgf("Error while replacing tx %s by %s", oldHash, newHash)
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ConfirmTx completes the refunds sent in txHash once it's mined, disperses are confirmed
+// by their own events
+func (d *outgoingTxDAO) ConfirmTx(txHash string) error {
+	_, err := d.db.Exec("UPDATE refunds SET status = $1, refunded_at = NOW(), updated_at = NOW() WHERE refund_tx_hash = $2 AND status = $3",
+		model.RefundRefunded, txHash, model.RefundSent)
+	if err != nil {
+		logger.Get().Err(err).Msgf("Error while confirming refund tx %s", txHash)
+	}
+	return err
+}
+
+// AbandonTx undoes what was sent in txHash once it's known it'll never go through: its
+// cashouts go back to the disperse retries, its refunds fail
+func (d *outgoingTxDAO) AbandonTx(txHash, note string) error {
+	log := logger.Get()
+	tx, err := d.db.Beginx()
+	if err != nil {
+		log.Err(err).Msg("Can't start transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("UPDATE wel_cashout_eth_trans SET disperse_status = $1 WHERE eth_disperse_tx_hash = $2 AND disperse_status = $3",
+		model.WelCashoutEthRetry, txHash, model.WelCashoutEthUnconfirmed)
+	if err != nil {
+		log.Err(err).Msgf("Error while abandoning disperse tx %s", txHash)
+		return err
+	}
+	// refunds marked refunded as soon as they were sent, before the sent status existed
+	_, err = tx.Exec("UPDATE refunds SET status = $1, note = $2, updated_at = NOW() WHERE refund_tx_hash = $3 AND status = ANY($4)",
+		model.RefundFailed, note, txHash, pq.Array([]string{model.RefundSent, model.RefundRefunded}))
+	if err != nil {
+		log.Err(err).Msgf("Error while abandoning refund tx %s", txHash)
+		return err
+	}
+	return tx.Commit()
+}
+
+func MkOutgoingTxDao(db *sqlx.DB) *outgoingTxDAO {
+	return &outgoingTxDAO{
+		db: db,
+	}
+}
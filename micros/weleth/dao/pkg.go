@@ -10,16 +10,41 @@ type DAOs struct {
 	EthCashoutWelTransDAO IEthCashoutWelTransDAO
 	EthCashinWelTransDAO  IEthCashinWelTransDAO
 	WelCashoutEthTransDAO IWelCashoutEthTransDAO
+	WelTx2TreasuryDAO     IWelTx2TreasuryDAO
+	FeeRuleDAO            IFeeRuleDAO
+	BridgeLimitDAO        IBridgeLimitDAO
+	BridgeTokenDAO        IBridgeTokenDAO
+	Tokens                *TokenCache
+	ProcessedEventDAO     IProcessedEventDAO
+	EventOutboxDAO        IEventOutboxDAO
+	RefundDAO             IRefundDAO
+	DisperseRetryDAO      IDisperseRetryDAO
+	OutgoingTxDAO         IOutgoingTxDAO
 	EthSysDAO             *ethSysDAO
 	WelSysDAO             *welSysDAO
+	ScanCursorDAO         *scanCursorDAO
+	ListenerLeaseDAO      *listenerLeaseDAO
 }
 
 func MkDAOs(db *sqlx.DB) *DAOs {
+	bridgeTokenDAO := MkBridgeTokenDao(db)
 	return &DAOs{
 		WelCashinEthTransDAO:  MkWelCashinEthTransDao(db),
 		EthCashoutWelTransDAO: MkEthCashoutWelTransDao(db),
 		EthCashinWelTransDAO:  MkEthCashinWelTransDao(db),
 		WelCashoutEthTransDAO: MkWelCashoutEthTransDao(db),
+		WelTx2TreasuryDAO:     MkWelTx2TreasuryDao(db),
+		FeeRuleDAO:            MkFeeRuleDao(db),
+		BridgeLimitDAO:        MkBridgeLimitDao(db),
+		BridgeTokenDAO:        bridgeTokenDAO,
+		Tokens:                MkTokenCache(bridgeTokenDAO),
+		ProcessedEventDAO:     MkProcessedEventDao(db),
+		EventOutboxDAO:        MkEventOutboxDao(db),
+		RefundDAO:             MkRefundDao(db),
+		DisperseRetryDAO:      MkDisperseRetryDao(db),
+		OutgoingTxDAO:         MkOutgoingTxDao(db),
 		EthSysDAO:             MkEthSysDao(db),
-		WelSysDAO:             MkWelSysDao(db)}
+		WelSysDAO:             MkWelSysDao(db),
+		ScanCursorDAO:         MkScanCursorDao(db),
+		ListenerLeaseDAO:      MkListenerLeaseDao(db)}
 }
@@ -0,0 +1,56 @@
+package dao
+
+import (
+	"bridge/micros/weleth/model"
+	"bridge/service-managers/logger"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IProcessedEventDAO interface {
+	// Processed tells whether an event was already processed with the same revision
+	Processed(e *model.ProcessedEvent) (bool, error)
+	// Record records an event as processed, a newer revision replaces the recorded one
+	Record(e *model.ProcessedEvent) error
+}
+
+// sort of a locator for DAOs
+type processedEventDAO struct {
+	db *sqlx.DB
+}
+
+func (p *processedEventDAO) Processed(e *model.ProcessedEvent) (bool, error) {
+	log := logger.Get()
+	var revision string
+	err := p.db.Get(&revision, "SELECT revision FROM processed_events WHERE chain = $1 AND tx_hash = $2 AND log_index = $3", e.Chain, e.TxHash, e.LogIndex)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		log.Err(err).Msgf("Error while looking up %s event %s:%d", e.Chain, e.TxHash, e.LogIndex)
+		return false, err
+	}
+	return revision == e.Revision, nil
+}
+
+func (p *processedEventDAO) Record(e *model.ProcessedEvent) error {
+	log := logger.Get()
+	_, err := p.db.NamedExec(
+		`INSERT INTO processed_events(chain, tx_hash, log_index, event, revision)
+			VALUES (:chain, :tx_hash, :log_index, :event, :revision)
+			ON CONFLICT (chain, tx_hash, log_index) DO UPDATE SET
+				revision = EXCLUDED.revision,
+				processed_at = NOW()`, e)
+	if err != nil {
+		log.Err(err).Msgf("Error while recording %s event %s:%d", e.Chain, e.TxHash, e.LogIndex)
+		return err
+	}
+	return nil
+}
+
+func MkProcessedEventDao(db *sqlx.DB) *processedEventDAO {
+	return &processedEventDAO{
+		db: db,
+	}
+}
@@ -0,0 +1,141 @@
+package dao
+
+import (
+	"bridge/micros/weleth/model"
+	"bridge/service-managers/logger"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type IRefundDAO interface {
+	ExpireTx2Treasury(before time.Time) ([]model.Refund, error)
+	CreateRefund(r *model.Refund) error
+
+	SelectRefundById(id int64) (*model.Refund, error)
+	SelectRefunds(status string, offset, size uint64) ([]model.Refund, error)
+	TransitionRefund(id int64, from []string, to, note string) (*model.Refund, error)
+	UpdateRefund(r *model.Refund) error
+}
+
+// sort of a locator for DAOs
+type refundDAO struct {
+	db *sqlx.DB
+}
+
+const qCreateRefund = `INSERT INTO refunds(
+				tx_id,
+				log_index,
+				reason,
+				token_addr,
+				to_address,
+				amount,
+				status) VALUES ($1,$2,$3,$4,$5,$6,$7) ON CONFLICT (tx_id, log_index) DO NOTHING`
+
+// ExpireTx2Treasury expires the deposits still waiting for a cashin request since before,
+// each of them getting a refund pending approval
+func (r *refundDAO) ExpireTx2Treasury(before time.Time) ([]model.Refund, error) {
+	log := logger.Get()
+	tx, err := r.db.Beginx()
+	if err != nil {
+		log.Err(err).Msg("Can't start transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	expired := []model.TxToTreasury{}
+	err = tx.Select(&expired,
+		`UPDATE tx_to_treasury SET status = $1 WHERE status = $2 AND created_at < $3 RETURNING *`,
+		model.Tx2TrExpired, model.Tx2TrUnconfirmed, before)
+	if err != nil {
+		log.Err(err).Msg("Error while expiring txs to treasury")
+		return nil, err
+	}
+
+	refunds := make([]model.Refund, 0, len(expired))
+	for _, t := range expired {
+		refund := model.RefundOfTx2Treasury(t)
+		if _, err := tx.Exec(qCreateRefund, refund.TxID, refund.LogIndex, refund.Reason, refund.TokenAddr, refund.ToAddress, refund.Amount, refund.Status); err != nil {
+			log.Err(err).Msgf("Error while creating refund of tx to treasury %s:%d", t.TxID, t.LogIndex)
+			return nil, err
+		}
+		refunds = append(refunds, refund)
+	}
+	return refunds, tx.Commit()
+}
+
+func (r *refundDAO) CreateRefund(refund *model.Refund) error {
+	_, err := r.db.Exec(qCreateRefund, refund.TxID, refund.LogIndex, refund.Reason, refund.TokenAddr, refund.ToAddress, refund.Amount, refund.Status)
+	if err != nil {
+		logger.Get().Err(err).Msgf("Error while creating refund of tx to treasury %s:%d", refund.TxID, refund.LogIndex)
+	}
+	return err
+}
+
+func (r *refundDAO) SelectRefundById(id int64) (*model.Refund, error) {
+	var refund = &model.Refund{}
+	err := r.db.Get(refund, "SELECT * FROM refunds WHERE id = $1", id)
+	if err == sql.ErrNoRows {
+		return nil, model.ErrRefundNotFound
+	}
+	return refund, err
+}
+
+func (r *refundDAO) SelectRefunds(status string, offset, size uint64) ([]model.Refund, error) {
+	var refunds = []model.Refund{}
+	q := "SELECT * FROM refunds WHERE ($1 = '' OR status = $1) ORDER BY id OFFSET $2"
+	params := []interface{}{status, offset}
+	if size > 0 {
+		q += " LIMIT $3"
+		params = append(params, size)
+	}
+	err := r.db.Select(&refunds, q, params...)
+	return refunds, err
+}
+
+// TransitionRefund moves a refund to status to, provided it's still in one of the from
+// statuses, so that a refund can't be approved twice
+func (r *refundDAO) TransitionRefund(id int64, from []string, to, note string) (*model.Refund, error) {
+	var refund = &model.Refund{}
+	err := r.db.Get(refund,
+		`UPDATE refunds SET status = $1, note = $2, updated_at = NOW()
+			WHERE id = $3 AND status = ANY($4) RETURNING *`,
+		to, note, id, pq.Array(from))
+	if err == sql.ErrNoRows {
+		if _, err := r.SelectRefundById(id); err != nil {
+			return nil, err
+		}
+		return nil, model.ErrRefundStatus
+	}
+	if err != nil {
+		logger.Get().Err(err).Msgf("Error while updating status of refund %d", id)
+		return nil, err
+	}
+	return refund, nil
+}
+
+func (r *refundDAO) UpdateRefund(refund *model.Refund) error {
+	_, err := r.db.Exec(
+		`UPDATE refunds SET
+			gas_fee = $1,
+			refund_amount = $2,
+			refund_tx_hash = $3,
+			status = $4,
+			note = $5,
+			updated_at = NOW(),
+			refunded_at = CASE WHEN $4 = 'refunded' THEN NOW() ELSE refunded_at END
+			WHERE id = $6`,
+		refund.GasFee, refund.RefundAmount, refund.RefundTxHash, refund.Status, refund.Note, refund.ID)
+	if err != nil {
+		logger.Get().Err(err).Msgf("Error while updating refund %d", refund.ID)
+	}
+	return err
+}
+
+func MkRefundDao(db *sqlx.DB) *refundDAO {
+	return &refundDAO{
+		db: db,
+	}
+}
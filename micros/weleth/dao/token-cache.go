@@ -0,0 +1,152 @@
+package dao
+
+import (
+	"bridge/common/consts"
+	"bridge/micros/weleth/model"
+	"bridge/service-managers/logger"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var DefaultTokenRefreshInterval = time.Minute
+
+// TokenCache keeps the enabled bridge tokens in memory, it's reloaded periodically and
+// after every change made through this process so that token changes apply without a
+// restart
+type TokenCache struct {
+	dao IBridgeTokenDAO
+
+	mu       sync.RWMutex
+	byEth    map[string]model.BridgeToken
+	byWel    map[string]model.BridgeToken
+	tokens   []model.BridgeToken
+	onReload []func(tokens []model.BridgeToken)
+}
+
+func MkTokenCache(dao IBridgeTokenDAO) *TokenCache {
+	return &TokenCache{
+		dao:   dao,
+		byEth: make(map[string]model.BridgeToken),
+		byWel: make(map[string]model.BridgeToken),
+	}
+}
+
+// eth addresses are matched whatever their case
+func ethKey(addr string) string {
+	return common.HexToAddress(addr).Hex()
+}
+
+// OnReload registers f to be called with the enabled tokens after every reload
+func (c *TokenCache) OnReload(f func(tokens []model.BridgeToken)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onReload = append(c.onReload, f)
+}
+
+func (c *TokenCache) Reload() error {
+	all, err := c.dao.SelectBridgeTokens()
+	if err != nil {
+		logger.Get().Err(err).Msg("[token cache] Unable to load bridge tokens")
+		return err
+	}
+
+	byEth := make(map[string]model.BridgeToken)
+	byWel := make(map[string]model.BridgeToken)
+	tokens := make([]model.BridgeToken, 0, len(all))
+	for _, t := range all {
+		if !t.Enabled {
+			continue
+		}
+		byEth[ethKey(t.EthAddr)] = t
+		byWel[t.WelAddr] = t
+		tokens = append(tokens, t)
+	}
+
+	c.mu.Lock()
+	c.byEth, c.byWel, c.tokens = byEth, byWel, tokens
+	hooks := c.onReload
+	c.mu.Unlock()
+
+	for _, f := range hooks {
+		f(tokens)
+	}
+	return nil
+}
+
+func (c *TokenCache) ByEth(addr string) (model.BridgeToken, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	t, ok := c.byEth[ethKey(addr)]
+	return t, ok
+}
+
+func (c *TokenCache) ByWel(addr string) (model.BridgeToken, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	t, ok := c.byWel[addr]
+	return t, ok
+}
+
+// WelFromEth returns the wel token paired with an eth token, "" when it isn't listed
+func (c *TokenCache) WelFromEth(addr string) string {
+	t, _ := c.ByEth(addr)
+	return t.WelAddr
+}
+
+// EthFromWel returns the eth token paired with a wel token, "" when it isn't listed
+func (c *TokenCache) EthFromWel(addr string) string {
+	t, _ := c.ByWel(addr)
+	return t.EthAddr
+}
+
+// Limits returns the limits of the transfers of a token out of its chain
+func (c *TokenCache) Limits(addr string) (limits model.BridgeLimits, paused bool) {
+	if t, ok := c.ByWel(addr); ok {
+		return t.WelLimits(), t.Paused
+	}
+	if t, ok := c.ByEth(addr); ok {
+		return t.EthLimits(), t.Paused
+	}
+	return model.BridgeLimits{}, false
+}
+
+// Normalize converts amount of token, on the source chain of direction, into units of its
+// pair on the destination chain. Tokens missing from the registry are assumed to share
+// their decimals.
+func (c *TokenCache) Normalize(token, direction, amount string) (model.NormalizedAmount, error) {
+	t, ok := c.ByEth(token)
+	if direction == model.FeeWelToEth {
+		t, ok = c.ByWel(token)
+	}
+	if !ok {
+		return model.SameDecimals(amount)
+	}
+	return t.Normalize(direction, amount)
+}
+
+func (c *TokenCache) Tokens() []model.BridgeToken {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]model.BridgeToken{}, c.tokens...)
+}
+
+// Refresh is a daemon generator periodically reloading the cache, picking up changes made
+// by other processes
+func (c *TokenCache) Refresh(ctx context.Context) (consts.Daemon, error) {
+	return func() {
+		logger.Get().Info().Msg("[token cache] Start refreshing bridge tokens")
+		for {
+			consts.SleepContext(ctx, DefaultTokenRefreshInterval)
+			select {
+			case <-ctx.Done():
+				logger.Get().Info().Msg("[token cache] Stop refreshing bridge tokens")
+				return
+			default:
+				c.Reload()
+			}
+		}
+	}, nil
+}
@@ -0,0 +1,64 @@
+package dao
+
+import (
+	"bridge/micros/weleth/model"
+	"testing"
+)
+
+type fakeBridgeTokenDAO struct {
+	IBridgeTokenDAO
+	tokens []model.BridgeToken
+}
+
+func (f *fakeBridgeTokenDAO) SelectBridgeTokens() ([]model.BridgeToken, error) {
+	return f.tokens, nil
+}
+
+func TestTokenCache(t *testing.T) {
+	fake := &fakeBridgeTokenDAO{tokens: []model.BridgeToken{
+		{EthAddr: "0xb60bd744550b46DBBDc3f17ccea62E619d772502", WelAddr: "W9yD14Nj9j7xAB4dbGeiX9h8unkKHxuTtb", Enabled: true, EthMaxTransfer: "100"},
+		{EthAddr: "0x0000000000000000000000000000000000000000", WelAddr: "WLNYdo8jy9xxuyGhQtqU2DAgcptBgJu4jd", Enabled: true, Paused: true, WelDailyCap: "5"},
+		{EthAddr: "0x1111111111111111111111111111111111111111", WelAddr: "WDisabled", Enabled: false},
+	}}
+	cache := MkTokenCache(fake)
+
+	var reloaded []model.BridgeToken
+	cache.OnReload(func(tokens []model.BridgeToken) { reloaded = tokens })
+	if err := cache.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reloaded) != 2 || len(cache.Tokens()) != 2 {
+		t.Errorf("disabled tokens should be left out, got %+v", reloaded)
+	}
+	// eth addresses are matched case insensitively
+	if wel := cache.WelFromEth("0xb60bd744550b46dbbdc3f17ccea62e619d772502"); wel != "W9yD14Nj9j7xAB4dbGeiX9h8unkKHxuTtb" {
+		t.Errorf("unexpected wel token %q", wel)
+	}
+	if eth := cache.EthFromWel("WDisabled"); eth != "" {
+		t.Errorf("disabled token mapped to %q", eth)
+	}
+
+	if limits, paused := cache.Limits("0xb60bd744550b46DBBDc3f17ccea62E619d772502"); limits.MaxTransfer != "100" || paused {
+		t.Errorf("unexpected eth limits %+v, paused %v", limits, paused)
+	}
+	if limits, paused := cache.Limits("WLNYdo8jy9xxuyGhQtqU2DAgcptBgJu4jd"); limits.DailyCap != "5" || !paused {
+		t.Errorf("unexpected wel limits %+v, paused %v", limits, paused)
+	}
+
+	fake.tokens[0].EthDecimals, fake.tokens[0].WelDecimals = 18, 6
+	cache.Reload()
+	if n, err := cache.Normalize("0xb60bd744550b46DBBDc3f17ccea62E619d772502", model.FeeEthToWel, "1000000000123"); err != nil || n.DestAmount != "1" || n.Dust != "123" {
+		t.Errorf("unexpected normalization %+v, %v", n, err)
+	}
+	if n, _ := cache.Normalize("WUnknown", model.FeeWelToEth, "42"); n.DestAmount != "42" {
+		t.Errorf("unlisted token amount should be left as is, got %+v", n)
+	}
+
+	// changes apply on the next reload
+	fake.tokens = fake.tokens[:1]
+	cache.Reload()
+	if _, ok := cache.ByWel("WLNYdo8jy9xxuyGhQtqU2DAgcptBgJu4jd"); ok {
+		t.Errorf("removed token still cached")
+	}
+}
@@ -13,10 +13,13 @@ type IWelCashinEthTransDAO interface {
 	CreateWelCashinEthTrans(t *model.WelCashinEthTrans) error
 
 	UpdateDepositWelCashinEthConfirmed(depositTxHash, welWalletAddr, amount, fee string) error
+	SelectTransPendingConfirmation() ([]*model.WelCashinEthTrans, error)
+	UpdateDepositConfirmations(id int64, confirmations int64, status string) error
 
 	UpdateClaimWelCashinEth(id int64, reqID, reqStatus, claimTxHash, status string) error
 
 	SelectTransByDepositTxHash(txHash string) (*model.WelCashinEthTrans, error)
+	SelectTransByDepositEvent(txHash string, logIndex int64) (*model.WelCashinEthTrans, error)
 	SelectTransById(id string) (*model.WelCashinEthTrans, error)
 	SelectTrans(sender, receiver, status string, offset, size uint64) ([]model.WelCashinEthTrans, error)
 
@@ -32,18 +35,24 @@ type welCashinEthTransDAO struct {
 }
 
 func (w *welCashinEthTransDAO) CreateWelCashinEthTrans(t *model.WelCashinEthTrans) error {
-	_, err := w.db.NamedExec(`INSERT INTO wel_cashin_eth_trans(deposit_tx_hash, wel_token_addr, eth_token_addr,eth_wallet_addr, wel_wallet_addr, network_id, amount, fee, deposit_at, deposit_status) VALUES (:deposit_tx_hash, :wel_token_addr, :eth_token_addr, :eth_wallet_addr, :wel_wallet_addr, :network_id, :amount, :fee, :deposit_at, :deposit_status)`,
+	_, err := w.db.NamedExec(`INSERT INTO wel_cashin_eth_trans(deposit_tx_hash, log_index, wel_token_addr, eth_token_addr,eth_wallet_addr, wel_wallet_addr, network_id, amount, dest_amount, dust, fee, deposit_at, deposit_status, deposit_block_number, confirmations) VALUES (:deposit_tx_hash, :log_index, :wel_token_addr, :eth_token_addr, :eth_wallet_addr, :wel_wallet_addr, :network_id, :amount, :dest_amount, :dust, :fee, :deposit_at, :deposit_status, :deposit_block_number, :confirmations) ON CONFLICT (deposit_tx_hash, log_index) DO NOTHING`,
 		map[string]interface{}{
 			"deposit_tx_hash": t.DepositTxHash,
+			"log_index":       t.LogIndex,
 			"eth_wallet_addr": t.EthWalletAddr,
 			"wel_wallet_addr": t.WelWalletAddr,
 			"eth_token_addr":  t.EthTokenAddr,
 			"wel_token_addr":  t.WelTokenAddr,
 			"network_id":      t.NetworkID,
 			"amount":          t.Amount,
+			"dest_amount":     t.DestAmount,
+			"dust":            t.Dust,
 			"fee":             t.Fee,
 			"deposit_at":      t.DepositAt,
 			"deposit_status":  t.DepositStatus,
+
+			"deposit_block_number": t.DepositBlockNumber,
+			"confirmations":        t.Confirmations,
 		})
 	return err
 }
@@ -60,6 +69,17 @@ func (w *welCashinEthTransDAO) UpdateDepositWelCashinEthConfirmed(depositTxHash,
 	return err
 }
 
+func (w *welCashinEthTransDAO) SelectTransPendingConfirmation() ([]*model.WelCashinEthTrans, error) {
+	var txs = []*model.WelCashinEthTrans{}
+	err := w.db.Select(&txs, "SELECT * FROM wel_cashin_eth_trans WHERE deposit_status = $1", model.StatusPendingConfirmation)
+	return txs, err
+}
+
+func (w *welCashinEthTransDAO) UpdateDepositConfirmations(id int64, confirmations int64, status string) error {
+	_, err := w.db.Exec("UPDATE wel_cashin_eth_trans SET confirmations = $1, deposit_status = $2 WHERE id = $3", confirmations, status, id)
+	return err
+}
+
 func (w *welCashinEthTransDAO) UpdateClaimWelCashinEth(id int64, reqID, reqStatus, claimTxHash, status string) error {
 	tx, err := w.db.Beginx()
 	if err != nil {
@@ -97,6 +117,12 @@ func (w *welCashinEthTransDAO) SelectTransByDepositTxHash(txHash string) (*model
 	return t, err
 }
 
+func (w *welCashinEthTransDAO) SelectTransByDepositEvent(txHash string, logIndex int64) (*model.WelCashinEthTrans, error) {
+	var t = &model.WelCashinEthTrans{}
+	err := w.db.Get(t, "SELECT * FROM wel_cashin_eth_trans WHERE deposit_tx_hash = $1 AND log_index = $2", txHash, logIndex)
+	return t, err
+}
+
 func (w *welCashinEthTransDAO) SelectTransById(id string) (*model.WelCashinEthTrans, error) {
 	var t = &model.WelCashinEthTrans{}
 	err := w.db.Get(t, "SELECT * FROM wel_cashin_eth_trans WHERE id = $1", id)
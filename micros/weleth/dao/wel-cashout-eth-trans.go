@@ -3,22 +3,28 @@ package dao
 import (
 	"bridge/micros/weleth/model"
 	"bridge/service-managers/logger"
+	"database/sql"
 	"fmt"
 	"strings"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type IWelCashoutEthTransDAO interface {
 	CreateWelCashoutEthTrans(t *model.WelCashoutEthTrans) (int64, error)
 
 	UpdateWelCashoutEthTx(t *model.WelCashoutEthTrans) error
+	SelectTransPendingConfirmation() ([]*model.WelCashoutEthTrans, error)
+	UpdateCashoutConfirmations(id int64, confirmations int64, status string) error
+	MarkOrphanedByBlockHashes(blockHashes []string) (int64, error)
+	RetryDeclinedDisperse(disperseTxHash, ethWalletAddr, amount string) (int64, error)
 
 	SelectTransByWithdrawTxHash(txHash string) (*model.WelCashoutEthTrans, error)
+	SelectTransByWithdrawEvent(txHash string, logIndex int64) (*model.WelCashoutEthTrans, error)
 	SelectTransByDisperseTxHash(txHash string) ([]*model.WelCashoutEthTrans, error)
 	SelectTransById(id string) (*model.WelCashoutEthTrans, error)
 
-	SelectTransByDisperseTxHashEthAddrAmount(txHash, ethWalletAddr, amount string) ([]*model.WelCashoutEthTrans, error)
 	SelectTrans(sender, receiver, status string, offset, size uint64) ([]model.WelCashoutEthTrans, error)
 }
 
@@ -40,6 +46,7 @@ func (w *welCashoutEthTransDAO) CreateWelCashoutEthTrans(t *model.WelCashoutEthT
 			`INSERT INTO wel_cashout_eth_trans(
 				eth_disperse_tx_hash,
 				wel_withdraw_tx_hash,
+				log_index,
 				eth_token_addr,
 				wel_token_addr,
 				network_id,
@@ -48,14 +55,20 @@ func (w *welCashoutEthTransDAO) CreateWelCashoutEthTrans(t *model.WelCashoutEthT
 				total,
 				amount,
 				commission_fee,
+				dest_amount,
+				dust,
 				cashout_status,
-				disperse_status) VALUES (?,?,?,?,?,?,?,?,?,?,?,?) RETURNING id`)
+				disperse_status,
+				withdraw_block_number,
+				confirmations) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)
+				ON CONFLICT (wel_withdraw_tx_hash, log_index) DO NOTHING RETURNING id`)
 	var id int64
 	err = tx.
 		Get(&id,
 			qCreate,
 			t.EthDisperseTxHash,
 			t.WelWithdrawTxHash,
+			t.LogIndex,
 			t.EthTokenAddr,
 			t.WelTokenAddr,
 			t.NetworkID,
@@ -64,22 +77,46 @@ func (w *welCashoutEthTransDAO) CreateWelCashoutEthTrans(t *model.WelCashoutEthT
 			t.Total,
 			t.Amount,
 			t.CommissionFee,
+			t.DestAmount,
+			t.Dust,
 			t.CashoutStatus,
-			t.DisperseStatus)
+			t.DisperseStatus,
+			t.WithdrawBlockNumber,
+			t.Confirmations)
 
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return -1, model.ErrAlreadyRecorded
+	}
 	if err != nil {
 		log.Err(err).Msgf("Error while inserting WelCashoutEth tx with wel tx hash %s", t.WelWithdrawTxHash)
 		tx.Rollback()
 		return id, err
 	}
 
-	qUpdateTx2Treasury := tx.Rebind(`UPDATE tx_to_treasury SET status='isCashin' WHERE tx_id = ?`)
-	_, err = tx.Exec(qUpdateTx2Treasury, t.EthDisperseTxHash)
+	// cashouts by a direct transfer to the treasury use up that transfer, unless it expired;
+	// cashouts through the contract have no such transfer
+	qUpdateTx2Treasury := tx.Rebind(`UPDATE wel_tx_to_treasury SET status='isCashin' WHERE tx_id = ? AND status <> 'expired'`)
+	res, err := tx.Exec(qUpdateTx2Treasury, t.WelWithdrawTxHash)
 	if err != nil {
 		log.Err(err).Msgf("Error while inserting WelCashoutEth tx with wel tx hash %s", t.WelWithdrawTxHash)
 		tx.Rollback()
 		return id, err
 	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		var expired bool
+		qExpired := tx.Rebind(`SELECT EXISTS(SELECT 1 FROM wel_tx_to_treasury WHERE tx_id = ? AND status = 'expired')`)
+		if err := tx.Get(&expired, qExpired, t.WelWithdrawTxHash); err != nil {
+			log.Err(err).Msgf("Error while inserting WelCashoutEth tx with wel tx hash %s", t.WelWithdrawTxHash)
+			tx.Rollback()
+			return id, err
+		}
+		if expired {
+			log.Warn().Msgf("Wel tx to treasury %s expired, not creating WelCashoutEth tx", t.WelWithdrawTxHash)
+			tx.Rollback()
+			return id, model.ErrTx2TreasuryExpired
+		}
+	}
 	tx.Commit()
 
 	return id, nil
@@ -101,9 +138,12 @@ func (w *welCashoutEthTransDAO) UpdateWelCashoutEthTx(t *model.WelCashoutEthTran
 		    wel_wallet_addr = ?,
 		    amount = ?,
 		    commission_fee = ?,
+		    dest_amount = ?,
 		    cashout_status = ?, 
 		    disperse_status = ?,
-				dispersed_at = ?
+				dispersed_at = ?,
+				disperse_block_number = ?,
+				disperse_block_hash = ?
 		    WHERE id = ?`)
 	_, err := db.
 		Exec(q,
@@ -116,9 +156,12 @@ func (w *welCashoutEthTransDAO) UpdateWelCashoutEthTx(t *model.WelCashoutEthTran
 			t.WelWalletAddr,
 			t.Amount,
 			t.CommissionFee,
+			t.DestAmount,
 			t.CashoutStatus,
 			t.DisperseStatus,
 			t.DispersedAt,
+			t.DisperseBlockNumber,
+			t.DisperseBlockHash,
 			t.ID)
 
 	if err != nil {
@@ -128,22 +171,64 @@ func (w *welCashoutEthTransDAO) UpdateWelCashoutEthTx(t *model.WelCashoutEthTran
 	return nil
 }
 
+func (w *welCashoutEthTransDAO) SelectTransPendingConfirmation() ([]*model.WelCashoutEthTrans, error) {
+	var txs = []*model.WelCashoutEthTrans{}
+	err := w.db.Select(&txs, "SELECT * FROM wel_cashout_eth_trans WHERE cashout_status = $1", model.WelCashoutEthPendingConfirmation)
+	return txs, err
+}
+
+func (w *welCashoutEthTransDAO) UpdateCashoutConfirmations(id int64, confirmations int64, status string) error {
+	log := logger.Get()
+	_, err := w.db.Exec("UPDATE wel_cashout_eth_trans SET confirmations = $1, cashout_status = $2 WHERE id = $3", confirmations, status, id)
+	if err != nil {
+		log.Err(err).Msgf("Error while updating confirmations of WelCashoutEth tx %d", id)
+	}
+	return err
+}
+
+// marks disperses included in blocks no longer part of the canonical chain as orphaned
+func (w *welCashoutEthTransDAO) MarkOrphanedByBlockHashes(blockHashes []string) (int64, error) {
+	log := logger.Get()
+	res, err := w.db.Exec("UPDATE wel_cashout_eth_trans SET disperse_status = $1 WHERE disperse_block_hash = ANY($2)", model.WelCashoutEthOrphaned, pq.Array(blockHashes))
+	if err != nil {
+		log.Err(err).Msg("Error while marking orphaned WelCashoutEth txs")
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
 func (w *welCashoutEthTransDAO) SelectTransByWithdrawTxHash(txHash string) (*model.WelCashoutEthTrans, error) {
 	var t = &model.WelCashoutEthTrans{}
 	err := w.db.Get(t, "SELECT * FROM wel_cashout_eth_trans WHERE wel_withdraw_tx_hash = $1", txHash)
 	return t, err
 }
 
+func (w *welCashoutEthTransDAO) SelectTransByWithdrawEvent(txHash string, logIndex int64) (*model.WelCashoutEthTrans, error) {
+	var t = &model.WelCashoutEthTrans{}
+	err := w.db.Get(t, "SELECT * FROM wel_cashout_eth_trans WHERE wel_withdraw_tx_hash = $1 AND log_index = $2", txHash, logIndex)
+	return t, err
+}
+
 func (w *welCashoutEthTransDAO) SelectTransByDisperseTxHash(txHash string) ([]*model.WelCashoutEthTrans, error) {
 	var txs = []*model.WelCashoutEthTrans{}
 	err := w.db.Select(&txs, "SELECT * FROM wel_cashout_eth_trans WHERE eth_disperse_tx_hash = $1", txHash)
 	return txs, err
 }
 
-func (w *welCashoutEthTransDAO) SelectTransByDisperseTxHashEthAddrAmount(txHash, ethWalletAddr, amount string) ([]*model.WelCashoutEthTrans, error) {
-	var txs = []*model.WelCashoutEthTrans{}
-	err := w.db.Select(&txs, "SELECT * FROM wel_cashout_eth_trans WHERE wel_withdraw_tx_hash = $1 AND eth_wallet_addr = $2 AND amount = $3", txHash, ethWalletAddr, amount)
-	return txs, err
+// RetryDeclinedDisperse hands the cashouts of a disperse declined to the retry scheduler,
+// only those still waiting on that disperse so that a redelivered decline is a no-op and a
+// cashout given up on stays failed
+func (w *welCashoutEthTransDAO) RetryDeclinedDisperse(disperseTxHash, ethWalletAddr, amount string) (int64, error) {
+	log := logger.Get()
+	res, err := w.db.Exec(
+		`UPDATE wel_cashout_eth_trans SET disperse_status = $1
+			WHERE disperse_status = $2 AND eth_disperse_tx_hash = $3 AND eth_wallet_addr = $4 AND dest_amount = $5`,
+		model.WelCashoutEthRetry, model.WelCashoutEthUnconfirmed, disperseTxHash, ethWalletAddr, amount)
+	if err != nil {
+		log.Err(err).Msgf("Error while retrying WelCashoutEth txs declined in disperse %s", disperseTxHash)
+		return 0, err
+	}
+	return res.RowsAffected()
 }
 
 func (w *welCashoutEthTransDAO) SelectTransById(id string) (*model.WelCashoutEthTrans, error) {
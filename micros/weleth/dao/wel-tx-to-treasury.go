@@ -0,0 +1,152 @@
+package dao
+
+import (
+	"bridge/micros/weleth/model"
+	"bridge/service-managers/logger"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// IWelTx2TreasuryDAO keeps the transfers to the Welups treasury, rows have the same shape
+// as the ones of tx_to_treasury on the eth side
+type IWelTx2TreasuryDAO interface {
+	CreateWelTx2Treasury(t *model.TxToTreasury) error
+	GetUnconfirmedWelTx2Treasury(from, treasury, token, amount string) (*model.TxToTreasury, error)
+	GetUnconfirmedWelTx2TreasuryByTxHash(txhash string, logIndex int64) (*model.TxToTreasury, error)
+	GetWelTx2TreasuryFromSender(sender string) ([]model.TxToTreasury, error)
+
+	SelectWelTx2TreasuryPendingConfirmation() ([]model.TxToTreasury, error)
+	UpdateWelTx2TreasuryConfirmations(txID string, logIndex, blockNumber, confirmations int64, status string) error
+}
+
+// sort of a locator for DAOs
+type welTx2TreasuryDAO struct {
+	db *sqlx.DB
+}
+
+func (w *welTx2TreasuryDAO) CreateWelTx2Treasury(t *model.TxToTreasury) error {
+	db := w.db
+	log := logger.Get()
+
+	q := db.Rebind(`INSERT INTO wel_tx_to_treasury(
+									tx_id,
+									log_index,
+									from_address,
+									treasury_address,
+									token_address,
+									amount,
+									tx_fee,
+									status,
+									block_number,
+									confirmations) VALUES (?,?,?,?,?,?,?,?,?,?) ON CONFLICT (tx_id, log_index) DO NOTHING`)
+	_, err := db.Exec(q, t.TxID, t.LogIndex, t.FromAddress, t.TreasuryAddr, t.TokenAddr, t.Amount, t.TxFee, t.Status, t.BlockNumber, t.Confirmations)
+
+	if err != nil {
+		log.Err(err).Msgf("Error while inserting wel tx to treasury %s:%d", t.TxID, t.LogIndex)
+		return err
+	}
+	return nil
+}
+
+func (w *welTx2TreasuryDAO) GetUnconfirmedWelTx2Treasury(from, treasury, token, amount string) (*model.TxToTreasury, error) {
+	db := w.db
+	log := logger.Get()
+
+	var res model.TxToTreasury
+	q := db.Rebind(
+		`SELECT * FROM wel_tx_to_treasury
+			WHERE from_address = ? AND
+						treasury_address = ? AND
+						token_address = ? AND
+						amount = ? AND
+						status = 'unconfirmed'
+			ORDER BY created_at DESC LIMIT 1`)
+
+	err := db.Get(&res, q, from, treasury, token, amount)
+	if err == sql.ErrNoRows {
+		log.Info().Msg("[GetUnconfirmedWelTx2Treasury] no tx found")
+		return nil, nil
+	}
+	if err != nil {
+		log.Err(err).Msg("[GetUnconfirmedWelTx2Treasury] error while querying DB")
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (w *welTx2TreasuryDAO) GetUnconfirmedWelTx2TreasuryByTxHash(txhash string, logIndex int64) (*model.TxToTreasury, error) {
+	db := w.db
+	log := logger.Get()
+
+	var res model.TxToTreasury
+	q := db.Rebind(
+		`SELECT * FROM wel_tx_to_treasury
+			WHERE tx_id = ? AND
+						log_index = ? AND
+						status = 'unconfirmed'`)
+
+	err := db.Get(&res, q, txhash, logIndex)
+	if err == sql.ErrNoRows {
+		log.Info().Msg("[GetUnconfirmedWelTx2Treasury] no tx found")
+		return nil, nil
+	}
+	if err != nil {
+		log.Err(err).Msg("[GetUnconfirmedWelTx2Treasury] error while querying DB")
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (w *welTx2TreasuryDAO) GetWelTx2TreasuryFromSender(sender string) ([]model.TxToTreasury, error) {
+	db := w.db
+	log := logger.Get()
+
+	res := []model.TxToTreasury{}
+	q := db.Rebind(
+		`SELECT * FROM wel_tx_to_treasury
+			WHERE from_address = ?
+			ORDER BY created_at DESC`)
+
+	err := db.Select(&res, q, sender)
+	if err != nil {
+		log.Err(err).Msg("[GetWelTx2Treasury] error while querying DB")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (w *welTx2TreasuryDAO) SelectWelTx2TreasuryPendingConfirmation() ([]model.TxToTreasury, error) {
+	db := w.db
+	log := logger.Get()
+
+	res := []model.TxToTreasury{}
+	err := db.Select(&res, "SELECT * FROM wel_tx_to_treasury WHERE status = $1", model.Tx2TrPendingConfirmation)
+	if err != nil {
+		log.Err(err).Msg("[SelectWelTx2TreasuryPendingConfirmation] error while querying DB")
+		return nil, err
+	}
+	return res, nil
+}
+
+func (w *welTx2TreasuryDAO) UpdateWelTx2TreasuryConfirmations(txID string, logIndex, blockNumber, confirmations int64, status string) error {
+	db := w.db
+	log := logger.Get()
+
+	q := db.Rebind(`UPDATE wel_tx_to_treasury SET block_number = ?, confirmations = ?, status = ? WHERE tx_id = ? AND log_index = ?`)
+	_, err := db.Exec(q, blockNumber, confirmations, status, txID, logIndex)
+	if err != nil {
+		log.Err(err).Msgf("Error while updating confirmations of wel tx to treasury %s:%d", txID, logIndex)
+		return err
+	}
+	return nil
+}
+
+func MkWelTx2TreasuryDao(db *sqlx.DB) *welTx2TreasuryDAO {
+	return &welTx2TreasuryDAO{
+		db: db,
+	}
+}
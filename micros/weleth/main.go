@@ -8,16 +8,22 @@ import (
 	"bridge/micros/weleth/service"
 	welethService "bridge/micros/weleth/temporal"
 	manager "bridge/service-managers"
+	"bridge/service-managers/daemon"
+	"bridge/service-managers/leader"
 	ethListener "bridge/service-managers/listener/eth"
 	welListener "bridge/service-managers/listener/wel"
 	"bridge/service-managers/logger"
 	"context"
+	"flag"
+	"os/signal"
 	"sync"
+	"syscall"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
 	_ "github.com/lib/pq"
 
-	"github.com/ethereum/go-ethereum/ethclient"
 	//"https://github.com/rs/zerolog/log"
 )
 
@@ -31,14 +37,6 @@ func main() {
 	logger := logger.Get()
 	//logger.Info().Msgf("Initialize system with config: %+v ", *config.Get())
 
-	// load cross chain tokens map
-	tkMap := config.Get().TokensMap
-	for _, pair := range tkMap {
-		model.WelTokenFromEth[pair.Eth] = pair.Wel
-		model.EthTokenFromWel[pair.Wel] = pair.Eth
-	}
-	logger.Info().Msgf("[main] Eth->Wel: %+v", model.WelTokenFromEth)
-	logger.Info().Msgf("[main] Wel->Eth: %+v", model.EthTokenFromWel)
 	//	ctx = logger.WithContext(ctx)
 	//	zerolog.Ctx(ctx).Info().Msgf("getting log from context: ", ctx)
 	// loading config, secret, key
@@ -75,12 +73,33 @@ func main() {
 	// create parent context
 	daos := dao.MkDAOs(db)
 
+	// cross chain tokens registry, seeded from the tokens map
+	if err := daos.BridgeTokenDAO.SeedBridgeTokens(config.Get().TokensMap.BridgeTokens()); err != nil {
+		logger.Err(err).Msg("[main] Unable to seed bridge tokens")
+		panic(err)
+	}
+	if err := daos.Tokens.Reload(); err != nil {
+		logger.Err(err).Msg("[main] Unable to load bridge tokens")
+		panic(err)
+	}
+	for _, t := range daos.Tokens.Tokens() {
+		logger.Info().Msgf("[main] Bridge token %s <-> %s", t.EthAddr, t.WelAddr)
+	}
+
+	if flag.Arg(0) == "backfill" {
+		if err := backfill(flag.Args()[1:], daos, tempCli); err != nil {
+			logger.Err(err).Msg("[main] Backfill failed")
+		}
+		return
+	}
+
 	ctx := context.Background()
 
 	wg := sync.WaitGroup{}
 
 	// ETH chain stuff: contract address, prkey, contract event watcher...
-	ethClient, err := ethclient.Dial(config.Get().EtherumConf.BlockchainRPC)
+	ethConf := config.Get().EtherumConf
+	ethClient, err := ethListener.NewMultiClient(append([]string{ethConf.BlockchainRPC}, ethConf.FallbackRPCs...), logger)
 	if err != nil {
 		logger.Err(err).Msg("[main] Etherum client initialization failed")
 		panic(err)
@@ -90,25 +109,47 @@ func main() {
 	ethListen := ethListener.NewEthListener(ethSysDAO, ethClient, config.Get().EtherumConf.BlockTime, config.Get().EtherumConf.BlockOffSet, logger)
 
 	ethEvtConsumer := service.NewEthConsumer(config.Get().EthContractAddress[0], config.Get().EthMultisenderAddress, tempCli, daos)
-	ethListen.RegisterConsumer(ethEvtConsumer)
+	ethListen.RegisterConsumer("bridge", 0, ethEvtConsumer)
+	ethListen.ScanCursors = daos.ScanCursorDAO
+	// matched events stay in the outbox until consumed
+	ethListen.Queue = service.MkEthEventQueue(daos)
+	// rounds follow pushed heads when a ws endpoint is set, polling picks up when it's down
+	if ethConf.WebsocketRPC != "" {
+		wsClient, err := ethclient.Dial(ethConf.WebsocketRPC)
+		if err != nil {
+			logger.Err(err).Msg("[main] Etherum ws client initialization failed, polling instead")
+		} else {
+			defer wsClient.Close()
+			ethListen.Subscriber = wsClient
+		}
+	}
 	ethTreasuryMonitor := service.MkTreasuryMonitor(config.Get().EthTreasuryAddress, daos)
 	ethListen.RegisterTxMonitor(ethTreasuryMonitor)
+	watchTokens := func(tokens []model.BridgeToken) {
+		addrs := make([]common.Address, 0, len(tokens))
+		for _, t := range tokens {
+			addrs = append(addrs, common.HexToAddress(t.EthAddr))
+		}
+		ethListen.SetWatchedTokens(addrs...)
+	}
+	watchTokens(daos.Tokens.Tokens())
+	daos.Tokens.OnReload(watchTokens)
 
+	// replicas compete for each chain's listener, only the lease holder scans it
+	leaseCtx, stopLeases := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGTERM)
+	defer stopLeases()
+	instanceID := leader.InstanceID()
 	wg.Add(1)
 	go func() {
-		ethListen.Start(ctx)
+		leader.MkElector(daos.ListenerLeaseDAO, "eth-listener", instanceID, config.Get().ListenerLeaseTTL, logger).Run(leaseCtx, ethListen.Start)
 		wg.Done()
 	}()
 
 	// WEL chain stuff
-	var welClient = &welListener.ExtNodeClient{}
-	for _, addr := range config.Get().WelupsConf.Nodes {
-		welClient = welListener.NewExtNodeClientWithTimeout(addr, time.Duration(config.Get().WelupsConf.ClientTimeout)*time.Minute)
-		if err := welClient.Start(); err == nil {
-			break
-		} else {
-			logger.Err(err).Msg("Can't start wel listener")
-		}
+	welClient, err := welListener.NewNodePool(config.Get().WelupsConf.Nodes, time.Duration(config.Get().WelupsConf.ClientTimeout)*time.Minute, logger)
+	if err != nil {
+		logger.Err(err).Msg("Can't start wel listener")
+		panic(err)
 	}
 	defer welClient.Stop()
 
@@ -117,17 +158,61 @@ func main() {
 	welListen := welListener.NewWelListener(welSysDAO, welTransHandler, config.Get().WelupsConf.BlockTime, config.Get().WelupsConf.BlockOffSet, logger)
 
 	welEvtConsumer := service.NewWelConsumer(config.Get().WelImportAddress, config.Get().WelContractAddress[0], tempCli, daos)
-	welListen.RegisterConsumer(welEvtConsumer)
+	welListen.RegisterConsumer("bridge", 0, welEvtConsumer)
+	welListen.ScanCursors = daos.ScanCursorDAO
+	welListen.Queue = service.MkWelEventQueue(daos)
+	if welTreasury := config.Get().WelTreasuryAddress; welTreasury != "" {
+		welListen.RegisterTxMonitor(service.MkWelTreasuryMonitor(welTreasury, daos))
+		watchWelTokens := func(tokens []model.BridgeToken) {
+			addrs := make([]string, 0, len(tokens))
+			for _, t := range tokens {
+				addrs = append(addrs, t.WelAddr)
+			}
+			welListen.SetWatchedTokens(addrs...)
+		}
+		watchWelTokens(daos.Tokens.Tokens())
+		daos.Tokens.OnReload(watchWelTokens)
+	}
+
+	wg.Add(1)
+	go func() {
+		leader.MkElector(daos.ListenerLeaseDAO, "wel-listener", instanceID, config.Get().ListenerLeaseTTL, logger).Run(leaseCtx, welListen.Start)
+		wg.Done()
+	}()
+
+	// deposits confirmation
+	confirmationTracker := service.MkConfirmationTracker(
+		ethClient,
+		welClient,
+		service.MkConfirmationDepths(config.Get().EtherumConf.Confirmations, config.Get().WelupsConf.Confirmations, nil),
+		tempCli,
+		daos)
+	confirmationTracker.Depths.Tokens = daos.Tokens
+	wg.Add(1)
+	go func() {
+		daemon.BootstrapDaemons(ctx, confirmationTracker.Recheck)
+		wg.Done()
+	}()
+
+	// unmatched deposits to treasury expiry
+	tx2TreasuryExpiry := service.MkTx2TreasuryExpiry(config.Get().EthCashinExpiry, daos)
+	wg.Add(1)
+	go func() {
+		daemon.BootstrapDaemons(ctx, tx2TreasuryExpiry.Expire)
+		wg.Done()
+	}()
 
+	// rpc endpoints health checking, bridge tokens refreshing
 	wg.Add(1)
 	go func() {
-		welListen.Start(ctx)
+		daemon.BootstrapDaemons(ctx, ethClient.HealthCheck, welClient.HealthCheck, daos.Tokens.Refresh)
 		wg.Done()
 	}()
 
 	//// Temporal workers
 
 	welethMS := welethService.MkWelethBridgeService(tempCli, daos)
+	welethMS.Reconciler = service.MkReconciler(ethClient, welClient, ethEvtConsumer, welEvtConsumer, daos)
 	if err := welethMS.StartService(); err != nil {
 		logger.Err(err).Msgf("Unable to start temporal worker")
 		panic(err)
@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+-- amounts delivered on the destination chain, in its token units, and the dust the
+-- conversion left on the source chain
+ALTER TABLE wel_cashin_eth_trans
+  ADD COLUMN IF NOT EXISTS dest_amount varchar(80) DEFAULT '',
+  ADD COLUMN IF NOT EXISTS dust varchar(80) DEFAULT '';
+
+ALTER TABLE eth_cashout_wel_trans
+  ADD COLUMN IF NOT EXISTS dest_amount varchar(80) DEFAULT '',
+  ADD COLUMN IF NOT EXISTS dust varchar(80) DEFAULT '';
+
+ALTER TABLE eth_cashin_wel_trans
+  ADD COLUMN IF NOT EXISTS dest_amount varchar(80) DEFAULT '',
+  ADD COLUMN IF NOT EXISTS dust varchar(80) DEFAULT '';
+
+ALTER TABLE wel_cashout_eth_trans
+  ADD COLUMN IF NOT EXISTS dest_amount varchar(80) DEFAULT '',
+  ADD COLUMN IF NOT EXISTS dust varchar(80) DEFAULT '';
+
+-- records made before the conversion assumed both tokens shared their decimals
+UPDATE wel_cashin_eth_trans SET dest_amount = amount, dust = '0' WHERE dest_amount = '';
+UPDATE eth_cashout_wel_trans SET dest_amount = amount, dust = '0' WHERE dest_amount = '';
+UPDATE eth_cashin_wel_trans SET dest_amount = amount, dust = '0' WHERE dest_amount = '';
+UPDATE wel_cashout_eth_trans SET dest_amount = amount, dust = '0' WHERE dest_amount = '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+ALTER TABLE wel_cashout_eth_trans
+  DROP COLUMN IF EXISTS dest_amount,
+  DROP COLUMN IF EXISTS dust;
+
+ALTER TABLE eth_cashin_wel_trans
+  DROP COLUMN IF EXISTS dest_amount,
+  DROP COLUMN IF EXISTS dust;
+
+ALTER TABLE eth_cashout_wel_trans
+  DROP COLUMN IF EXISTS dest_amount,
+  DROP COLUMN IF EXISTS dust;
+
+ALTER TABLE wel_cashin_eth_trans
+  DROP COLUMN IF EXISTS dest_amount,
+  DROP COLUMN IF EXISTS dust;
+-- +goose StatementEnd
@@ -0,0 +1,71 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+-- contract events handled by weleth, revision is what a re-delivered event must differ by
+-- to be handled again: the block hash on eth, the tx status on wel
+CREATE TABLE IF NOT EXISTS processed_events (
+  chain varchar(10) NOT NULL,
+  tx_hash varchar(100) NOT NULL,
+  log_index bigint NOT NULL,
+  event varchar(40) NOT NULL,
+  revision varchar(100) DEFAULT '',
+  processed_at timestamp DEFAULT NOW(),
+
+  PRIMARY KEY (chain, tx_hash, log_index)
+);
+
+-- a transaction may hold several deposits or withdraws, bridge transfers are keyed by the
+-- position of their event in the transaction
+ALTER TABLE wel_cashin_eth_trans ADD COLUMN IF NOT EXISTS log_index bigint DEFAULT 0;
+ALTER TABLE eth_cashout_wel_trans ADD COLUMN IF NOT EXISTS log_index bigint DEFAULT 0;
+ALTER TABLE wel_cashout_eth_trans ADD COLUMN IF NOT EXISTS log_index bigint DEFAULT 0;
+
+ALTER TABLE wel_cashin_eth_trans DROP CONSTRAINT IF EXISTS wel_cashin_eth_trans_deposit_tx_hash_key;
+DROP INDEX IF EXISTS wel_cashin_eth_deposit_tx_index;
+ALTER TABLE wel_cashin_eth_trans ADD CONSTRAINT wel_cashin_eth_deposit_log_key UNIQUE (deposit_tx_hash, log_index);
+
+ALTER TABLE eth_cashout_wel_trans DROP CONSTRAINT IF EXISTS eth_cashout_wel_trans_deposit_tx_hash_key;
+DROP INDEX IF EXISTS eth_cashout_wel_deposit_tx_index;
+ALTER TABLE eth_cashout_wel_trans ADD CONSTRAINT eth_cashout_wel_deposit_log_key UNIQUE (deposit_tx_hash, log_index);
+
+ALTER TABLE wel_cashout_eth_trans DROP CONSTRAINT IF EXISTS wel_cashout_eth_trans_wel_withdraw_tx_hash_key;
+ALTER TABLE wel_cashout_eth_trans ADD CONSTRAINT wel_cashout_eth_withdraw_log_key UNIQUE (wel_withdraw_tx_hash, log_index);
+
+-- so are the transfers checked against the bridge limits
+ALTER TABLE bridge_volumes ADD COLUMN IF NOT EXISTS log_index bigint DEFAULT 0;
+ALTER TABLE held_transfers ADD COLUMN IF NOT EXISTS log_index bigint DEFAULT 0;
+
+ALTER TABLE bridge_volumes DROP CONSTRAINT IF EXISTS bridge_volumes_kind_ref_key;
+ALTER TABLE bridge_volumes ADD CONSTRAINT bridge_volumes_kind_ref_log_key UNIQUE (kind, ref, log_index);
+ALTER TABLE held_transfers DROP CONSTRAINT IF EXISTS held_transfers_kind_ref_key;
+ALTER TABLE held_transfers ADD CONSTRAINT held_transfers_kind_ref_log_key UNIQUE (kind, ref, log_index);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+ALTER TABLE held_transfers DROP CONSTRAINT IF EXISTS held_transfers_kind_ref_log_key;
+ALTER TABLE held_transfers ADD CONSTRAINT held_transfers_kind_ref_key UNIQUE (kind, ref);
+ALTER TABLE bridge_volumes DROP CONSTRAINT IF EXISTS bridge_volumes_kind_ref_log_key;
+ALTER TABLE bridge_volumes ADD CONSTRAINT bridge_volumes_kind_ref_key UNIQUE (kind, ref);
+ALTER TABLE held_transfers DROP COLUMN IF EXISTS log_index;
+ALTER TABLE bridge_volumes DROP COLUMN IF EXISTS log_index;
+
+ALTER TABLE wel_cashout_eth_trans DROP CONSTRAINT IF EXISTS wel_cashout_eth_withdraw_log_key;
+ALTER TABLE wel_cashout_eth_trans ADD CONSTRAINT wel_cashout_eth_trans_wel_withdraw_tx_hash_key UNIQUE (wel_withdraw_tx_hash);
+
+ALTER TABLE eth_cashout_wel_trans DROP CONSTRAINT IF EXISTS eth_cashout_wel_deposit_log_key;
+ALTER TABLE eth_cashout_wel_trans ADD CONSTRAINT eth_cashout_wel_trans_deposit_tx_hash_key UNIQUE (deposit_tx_hash);
+CREATE UNIQUE INDEX IF NOT EXISTS eth_cashout_wel_deposit_tx_index ON eth_cashout_wel_trans(deposit_tx_hash);
+
+ALTER TABLE wel_cashin_eth_trans DROP CONSTRAINT IF EXISTS wel_cashin_eth_deposit_log_key;
+ALTER TABLE wel_cashin_eth_trans ADD CONSTRAINT wel_cashin_eth_trans_deposit_tx_hash_key UNIQUE (deposit_tx_hash);
+CREATE UNIQUE INDEX IF NOT EXISTS wel_cashin_eth_deposit_tx_index ON wel_cashin_eth_trans(deposit_tx_hash);
+
+ALTER TABLE wel_cashout_eth_trans DROP COLUMN IF EXISTS log_index;
+ALTER TABLE eth_cashout_wel_trans DROP COLUMN IF EXISTS log_index;
+ALTER TABLE wel_cashin_eth_trans DROP COLUMN IF EXISTS log_index;
+
+DROP TABLE IF EXISTS processed_events;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- contract events queued by the listeners before moving past their block, consumed events
+-- are kept done so that re-scans don't queue them again
+CREATE TABLE IF NOT EXISTS event_outbox (
+  id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  chain varchar(10) NOT NULL,
+  tx_hash varchar(100) NOT NULL,
+  log_index bigint NOT NULL,
+  revision varchar(100) DEFAULT '',
+  payload text NOT NULL,
+  status varchar(20) DEFAULT 'pending',
+  attempts integer DEFAULT 0,
+  last_error text DEFAULT '',
+  next_attempt_at timestamp DEFAULT NOW(),
+  created_at timestamp DEFAULT NOW(),
+  updated_at timestamp DEFAULT NOW(),
+
+  UNIQUE (chain, tx_hash, log_index, revision),
+  CHECK (status IN ('pending', 'done', 'dead'))
+);
+
+CREATE INDEX IF NOT EXISTS event_outbox_due_index ON event_outbox(chain, status, next_attempt_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP INDEX IF EXISTS event_outbox_due_index;
+DROP TABLE event_outbox CASCADE;
+-- +goose StatementEnd
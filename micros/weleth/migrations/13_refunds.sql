@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- deposits to the treasury sent back to their sender, either never matched by a cashin
+-- request before expiring or whose cashin failed to be issued
+CREATE TABLE IF NOT EXISTS refunds (
+  id integer PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  tx_id varchar(100) NOT NULL REFERENCES tx_to_treasury(tx_id),
+  reason varchar(20) NOT NULL,
+  token_addr varchar(100),
+  to_address varchar(100),
+  amount varchar(40),
+  gas_fee varchar(40) DEFAULT '0',
+  refund_amount varchar(40) DEFAULT '0',
+  refund_tx_hash varchar(100) DEFAULT '',
+  status varchar(20) DEFAULT 'pending_approval',
+  note text DEFAULT '',
+  created_at timestamp DEFAULT NOW(),
+  updated_at timestamp DEFAULT NOW(),
+  refunded_at timestamp,
+
+  UNIQUE (tx_id),
+  CHECK (reason IN ('expired', 'cashin_failed')),
+  CHECK (status IN ('pending_approval', 'refunding', 'refunded', 'rejected', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS refunds_status_index ON refunds(status);
+CREATE INDEX IF NOT EXISTS tx_to_treasury_created_at_index ON tx_to_treasury(status, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP INDEX IF EXISTS tx_to_treasury_created_at_index;
+DROP INDEX IF EXISTS refunds_status_index;
+DROP TABLE refunds CASCADE;
+-- +goose StatementEnd
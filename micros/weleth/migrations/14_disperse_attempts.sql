@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- declined disperses re-submitted by the retry scheduler, tx_hash is filled in once the
+-- batch carrying the attempt is sent
+CREATE TABLE IF NOT EXISTS disperse_attempts (
+  id integer PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  cashout_id integer NOT NULL REFERENCES wel_cashout_eth_trans(id),
+  attempt integer NOT NULL,
+  tx_hash varchar(100) DEFAULT '',
+  created_at timestamp DEFAULT NOW(),
+
+  UNIQUE (cashout_id, attempt)
+);
+
+-- given up once out of attempts, left for admins to handle
+ALTER TABLE wel_cashout_eth_trans DROP CONSTRAINT IF EXISTS wel_cashout_eth_trans_disperse_status_check;
+ALTER TABLE wel_cashout_eth_trans ADD CONSTRAINT wel_cashout_eth_trans_disperse_status_check
+  CHECK (disperse_status IN ('unconfirmed', 'confirmed', 'retry', 'orphaned', 'failed'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+UPDATE wel_cashout_eth_trans SET disperse_status = 'retry' WHERE disperse_status = 'failed';
+ALTER TABLE wel_cashout_eth_trans DROP CONSTRAINT IF EXISTS wel_cashout_eth_trans_disperse_status_check;
+ALTER TABLE wel_cashout_eth_trans ADD CONSTRAINT wel_cashout_eth_trans_disperse_status_check
+  CHECK (disperse_status IN ('unconfirmed', 'confirmed', 'retry', 'orphaned'));
+
+DROP TABLE disperse_attempts CASCADE;
+-- +goose StatementEnd
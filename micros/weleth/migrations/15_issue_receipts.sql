@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- issue txs are verified on Welups: failures are recorded with their reason, retryable
+-- ones are issued again in another batch
+ALTER TABLE eth_cashin_wel_trans ADD COLUMN IF NOT EXISTS fail_reason text NOT NULL DEFAULT '';
+ALTER TABLE eth_cashin_wel_trans ADD COLUMN IF NOT EXISTS issue_attempts integer NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+ALTER TABLE eth_cashin_wel_trans DROP COLUMN IF EXISTS issue_attempts;
+ALTER TABLE eth_cashin_wel_trans DROP COLUMN IF EXISTS fail_reason;
+-- +goose StatementEnd
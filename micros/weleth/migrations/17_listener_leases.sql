@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+-- the replica holding a lease runs its listener, the others stand by until it expires
+CREATE TABLE IF NOT EXISTS listener_leases (
+  name varchar(64) PRIMARY KEY,
+  holder varchar(256) NOT NULL,
+  acquired_at timestamp NOT NULL DEFAULT NOW(),
+  renewed_at timestamp NOT NULL DEFAULT NOW(),
+  expires_at timestamp NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE listener_leases CASCADE;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+-- transfers to the Welups treasury, WEL or TRC20 tokens, waiting for a W2E cashout request.
+-- A tx may hold several TRC20 transfers, they're keyed by the position of their log, 0 for
+-- WEL transfers
+CREATE TABLE IF NOT EXISTS wel_tx_to_treasury (
+  tx_id varchar(100) NOT NULL,
+  log_index bigint NOT NULL DEFAULT 0,
+  from_address varchar(100),
+  treasury_address varchar(100),
+  token_address varchar(100),
+  amount varchar(40),
+  tx_fee varchar(40),
+  status varchar(20) DEFAULT 'pending_confirmation',
+  block_number bigint DEFAULT 0,
+  confirmations bigint DEFAULT 0,
+  created_at timestamp DEFAULT NOW(),
+
+  PRIMARY KEY (tx_id, log_index),
+  CHECK (status IN ('unconfirmed','isCashin', 'expired', 'pending_confirmation'))
+);
+
+CREATE INDEX IF NOT EXISTS wel_tx_to_treasury_status_index ON wel_tx_to_treasury(status);
+CREATE INDEX IF NOT EXISTS wel_tx_to_treasury_from_index ON wel_tx_to_treasury(from_address);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE wel_tx_to_treasury CASCADE;
+-- +goose StatementEnd
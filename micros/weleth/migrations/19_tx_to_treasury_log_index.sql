@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+-- a tx may hold several deposits to the treasury, ERC20 ones are keyed by the index of their
+-- Transfer log, the ether sent along by -1 as it has no log
+ALTER TABLE tx_to_treasury ADD COLUMN IF NOT EXISTS log_index bigint NOT NULL DEFAULT 0;
+ALTER TABLE eth_cashin_wel_trans ADD COLUMN IF NOT EXISTS log_index bigint NOT NULL DEFAULT 0;
+ALTER TABLE refunds ADD COLUMN IF NOT EXISTS log_index bigint NOT NULL DEFAULT 0;
+
+UPDATE tx_to_treasury SET log_index = -1 WHERE token_address = '0x0000000000000000000000000000000000000000';
+UPDATE eth_cashin_wel_trans c SET log_index = t.log_index FROM tx_to_treasury t WHERE c.eth_tx_hash = t.tx_id;
+UPDATE refunds r SET log_index = t.log_index FROM tx_to_treasury t WHERE r.tx_id = t.tx_id;
+
+ALTER TABLE eth_cashin_wel_trans DROP CONSTRAINT IF EXISTS eth_cashin_wel_trans_eth_tx_hash_fkey;
+ALTER TABLE refunds DROP CONSTRAINT IF EXISTS refunds_tx_id_fkey;
+ALTER TABLE refunds DROP CONSTRAINT IF EXISTS refunds_tx_id_key;
+ALTER TABLE tx_to_treasury DROP CONSTRAINT IF EXISTS tx_to_treasury_pkey;
+
+ALTER TABLE tx_to_treasury ADD CONSTRAINT tx_to_treasury_pkey PRIMARY KEY (tx_id, log_index);
+ALTER TABLE eth_cashin_wel_trans ADD CONSTRAINT eth_cashin_wel_trans_deposit_fkey
+  FOREIGN KEY (eth_tx_hash, log_index) REFERENCES tx_to_treasury(tx_id, log_index);
+ALTER TABLE refunds ADD CONSTRAINT refunds_deposit_fkey
+  FOREIGN KEY (tx_id, log_index) REFERENCES tx_to_treasury(tx_id, log_index);
+ALTER TABLE refunds ADD CONSTRAINT refunds_deposit_key UNIQUE (tx_id, log_index);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+ALTER TABLE refunds DROP CONSTRAINT IF EXISTS refunds_deposit_key;
+ALTER TABLE refunds DROP CONSTRAINT IF EXISTS refunds_deposit_fkey;
+ALTER TABLE eth_cashin_wel_trans DROP CONSTRAINT IF EXISTS eth_cashin_wel_trans_deposit_fkey;
+ALTER TABLE tx_to_treasury DROP CONSTRAINT IF EXISTS tx_to_treasury_pkey;
+
+ALTER TABLE tx_to_treasury ADD CONSTRAINT tx_to_treasury_pkey PRIMARY KEY (tx_id);
+ALTER TABLE refunds ADD CONSTRAINT refunds_tx_id_key UNIQUE (tx_id);
+ALTER TABLE refunds ADD CONSTRAINT refunds_tx_id_fkey FOREIGN KEY (tx_id) REFERENCES tx_to_treasury(tx_id);
+ALTER TABLE eth_cashin_wel_trans ADD CONSTRAINT eth_cashin_wel_trans_eth_tx_hash_fkey
+  FOREIGN KEY (eth_tx_hash) REFERENCES tx_to_treasury(tx_id);
+
+ALTER TABLE refunds DROP COLUMN IF EXISTS log_index;
+ALTER TABLE eth_cashin_wel_trans DROP COLUMN IF EXISTS log_index;
+ALTER TABLE tx_to_treasury DROP COLUMN IF EXISTS log_index;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- a refund is recorded as sent before its tx is, and only refunded once the tx is mined
+ALTER TABLE refunds DROP CONSTRAINT IF EXISTS refunds_status_check;
+ALTER TABLE refunds ADD CONSTRAINT refunds_status_check
+  CHECK (status IN ('pending_approval', 'refunding', 'sent', 'refunded', 'rejected', 'failed'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+UPDATE refunds SET status = 'refunded' WHERE status = 'sent';
+ALTER TABLE refunds DROP CONSTRAINT IF EXISTS refunds_status_check;
+ALTER TABLE refunds ADD CONSTRAINT refunds_status_check
+  CHECK (status IN ('pending_approval', 'refunding', 'refunded', 'rejected', 'failed'));
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+ALTER TABLE eth_cashout_wel_trans
+  ADD COLUMN IF NOT EXISTS deposit_block_number bigint DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS deposit_block_hash varchar(100) DEFAULT '';
+
+ALTER TABLE wel_cashout_eth_trans
+  ADD COLUMN IF NOT EXISTS disperse_block_number bigint DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS disperse_block_hash varchar(100) DEFAULT '';
+
+ALTER TABLE wel_cashout_eth_trans DROP CONSTRAINT IF EXISTS wel_cashout_eth_trans_disperse_status_check;
+ALTER TABLE wel_cashout_eth_trans ADD CONSTRAINT wel_cashout_eth_trans_disperse_status_check
+  CHECK (disperse_status IN ('unconfirmed', 'confirmed', 'retry', 'orphaned'));
+
+CREATE INDEX IF NOT EXISTS eth_cashout_wel_deposit_block_hash_index ON eth_cashout_wel_trans(deposit_block_hash);
+CREATE INDEX IF NOT EXISTS wel_cashout_eth_disperse_block_hash_index ON wel_cashout_eth_trans(disperse_block_hash);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP INDEX IF EXISTS eth_cashout_wel_deposit_block_hash_index;
+DROP INDEX IF EXISTS wel_cashout_eth_disperse_block_hash_index;
+
+UPDATE wel_cashout_eth_trans SET disperse_status = 'retry' WHERE disperse_status = 'orphaned';
+ALTER TABLE wel_cashout_eth_trans DROP CONSTRAINT IF EXISTS wel_cashout_eth_trans_disperse_status_check;
+ALTER TABLE wel_cashout_eth_trans ADD CONSTRAINT wel_cashout_eth_trans_disperse_status_check
+  CHECK (disperse_status IN ('unconfirmed', 'confirmed', 'retry'));
+
+ALTER TABLE wel_cashout_eth_trans
+  DROP COLUMN IF EXISTS disperse_block_number,
+  DROP COLUMN IF EXISTS disperse_block_hash;
+
+ALTER TABLE eth_cashout_wel_trans
+  DROP COLUMN IF EXISTS deposit_block_number,
+  DROP COLUMN IF EXISTS deposit_block_hash;
+-- +goose StatementEnd
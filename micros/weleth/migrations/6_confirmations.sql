@@ -0,0 +1,66 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+ALTER TABLE wel_cashin_eth_trans
+  ADD COLUMN IF NOT EXISTS deposit_block_number bigint DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS confirmations bigint DEFAULT 0;
+
+ALTER TABLE eth_cashout_wel_trans
+  ADD COLUMN IF NOT EXISTS confirmations bigint DEFAULT 0;
+
+ALTER TABLE wel_cashout_eth_trans
+  ADD COLUMN IF NOT EXISTS withdraw_block_number bigint DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS confirmations bigint DEFAULT 0;
+
+ALTER TABLE wel_cashout_eth_trans DROP CONSTRAINT IF EXISTS wel_cashout_eth_trans_cashout_status_check;
+ALTER TABLE wel_cashout_eth_trans ADD CONSTRAINT wel_cashout_eth_trans_cashout_status_check
+  CHECK (cashout_status IN ('unconfirmed', 'confirmed', 'pending_confirmation'));
+
+ALTER TABLE tx_to_treasury
+  ADD COLUMN IF NOT EXISTS block_number bigint DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS confirmations bigint DEFAULT 0;
+
+ALTER TABLE tx_to_treasury DROP CONSTRAINT IF EXISTS tx_to_treasury_status_check;
+ALTER TABLE tx_to_treasury ADD CONSTRAINT tx_to_treasury_status_check
+  CHECK (status IN ('unconfirmed','isCashin', 'expired', 'pending_confirmation'));
+
+CREATE INDEX IF NOT EXISTS wel_cashin_eth_deposit_status_index ON wel_cashin_eth_trans(deposit_status);
+CREATE INDEX IF NOT EXISTS eth_cashout_wel_deposit_status_index ON eth_cashout_wel_trans(deposit_status);
+CREATE INDEX IF NOT EXISTS wel_cashout_eth_cashout_status_index ON wel_cashout_eth_trans(cashout_status);
+CREATE INDEX IF NOT EXISTS tx_to_treasury_status_index ON tx_to_treasury(status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP INDEX IF EXISTS wel_cashin_eth_deposit_status_index;
+DROP INDEX IF EXISTS eth_cashout_wel_deposit_status_index;
+DROP INDEX IF EXISTS wel_cashout_eth_cashout_status_index;
+DROP INDEX IF EXISTS tx_to_treasury_status_index;
+
+UPDATE tx_to_treasury SET status = 'unconfirmed' WHERE status = 'pending_confirmation';
+ALTER TABLE tx_to_treasury DROP CONSTRAINT IF EXISTS tx_to_treasury_status_check;
+ALTER TABLE tx_to_treasury ADD CONSTRAINT tx_to_treasury_status_check
+  CHECK (status IN ('unconfirmed','isCashin', 'expired'));
+
+ALTER TABLE tx_to_treasury
+  DROP COLUMN IF EXISTS block_number,
+  DROP COLUMN IF EXISTS confirmations;
+
+UPDATE wel_cashout_eth_trans SET cashout_status = 'unconfirmed' WHERE cashout_status = 'pending_confirmation';
+ALTER TABLE wel_cashout_eth_trans DROP CONSTRAINT IF EXISTS wel_cashout_eth_trans_cashout_status_check;
+ALTER TABLE wel_cashout_eth_trans ADD CONSTRAINT wel_cashout_eth_trans_cashout_status_check
+  CHECK (cashout_status IN ('unconfirmed', 'confirmed'));
+
+ALTER TABLE wel_cashout_eth_trans
+  DROP COLUMN IF EXISTS withdraw_block_number,
+  DROP COLUMN IF EXISTS confirmations;
+
+ALTER TABLE eth_cashout_wel_trans
+  DROP COLUMN IF EXISTS confirmations;
+
+ALTER TABLE wel_cashin_eth_trans
+  DROP COLUMN IF EXISTS deposit_block_number,
+  DROP COLUMN IF EXISTS confirmations;
+-- +goose StatementEnd
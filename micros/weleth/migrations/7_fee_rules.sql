@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- one row per tier, the tier applying to a total is the one with the greatest
+-- min_amount not above it
+CREATE TABLE IF NOT EXISTS fee_rules (
+  id integer PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  token_addr varchar(100),
+  direction varchar(20),
+
+  min_amount varchar(40) DEFAULT '0',
+  flat_fee varchar(40) DEFAULT '0',
+  percent_bps integer DEFAULT 0,
+  min_fee varchar(40) DEFAULT '0',
+  max_fee varchar(40) DEFAULT '',
+
+  created_at timestamp DEFAULT NOW(),
+  updated_at timestamp DEFAULT NOW(),
+
+  UNIQUE (token_addr, direction, min_amount),
+  CHECK (direction IN ('eth2wel', 'wel2eth')),
+  CHECK (percent_bps BETWEEN 0 AND 10000)
+);
+
+CREATE INDEX IF NOT EXISTS fee_rules_token_direction_index ON fee_rules(token_addr, direction);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP INDEX IF EXISTS fee_rules_token_direction_index;
+DROP TABLE fee_rules CASCADE;
+-- +goose StatementEnd
@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- transfers let through the bridge limits, summed up for the daily caps
+CREATE TABLE IF NOT EXISTS bridge_volumes (
+  id integer PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  kind varchar(30),
+  ref varchar(100),
+  token_addr varchar(100),
+  address varchar(100),
+  amount numeric(78, 0) DEFAULT 0,
+  created_at timestamp DEFAULT NOW(),
+
+  UNIQUE (kind, ref)
+);
+
+CREATE INDEX IF NOT EXISTS bridge_volumes_token_index ON bridge_volumes(token_addr, created_at);
+CREATE INDEX IF NOT EXISTS bridge_volumes_address_index ON bridge_volumes(token_addr, address, created_at);
+
+-- transfers breaking the bridge limits, waiting for an admin to release them
+CREATE TABLE IF NOT EXISTS held_transfers (
+  id integer PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  kind varchar(30),
+  ref varchar(100),
+  token_addr varchar(100),
+  address varchar(100),
+  amount varchar(40),
+  reason varchar(100) DEFAULT '',
+  status varchar(20) DEFAULT 'held',
+  created_at timestamp DEFAULT NOW(),
+  released_at timestamp,
+
+  UNIQUE (kind, ref),
+  CHECK (kind IN ('w2e_cashin_claim', 'e2w_cashout_claim', 'e2w_cashin_issue', 'w2e_cashout_disperse')),
+  CHECK (status IN ('held', 'released'))
+);
+
+CREATE INDEX IF NOT EXISTS held_transfers_status_index ON held_transfers(status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP INDEX IF EXISTS held_transfers_status_index;
+DROP TABLE held_transfers CASCADE;
+DROP INDEX IF EXISTS bridge_volumes_address_index;
+DROP INDEX IF EXISTS bridge_volumes_token_index;
+DROP TABLE bridge_volumes CASCADE;
+-- +goose StatementEnd
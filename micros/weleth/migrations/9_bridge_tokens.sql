@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- token pairs listed on the bridge, seeded from tokens-map.json on first start
+CREATE TABLE IF NOT EXISTS bridge_tokens (
+  id integer PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  eth_addr varchar(100) UNIQUE,
+  wel_addr varchar(100) UNIQUE,
+  eth_name varchar(40) DEFAULT '',
+  wel_name varchar(40) DEFAULT '',
+  eth_decimals integer DEFAULT 18,
+  wel_decimals integer DEFAULT 18,
+
+  -- a disabled pair isn't bridged at all, a paused one has its transfers held for review
+  enabled boolean DEFAULT true,
+  paused boolean DEFAULT false,
+
+  -- limits of the transfers out of each chain, in the units of the token on that chain,
+  -- empty = not enforced
+  eth_min_amount varchar(40) DEFAULT '',
+  wel_min_amount varchar(40) DEFAULT '',
+  eth_max_transfer varchar(40) DEFAULT '',
+  wel_max_transfer varchar(40) DEFAULT '',
+  eth_address_daily_cap varchar(40) DEFAULT '',
+  wel_address_daily_cap varchar(40) DEFAULT '',
+  eth_daily_cap varchar(40) DEFAULT '',
+  wel_daily_cap varchar(40) DEFAULT '',
+
+  -- 0 = chain-wide ETH_CONFIRMATIONS/WEL_CONFIRMATIONS
+  eth_confirmations bigint DEFAULT 0,
+  wel_confirmations bigint DEFAULT 0,
+
+  created_at timestamp DEFAULT NOW(),
+  updated_at timestamp DEFAULT NOW(),
+
+  CHECK (eth_decimals BETWEEN 0 AND 77 AND wel_decimals BETWEEN 0 AND 77)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE bridge_tokens CASCADE;
+-- +goose StatementEnd
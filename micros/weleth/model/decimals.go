@@ -0,0 +1,68 @@
+package model
+
+import (
+	"math/big"
+)
+
+// ScaleAmount converts amount from units of fromDecimals to units of toDecimals. Scaling
+// down can't represent the lowest digits of amount, they're returned as dust, in source
+// units.
+func ScaleAmount(amount *big.Int, fromDecimals, toDecimals int) (scaled, dust *big.Int) {
+	if toDecimals >= fromDecimals {
+		factor := (&big.Int{}).Exp(big.NewInt(10), big.NewInt(int64(toDecimals-fromDecimals)), nil)
+		return (&big.Int{}).Mul(amount, factor), big.NewInt(0)
+	}
+	factor := (&big.Int{}).Exp(big.NewInt(10), big.NewInt(int64(fromDecimals-toDecimals)), nil)
+	scaled, dust = (&big.Int{}).QuoRem(amount, factor, &big.Int{})
+	return scaled, dust
+}
+
+// NormalizedAmount is an amount crossing the bridge: Amount is taken on the source chain,
+// in its token units, DestAmount is delivered on the destination chain, in its token units,
+// and Dust is what's left of the original amount on the source chain.
+type NormalizedAmount struct {
+	Amount     string
+	DestAmount string
+	Dust       string
+}
+
+// Normalize converts amount of the pair's token on the source chain of direction into
+// units of the token on the destination chain
+func (t *BridgeToken) Normalize(direction, amount string) (NormalizedAmount, error) {
+	from, to := t.EthDecimals, t.WelDecimals
+	if direction == FeeWelToEth {
+		from, to = t.WelDecimals, t.EthDecimals
+	}
+	return normalize(amount, from, to)
+}
+
+// SameDecimals normalizes amount between tokens of the same decimals, for tokens missing
+// from the registry
+func SameDecimals(amount string) (NormalizedAmount, error) {
+	return normalize(amount, 0, 0)
+}
+
+func normalize(amount string, from, to int) (NormalizedAmount, error) {
+	a, ok := parseAmount(amount, -1)
+	if !ok || a.Sign() < 0 {
+		return NormalizedAmount{}, ErrInvalidAmount
+	}
+	scaled, dust := ScaleAmount(a, from, to)
+	return NormalizedAmount{
+		Amount:     (&big.Int{}).Sub(a, dust).String(),
+		DestAmount: scaled.String(),
+		Dust:       dust.String(),
+	}, nil
+}
+
+// Settle applies the normalization of the quote's amount, the dust is charged as
+// commission fee
+func (q FeeQuote) Settle(n NormalizedAmount) FeeQuote {
+	fee, _ := parseAmount(q.CommissionFee, 0)
+	dust, _ := parseAmount(n.Dust, 0)
+	q.Amount = n.Amount
+	q.CommissionFee = fee.Add(fee, dust).String()
+	q.DestAmount = n.DestAmount
+	q.Dust = n.Dust
+	return q
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestScaleAmount(t *testing.T) {
+	for _, c := range []struct {
+		amount   string
+		from, to int
+		scaled   string
+		dust     string
+	}{
+		{"1000000", 6, 18, "1000000000000000000", "0"},
+		{"1234567890123456789", 18, 6, "1234567", "890123456789"},
+		{"999999999999", 18, 6, "0", "999999999999"},
+		{"42", 8, 8, "42", "0"},
+	} {
+		amount, _ := (&big.Int{}).SetString(c.amount, 10)
+		scaled, dust := ScaleAmount(amount, c.from, c.to)
+		if scaled.String() != c.scaled || dust.String() != c.dust {
+			t.Errorf("ScaleAmount(%s, %d, %d) = %s, %s, expected %s, %s", c.amount, c.from, c.to, scaled, dust, c.scaled, c.dust)
+		}
+	}
+}
+
+func TestNormalizeAndSettle(t *testing.T) {
+	token := &BridgeToken{EthDecimals: 18, WelDecimals: 6}
+
+	n, err := token.Normalize(FeeEthToWel, "2500000000000000123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Amount != "2500000000000000000" || n.DestAmount != "2500000" || n.Dust != "123" {
+		t.Errorf("unexpected eth to wel normalization %+v", n)
+	}
+
+	n, err = token.Normalize(FeeWelToEth, "2500000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Amount != "2500000" || n.DestAmount != "2500000000000000000" || n.Dust != "0" {
+		t.Errorf("unexpected wel to eth normalization %+v", n)
+	}
+
+	if _, err := token.Normalize(FeeEthToWel, "-1"); err != ErrInvalidAmount {
+		t.Errorf("expected ErrInvalidAmount, got %v", err)
+	}
+
+	quote := FeeQuote{Total: "3000000000000000123", Amount: "2500000000000000123", CommissionFee: "500000000000000000"}
+	n, _ = token.Normalize(FeeEthToWel, quote.Amount)
+	quote = quote.Settle(n)
+	if quote.Amount != "2500000000000000000" || quote.CommissionFee != "500000000000000123" || quote.DestAmount != "2500000" {
+		t.Errorf("unexpected settled quote %+v", quote)
+	}
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// ProcessedEvent is a contract event handled by weleth, keyed by chain, tx hash and log
+// index. An event delivered again with another Revision, the block hash on eth or the tx
+// status on wel, is handled again.
+type ProcessedEvent struct {
+	Chain    string `json:"chain" db:"chain"`
+	TxHash   string `json:"tx_hash" db:"tx_hash"`
+	LogIndex int64  `json:"log_index" db:"log_index"`
+	Event    string `json:"event" db:"event"`
+	Revision string `json:"revision" db:"revision"`
+
+	ProcessedAt time.Time `json:"processed_at" db:"processed_at"`
+}
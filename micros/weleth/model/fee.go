@@ -0,0 +1,160 @@
+package model
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+)
+
+const (
+	// fee direction, rules are keyed by the token on the source chain
+	FeeEthToWel = "eth2wel" // E2W cashin, keyed by eth token
+	FeeWelToEth = "wel2eth" // W2E cashout, keyed by wel token
+
+	FeeBpsDenominator = 10000
+)
+
+var (
+	ErrFeeRuleNotFound = fmt.Errorf("Fee rule not found")
+	ErrInvalidFeeRule  = fmt.Errorf("Invalid fee rule")
+	ErrInvalidAmount   = fmt.Errorf("Invalid amount")
+)
+
+// FeeRule is one tier of a token's fee schedule in a direction: a total of at least
+// MinAmount is charged FlatFee + PercentBps/10000 of the total, clamped to
+// [MinFee, MaxFee]. An empty MaxFee leaves the fee uncapped.
+type FeeRule struct {
+	ID        int64  `json:"id" db:"id"`
+	TokenAddr string `json:"token_addr" db:"token_addr"`
+	Direction string `json:"direction" db:"direction"`
+
+	MinAmount  string `json:"min_amount" db:"min_amount"`
+	FlatFee    string `json:"flat_fee" db:"flat_fee"`
+	PercentBps int64  `json:"percent_bps" db:"percent_bps"`
+	MinFee     string `json:"min_fee" db:"min_fee"`
+	MaxFee     string `json:"max_fee" db:"max_fee"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// FeeQuote is what a fee schedule charges on a total, Amount = Total - CommissionFee.
+// Once settled, DestAmount is Amount in destination token units and the rounding Dust is
+// part of CommissionFee.
+type FeeQuote struct {
+	Total         string `json:"total"`
+	Amount        string `json:"amount"`
+	CommissionFee string `json:"commission_fee"`
+	DestAmount    string `json:"dest_amount"`
+	Dust          string `json:"dust"`
+}
+
+func parseAmount(s string, def int64) (*big.Int, bool) {
+	if s == "" {
+		return big.NewInt(def), true
+	}
+	v, ok := (&big.Int{}).SetString(s, 10)
+	if !ok || v.Sign() < 0 {
+		return nil, false
+	}
+	return v, true
+}
+
+// Validate checks the rule's amounts and fills the defaults in
+func (r *FeeRule) Validate() error {
+	if r.TokenAddr == "" || (r.Direction != FeeEthToWel && r.Direction != FeeWelToEth) {
+		return ErrInvalidFeeRule
+	}
+	if r.PercentBps < 0 || r.PercentBps > FeeBpsDenominator {
+		return ErrInvalidFeeRule
+	}
+	minAmount, ok1 := parseAmount(r.MinAmount, 0)
+	flat, ok2 := parseAmount(r.FlatFee, 0)
+	minFee, ok3 := parseAmount(r.MinFee, 0)
+	if !ok1 || !ok2 || !ok3 {
+		return ErrInvalidFeeRule
+	}
+	if r.MaxFee != "" {
+		maxFee, ok := parseAmount(r.MaxFee, 0)
+		if !ok || maxFee.Cmp(minFee) < 0 {
+			return ErrInvalidFeeRule
+		}
+		r.MaxFee = maxFee.String()
+	}
+	r.MinAmount, r.FlatFee, r.MinFee = minAmount.String(), flat.String(), minFee.String()
+	return nil
+}
+
+// FeeSchedule holds the tiers of one token in one direction
+type FeeSchedule []FeeRule
+
+// tier returns the rule with the greatest MinAmount not above total, nil if none applies
+func (s FeeSchedule) tier(total *big.Int) *FeeRule {
+	rules := make(FeeSchedule, len(s))
+	copy(rules, s)
+	sort.SliceStable(rules, func(i, j int) bool {
+		a, _ := parseAmount(rules[i].MinAmount, 0)
+		b, _ := parseAmount(rules[j].MinAmount, 0)
+		return a.Cmp(b) > 0
+	})
+	for i := range rules {
+		minAmount, ok := parseAmount(rules[i].MinAmount, 0)
+		if ok && minAmount.Cmp(total) <= 0 {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// Fee computes the commission charged on total, it never exceeds total
+func (s FeeSchedule) Fee(total *big.Int) (*big.Int, error) {
+	fee := big.NewInt(0)
+	r := s.tier(total)
+	if r == nil {
+		return fee, nil
+	}
+
+	flat, ok1 := parseAmount(r.FlatFee, 0)
+	minFee, ok2 := parseAmount(r.MinFee, 0)
+	if !ok1 || !ok2 {
+		return nil, ErrInvalidFeeRule
+	}
+	pct := (&big.Int{}).Mul(total, big.NewInt(r.PercentBps))
+	pct.Quo(pct, big.NewInt(FeeBpsDenominator))
+	fee.Add(flat, pct)
+
+	if fee.Cmp(minFee) < 0 {
+		fee.Set(minFee)
+	}
+	if r.MaxFee != "" {
+		maxFee, ok := parseAmount(r.MaxFee, 0)
+		if !ok {
+			return nil, ErrInvalidFeeRule
+		}
+		if fee.Cmp(maxFee) > 0 {
+			fee.Set(maxFee)
+		}
+	}
+	if fee.Cmp(total) > 0 {
+		fee.Set(total)
+	}
+	return fee, nil
+}
+
+// Quote splits total into the amount delivered and the commission fee
+func (s FeeSchedule) Quote(total string) (FeeQuote, error) {
+	t, ok := parseAmount(total, -1)
+	if !ok || t.Sign() < 0 {
+		return FeeQuote{}, ErrInvalidAmount
+	}
+	fee, err := s.Fee(t)
+	if err != nil {
+		return FeeQuote{}, err
+	}
+	return FeeQuote{
+		Total:         t.String(),
+		Amount:        (&big.Int{}).Sub(t, fee).String(),
+		CommissionFee: fee.String(),
+	}, nil
+}
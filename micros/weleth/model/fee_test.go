@@ -0,0 +1,61 @@
+package model
+
+import "testing"
+
+func TestFeeScheduleQuote(t *testing.T) {
+	schedule := FeeSchedule{
+		// 1% with a floor of 5 under 1000
+		{MinAmount: "0", PercentBps: 100, MinFee: "5"},
+		// flat 2 + 0.5% capped at 20 from 1000 on
+		{MinAmount: "1000", FlatFee: "2", PercentBps: 50, MaxFee: "20"},
+	}
+
+	tests := []struct {
+		total, amount, fee string
+	}{
+		{"100", "95", "5"},
+		{"900", "891", "9"},
+		{"1000", "993", "7"},
+		{"100000", "99980", "20"},
+		{"3", "0", "3"},
+	}
+	for _, tt := range tests {
+		q, err := schedule.Quote(tt.total)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if q.Amount != tt.amount || q.CommissionFee != tt.fee {
+			t.Errorf("total %s: expected amount %s fee %s, got %+v", tt.total, tt.amount, tt.fee, q)
+		}
+	}
+
+	q, err := FeeSchedule(nil).Quote("42")
+	if err != nil || q.Amount != "42" || q.CommissionFee != "0" {
+		t.Errorf("empty schedule should charge nothing, got %+v %v", q, err)
+	}
+	if _, err := schedule.Quote("-1"); err != ErrInvalidAmount {
+		t.Errorf("expected ErrInvalidAmount, got %v", err)
+	}
+}
+
+func TestFeeRuleValidate(t *testing.T) {
+	r := FeeRule{TokenAddr: "0x0", Direction: FeeEthToWel, PercentBps: 30}
+	if err := r.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if r.MinAmount != "0" || r.FlatFee != "0" || r.MinFee != "0" || r.MaxFee != "" {
+		t.Errorf("defaults not filled in: %+v", r)
+	}
+
+	invalid := []FeeRule{
+		{TokenAddr: "0x0", Direction: "sideways"},
+		{TokenAddr: "0x0", Direction: FeeWelToEth, PercentBps: 10001},
+		{TokenAddr: "0x0", Direction: FeeWelToEth, FlatFee: "1.5"},
+		{TokenAddr: "0x0", Direction: FeeWelToEth, MinFee: "10", MaxFee: "5"},
+	}
+	for _, r := range invalid {
+		if err := r.Validate(); err != ErrInvalidFeeRule {
+			t.Errorf("%+v: expected ErrInvalidFeeRule, got %v", r, err)
+		}
+	}
+}
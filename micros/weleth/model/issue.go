@@ -0,0 +1,20 @@
+package model
+
+// issues failing for a retryable reason, e.g. running out of energy, are attempted again
+// in another batch until IssueMaxAttempts of them failed
+var IssueMaxAttempts = 3
+
+// IssueFailed records the failure of the cashin's issue tx, sending the cashin back to be
+// issued again when the failure is retryable and attempts are left. It tells whether the
+// cashin is to be re-queued, otherwise it's failed for good and gets refunded.
+func (t *EthCashinWelTrans) IssueFailed(reason string, retryable bool) bool {
+	t.FailReason = reason
+	t.IssueAttempts++
+	if retryable && t.IssueAttempts < IssueMaxAttempts {
+		t.Status = EthCashinWelUnconfirmed
+		t.WelIssueTxHash = ""
+		return true
+	}
+	t.Status = EthCashinWelFailed
+	return false
+}
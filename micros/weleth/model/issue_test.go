@@ -0,0 +1,24 @@
+package model
+
+import "testing"
+
+func TestIssueFailed(t *testing.T) {
+	tran := EthCashinWelTrans{WelIssueTxHash: "abc", Status: EthCashinWelUnconfirmed}
+
+	for i := 1; i < IssueMaxAttempts; i++ {
+		if !tran.IssueFailed("OUT_OF_ENERGY", true) {
+			t.Fatalf("retryable failure %d not re-queued", i)
+		}
+		if tran.Status != EthCashinWelUnconfirmed || tran.WelIssueTxHash != "" || tran.IssueAttempts != i {
+			t.Fatalf("re-queued cashin not reset: %+v", tran)
+		}
+	}
+	if tran.IssueFailed("OUT_OF_ENERGY", true) || tran.Status != EthCashinWelFailed {
+		t.Fatalf("cashin out of attempts re-queued: %+v", tran)
+	}
+
+	tran = EthCashinWelTrans{WelIssueTxHash: "abc"}
+	if tran.IssueFailed("REVERT", false) || tran.Status != EthCashinWelFailed || tran.FailReason != "REVERT" {
+		t.Fatalf("non retryable failure re-queued: %+v", tran)
+	}
+}
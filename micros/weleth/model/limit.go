@@ -0,0 +1,107 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+const (
+	// transfers checked against the bridge limits, the token and address checked are the
+	// ones on the source chain
+	TransferW2ECashinClaim     = "w2e_cashin_claim"
+	TransferE2WCashoutClaim    = "e2w_cashout_claim"
+	TransferE2WCashinIssue     = "e2w_cashin_issue"
+	TransferW2ECashoutDisperse = "w2e_cashout_disperse"
+
+	// held transfer status
+	HeldForReview = "held"
+	HeldReleased  = "released"
+)
+
+var (
+	ErrTransferHeld         = fmt.Errorf("Transfer held for review")
+	ErrHeldTransferNotFound = fmt.Errorf("Held transfer not found")
+	ErrNotHeld              = fmt.Errorf("Transfer not held for review")
+
+	ErrBelowMinTransfer = fmt.Errorf("Transfer below minimum amount")
+	ErrAboveMaxTransfer = fmt.Errorf("Transfer above maximum amount")
+	ErrAddressDailyCap  = fmt.Errorf("Address daily cap exceeded")
+	ErrTokenDailyCap    = fmt.Errorf("Token daily volume cap exceeded")
+)
+
+// BridgeLimits bounds the transfers of a token, in its own units. An empty limit is not
+// enforced. Daily caps apply over the last 24 hours.
+type BridgeLimits struct {
+	MinTransfer     string `json:"min_transfer,omitempty"`
+	MaxTransfer     string `json:"max_transfer,omitempty"`
+	AddressDailyCap string `json:"address_daily_cap,omitempty"`
+	DailyCap        string `json:"daily_cap,omitempty"`
+}
+
+func (l BridgeLimits) IsZero() bool {
+	return l == BridgeLimits{}
+}
+
+func (l BridgeLimits) HasDailyCaps() bool {
+	return l.AddressDailyCap != "" || l.DailyCap != ""
+}
+
+// exceeds tells whether value is above the limit, an empty or malformed limit isn't enforced
+func exceeds(value *big.Int, limit string) bool {
+	lim, ok := (&big.Int{}).SetString(limit, 10)
+	return ok && value.Cmp(lim) > 0
+}
+
+// Check returns which limit a transfer of amount breaks, given the volumes of its address
+// and of its token over the last 24 hours
+func (l BridgeLimits) Check(amount, addressVolume, tokenVolume *big.Int) error {
+	if min, ok := (&big.Int{}).SetString(l.MinTransfer, 10); ok && amount.Cmp(min) < 0 {
+		return ErrBelowMinTransfer
+	}
+	if exceeds(amount, l.MaxTransfer) {
+		return ErrAboveMaxTransfer
+	}
+	if exceeds((&big.Int{}).Add(addressVolume, amount), l.AddressDailyCap) {
+		return ErrAddressDailyCap
+	}
+	if exceeds((&big.Int{}).Add(tokenVolume, amount), l.DailyCap) {
+		return ErrTokenDailyCap
+	}
+	return nil
+}
+
+// IsLimitViolation tells whether err is one of the errors returned by BridgeLimits.Check,
+// or ErrTokenPaused
+func IsLimitViolation(err error) bool {
+	for _, v := range []error{ErrBelowMinTransfer, ErrAboveMaxTransfer, ErrAddressDailyCap, ErrTokenDailyCap, ErrTokenPaused} {
+		if errors.Is(err, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// BridgeTransfer is a transfer about to leave the bridge, Ref is the source chain tx hash
+// and LogIndex the position of the transfer's event in it, 0 for transfers keyed by tx alone
+type BridgeTransfer struct {
+	Kind      string `json:"kind" db:"kind"`
+	Ref       string `json:"ref" db:"ref"`
+	LogIndex  int64  `json:"log_index" db:"log_index"`
+	TokenAddr string `json:"token_addr" db:"token_addr"`
+	Address   string `json:"address" db:"address"`
+	Amount    string `json:"amount" db:"amount"`
+}
+
+type HeldTransfer struct {
+	ID int64 `json:"id" db:"id"`
+	BridgeTransfer
+
+	Reason string `json:"reason" db:"reason"`
+	Status string `json:"status" db:"status"`
+
+	CreatedAt  time.Time    `json:"created_at" db:"created_at"`
+	ReleasedAt sql.NullTime `json:"released_at" db:"released_at"`
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestBridgeLimitsCheck(t *testing.T) {
+	l := BridgeLimits{MinTransfer: "10", MaxTransfer: "1000", AddressDailyCap: "1500", DailyCap: "5000"}
+
+	tests := []struct {
+		amount, addressVolume, tokenVolume int64
+		expected                           error
+	}{
+		{100, 0, 0, nil},
+		{9, 0, 0, ErrBelowMinTransfer},
+		{1001, 0, 0, ErrAboveMaxTransfer},
+		{600, 1000, 1000, ErrAddressDailyCap},
+		{500, 1000, 4000, nil},
+		{500, 0, 4600, ErrTokenDailyCap},
+	}
+	for _, tt := range tests {
+		err := l.Check(big.NewInt(tt.amount), big.NewInt(tt.addressVolume), big.NewInt(tt.tokenVolume))
+		if err != tt.expected {
+			t.Errorf("%+v: expected %v, got %v", tt, tt.expected, err)
+		}
+		if tt.expected != nil && !IsLimitViolation(err) {
+			t.Errorf("%v should be a limit violation", err)
+		}
+	}
+
+	if err := (BridgeLimits{}).Check(big.NewInt(1e18), big.NewInt(1e18), big.NewInt(1e18)); err != nil {
+		t.Errorf("empty limits should not be enforced, got %v", err)
+	}
+	if IsLimitViolation(ErrTransferHeld) {
+		t.Errorf("ErrTransferHeld isn't a limit violation")
+	}
+}
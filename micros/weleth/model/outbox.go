@@ -0,0 +1,67 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// event outbox status
+	OutboxPending = "pending"
+	OutboxDone    = "done"
+	OutboxDead    = "dead"
+)
+
+var (
+	ErrDeadLetterNotFound = fmt.Errorf("Dead letter not found")
+
+	// failed events are retried after OutboxBaseBackoff, doubled on every attempt up to
+	// OutboxMaxBackoff, and dead-lettered after OutboxMaxAttempts
+	OutboxBaseBackoff = 5 * time.Second
+	OutboxMaxBackoff  = 30 * time.Minute
+	OutboxMaxAttempts = 12
+)
+
+// OutboxEvent is a contract event queued by a listener, Payload is the raw log or
+// transaction it was found in
+type OutboxEvent struct {
+	ID       int64  `json:"id" db:"id"`
+	Chain    string `json:"chain" db:"chain"`
+	TxHash   string `json:"tx_hash" db:"tx_hash"`
+	LogIndex int64  `json:"log_index" db:"log_index"`
+	Revision string `json:"revision" db:"revision"`
+	Payload  string `json:"payload" db:"payload"`
+
+	Status        string    `json:"status" db:"status"`
+	Attempts      int       `json:"attempts" db:"attempts"`
+	LastError     string    `json:"last_error" db:"last_error"`
+	NextAttemptAt time.Time `json:"next_attempt_at" db:"next_attempt_at"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Failed records a failed attempt, the event is retried with backoff or dead-lettered once
+// out of attempts
+func (e *OutboxEvent) Failed(err error, now time.Time) {
+	e.Attempts++
+	e.LastError = err.Error()
+	if e.Attempts >= OutboxMaxAttempts {
+		e.Status = OutboxDead
+		return
+	}
+	e.Status = OutboxPending
+	e.NextAttemptAt = now.Add(OutboxBackoff(e.Attempts))
+}
+
+// OutboxBackoff is the delay before retrying an event failed attempts times
+func OutboxBackoff(attempts int) time.Duration {
+	d := OutboxBaseBackoff
+	for i := 1; i < attempts && d < OutboxMaxBackoff; i++ {
+		d *= 2
+	}
+	if d > OutboxMaxBackoff {
+		d = OutboxMaxBackoff
+	}
+	return d
+}
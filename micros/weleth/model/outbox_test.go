@@ -0,0 +1,32 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestOutboxEventFailed(t *testing.T) {
+	now := time.Now()
+	e := OutboxEvent{Status: OutboxPending}
+
+	e.Failed(fmt.Errorf("db down"), now)
+	if e.Status != OutboxPending || e.Attempts != 1 || !e.NextAttemptAt.Equal(now.Add(OutboxBaseBackoff)) {
+		t.Errorf("unexpected first retry %+v", e)
+	}
+	e.Failed(fmt.Errorf("db down"), now)
+	if !e.NextAttemptAt.Equal(now.Add(2 * OutboxBaseBackoff)) {
+		t.Errorf("backoff should double, next attempt at %v", e.NextAttemptAt.Sub(now))
+	}
+
+	if d := OutboxBackoff(100); d != OutboxMaxBackoff {
+		t.Errorf("backoff should be capped, got %v", d)
+	}
+
+	for e.Status == OutboxPending {
+		e.Failed(fmt.Errorf("temporal unavailable"), now)
+	}
+	if e.Status != OutboxDead || e.Attempts != OutboxMaxAttempts || e.LastError != "temporal unavailable" {
+		t.Errorf("unexpected dead letter %+v", e)
+	}
+}
@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+const (
+	// reconciliation issue kinds
+	ReconcileMissing        = "missing"
+	ReconcileDuplicated     = "duplicated"
+	ReconcileAmountMismatch = "amount_mismatch"
+
+	ChainEth = "eth"
+	ChainWel = "wel"
+)
+
+// ReconcileIssue is a contract event that has no, several or a diverging record in the
+// bridge tables
+type ReconcileIssue struct {
+	Chain       string `json:"chain"`
+	BlockNumber int64  `json:"block_number"`
+	TxHash      string `json:"tx_hash"`
+	LogIndex    int64  `json:"log_index"`
+	Event       string `json:"event"`
+	Table       string `json:"table"`
+	Kind        string `json:"kind"`
+
+	// request id of claims, receiver of batched events
+	Key    string `json:"key,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type ReconcileChainReport struct {
+	Chain     string           `json:"chain"`
+	FromBlock int64            `json:"from_block"`
+	ToBlock   int64            `json:"to_block"`
+	Events    int              `json:"events"`
+	Issues    []ReconcileIssue `json:"issues"`
+}
+
+type ReconcileReport struct {
+	Eth         ReconcileChainReport `json:"eth"`
+	Wel         ReconcileChainReport `json:"wel"`
+	GeneratedAt time.Time            `json:"generated_at"`
+}
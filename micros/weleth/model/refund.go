@@ -0,0 +1,101 @@
+package model
+
+import (
+	"database/sql"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+const (
+	// refund reason
+	RefundExpired      = "expired"
+	RefundCashinFailed = "cashin_failed"
+
+	// refund status
+	RefundPendingApproval = "pending_approval"
+	RefundRefunding       = "refunding"
+	RefundSent            = "sent" // waiting for its tx to be mined
+	RefundRefunded        = "refunded"
+	RefundRejected        = "rejected"
+	RefundFailed          = "failed"
+)
+
+var (
+	ErrRefundNotFound = fmt.Errorf("Refund not found")
+	ErrRefundStatus   = fmt.Errorf("Refund not in a status allowing this")
+	ErrRefundBelowGas = fmt.Errorf("Refunded amount doesn't cover the gas fee")
+	ErrInvalidGasFee  = fmt.Errorf("Invalid gas fee")
+
+	ErrTx2TreasuryExpired = fmt.Errorf("Tx to treasury expired")
+)
+
+// Refund sends a deposit to the treasury back to its sender, minus the gas of the refund
+// when it's paid in ether
+type Refund struct {
+	ID       int64  `json:"id" db:"id"`
+	TxID     string `json:"tx_id" db:"tx_id"`
+	LogIndex int64  `json:"log_index" db:"log_index"`
+	Reason   string `json:"reason" db:"reason"`
+
+	TokenAddr string `json:"token_addr" db:"token_addr"`
+	ToAddress string `json:"to_address" db:"to_address"`
+
+	Amount       string `json:"amount" db:"amount"`
+	GasFee       string `json:"gas_fee" db:"gas_fee"`
+	RefundAmount string `json:"refund_amount" db:"refund_amount"`
+	RefundTxHash string `json:"refund_tx_hash" db:"refund_tx_hash"`
+
+	Status string `json:"status" db:"status"`
+	Note   string `json:"note" db:"note"`
+
+	CreatedAt  time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at" db:"updated_at"`
+	RefundedAt sql.NullTime `json:"refunded_at" db:"refunded_at"`
+}
+
+// RefundOfTx2Treasury refunds a deposit never matched by a cashin request
+func RefundOfTx2Treasury(t TxToTreasury) Refund {
+	return Refund{
+		TxID:      t.TxID,
+		LogIndex:  t.LogIndex,
+		Reason:    RefundExpired,
+		TokenAddr: t.TokenAddr,
+		ToAddress: t.FromAddress,
+		Amount:    t.Amount,
+		Status:    RefundPendingApproval,
+	}
+}
+
+// RefundOfCashin refunds the whole deposit of a cashin which failed to be issued
+func RefundOfCashin(t EthCashinWelTrans) Refund {
+	return Refund{
+		TxID:      t.EthTxHash,
+		LogIndex:  t.LogIndex,
+		Reason:    RefundCashinFailed,
+		TokenAddr: t.EthTokenAddr,
+		ToAddress: t.EthWalletAddr,
+		Amount:    t.Total,
+		Status:    RefundPendingApproval,
+	}
+}
+
+// Deduct sets the amount sent back once gasFee is taken out of it, gasFee being "0" when
+// the treasury bears the gas
+func (r *Refund) Deduct(gasFee string) error {
+	amount, ok := (&big.Int{}).SetString(r.Amount, 10)
+	if !ok {
+		return ErrInvalidAmount
+	}
+	fee, ok := (&big.Int{}).SetString(gasFee, 10)
+	if !ok || fee.Sign() < 0 {
+		return ErrInvalidGasFee
+	}
+	refunded := amount.Sub(amount, fee)
+	if refunded.Sign() <= 0 {
+		return ErrRefundBelowGas
+	}
+	r.GasFee = fee.String()
+	r.RefundAmount = refunded.String()
+	return nil
+}
@@ -0,0 +1,33 @@
+package model
+
+import "testing"
+
+func TestRefundDeduct(t *testing.T) {
+	r := RefundOfTx2Treasury(TxToTreasury{TxID: "0xabc", FromAddress: "0xsender", TokenAddr: "0xtoken", Amount: "1000"})
+	if r.ToAddress != "0xsender" || r.Reason != RefundExpired || r.Status != RefundPendingApproval {
+		t.Fatalf("unexpected refund %+v", r)
+	}
+
+	if err := r.Deduct("300"); err != nil {
+		t.Fatal(err)
+	}
+	if r.GasFee != "300" || r.RefundAmount != "700" {
+		t.Errorf("expected 700 refunded for 300 of gas, got %+v", r)
+	}
+
+	for fee, expected := range map[string]error{
+		"1000": ErrRefundBelowGas,
+		"5000": ErrRefundBelowGas,
+		"-1":   ErrInvalidGasFee,
+		"abc":  ErrInvalidGasFee,
+	} {
+		if err := r.Deduct(fee); err != expected {
+			t.Errorf("gas fee %s: expected %v, got %v", fee, expected, err)
+		}
+	}
+
+	r = RefundOfCashin(EthCashinWelTrans{EthTxHash: "0xdef", EthWalletAddr: "0xsender", Total: "1000", Amount: "990"})
+	if err := r.Deduct("0"); err != nil || r.RefundAmount != "1000" {
+		t.Errorf("failed cashin should be refunded in full, got %+v, %v", r, err)
+	}
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+var (
+	ErrNotRetrying = fmt.Errorf("Cashout disperse not waiting for a retry")
+
+	// declined disperses are retried right away, then after DisperseRetryBaseBackoff doubled
+	// on every attempt up to DisperseRetryMaxBackoff, and given up after DisperseMaxAttempts
+	DisperseRetryBaseBackoff = time.Minute
+	DisperseRetryMaxBackoff  = 6 * time.Hour
+	DisperseMaxAttempts      = 5
+
+	// an attempt still without a tx past this got lost on its way to BatchDisperse, or in
+	// it, and is retried
+	DisperseAttemptStallTimeout = 30 * time.Minute
+)
+
+// DisperseAttempt is a re-submission of a declined W2E cashout disperse
+type DisperseAttempt struct {
+	ID        int64     `json:"id" db:"id"`
+	CashoutID int64     `json:"cashout_id" db:"cashout_id"`
+	Attempt   int       `json:"attempt" db:"attempt"`
+	TxHash    string    `json:"tx_hash" db:"tx_hash"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// DisperseRetry is a declined W2E cashout along with the attempts already made to disperse
+// it again
+type DisperseRetry struct {
+	WelCashoutEthTrans
+	Attempts      int       `json:"attempts" db:"attempts"`
+	LastAttemptAt time.Time `json:"last_attempt_at" db:"last_attempt_at"`
+}
+
+// DisperseRetryBackoff is the delay before retrying a disperse declined attempts times
+func DisperseRetryBackoff(attempts int) time.Duration {
+	d := DisperseRetryBaseBackoff
+	for i := 1; i < attempts && d < DisperseRetryMaxBackoff; i++ {
+		d *= 2
+	}
+	if d > DisperseRetryMaxBackoff {
+		d = DisperseRetryMaxBackoff
+	}
+	return d
+}
+
+func (r DisperseRetry) Due(now time.Time) bool {
+	if r.Attempts == 0 {
+		return true
+	}
+	return !now.Before(r.LastAttemptAt.Add(DisperseRetryBackoff(r.Attempts)))
+}
+
+func (r DisperseRetry) Exhausted() bool {
+	return r.Attempts >= DisperseMaxAttempts
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDisperseRetryBackoff(t *testing.T) {
+	last := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	r := DisperseRetry{}
+	if !r.Due(last) {
+		t.Errorf("first retry should be due right away")
+	}
+
+	r = DisperseRetry{Attempts: 3, LastAttemptAt: last}
+	if r.Due(last.Add(3 * time.Minute)) {
+		t.Errorf("third retry shouldn't be due before 4 minutes")
+	}
+	if !r.Due(last.Add(4 * time.Minute)) {
+		t.Errorf("third retry should be due after 4 minutes")
+	}
+
+	if d := DisperseRetryBackoff(20); d != DisperseRetryMaxBackoff {
+		t.Errorf("expected backoff capped at %s, got %s", DisperseRetryMaxBackoff, d)
+	}
+	if (DisperseRetry{Attempts: DisperseMaxAttempts - 1}).Exhausted() || !(DisperseRetry{Attempts: DisperseMaxAttempts}).Exhausted() {
+		t.Errorf("retries should be exhausted after %d attempts", DisperseMaxAttempts)
+	}
+}
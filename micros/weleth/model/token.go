@@ -0,0 +1,93 @@
+package model
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+var (
+	ErrTokenNotFound = fmt.Errorf("Corresponding token not found")
+	ErrTokenPaused   = fmt.Errorf("Token paused")
+	ErrInvalidToken  = fmt.Errorf("Invalid bridge token")
+)
+
+// BridgeToken is a token pair listed on the bridge
+type BridgeToken struct {
+	ID          int64  `json:"id" db:"id"`
+	EthAddr     string `json:"eth_addr" db:"eth_addr"`
+	WelAddr     string `json:"wel_addr" db:"wel_addr"`
+	EthName     string `json:"eth_name" db:"eth_name"`
+	WelName     string `json:"wel_name" db:"wel_name"`
+	EthDecimals int    `json:"eth_decimals" db:"eth_decimals"`
+	WelDecimals int    `json:"wel_decimals" db:"wel_decimals"`
+
+	Enabled bool `json:"enabled" db:"enabled"`
+	Paused  bool `json:"paused" db:"paused"`
+
+	EthMinAmount       string `json:"eth_min_amount" db:"eth_min_amount"`
+	WelMinAmount       string `json:"wel_min_amount" db:"wel_min_amount"`
+	EthMaxTransfer     string `json:"eth_max_transfer" db:"eth_max_transfer"`
+	WelMaxTransfer     string `json:"wel_max_transfer" db:"wel_max_transfer"`
+	EthAddressDailyCap string `json:"eth_address_daily_cap" db:"eth_address_daily_cap"`
+	WelAddressDailyCap string `json:"wel_address_daily_cap" db:"wel_address_daily_cap"`
+	EthDailyCap        string `json:"eth_daily_cap" db:"eth_daily_cap"`
+	WelDailyCap        string `json:"wel_daily_cap" db:"wel_daily_cap"`
+
+	EthConfirmations int64 `json:"eth_confirmations" db:"eth_confirmations"`
+	WelConfirmations int64 `json:"wel_confirmations" db:"wel_confirmations"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// EthLimits bounds the transfers of the pair out of eth
+func (t *BridgeToken) EthLimits() BridgeLimits {
+	return BridgeLimits{
+		MinTransfer:     t.EthMinAmount,
+		MaxTransfer:     t.EthMaxTransfer,
+		AddressDailyCap: t.EthAddressDailyCap,
+		DailyCap:        t.EthDailyCap,
+	}
+}
+
+// WelLimits bounds the transfers of the pair out of wel
+func (t *BridgeToken) WelLimits() BridgeLimits {
+	return BridgeLimits{
+		MinTransfer:     t.WelMinAmount,
+		MaxTransfer:     t.WelMaxTransfer,
+		AddressDailyCap: t.WelAddressDailyCap,
+		DailyCap:        t.WelDailyCap,
+	}
+}
+
+func validAmount(s string) bool {
+	if s == "" {
+		return true
+	}
+	v, ok := (&big.Int{}).SetString(s, 10)
+	return ok && v.Sign() >= 0
+}
+
+func (t *BridgeToken) Validate() error {
+	if t.EthAddr == "" || t.WelAddr == "" {
+		return ErrInvalidToken
+	}
+	if t.EthDecimals < 0 || t.EthDecimals > 77 || t.WelDecimals < 0 || t.WelDecimals > 77 {
+		return ErrInvalidToken
+	}
+	if t.EthConfirmations < 0 || t.WelConfirmations < 0 {
+		return ErrInvalidToken
+	}
+	for _, amount := range []string{
+		t.EthMinAmount, t.WelMinAmount,
+		t.EthMaxTransfer, t.WelMaxTransfer,
+		t.EthAddressDailyCap, t.WelAddressDailyCap,
+		t.EthDailyCap, t.WelDailyCap,
+	} {
+		if !validAmount(amount) {
+			return ErrInvalidToken
+		}
+	}
+	return nil
+}
@@ -7,9 +7,6 @@ import (
 )
 
 var (
-	WelTokenFromEth map[string]string = make(map[string]string)
-	EthTokenFromWel map[string]string = make(map[string]string)
-
 	EthereumTk = "0x0000000000000000000000000000000000000000"
 	WelupsTk   = "W9yD14Nj9j7xAB4dbGeiX9h8unkKHxuTtb"
 )
@@ -19,6 +16,10 @@ const (
 	StatusSuccess = "confirmed"
 	StatusUnknown = "unconfirmed"
 	StatusPending = "pending"
+	// deposit got orphaned by a chain reorganization
+	StatusOrphaned = "orphaned"
+	// deposit seen on chain but not yet buried under enough blocks
+	StatusPendingConfirmation = "pending_confirmation"
 
 	// claim status
 	RequestDoubleClaimed = "doubleclaimed"
@@ -30,6 +31,8 @@ var (
 	ErrAlreadyClaimed     = fmt.Errorf("Already claimed")
 	ErrRequestPending     = fmt.Errorf("Request pending")
 	ErrUnrecognizedStatus = fmt.Errorf("Unrecognized transaction status")
+	ErrNotConfirmed       = fmt.Errorf("Deposit not confirmed yet")
+	ErrAlreadyRecorded    = fmt.Errorf("Transaction already recorded")
 )
 
 type ClaimRequest struct {
@@ -46,6 +49,8 @@ type WelCashinEthTrans struct {
 
 	DepositTxHash string `json:"deposit_tx_hash" db:"deposit_tx_hash"`
 	ClaimTxHash   string `json:"claim_tx_hash" db:"claim_tx_hash"`
+	// position of the deposit event in its transaction
+	LogIndex int64 `json:"log_index" db:"log_index"`
 
 	WelTokenAddr string `json:"wel_token_addr" db:"wel_token_addr,omitempty"`
 	EthTokenAddr string `json:"eth_token_addr" db:"eth_token_addr,omitempty"`
@@ -56,12 +61,18 @@ type WelCashinEthTrans struct {
 	NetworkID string `json:"network_id" db:"network_id"`
 
 	Amount string `json:"amount" db:"amount"`
+	// amount in destination token units, the dust left by the conversion is kept as fee
+	DestAmount string `json:"dest_amount" db:"dest_amount"`
+	Dust       string `json:"dust" db:"dust"`
 
 	Fee string `json:"fee" db:"fee"`
 
 	DepositStatus string `json:"withdraw_status" db:"deposit_status"` // it's "withdraw" following the convention of the contract, but my junior decided "deposit" was more intuitive, thus the inconsistency
 	ClaimStatus   string `json:"claim_status" db:"claim_status"`
 
+	DepositBlockNumber int64 `json:"deposit_block_number" db:"deposit_block_number"`
+	Confirmations      int64 `json:"confirmations" db:"confirmations"`
+
 	DepositAt time.Time    `json:"withdraw_at" db:"deposit_at"` // same as above
 	ClaimAt   sql.NullTime `json:"claim_at" db:"claim_at"`
 }
@@ -73,6 +84,8 @@ type EthCashoutWelTrans struct {
 
 	DepositTxHash string `json:"deposit_tx_hash" db:"deposit_tx_hash"`
 	ClaimTxHash   string `json:"claim_tx_hash" db:"claim_tx_hash"`
+	// position of the deposit event in its transaction
+	LogIndex int64 `json:"log_index" db:"log_index"`
 
 	WelTokenAddr string `json:"wel_token_addr" db:"wel_token_addr,omitempty"`
 	EthTokenAddr string `json:"eth_token_addr" db:"eth_token_addr,omitempty"`
@@ -83,12 +96,19 @@ type EthCashoutWelTrans struct {
 	NetworkID string `json:"network_id" db:"network_id"`
 
 	Amount string `json:"amount" db:"amount"`
+	// amount in destination token units, the dust left by the conversion is kept as fee
+	DestAmount string `json:"dest_amount" db:"dest_amount"`
+	Dust       string `json:"dust" db:"dust"`
 
 	Fee string `json:"fee" db:"fee"`
 
 	DepositStatus string `json:"withdraw_status" db:"deposit_status"` // ^ see the above comment for WelCashinEthTrans
 	ClaimStatus   string `json:"claim_status" db:"claim_status"`
 
+	DepositBlockNumber int64  `json:"deposit_block_number" db:"deposit_block_number"`
+	DepositBlockHash   string `json:"deposit_block_hash" db:"deposit_block_hash"`
+	Confirmations      int64  `json:"confirmations" db:"confirmations"`
+
 	DepositAt time.Time    `json:"withdraw_at" db:"deposit_at"` // same as above
 	ClaimAt   sql.NullTime `json:"claim_at" db:"claim_at"`
 }
@@ -100,9 +120,12 @@ const (
 	// transfer
 	// isCashin = was requested by frontend to be a cashin transaction
 	// expired = expired
-	Tx2TrUnconfirmed = "unconfirmed"
-	Tx2TrIsCashin    = "isCashin"
-	Tx2TrExpired     = "expired"
+	// pending_confirmation = transfer seen but not yet buried under enough blocks to be
+	// picked up as a cashin
+	Tx2TrUnconfirmed         = "unconfirmed"
+	Tx2TrIsCashin            = "isCashin"
+	Tx2TrExpired             = "expired"
+	Tx2TrPendingConfirmation = "pending_confirmation"
 
 	// eth to wel cashin status
 	EthCashinWelUnconfirmed = "unconfirmed"
@@ -113,14 +136,27 @@ const (
 	WelCashoutEthUnconfirmed = "unconfirmed"
 	WelCashoutEthConfirmed   = "confirmed"
 	WelCashoutEthRetry       = "retry"
+	WelCashoutEthOrphaned    = "orphaned"
+	// disperse status only, declined and out of retries
+	WelCashoutEthFailed = "failed"
+	// cashout status only, withdraw seen but not yet buried under enough blocks
+	WelCashoutEthPendingConfirmation = "pending_confirmation"
 )
 
 var (
-	ErrTx2TreasuryNotFound = fmt.Errorf("Tx to treasury not found")
+	ErrTx2TreasuryNotFound  = fmt.Errorf("Tx to treasury not found")
+	ErrTx2TreasuryAmbiguous = fmt.Errorf("Tx holds several deposits to treasury, log index required")
 )
 
+// EthValueLogIndex keys the ether sent to the treasury by a tx, which has no log
+const EthValueLogIndex int64 = -1
+
 type TxToTreasury struct {
-	TxID         string `json:"tx_id" db:"tx_id"`
+	TxID string `json:"tx_id" db:"tx_id"`
+	// index of the Transfer log on eth, EthValueLogIndex for ether, position of the
+	// transfer in the tx on wel
+	LogIndex int64 `json:"log_index" db:"log_index"`
+
 	FromAddress  string `json:"from_address" db:"from_address"`
 	TreasuryAddr string `json:"treasury_address" db:"treasury_address"`
 	TokenAddr    string `json:"token_address" db:"token_address"`
@@ -130,13 +166,18 @@ type TxToTreasury struct {
 
 	Status string `json:"status" db:"status"`
 
+	BlockNumber   int64 `json:"block_number" db:"block_number"`
+	Confirmations int64 `json:"confirmations" db:"confirmations"`
+
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
 
 type EthCashinWelTrans struct {
 	ID int64 `json:"id,omitempty" db:"id,omitempty"`
 
-	EthTxHash      string `json:"eth_tx_hash,omitempty" db:"eth_tx_hash,omitempty"`
+	EthTxHash string `json:"eth_tx_hash,omitempty" db:"eth_tx_hash,omitempty"`
+	// log index of the deposit to treasury cashed in
+	LogIndex       int64  `json:"log_index" db:"log_index"`
 	WelIssueTxHash string `json:"wel_issue_tx_hash" db:"wel_issue_tx_hash,omitempty"`
 
 	EthTokenAddr string `json:"eth_token_addr" db:"eth_token_addr,omitempty"`
@@ -150,8 +191,16 @@ type EthCashinWelTrans struct {
 	Total         string `json:"total" db:"total,omitempty"`
 	Amount        string `json:"amount" db:"amount,omitempty"`
 	CommissionFee string `json:"commission_fee" db:"commission_fee,omitempty"`
+	// amount in destination token units, the dust left by the conversion is part of the
+	// commission fee
+	DestAmount string `json:"dest_amount" db:"dest_amount"`
+	Dust       string `json:"dust" db:"dust"`
 
 	Status string `json:"status" db:"status"`
+	// why the latest issue tx failed on Welups, issues failing for a retryable reason are
+	// attempted again
+	FailReason    string `json:"fail_reason,omitempty" db:"fail_reason"`
+	IssueAttempts int    `json:"issue_attempts" db:"issue_attempts"`
 
 	CreatedAt time.Time    `json:"created_at" db:"created_at"`
 	IssuedAt  sql.NullTime `json:"issued_at" db:"issued_at,omitempty"`
@@ -167,6 +216,8 @@ type WelCashoutEthTrans struct {
 
 	EthDisperseTxHash string `json:"eth_disperse_tx_hash,omitempty" db:"eth_disperse_tx_hash,omitempty"`
 	WelWithdrawTxHash string `json:"wel_withdraw_tx_hash" db:"wel_withdraw_tx_hash,omitempty"`
+	// position of the withdraw event in its transaction
+	LogIndex int64 `json:"log_index" db:"log_index"`
 
 	EthTokenAddr string `json:"eth_token_addr" db:"eth_token_addr,omitempty"`
 	WelTokenAddr string `json:"wel_token_addr" db:"wel_token_addr,omitempty"`
@@ -179,10 +230,20 @@ type WelCashoutEthTrans struct {
 	Total         string `json:"total" db:"total,omitempty"`
 	Amount        string `json:"amount" db:"amount,omitempty"`
 	CommissionFee string `json:"commission_fee" db:"commission_fee,omitempty"`
+	// amount in destination token units, the dust left by the conversion is part of the
+	// commission fee
+	DestAmount string `json:"dest_amount" db:"dest_amount"`
+	Dust       string `json:"dust" db:"dust"`
 
 	CashoutStatus  string `json:"cashout_status" db:"cashout_status"`
 	DisperseStatus string `json:"disperse_status" db:"disperse_status"`
 
+	WithdrawBlockNumber int64 `json:"withdraw_block_number" db:"withdraw_block_number"`
+	Confirmations       int64 `json:"confirmations" db:"confirmations"`
+
+	DisperseBlockNumber int64  `json:"disperse_block_number" db:"disperse_block_number"`
+	DisperseBlockHash   string `json:"disperse_block_hash" db:"disperse_block_hash"`
+
 	CreatedAt   time.Time    `json:"created_at" db:"created_at"`
 	DispersedAt sql.NullTime `json:"issued_at" db:"dispersed_at,omitempty"`
 }
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"bridge/common/consts"
+	coreEthService "bridge/micros/core/service/eth"
+	"bridge/micros/weleth/config"
+	"bridge/micros/weleth/dao"
+	"bridge/micros/weleth/model"
+	ethListener "bridge/service-managers/listener/eth"
+	welListener "bridge/service-managers/listener/wel"
+	"bridge/service-managers/logger"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"go.temporal.io/sdk/client"
+)
+
+var DefaultRecheckInterval = 15 * time.Second
+
+// ConfirmationDepths holds the number of blocks a deposit must be buried under on each
+// chain before it's confirmed, with per token overrides. Depths set in the token registry
+// take precedence over the static ones.
+type ConfirmationDepths struct {
+	Eth       uint64
+	Wel       uint64
+	EthTokens map[string]uint64
+	WelTokens map[string]uint64
+	Tokens    *dao.TokenCache
+}
+
+func MkConfirmationDepths(eth, wel uint64, tkMap config.TokensMap) ConfirmationDepths {
+	d := ConfirmationDepths{
+		Eth:       eth,
+		Wel:       wel,
+		EthTokens: make(map[string]uint64),
+		WelTokens: make(map[string]uint64),
+	}
+	for _, pair := range tkMap {
+		if pair.EthConfirmations > 0 {
+			d.EthTokens[pair.Eth] = pair.EthConfirmations
+		}
+		if pair.WelConfirmations > 0 {
+			d.WelTokens[pair.Wel] = pair.WelConfirmations
+		}
+	}
+	return d
+}
+
+func (d ConfirmationDepths) ForEth(token string) int64 {
+	if d.Tokens != nil {
+		if t, ok := d.Tokens.ByEth(token); ok && t.EthConfirmations > 0 {
+			return int64(t.EthConfirmations)
+		}
+	}
+	if depth, ok := d.EthTokens[token]; ok {
+		return int64(depth)
+	}
+	return int64(d.Eth)
+}
+
+func (d ConfirmationDepths) ForWel(token string) int64 {
+	if d.Tokens != nil {
+		if t, ok := d.Tokens.ByWel(token); ok && t.WelConfirmations > 0 {
+			return int64(t.WelConfirmations)
+		}
+	}
+	if depth, ok := d.WelTokens[token]; ok {
+		return int64(depth)
+	}
+	return int64(d.Wel)
+}
+
+// confirmations counts the block of the transaction itself as the first confirmation
+func confirmations(head, block int64) int64 {
+	if block <= 0 || head < block {
+		return 0
+	}
+	return head - block + 1
+}
+
+// ConfirmationTracker re-checks deposits recorded as pending confirmation until they are
+// buried under enough blocks, then makes them eligible for claim/issue/disperse
+type ConfirmationTracker struct {
+	EthClient ethListener.IEthClient
+	WelClient welListener.IWelClient
+	Depths    ConfirmationDepths
+	Interval  time.Duration
+
+	WelCashinEthTransDAO  dao.IWelCashinEthTransDAO
+	EthCashoutWelTransDAO dao.IEthCashoutWelTransDAO
+	EthCashinWelTransDAO  dao.IEthCashinWelTransDAO
+	WelCashoutEthTransDAO dao.IWelCashoutEthTransDAO
+	WelTx2TreasuryDAO     dao.IWelTx2TreasuryDAO
+
+	tempCli client.Client
+}
+
+func MkConfirmationTracker(ethCli ethListener.IEthClient, welCli welListener.IWelClient, depths ConfirmationDepths, tempCli client.Client, daos *dao.DAOs) *ConfirmationTracker {
+	return &ConfirmationTracker{
+		EthClient: ethCli,
+		WelClient: welCli,
+		Depths:    depths,
+		Interval:  DefaultRecheckInterval,
+
+		WelCashinEthTransDAO:  daos.WelCashinEthTransDAO,
+		EthCashoutWelTransDAO: daos.EthCashoutWelTransDAO,
+		EthCashinWelTransDAO:  daos.EthCashinWelTransDAO,
+		WelCashoutEthTransDAO: daos.WelCashoutEthTransDAO,
+		WelTx2TreasuryDAO:     daos.WelTx2TreasuryDAO,
+
+		tempCli: tempCli,
+	}
+}
+
+// Recheck is a daemon generator periodically updating confirmations of pending deposits
+func (c *ConfirmationTracker) Recheck(ctx context.Context) (consts.Daemon, error) {
+	return func() {
+		logger.Get().Info().Msg("[confirmation tracker] Start rechecking pending confirmations")
+		for {
+			consts.SleepContext(ctx, c.Interval)
+			select {
+			case <-ctx.Done():
+				logger.Get().Info().Msg("[confirmation tracker] Stop rechecking pending confirmations")
+				return
+			default:
+				c.recheckEth(ctx)
+				c.recheckWel()
+			}
+		}
+	}, nil
+}
+
+func (c *ConfirmationTracker) recheckEth(ctx context.Context) {
+	log := logger.Get()
+	_head, err := c.EthClient.BlockNumber(ctx)
+	if err != nil {
+		log.Err(err).Msg("[confirmation tracker] can't get eth head")
+		return
+	}
+	head := int64(_head)
+
+	deposits, err := c.EthCashoutWelTransDAO.SelectTransPendingConfirmation()
+	if err != nil {
+		log.Err(err).Msg("[confirmation tracker] can't get pending E2W cashouts")
+	}
+	for _, tran := range deposits {
+		confs := confirmations(head, tran.DepositBlockNumber)
+		status := model.StatusPendingConfirmation
+		if confs >= c.Depths.ForEth(tran.EthTokenAddr) {
+			status = model.StatusSuccess
+			log.Info().Msgf("[confirmation tracker] E2W cashout %s confirmed", tran.DepositTxHash)
+		}
+		if err := c.EthCashoutWelTransDAO.UpdateDepositConfirmations(tran.ID, confs, status); err != nil {
+			log.Err(err).Msgf("[confirmation tracker] can't update confirmations of E2W cashout %s", tran.DepositTxHash)
+		}
+	}
+
+	// transfers to treasury don't carry their block, it's looked up from their receipt
+	tx2trs, err := c.EthCashinWelTransDAO.SelectTx2TreasuryPendingConfirmation()
+	if err != nil {
+		log.Err(err).Msg("[confirmation tracker] can't get pending txs to treasury")
+	}
+	for _, tx2tr := range tx2trs {
+		receipt, err := c.EthClient.TransactionReceipt(ctx, common.HexToHash(tx2tr.TxID))
+		if errors.Is(err, ethereum.NotFound) {
+			// dropped by a reorg, it may get re-included later
+			continue
+		}
+		if err != nil {
+			log.Err(err).Msgf("[confirmation tracker] can't get receipt of tx to treasury %s", tx2tr.TxID)
+			continue
+		}
+		blockNumber := receipt.BlockNumber.Int64()
+		confs := confirmations(head, blockNumber)
+		status := model.Tx2TrPendingConfirmation
+		if receipt.Status != 1 {
+			log.Warn().Msgf("[confirmation tracker] tx to treasury %s:%d failed", tx2tr.TxID, tx2tr.LogIndex)
+			status = model.Tx2TrExpired
+		} else if confs >= c.Depths.ForEth(tx2tr.TokenAddr) {
+			// now waiting for a cashin request
+			status = model.Tx2TrUnconfirmed
+			log.Info().Msgf("[confirmation tracker] tx to treasury %s:%d confirmed", tx2tr.TxID, tx2tr.LogIndex)
+		}
+		if err := c.EthCashinWelTransDAO.UpdateTx2TreasuryConfirmations(tx2tr.TxID, tx2tr.LogIndex, blockNumber, confs, status); err != nil {
+			log.Err(err).Msgf("[confirmation tracker] can't update confirmations of tx to treasury %s:%d", tx2tr.TxID, tx2tr.LogIndex)
+		}
+	}
+}
+
+func (c *ConfirmationTracker) recheckWel() {
+	log := logger.Get()
+	block, err := c.WelClient.GetNowBlock()
+	if err != nil {
+		log.Err(err).Msg("[confirmation tracker] can't get wel head")
+		return
+	}
+	head := block.GetBlockHeader().GetRawData().GetNumber()
+
+	deposits, err := c.WelCashinEthTransDAO.SelectTransPendingConfirmation()
+	if err != nil {
+		log.Err(err).Msg("[confirmation tracker] can't get pending W2E cashins")
+	}
+	for _, tran := range deposits {
+		confs := confirmations(head, tran.DepositBlockNumber)
+		status := model.StatusPendingConfirmation
+		if confs >= c.Depths.ForWel(tran.WelTokenAddr) {
+			status = model.StatusSuccess
+			log.Info().Msgf("[confirmation tracker] W2E cashin %s confirmed", tran.DepositTxHash)
+		}
+		if err := c.WelCashinEthTransDAO.UpdateDepositConfirmations(tran.ID, confs, status); err != nil {
+			log.Err(err).Msgf("[confirmation tracker] can't update confirmations of W2E cashin %s", tran.DepositTxHash)
+		}
+	}
+
+	withdraws, err := c.WelCashoutEthTransDAO.SelectTransPendingConfirmation()
+	if err != nil {
+		log.Err(err).Msg("[confirmation tracker] can't get pending W2E cashouts")
+	}
+	for _, tran := range withdraws {
+		confs := confirmations(head, tran.WithdrawBlockNumber)
+		if confs < c.Depths.ForWel(tran.WelTokenAddr) {
+			if err := c.WelCashoutEthTransDAO.UpdateCashoutConfirmations(tran.ID, confs, model.WelCashoutEthPendingConfirmation); err != nil {
+				log.Err(err).Msgf("[confirmation tracker] can't update confirmations of W2E cashout %s", tran.WelWithdrawTxHash)
+			}
+			continue
+		}
+
+		if err := c.WelCashoutEthTransDAO.UpdateCashoutConfirmations(tran.ID, confs, model.WelCashoutEthConfirmed); err != nil {
+			log.Err(err).Msgf("[confirmation tracker] can't confirm W2E cashout %s", tran.WelWithdrawTxHash)
+			continue
+		}
+		tran.Confirmations = confs
+		tran.CashoutStatus = model.WelCashoutEthConfirmed
+		log.Info().Msgf("[confirmation tracker] W2E cashout %s confirmed, sending to BatchDisperse", tran.WelWithdrawTxHash)
+		err = c.tempCli.SignalWorkflow(context.Background(), coreEthService.BatchDisperseID, "", coreEthService.BatchDisperseSignal, *tran)
+		if err != nil {
+			log.Err(err).Msgf("[confirmation tracker] Error sending BatchDisperseWF tx %+v", tran)
+			// retried on the next round
+			c.WelCashoutEthTransDAO.UpdateCashoutConfirmations(tran.ID, confs, model.WelCashoutEthPendingConfirmation)
+		}
+	}
+
+	// transfers to the wel treasury are recorded with their block, failed ones aren't
+	tx2trs, err := c.WelTx2TreasuryDAO.SelectWelTx2TreasuryPendingConfirmation()
+	if err != nil {
+		log.Err(err).Msg("[confirmation tracker] can't get pending txs to wel treasury")
+	}
+	for _, tx2tr := range tx2trs {
+		confs := confirmations(head, tx2tr.BlockNumber)
+		status := model.Tx2TrPendingConfirmation
+		if confs >= c.Depths.ForWel(tx2tr.TokenAddr) {
+			// now waiting for a cashout request
+			status = model.Tx2TrUnconfirmed
+			log.Info().Msgf("[confirmation tracker] tx to wel treasury %s:%d confirmed", tx2tr.TxID, tx2tr.LogIndex)
+		}
+		if err := c.WelTx2TreasuryDAO.UpdateWelTx2TreasuryConfirmations(tx2tr.TxID, tx2tr.LogIndex, tx2tr.BlockNumber, confs, status); err != nil {
+			log.Err(err).Msgf("[confirmation tracker] can't update confirmations of tx to wel treasury %s:%d", tx2tr.TxID, tx2tr.LogIndex)
+		}
+	}
+}
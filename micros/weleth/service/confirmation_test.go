@@ -0,0 +1,46 @@
+package service
+
+import (
+	"bridge/micros/weleth/config"
+	"encoding/json"
+	"testing"
+)
+
+func TestConfirmationDepths(t *testing.T) {
+	var tkMap config.TokensMap
+	err := json.Unmarshal([]byte(`[
+		{"eth": "0xb60bd744550b46DBBDc3f17ccea62E619d772502", "wel": "W9yD14Nj9j7xAB4dbGeiX9h8unkKHxuTtb", "eth_confirmations": 30},
+		{"eth": "0x0000000000000000000000000000000000000000", "wel": "WLNYdo8jy9xxuyGhQtqU2DAgcptBgJu4jd", "wel_confirmations": 40}
+	]`), &tkMap)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := MkConfirmationDepths(12, 20, tkMap)
+	cases := []struct {
+		got, expected int64
+	}{
+		{d.ForEth("0xb60bd744550b46DBBDc3f17ccea62E619d772502"), 30},
+		{d.ForEth("0x0000000000000000000000000000000000000000"), 12},
+		{d.ForWel("W9yD14Nj9j7xAB4dbGeiX9h8unkKHxuTtb"), 20},
+		{d.ForWel("WLNYdo8jy9xxuyGhQtqU2DAgcptBgJu4jd"), 40},
+	}
+	for i, c := range cases {
+		if c.got != c.expected {
+			t.Errorf("case %d: expected depth %d, got %d", i, c.expected, c.got)
+		}
+	}
+}
+
+func TestConfirmations(t *testing.T) {
+	if n := confirmations(100, 100); n != 1 {
+		t.Errorf("tx in the head block should have 1 confirmation, got %d", n)
+	}
+	if n := confirmations(111, 100); n != 12 {
+		t.Errorf("expected 12 confirmations, got %d", n)
+	}
+	// lagging head or block not recorded
+	if confirmations(99, 100) != 0 || confirmations(100, 0) != 0 {
+		t.Errorf("unknown confirmations should be 0")
+	}
+}
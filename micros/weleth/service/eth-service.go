@@ -2,12 +2,10 @@ package service
 
 import (
 	"bridge/libs"
-	coreEthService "bridge/micros/core/service/eth"
 	"bridge/micros/weleth/dao"
 	"bridge/micros/weleth/model"
 	ethListener "bridge/service-managers/listener/eth"
 	"bridge/service-managers/logger"
-	"context"
 	"database/sql"
 	"fmt"
 	"math/big"
@@ -30,6 +28,8 @@ type EthConsumer struct {
 	WelCashinEthTransDAO  dao.IWelCashinEthTransDAO
 	EthCashoutWelTransDAO dao.IEthCashoutWelTransDAO
 	WelCashoutEthTransDAO dao.IWelCashoutEthTransDAO
+	Tokens                *dao.TokenCache
+	Ledger                *EventLedger
 
 	importAbi  abi.ABI
 	mulsendAbi abi.ABI
@@ -68,6 +68,8 @@ func NewEthConsumer(iaddr, msaddr string, tempCli client.Client, daos *dao.DAOs)
 		WelCashinEthTransDAO:  daos.WelCashinEthTransDAO,
 		EthCashoutWelTransDAO: daos.EthCashoutWelTransDAO,
 		WelCashoutEthTransDAO: daos.WelCashoutEthTransDAO,
+		Tokens:                daos.Tokens,
+		Ledger:                MkEventLedger(daos),
 
 		importAbi:  importAbi,
 		mulsendAbi: mulsendAbi,
@@ -85,28 +87,28 @@ func (e *EthConsumer) GetConsumer() ([]*ethListener.EventConsumer, error) {
 			Topic: crypto.Keccak256Hash(
 				[]byte(e.importAbi.Events["Imported"].Sig),
 			),
-			ParseEvent: e.DoneClaimParser,
+			ParseEvent: e.Ledger.OnceEth("Imported", e.DoneClaimParser),
 		},
 		{
 			Address: common.HexToAddress(e.ImportContractAddr),
 			Topic: crypto.Keccak256Hash(
 				[]byte(e.importAbi.Events["Withdraw"].Sig),
 			),
-			ParseEvent: e.DoneDepositParser,
+			ParseEvent: e.Ledger.OnceEth("Withdraw", e.DoneDepositParser),
 		},
 		{
 			Address: common.HexToAddress(e.MulsendContractAddr),
 			Topic: crypto.Keccak256Hash(
 				[]byte(e.mulsendAbi.Events["Decline"].Sig),
 			),
-			ParseEvent: e.DeclineParser,
+			ParseEvent: e.Ledger.OnceEth("Decline", e.DeclineParser),
 		},
 		{
 			Address: common.HexToAddress(e.MulsendContractAddr),
 			Topic: crypto.Keccak256Hash(
 				[]byte(e.mulsendAbi.Events["Disperse"].Sig),
 			),
-			ParseEvent: e.DisperseParser,
+			ParseEvent: e.Ledger.OnceEth("Disperse", e.DisperseParser),
 		},
 	}, nil
 }
@@ -195,17 +197,13 @@ func (e *EthConsumer) DisperseParser(l types.Log) error {
 			logger.Get().Err(err).Msgf("[DisperseEV] transaction possibly declined: %+v", tran)
 			continue
 		}
-		//tran.Remain = remain.String()
-
-		//total := big.NewInt(0)
-		//total.SetString(tran.Total, 10)
-
-		//fee := &big.Int{}
-		//fee = fee.Sub(total, remain)
-		//tran.CommissionFee = fee.String()
+		// the commission fee was settled by the fee schedule when the cashout got recorded,
+		// the disperse only delivers the amount
 
 		tran.DisperseStatus = model.WelCashoutEthConfirmed
 		tran.DispersedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		tran.DisperseBlockNumber = int64(l.BlockNumber)
+		tran.DisperseBlockHash = l.BlockHash.Hex()
 
 		logger.Get().Info().Msgf("[DisperseEV] tran to be updated: %+v", tran)
 		if err := e.WelCashoutEthTransDAO.UpdateWelCashoutEthTx(tran); err != nil {
@@ -233,32 +231,14 @@ func (e *EthConsumer) DeclineParser(l types.Log) error {
 	amount := data["amount"].(*big.Int).String()
 	logger.Get().Info().Msgf("amount: %s", amount)
 
-	// set corresponding record in DB: disperse_status = retry
-	trans, err := e.WelCashoutEthTransDAO.SelectTransByDisperseTxHashEthAddrAmount(ethTx, ethWalletAddr, amount)
+	// set corresponding record in DB: disperse_status = retry, re-batched by the disperse
+	// retry scheduler in core, with backoff
+	n, err := e.WelCashoutEthTransDAO.RetryDeclinedDisperse(ethTx, ethWalletAddr, amount)
 	if err != nil {
-		logger.Get().Err(err).Msgf("[DeclineEV] error while retrieving transactions with disperse txhash %s", ethTx)
+		logger.Get().Err(err).Msgf("[DeclineEV] error while updating transactions with disperse txhash %s", ethTx)
 		return err
 	}
-	logger.Get().Info().Msgf("trans: %+v", trans)
-	for _, tran := range trans {
-		if tran.DisperseStatus == model.WelCashoutEthConfirmed {
-			continue
-		}
-		tran.DisperseStatus = model.WelCashoutEthRetry
-
-		logger.Get().Info().Msgf("[DeclineEV] tran to be updated: %+v", tran)
-		if err := e.WelCashoutEthTransDAO.UpdateWelCashoutEthTx(tran); err != nil {
-			logger.Get().Err(err).Msgf("[DeclineEV] error while updating transaction %+v", tran)
-			return err
-		}
-		// signal WF to re-batch tx
-		err = e.tempCli.SignalWorkflow(context.Background(), coreEthService.BatchDisperseID, "", coreEthService.BatchDisperseSignal, tran)
-		if err != nil {
-			logger.Get().Err(err).Msgf("[DeclineEV] Error sending BatchDisperseWF tx %+v", tran)
-			return err
-		}
-
-	}
+	logger.Get().Info().Msgf("[DeclineEV] %d transactions of disperse %s to be retried", n, ethTx)
 	return nil
 }
 
@@ -274,7 +254,7 @@ func (e *EthConsumer) DoneDepositParser(l types.Log) error {
 	txHash := l.TxHash.Hex()
 	ethWalletAddr := common.HexToAddress(l.Topics[2].Hex()).Hex()
 
-	tran, err := e.EthCashoutWelTransDAO.SelectTransByDepositTxHash(l.TxHash.Hex())
+	tran, err := e.EthCashoutWelTransDAO.SelectTransByDepositEvent(txHash, int64(l.Index))
 	if err != nil && err != sql.ErrNoRows {
 		return err
 	}
@@ -295,18 +275,27 @@ func (e *EthConsumer) DoneDepositParser(l types.Log) error {
 		//event.ID = crypto.Keccak256Hash(m).Big().String()
 
 		event.DepositTxHash = txHash
+		event.LogIndex = int64(l.Index)
 		event.EthWalletAddr = ethWalletAddr
-		event.WelTokenAddr = model.WelTokenFromEth[event.EthTokenAddr]
+		event.WelTokenAddr = e.Tokens.WelFromEth(event.EthTokenAddr)
 		event.Amount = amount
-		event.DepositStatus = model.StatusSuccess
+		// claimed in wel token units, amount comes from the chain thus is always valid
+		n, _ := e.Tokens.Normalize(event.EthTokenAddr, model.FeeEthToWel, amount)
+		event.DestAmount, event.Dust = n.DestAmount, n.Dust
+		// recorded as soon as seen, the confirmation tracker confirms it once it's buried
+		// under enough blocks
+		event.DepositStatus = model.StatusPendingConfirmation
+		event.DepositBlockNumber = int64(l.BlockNumber)
+		event.DepositBlockHash = l.BlockHash.Hex()
 
 		err = e.EthCashoutWelTransDAO.CreateEthCashoutWelTrans(&event)
 		if err != nil {
 			return err
 		}
 	} else {
-		if tran.DepositStatus != model.StatusSuccess {
-			err := e.EthCashoutWelTransDAO.UpdateDepositEthCashoutWelConfirmed(txHash, ethWalletAddr, amount)
+		// a deposit re-included in another block after a reorg goes through confirmation again
+		if tran.DepositBlockHash != l.BlockHash.Hex() {
+			err := e.EthCashoutWelTransDAO.UpdateDepositEthCashoutWelBlock(txHash, int64(l.Index), ethWalletAddr, amount, l.BlockHash.Hex(), int64(l.BlockNumber))
 			if err != nil {
 				return err
 			}
@@ -334,7 +323,8 @@ func (e *EthConsumer) DoneClaimParser(l types.Log) error {
 	if err != nil {
 		return err
 	}
-	if amount != tran.Amount {
+	// claims are made in eth token units
+	if amount != tran.DestAmount {
 		return fmt.Errorf("Claim wrong amount")
 	}
 	if ethWalletAddr != tran.EthWalletAddr {
@@ -361,6 +351,30 @@ func (e *EthConsumer) DoneClaimParser(l types.Log) error {
 	return nil
 }
 
+func (e *EthConsumer) HandleReorg(ev ethListener.ReorgEvent) error {
+	logger.Get().Warn().Msgf("[ReorgEV] blocks %d to %d orphaned", ev.FromBlock, ev.ToBlock)
+	blockHashes := libs.Map(
+		func(hash common.Hash) string {
+			return hash.Hex()
+		},
+		ev.OrphanedHashes)
+
+	n, err := e.EthCashoutWelTransDAO.MarkOrphanedByBlockHashes(blockHashes)
+	if err != nil {
+		logger.Get().Err(err).Msgf("[ReorgEV] error while marking orphaned eth deposits in blocks %d to %d", ev.FromBlock, ev.ToBlock)
+		return err
+	}
+	logger.Get().Info().Msgf("[ReorgEV] %d eth deposits marked as orphaned", n)
+
+	n, err = e.WelCashoutEthTransDAO.MarkOrphanedByBlockHashes(blockHashes)
+	if err != nil {
+		logger.Get().Err(err).Msgf("[ReorgEV] error while marking orphaned eth disperses in blocks %d to %d", ev.FromBlock, ev.ToBlock)
+		return err
+	}
+	logger.Get().Info().Msgf("[ReorgEV] %d eth disperses marked as orphaned", n)
+	return nil
+}
+
 //---------------------------------------------------------------//
 type TreasuryMonitor struct {
 	treasury_address string
@@ -378,11 +392,12 @@ func (tm *TreasuryMonitor) MonitoredAddress() common.Address {
 	return common.HexToAddress(tm.treasury_address)
 }
 
-func (tm *TreasuryMonitor) TxParse(t *types.Transaction, from, to, tokenAddr, amount string) error {
+func (tm *TreasuryMonitor) TxParse(t *types.Transaction, logIndex int64, from, to, tokenAddr, amount string) error {
 	logger.Get().Info().Msgf("transaction to treasury: %x", t.Hash())
 	tx2treasury := &model.TxToTreasury{}
 
 	tx2treasury.TxID = t.Hash().Hex()
+	tx2treasury.LogIndex = logIndex
 	tx2treasury.FromAddress = from
 	tx2treasury.TreasuryAddr = to
 	tx2treasury.TokenAddr = tokenAddr
@@ -391,7 +406,9 @@ func (tm *TreasuryMonitor) TxParse(t *types.Transaction, from, to, tokenAddr, am
 	tx_fee := t.Cost()
 	tx_fee = tx_fee.Sub(tx_fee, t.Value())
 	tx2treasury.TxFee = tx_fee.String()
-	tx2treasury.Status = "unconfirmed"
+	// becomes eligible for cashin once the confirmation tracker sees it buried under
+	// enough blocks
+	tx2treasury.Status = model.Tx2TrPendingConfirmation
 	tx2treasury.CreatedAt = time.Now()
 	logger.Get().Info().Msgf("record tx to treasury: %+v\n", tx2treasury)
 	if err := tm.EthCashinWelDAO.CreateTx2Treasury(tx2treasury); err != nil {
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"bridge/micros/weleth/dao"
+	"bridge/micros/weleth/model"
+	ethListener "bridge/service-managers/listener/eth"
+	welListener "bridge/service-managers/listener/wel"
+	"bridge/service-managers/logger"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// EventLedger skips events already handled: an event, keyed by chain, tx hash and log
+// index, is handled once per revision whatever the number of re-scans. Without a DAO every
+// event is handled.
+type EventLedger struct {
+	DAO dao.IProcessedEventDAO
+}
+
+func MkEventLedger(daos *dao.DAOs) *EventLedger {
+	return &EventLedger{DAO: daos.ProcessedEventDAO}
+}
+
+// once runs handle unless the event was already processed. The event is recorded only
+// once handled: handlers are idempotent on (tx hash, log index), so an event interrupted
+// midway, or handled concurrently, is simply handled again and never lost
+func (l *EventLedger) once(e model.ProcessedEvent, handle func() error) error {
+	if l == nil || l.DAO == nil {
+		return handle()
+	}
+	processed, err := l.DAO.Processed(&e)
+	if err != nil {
+		return err
+	}
+	if processed {
+		logger.Get().Info().Msgf("[event ledger] %s event %s:%d already processed", e.Event, e.TxHash, e.LogIndex)
+		return nil
+	}
+	if err := handle(); err != nil {
+		return err
+	}
+	if err := l.DAO.Record(&e); err != nil {
+		// handled anyway, it's only handled again on the next scan
+		logger.Get().Err(err).Msgf("[event ledger] unable to record %s event %s:%d", e.Event, e.TxHash, e.LogIndex)
+	}
+	return nil
+}
+
+// OnceEth handles an eth event once per block it's included in
+func (l *EventLedger) OnceEth(event string, parse ethListener.EventParser) ethListener.EventParser {
+	return func(lg types.Log) error {
+		return l.once(model.ProcessedEvent{
+			Chain:    model.ChainEth,
+			TxHash:   lg.TxHash.Hex(),
+			LogIndex: int64(lg.Index),
+			Event:    event,
+			Revision: lg.BlockHash.Hex(),
+		}, func() error { return parse(lg) })
+	}
+}
+
+// OnceWel handles a wel event once per status of its transaction, unconfirmed then
+// confirmed
+func (l *EventLedger) OnceWel(event string, parse welListener.EventParser) welListener.EventParser {
+	return func(t *welListener.Transaction, logpos int) error {
+		return l.once(model.ProcessedEvent{
+			Chain:    model.ChainWel,
+			TxHash:   t.Hash,
+			LogIndex: int64(logpos),
+			Event:    event,
+			Revision: t.Status,
+		}, func() error { return parse(t, logpos) })
+	}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"bridge/micros/weleth/model"
+	welListener "bridge/service-managers/listener/wel"
+	"fmt"
+	"testing"
+)
+
+// fakeProcessedEventDAO mirrors the revision check done against processed_events
+type fakeProcessedEventDAO struct {
+	revisions map[string]string
+}
+
+func (f *fakeProcessedEventDAO) key(chain, txHash string, logIndex int64) string {
+	return fmt.Sprintf("%s/%s/%d", chain, txHash, logIndex)
+}
+
+func (f *fakeProcessedEventDAO) Processed(e *model.ProcessedEvent) (bool, error) {
+	rev, ok := f.revisions[f.key(e.Chain, e.TxHash, e.LogIndex)]
+	return ok && rev == e.Revision, nil
+}
+
+func (f *fakeProcessedEventDAO) Record(e *model.ProcessedEvent) error {
+	f.revisions[f.key(e.Chain, e.TxHash, e.LogIndex)] = e.Revision
+	return nil
+}
+
+func TestEventLedger(t *testing.T) {
+	ledger := &EventLedger{DAO: &fakeProcessedEventDAO{revisions: map[string]string{}}}
+
+	handled := map[int]int{}
+	fail := true
+	parse := ledger.OnceWel("Withdraw", func(tx *welListener.Transaction, logpos int) error {
+		if logpos == 2 && fail {
+			return fmt.Errorf("failed")
+		}
+		handled[logpos]++
+		return nil
+	})
+
+	tx := &welListener.Transaction{Hash: "0xabc", Status: "unconfirmed"}
+	for _, logpos := range []int{0, 1, 2, 0, 1} {
+		parse(tx, logpos)
+	}
+	if handled[0] != 1 || handled[1] != 1 {
+		t.Errorf("re-scanned events should be handled once, got %v", handled)
+	}
+
+	// a failed event is handled again on the next scan
+	fail = false
+	parse(tx, 2)
+	if handled[2] != 1 {
+		t.Errorf("failed event not handled again, got %v", handled)
+	}
+
+	// a new revision of the event is handled again
+	tx.Status = "confirmed"
+	parse(tx, 0)
+	if handled[0] != 2 {
+		t.Errorf("confirmed event not handled again, got %v", handled)
+	}
+
+	// without a DAO every event is handled
+	var none *EventLedger
+	n := 0
+	passthrough := none.OnceWel("Withdraw", func(*welListener.Transaction, int) error { n++; return nil })
+	passthrough(tx, 0)
+	passthrough(tx, 0)
+	if n != 2 {
+		t.Errorf("expected every event to be handled without ledger, got %d", n)
+	}
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"bridge/micros/weleth/dao"
+	"bridge/micros/weleth/model"
+	ethListener "bridge/service-managers/listener/eth"
+	welListener "bridge/service-managers/listener/wel"
+	"bridge/service-managers/logger"
+	"encoding/json"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// outbox keeps the events of a chain in the event outbox table
+type outbox struct {
+	chain string
+	DAO   dao.IEventOutboxDAO
+}
+
+func (o *outbox) due(limit int) ([]model.OutboxEvent, error) {
+	return o.DAO.SelectDueEvents(o.chain, limit)
+}
+
+func (o *outbox) fail(id int64, attempts int, err error) error {
+	e := &model.OutboxEvent{ID: id, Chain: o.chain, Attempts: attempts}
+	e.Failed(err, time.Now())
+	if e.Status == model.OutboxDead {
+		logger.Get().Error().Msgf("[event outbox] %s event %d dead-lettered after %d attempts: %s", o.chain, id, e.Attempts, e.LastError)
+	}
+	return o.DAO.UpdateFailedEvent(e)
+}
+
+// EthEventQueue queues the eth logs matched by the listener in the event outbox
+type EthEventQueue struct {
+	outbox
+}
+
+func MkEthEventQueue(daos *dao.DAOs) *EthEventQueue {
+	return &EthEventQueue{outbox{chain: model.ChainEth, DAO: daos.EventOutboxDAO}}
+}
+
+func (q *EthEventQueue) Enqueue(events []ethListener.QueuedEvent) error {
+	es := make([]model.OutboxEvent, 0, len(events))
+	for _, ev := range events {
+		payload, err := json.Marshal(ev.Log)
+		if err != nil {
+			return err
+		}
+		// a log re-included in another block after a reorg is queued again
+		es = append(es, model.OutboxEvent{
+			Chain:    model.ChainEth,
+			TxHash:   ev.Log.TxHash.Hex(),
+			LogIndex: int64(ev.Log.Index),
+			Revision: ev.Log.BlockHash.Hex(),
+			Payload:  string(payload),
+		})
+	}
+	return q.DAO.EnqueueEvents(es)
+}
+
+func (q *EthEventQueue) Due(limit int) ([]ethListener.QueuedEvent, error) {
+	es, err := q.due(limit)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]ethListener.QueuedEvent, 0, len(es))
+	for _, e := range es {
+		var vLog types.Log
+		if err := json.Unmarshal([]byte(e.Payload), &vLog); err != nil {
+			logger.Get().Err(err).Msgf("[event outbox] malformed eth event %d", e.ID)
+			q.fail(e.ID, model.OutboxMaxAttempts, err)
+			continue
+		}
+		events = append(events, ethListener.QueuedEvent{ID: e.ID, Attempts: e.Attempts, Log: vLog})
+	}
+	return events, nil
+}
+
+func (q *EthEventQueue) Ack(ev ethListener.QueuedEvent) error {
+	return q.DAO.AckEvent(ev.ID)
+}
+
+func (q *EthEventQueue) Fail(ev ethListener.QueuedEvent, err error) error {
+	return q.fail(ev.ID, ev.Attempts, err)
+}
+
+// WelEventQueue queues the wel events matched by the listener in the event outbox, along
+// with their transaction
+type WelEventQueue struct {
+	outbox
+}
+
+func MkWelEventQueue(daos *dao.DAOs) *WelEventQueue {
+	return &WelEventQueue{outbox{chain: model.ChainWel, DAO: daos.EventOutboxDAO}}
+}
+
+func (q *WelEventQueue) Enqueue(events []welListener.QueuedEvent) error {
+	es := make([]model.OutboxEvent, 0, len(events))
+	for _, ev := range events {
+		payload, err := json.Marshal(ev.Tx)
+		if err != nil {
+			return err
+		}
+		// an event seen unconfirmed is queued again once confirmed
+		es = append(es, model.OutboxEvent{
+			Chain:    model.ChainWel,
+			TxHash:   ev.Tx.Hash,
+			LogIndex: int64(ev.LogPos),
+			Revision: ev.Tx.Status,
+			Payload:  string(payload),
+		})
+	}
+	return q.DAO.EnqueueEvents(es)
+}
+
+func (q *WelEventQueue) Due(limit int) ([]welListener.QueuedEvent, error) {
+	es, err := q.due(limit)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]welListener.QueuedEvent, 0, len(es))
+	for _, e := range es {
+		tx := &welListener.Transaction{}
+		if err := json.Unmarshal([]byte(e.Payload), tx); err != nil {
+			logger.Get().Err(err).Msgf("[event outbox] malformed wel event %d", e.ID)
+			q.fail(e.ID, model.OutboxMaxAttempts, err)
+			continue
+		}
+		events = append(events, welListener.QueuedEvent{ID: e.ID, Attempts: e.Attempts, Tx: tx, LogPos: int(e.LogIndex)})
+	}
+	return events, nil
+}
+
+func (q *WelEventQueue) Ack(ev welListener.QueuedEvent) error {
+	return q.DAO.AckEvent(ev.ID)
+}
+
+func (q *WelEventQueue) Fail(ev welListener.QueuedEvent, err error) error {
+	return q.fail(ev.ID, ev.Attempts, err)
+}
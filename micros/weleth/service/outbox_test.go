@@ -0,0 +1,107 @@
+package service
+
+import (
+	"bridge/micros/weleth/dao"
+	"bridge/micros/weleth/model"
+	ethListener "bridge/service-managers/listener/eth"
+	welListener "bridge/service-managers/listener/wel"
+	"bytes"
+	"fmt"
+	"testing"
+
+	CoreProto "github.com/Paven-Org/gotron-sdk/pkg/proto/core"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type fakeEventOutboxDAO struct {
+	dao.IEventOutboxDAO
+	events []model.OutboxEvent
+	failed []model.OutboxEvent
+}
+
+func (f *fakeEventOutboxDAO) EnqueueEvents(es []model.OutboxEvent) error {
+	for _, e := range es {
+		e.ID = int64(len(f.events) + 1)
+		f.events = append(f.events, e)
+	}
+	return nil
+}
+
+func (f *fakeEventOutboxDAO) SelectDueEvents(chain string, limit int) ([]model.OutboxEvent, error) {
+	return f.events, nil
+}
+
+func (f *fakeEventOutboxDAO) UpdateFailedEvent(e *model.OutboxEvent) error {
+	f.failed = append(f.failed, *e)
+	return nil
+}
+
+func TestWelEventQueue(t *testing.T) {
+	fake := &fakeEventOutboxDAO{}
+	q := MkWelEventQueue(&dao.DAOs{EventOutboxDAO: fake})
+
+	tx := &welListener.Transaction{
+		Hash:   "0xabc",
+		Status: "confirmed",
+		Log: []*CoreProto.TransactionInfo_Log{
+			{Topics: [][]byte{{1}}},
+			{Topics: [][]byte{{2}}, Data: []byte{3}},
+		},
+	}
+	tx.Contract.Parameter.Raw = map[string]interface{}{"ContractAddress": "WContract"}
+	if err := q.Enqueue([]welListener.QueuedEvent{{Tx: tx, LogPos: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if e := fake.events[0]; e.Chain != model.ChainWel || e.TxHash != "0xabc" || e.LogIndex != 1 || e.Revision != "confirmed" {
+		t.Errorf("unexpected queued event %+v", e)
+	}
+
+	// the transaction survives the round trip well enough to be matched and parsed again
+	events, err := q.Due(10)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("unexpected due events %+v, %v", events, err)
+	}
+	got := events[0]
+	if got.LogPos != 1 || got.Tx.Hash != "0xabc" || !bytes.Equal(got.Tx.Log[1].Data, []byte{3}) || got.Tx.Contract.Parameter.Raw["ContractAddress"] != "WContract" {
+		t.Errorf("unexpected decoded event %+v", got)
+	}
+
+	got.Attempts = model.OutboxMaxAttempts - 1
+	q.Fail(got, fmt.Errorf("temporal unavailable"))
+	if f := fake.failed[0]; f.Status != model.OutboxDead || f.LastError != "temporal unavailable" {
+		t.Errorf("event out of attempts should be dead-lettered, got %+v", f)
+	}
+}
+
+func TestEthEventQueue(t *testing.T) {
+	fake := &fakeEventOutboxDAO{}
+	q := MkEthEventQueue(&dao.DAOs{EventOutboxDAO: fake})
+
+	vLog := types.Log{
+		Address:     common.HexToAddress("0x01"),
+		Topics:      []common.Hash{common.HexToHash("0x02")},
+		Data:        []byte{3},
+		BlockNumber: 10,
+		TxHash:      common.HexToHash("0x04"),
+		BlockHash:   common.HexToHash("0x05"),
+		Index:       6,
+	}
+	q.Enqueue([]ethListener.QueuedEvent{{Log: vLog}})
+	if e := fake.events[0]; e.Chain != model.ChainEth || e.LogIndex != 6 || e.Revision != vLog.BlockHash.Hex() {
+		t.Errorf("unexpected queued event %+v", e)
+	}
+
+	events, err := q.Due(10)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("unexpected due events %+v, %v", events, err)
+	}
+	if got := events[0].Log; got.TxHash != vLog.TxHash || got.Index != 6 || got.BlockNumber != 10 || got.Topics[0] != vLog.Topics[0] {
+		t.Errorf("unexpected decoded log %+v", got)
+	}
+
+	q.Fail(events[0], fmt.Errorf("db down"))
+	if f := fake.failed[0]; f.Status != model.OutboxPending || f.Attempts != 1 {
+		t.Errorf("failed event should be retried, got %+v", f)
+	}
+}
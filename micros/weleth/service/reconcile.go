@@ -0,0 +1,553 @@
+package service
+
+import (
+	"bridge/libs"
+	"bridge/micros/weleth/dao"
+	"bridge/micros/weleth/model"
+	ethListener "bridge/service-managers/listener/eth"
+	welListener "bridge/service-managers/listener/wel"
+	"bridge/service-managers/logger"
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"strings"
+
+	GotronCommon "github.com/Paven-Org/gotron-sdk/pkg/common"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	// max block ranges fetched at once from each chain
+	DefaultReconcileEthChunk int64 = 2000
+	DefaultReconcileWelChunk int64 = 100
+)
+
+const (
+	tableWelCashinEth  = "wel_cashin_eth_trans"
+	tableEthCashoutWel = "eth_cashout_wel_trans"
+	tableEthCashinWel  = "eth_cashin_wel_trans"
+	tableWelCashoutEth = "wel_cashout_eth_trans"
+
+	eventImportWithdraw = "Import.Withdraw"
+	eventImportImported = "Import.Imported"
+	eventExportWithdraw = "Export.Withdraw"
+	eventExportReturned = "Export.Returned"
+	eventDisperse       = "MultiSender.Disperse"
+)
+
+// chainEvent is a bridge contract event along with where its record is expected
+type chainEvent struct {
+	Chain       string
+	BlockNumber int64
+	TxHash      string
+	Event       string
+	Table       string
+
+	// records are looked up by request id for claims, by tx hash otherwise
+	Key string
+	// position of the event in its tx, records looked up by tx hash are told apart by it
+	LogIndex int64
+	// batched events are matched to their records per receiver
+	Receiver string
+	// empty when the event doesn't carry the recorded amount
+	Amount string
+}
+
+type bridgeRecord struct {
+	Receiver string
+	Amount   string
+}
+
+type recordLookup func(ev chainEvent) ([]bridgeRecord, error)
+
+// diffEvents reports events without record, recorded several times or recorded with
+// another amount
+func diffEvents(events []chainEvent, lookup recordLookup) ([]model.ReconcileIssue, error) {
+	issues := []model.ReconcileIssue{}
+
+	eventID := func(ev chainEvent) string {
+		key := strings.ToLower(ev.Key)
+		if ev.Key == ev.TxHash {
+			key = fmt.Sprintf("%s:%d", key, ev.LogIndex)
+		}
+		return ev.Table + "/" + key + "/" + strings.ToLower(ev.Receiver)
+	}
+	occurrences := make(map[string]int)
+	for _, ev := range events {
+		occurrences[eventID(ev)]++
+	}
+
+	reported := make(map[string]bool)
+	for _, ev := range events {
+		issue := model.ReconcileIssue{
+			Chain:       ev.Chain,
+			BlockNumber: ev.BlockNumber,
+			TxHash:      ev.TxHash,
+			LogIndex:    ev.LogIndex,
+			Event:       ev.Event,
+			Table:       ev.Table,
+			Key:         ev.Receiver,
+		}
+		if ev.Receiver == "" && ev.Key != ev.TxHash {
+			issue.Key = ev.Key
+		}
+
+		// e.g. the same request claimed twice
+		if id := eventID(ev); occurrences[id] > 1 {
+			if !reported[id] {
+				reported[id] = true
+				issue.Kind = model.ReconcileDuplicated
+				issue.Detail = fmt.Sprintf("event emitted %d times", occurrences[id])
+				issues = append(issues, issue)
+			}
+			continue
+		}
+
+		records, err := lookup(ev)
+		if err != nil {
+			return nil, err
+		}
+		if ev.Receiver != "" {
+			records = libs.Filter(func(r bridgeRecord) bool {
+				return strings.EqualFold(r.Receiver, ev.Receiver)
+			}, records)
+		}
+
+		switch {
+		case len(records) == 0:
+			issue.Kind = model.ReconcileMissing
+		case len(records) > 1:
+			issue.Kind = model.ReconcileDuplicated
+			issue.Detail = fmt.Sprintf("%d records", len(records))
+		case ev.Amount != "" && !sameAmount(ev.Amount, records[0].Amount):
+			issue.Kind = model.ReconcileAmountMismatch
+			issue.Detail = fmt.Sprintf("on chain %s, recorded %s", ev.Amount, records[0].Amount)
+		default:
+			continue
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+func sameAmount(a, b string) bool {
+	x, okx := new(big.Int).SetString(a, 10)
+	y, oky := new(big.Int).SetString(b, 10)
+	if !okx || !oky {
+		return a == b
+	}
+	return x.Cmp(y) == 0
+}
+
+// addAmounts sums two amounts, an empty or malformed one counting as 0
+func addAmounts(a, b string) string {
+	x, ok := new(big.Int).SetString(a, 10)
+	if !ok {
+		x = new(big.Int)
+	}
+	if y, ok := new(big.Int).SetString(b, 10); ok {
+		x.Add(x, y)
+	}
+	return x.String()
+}
+
+// Reconciler re-fetches the bridge contracts' events over a block range and diffs them
+// against the weleth transaction tables
+type Reconciler struct {
+	EthClient ethListener.IEthClient
+	WelTrans  *welListener.TransHandler
+	EthChunk  int64
+	WelChunk  int64
+
+	WelCashinEthTransDAO  dao.IWelCashinEthTransDAO
+	EthCashoutWelTransDAO dao.IEthCashoutWelTransDAO
+	EthCashinWelTransDAO  dao.IEthCashinWelTransDAO
+	WelCashoutEthTransDAO dao.IWelCashoutEthTransDAO
+
+	// contract addresses and ABIs are the ones the event consumers use
+	eth *EthConsumer
+	wel *WelConsumer
+}
+
+func MkReconciler(ethCli ethListener.IEthClient, welCli welListener.IWelBlockClient, ethConsumer *EthConsumer, welConsumer *WelConsumer, daos *dao.DAOs) *Reconciler {
+	welTrans := welListener.NewTransHandler(welCli, 0)
+	welTrans.WatchAddress(welConsumer.ExportContractAddr)
+	welTrans.WatchAddress(welConsumer.ImportContractAddr)
+
+	return &Reconciler{
+		EthClient: ethCli,
+		WelTrans:  welTrans,
+		EthChunk:  DefaultReconcileEthChunk,
+		WelChunk:  DefaultReconcileWelChunk,
+
+		WelCashinEthTransDAO:  daos.WelCashinEthTransDAO,
+		EthCashoutWelTransDAO: daos.EthCashoutWelTransDAO,
+		EthCashinWelTransDAO:  daos.EthCashinWelTransDAO,
+		WelCashoutEthTransDAO: daos.WelCashoutEthTransDAO,
+
+		eth: ethConsumer,
+		wel: welConsumer,
+	}
+}
+
+func (r *Reconciler) ReconcileEth(ctx context.Context, from, to int64) (model.ReconcileChainReport, error) {
+	log := logger.Get()
+	report := model.ReconcileChainReport{Chain: model.ChainEth, FromBlock: from, ToBlock: to}
+	log.Info().Msgf("[reconciler] reconciling eth blocks %d to %d", from, to)
+
+	importAddr := common.HexToAddress(r.eth.ImportContractAddr)
+	mulsendAddr := common.HexToAddress(r.eth.MulsendContractAddr)
+	withdrawTopic := crypto.Keccak256Hash([]byte(r.eth.importAbi.Events["Withdraw"].Sig))
+	importedTopic := crypto.Keccak256Hash([]byte(r.eth.importAbi.Events["Imported"].Sig))
+	disperseTopic := crypto.Keccak256Hash([]byte(r.eth.mulsendAbi.Events["Disperse"].Sig))
+
+	var events []chainEvent
+	for begin := from; begin <= to; begin += r.EthChunk {
+		end := begin + r.EthChunk - 1
+		if end > to {
+			end = to
+		}
+		logs, err := r.EthClient.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: big.NewInt(begin),
+			ToBlock:   big.NewInt(end),
+			Addresses: []common.Address{importAddr, mulsendAddr},
+			Topics:    [][]common.Hash{{withdrawTopic, importedTopic, disperseTopic}},
+		})
+		if err != nil {
+			log.Err(err).Msgf("[reconciler] can't get eth logs of blocks %d to %d", begin, end)
+			return report, err
+		}
+
+		for _, l := range logs {
+			if l.Removed || len(l.Topics) == 0 {
+				continue
+			}
+			var evs []chainEvent
+			switch {
+			case l.Address == importAddr && l.Topics[0] == withdrawTopic:
+				evs, err = r.ethImportWithdraw(l)
+			case l.Address == importAddr && l.Topics[0] == importedTopic:
+				evs, err = r.ethImportImported(l)
+			case l.Address == mulsendAddr && l.Topics[0] == disperseTopic:
+				evs, err = r.ethDisperse(l)
+			default:
+				continue
+			}
+			if err != nil {
+				log.Err(err).Msgf("[reconciler] can't decode eth log %d of tx %s", l.Index, l.TxHash.Hex())
+				return report, err
+			}
+			events = append(events, evs...)
+			report.Events++
+		}
+	}
+
+	issues, err := diffEvents(events, r.lookup)
+	if err != nil {
+		log.Err(err).Msg("[reconciler] can't diff eth events against records")
+		return report, err
+	}
+	report.Issues = issues
+	log.Info().Msgf("[reconciler] eth blocks %d to %d: %d events, %d issues", from, to, report.Events, len(issues))
+	return report, nil
+}
+
+func (r *Reconciler) ReconcileWel(ctx context.Context, from, to int64) (model.ReconcileChainReport, error) {
+	log := logger.Get()
+	report := model.ReconcileChainReport{Chain: model.ChainWel, FromBlock: from, ToBlock: to}
+	log.Info().Msgf("[reconciler] reconciling wel blocks %d to %d", from, to)
+
+	exportWithdrawTopic := crypto.Keccak256Hash([]byte(r.wel.exportAbi.Events["Withdraw"].Sig))
+	returnedTopic := crypto.Keccak256Hash([]byte(r.wel.exportAbi.Events["Returned"].Sig))
+	importWithdrawTopic := crypto.Keccak256Hash([]byte(r.wel.importAbi.Events["Withdraw"].Sig))
+	importedTopic := crypto.Keccak256Hash([]byte(r.wel.importAbi.Events["Imported"].Sig))
+
+	var events []chainEvent
+	for begin := from; begin <= to; begin += r.WelChunk {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		end := begin + r.WelChunk - 1
+		if end > to {
+			end = to
+		}
+		trans, err := r.WelTrans.CollectRange(begin, end)
+		if err != nil {
+			log.Err(err).Msgf("[reconciler] can't get wel transactions of blocks %d to %d", begin, end)
+			return report, err
+		}
+
+		for _, t := range trans {
+			for logpos, l := range t.Log {
+				if len(l.Topics) == 0 {
+					continue
+				}
+				topic := l.Topics[0]
+				var evs []chainEvent
+				switch {
+				case t.ContractAddress == r.wel.ExportContractAddr && bytes.Equal(topic, exportWithdrawTopic.Bytes()):
+					evs, err = r.welExportWithdraw(t, logpos, l.Data)
+				case t.ContractAddress == r.wel.ExportContractAddr && bytes.Equal(topic, returnedTopic.Bytes()):
+					evs, err = r.welExportReturned(t, logpos, l.Data)
+				case t.ContractAddress == r.wel.ImportContractAddr && bytes.Equal(topic, importWithdrawTopic.Bytes()):
+					evs, err = r.welImportWithdraw(t, logpos, l.Data)
+				case t.ContractAddress == r.wel.ImportContractAddr && bytes.Equal(topic, importedTopic.Bytes()):
+					evs, err = r.welImportImported(t, logpos, l.Data)
+				default:
+					continue
+				}
+				if err != nil {
+					log.Err(err).Msgf("[reconciler] can't decode wel log %d of tx %s", logpos, t.Hash)
+					return report, err
+				}
+				events = append(events, evs...)
+				report.Events++
+			}
+		}
+	}
+
+	issues, err := diffEvents(events, r.lookup)
+	if err != nil {
+		log.Err(err).Msg("[reconciler] can't diff wel events against records")
+		return report, err
+	}
+	report.Issues = issues
+	log.Info().Msgf("[reconciler] wel blocks %d to %d: %d events, %d issues", from, to, report.Events, len(issues))
+	return report, nil
+}
+
+func unpackLog(contract abi.ABI, event string, data []byte) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	if err := contract.UnpackIntoMap(values, event, data); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func welAddress(raw []byte) string {
+	addr, _ := libs.HexToB58("0x41" + GotronCommon.Bytes2Hex(raw))
+	return addr
+}
+
+func (r *Reconciler) ethImportWithdraw(l types.Log) ([]chainEvent, error) {
+	data, err := unpackLog(r.eth.importAbi, "Withdraw", l.Data)
+	if err != nil {
+		return nil, err
+	}
+	return []chainEvent{{
+		Chain:       model.ChainEth,
+		BlockNumber: int64(l.BlockNumber),
+		TxHash:      l.TxHash.Hex(),
+		LogIndex:    int64(l.Index),
+		Event:       eventImportWithdraw,
+		Table:       tableEthCashoutWel,
+		Key:         l.TxHash.Hex(),
+		Amount:      data["amount"].(*big.Int).String(),
+	}}, nil
+}
+
+func (r *Reconciler) ethImportImported(l types.Log) ([]chainEvent, error) {
+	data, err := unpackLog(r.eth.importAbi, "Imported", l.Data)
+	if err != nil {
+		return nil, err
+	}
+	if len(l.Topics) < 2 {
+		return nil, fmt.Errorf("missing request id topic")
+	}
+	return []chainEvent{{
+		Chain:       model.ChainEth,
+		BlockNumber: int64(l.BlockNumber),
+		TxHash:      l.TxHash.Hex(),
+		LogIndex:    int64(l.Index),
+		Event:       eventImportImported,
+		Table:       tableWelCashinEth,
+		Key:         new(big.Int).SetBytes(l.Topics[1].Bytes()).String(),
+		Amount:      data["amount"].(*big.Int).String(),
+	}}, nil
+}
+
+func (r *Reconciler) ethDisperse(l types.Log) ([]chainEvent, error) {
+	data, err := unpackLog(r.eth.mulsendAbi, "Disperse", l.Data)
+	if err != nil {
+		return nil, err
+	}
+	// remains don't tell the dispersed amounts, records are only matched per receiver
+	return libs.Map(func(receiver common.Address) chainEvent {
+		return chainEvent{
+			Chain:       model.ChainEth,
+			BlockNumber: int64(l.BlockNumber),
+			TxHash:      l.TxHash.Hex(),
+			LogIndex:    int64(l.Index),
+			Event:       eventDisperse,
+			Table:       tableWelCashoutEth,
+			Key:         l.TxHash.Hex(),
+			Receiver:    receiver.Hex(),
+		}
+	}, data["receivers"].([]common.Address)), nil
+}
+
+func (r *Reconciler) welExportWithdraw(t *welListener.Transaction, logpos int, data []byte) ([]chainEvent, error) {
+	values, err := unpackLog(r.wel.exportAbi, "Withdraw", data)
+	if err != nil {
+		return nil, err
+	}
+	return []chainEvent{{
+		Chain:       model.ChainWel,
+		BlockNumber: t.BlockNumber,
+		TxHash:      t.Hash,
+		LogIndex:    int64(logpos),
+		Event:       eventExportWithdraw,
+		Table:       tableWelCashinEth,
+		Key:         t.Hash,
+		Amount:      values["amount"].(*big.Int).String(),
+	}}, nil
+}
+
+func (r *Reconciler) welExportReturned(t *welListener.Transaction, logpos int, data []byte) ([]chainEvent, error) {
+	values, err := unpackLog(r.wel.exportAbi, "Returned", data)
+	if err != nil {
+		return nil, err
+	}
+	// the E2W cashout recorded amount includes the fee
+	total := new(big.Int).Add(values["amount"].(*big.Int), values["fee"].(*big.Int))
+	return []chainEvent{{
+		Chain:       model.ChainWel,
+		BlockNumber: t.BlockNumber,
+		TxHash:      t.Hash,
+		LogIndex:    int64(logpos),
+		Event:       eventExportReturned,
+		Table:       tableEthCashoutWel,
+		Key:         values["requestId"].(*big.Int).String(),
+		Amount:      total.String(),
+	}}, nil
+}
+
+func (r *Reconciler) welImportWithdraw(t *welListener.Transaction, logpos int, data []byte) ([]chainEvent, error) {
+	values, err := unpackLog(r.wel.importAbi, "Withdraw", data)
+	if err != nil {
+		return nil, err
+	}
+	return []chainEvent{{
+		Chain:       model.ChainWel,
+		BlockNumber: t.BlockNumber,
+		TxHash:      t.Hash,
+		LogIndex:    int64(logpos),
+		Event:       eventImportWithdraw,
+		Table:       tableWelCashoutEth,
+		Key:         t.Hash,
+		Amount:      values["amount"].(*big.Int).String(),
+	}}, nil
+}
+
+func (r *Reconciler) welImportImported(t *welListener.Transaction, logpos int, data []byte) ([]chainEvent, error) {
+	values, err := unpackLog(r.wel.importAbi, "Imported", data)
+	if err != nil {
+		return nil, err
+	}
+	receivers := values["receivers"].([]common.Address)
+	amounts := values["amounts"].([]*big.Int)
+	if len(receivers) != len(amounts) {
+		return nil, fmt.Errorf("%d receivers for %d amounts", len(receivers), len(amounts))
+	}
+	events := make([]chainEvent, len(receivers))
+	for i, receiver := range receivers {
+		events[i] = chainEvent{
+			Chain:       model.ChainWel,
+			BlockNumber: t.BlockNumber,
+			TxHash:      t.Hash,
+			LogIndex:    int64(logpos),
+			Event:       eventImportImported,
+			Table:       tableEthCashinWel,
+			Key:         t.Hash,
+			Receiver:    welAddress(receiver.Bytes()),
+			Amount:      amounts[i].String(),
+		}
+	}
+	return events, nil
+}
+
+// lookup gets the records an event is expected to have been saved to, claims only count
+// once their claim tx is recorded
+func (r *Reconciler) lookup(ev chainEvent) ([]bridgeRecord, error) {
+	switch ev.Table {
+	case tableWelCashinEth:
+		var tran *model.WelCashinEthTrans
+		var err error
+		if ev.Event == eventImportImported {
+			tran, err = r.WelCashinEthTransDAO.SelectTransByRqId(ev.Key)
+		} else {
+			tran, err = r.WelCashinEthTransDAO.SelectTransByDepositEvent(ev.Key, ev.LogIndex)
+		}
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if ev.Event == eventImportImported {
+			if !strings.EqualFold(tran.ClaimTxHash, ev.TxHash) {
+				return nil, nil
+			}
+			return []bridgeRecord{{Amount: tran.DestAmount}}, nil
+		}
+		return []bridgeRecord{{Amount: tran.Amount}}, nil
+
+	case tableEthCashoutWel:
+		var tran *model.EthCashoutWelTrans
+		var err error
+		if ev.Event == eventExportReturned {
+			tran, err = r.EthCashoutWelTransDAO.SelectTransByRqId(ev.Key)
+		} else {
+			tran, err = r.EthCashoutWelTransDAO.SelectTransByDepositEvent(ev.Key, ev.LogIndex)
+		}
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if ev.Event == eventExportReturned {
+			if !strings.EqualFold(tran.ClaimTxHash, ev.TxHash) {
+				return nil, nil
+			}
+			// the claim records what it delivered and charged in wel token units
+			return []bridgeRecord{{Amount: addAmounts(tran.DestAmount, tran.Fee)}}, nil
+		}
+		return []bridgeRecord{{Amount: tran.Amount}}, nil
+
+	case tableEthCashinWel:
+		trans, err := r.EthCashinWelTransDAO.SelectTransByIssueTxHash(ev.Key)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		return libs.Map(func(tran *model.EthCashinWelTrans) bridgeRecord {
+			return bridgeRecord{Receiver: tran.WelWalletAddr, Amount: tran.DestAmount}
+		}, trans), nil
+
+	case tableWelCashoutEth:
+		if ev.Event == eventDisperse {
+			trans, err := r.WelCashoutEthTransDAO.SelectTransByDisperseTxHash(ev.Key)
+			if err != nil && err != sql.ErrNoRows {
+				return nil, err
+			}
+			return libs.Map(func(tran *model.WelCashoutEthTrans) bridgeRecord {
+				return bridgeRecord{Receiver: tran.EthWalletAddr, Amount: tran.DestAmount}
+			}, trans), nil
+		}
+		tran, err := r.WelCashoutEthTransDAO.SelectTransByWithdrawEvent(ev.Key, ev.LogIndex)
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return []bridgeRecord{{Amount: tran.Amount}}, nil
+	}
+	return nil, fmt.Errorf("unknown table %s", ev.Table)
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"bridge/micros/weleth/model"
+	"testing"
+)
+
+func TestDiffEvents(t *testing.T) {
+	records := map[string][]bridgeRecord{
+		"0xdeposit": {{Amount: "100"}},
+		"0xwrong":   {{Amount: "90"}},
+		"0xtwice":   {{Amount: "5"}},
+		"0xdisperse": {
+			{Receiver: "0xAAAA", Amount: "10"},
+			{Receiver: "0xbbbb", Amount: "20"},
+			{Receiver: "0xbbbb", Amount: "20"},
+		},
+		"42": {{Amount: "7"}},
+	}
+	lookup := func(ev chainEvent) ([]bridgeRecord, error) {
+		return records[ev.Key], nil
+	}
+
+	events := []chainEvent{
+		{TxHash: "0xdeposit", Key: "0xdeposit", Table: tableEthCashoutWel, Amount: "100"},
+		{TxHash: "0xwrong", Key: "0xwrong", Table: tableEthCashoutWel, Amount: "100"},
+		{TxHash: "0xmissing", Key: "0xmissing", Table: tableEthCashoutWel, Amount: "100"},
+		// two deposits in one tx are distinct events
+		{TxHash: "0xtwice", Key: "0xtwice", LogIndex: 0, Table: tableEthCashoutWel, Amount: "5"},
+		{TxHash: "0xtwice", Key: "0xtwice", LogIndex: 3, Table: tableEthCashoutWel, Amount: "5"},
+		// matched per receiver, case insensitively
+		{TxHash: "0xdisperse", Key: "0xdisperse", Table: tableWelCashoutEth, Receiver: "0xaaaa"},
+		{TxHash: "0xdisperse", Key: "0xdisperse", Table: tableWelCashoutEth, Receiver: "0xBBBB"},
+		{TxHash: "0xdisperse", Key: "0xdisperse", Table: tableWelCashoutEth, Receiver: "0xcccc"},
+		// request 42 claimed twice
+		{TxHash: "0xclaim1", Key: "42", Table: tableWelCashinEth, Amount: "7"},
+		{TxHash: "0xclaim2", Key: "42", Table: tableWelCashinEth, Amount: "7"},
+	}
+
+	issues, err := diffEvents(events, lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		txHash, key, kind string
+	}{
+		{"0xwrong", "", model.ReconcileAmountMismatch},
+		{"0xmissing", "", model.ReconcileMissing},
+		{"0xdisperse", "0xBBBB", model.ReconcileDuplicated},
+		{"0xdisperse", "0xcccc", model.ReconcileMissing},
+		{"0xclaim1", "42", model.ReconcileDuplicated},
+	}
+	if len(issues) != len(expected) {
+		t.Fatalf("expected %d issues, got %d: %+v", len(expected), len(issues), issues)
+	}
+	for i, e := range expected {
+		if issues[i].TxHash != e.txHash || issues[i].Key != e.key || issues[i].Kind != e.kind {
+			t.Errorf("issue %d: expected %s %s %s, got %+v", i, e.txHash, e.key, e.kind, issues[i])
+		}
+	}
+}
+
+func TestSameAmount(t *testing.T) {
+	if !sameAmount("0100", "100") {
+		t.Errorf("amounts should be compared as numbers")
+	}
+	if sameAmount("100", "101") {
+		t.Errorf("different amounts reported equal")
+	}
+}
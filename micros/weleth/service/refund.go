@@ -0,0 +1,56 @@
+package service
+
+import (
+	"bridge/common/consts"
+	"bridge/micros/weleth/dao"
+	"bridge/service-managers/logger"
+	"context"
+	"time"
+)
+
+var DefaultExpiryCheckInterval = 10 * time.Minute
+
+// Tx2TreasuryExpiry expires the deposits to the treasury left without a cashin request for
+// longer than Window, they are then refunded once an admin approves it
+type Tx2TreasuryExpiry struct {
+	DAO      dao.IRefundDAO
+	Window   time.Duration
+	Interval time.Duration
+}
+
+func MkTx2TreasuryExpiry(window time.Duration, daos *dao.DAOs) *Tx2TreasuryExpiry {
+	return &Tx2TreasuryExpiry{
+		DAO:      daos.RefundDAO,
+		Window:   window,
+		Interval: DefaultExpiryCheckInterval,
+	}
+}
+
+func (e *Tx2TreasuryExpiry) expire(now time.Time) {
+	log := logger.Get()
+	refunds, err := e.DAO.ExpireTx2Treasury(now.Add(-e.Window))
+	if err != nil {
+		log.Err(err).Msg("[tx2treasury expiry] can't expire txs to treasury")
+		return
+	}
+	for _, r := range refunds {
+		log.Info().Msgf("[tx2treasury expiry] tx to treasury %s:%d expired, refund of %s token %s to %s pending approval", r.TxID, r.LogIndex, r.Amount, r.TokenAddr, r.ToAddress)
+	}
+}
+
+// Expire is a daemon generator periodically expiring unmatched deposits
+func (e *Tx2TreasuryExpiry) Expire(ctx context.Context) (consts.Daemon, error) {
+	return func() {
+		logger.Get().Info().Msgf("[tx2treasury expiry] Start expiring txs to treasury older than %s", e.Window)
+		for {
+			consts.SleepContext(ctx, e.Interval)
+			select {
+			case <-ctx.Done():
+				logger.Get().Info().Msg("[tx2treasury expiry] Stop expiring txs to treasury")
+				return
+			default:
+				e.expire(time.Now())
+			}
+		}
+	}, nil
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"bridge/micros/weleth/dao"
+	"bridge/micros/weleth/model"
+	"testing"
+	"time"
+)
+
+type fakeRefundDAO struct {
+	dao.IRefundDAO
+	before time.Time
+}
+
+func (f *fakeRefundDAO) ExpireTx2Treasury(before time.Time) ([]model.Refund, error) {
+	f.before = before
+	return []model.Refund{model.RefundOfTx2Treasury(model.TxToTreasury{TxID: "0xabc", Amount: "1"})}, nil
+}
+
+func TestTx2TreasuryExpiry(t *testing.T) {
+	fake := &fakeRefundDAO{}
+	e := MkTx2TreasuryExpiry(24*time.Hour, &dao.DAOs{RefundDAO: fake})
+
+	now := time.Date(2022, 6, 2, 12, 0, 0, 0, time.UTC)
+	e.expire(now)
+	if !fake.before.Equal(time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected deposits older than the window to expire, got cutoff %s", fake.before)
+	}
+}
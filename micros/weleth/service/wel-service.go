@@ -2,12 +2,10 @@ package service
 
 import (
 	"bridge/libs"
-	coreEthService "bridge/micros/core/service/eth"
 	"bridge/micros/weleth/dao"
 	"bridge/micros/weleth/model"
 	welListener "bridge/service-managers/listener/wel"
 	"bridge/service-managers/logger"
-	"context"
 	"database/sql"
 	"fmt"
 	"math/big"
@@ -33,6 +31,10 @@ type WelConsumer struct {
 	WelCashoutEthTransDAO dao.IWelCashoutEthTransDAO
 	importAbi             abi.ABI
 
+	FeeRuleDAO dao.IFeeRuleDAO
+	Tokens     *dao.TokenCache
+	Ledger     *EventLedger
+
 	tempCli client.Client
 }
 
@@ -72,6 +74,10 @@ func NewWelConsumer(iaddr, eaddr string, tempCli client.Client, daos *dao.DAOs)
 		WelCashoutEthTransDAO: daos.WelCashoutEthTransDAO,
 		importAbi:             importabi,
 
+		FeeRuleDAO: daos.FeeRuleDAO,
+		Tokens:     daos.Tokens,
+		Ledger:     MkEventLedger(daos),
+
 		tempCli: tempCli,
 	}
 }
@@ -83,7 +89,7 @@ func (e *WelConsumer) GetConsumer() ([]*welListener.EventConsumer, error) {
 			Topic: crypto.Keccak256Hash(
 				[]byte(e.exportAbi.Events["Withdraw"].Sig),
 			),
-			ParseEvent: e.DoneDepositParser,
+			ParseEvent: e.Ledger.OnceWel("Withdraw", e.DoneDepositParser),
 		},
 		{
 			Address: e.ExportContractAddr,
@@ -91,7 +97,7 @@ func (e *WelConsumer) GetConsumer() ([]*welListener.EventConsumer, error) {
 				[]byte(e.exportAbi.Events["Returned"].Sig),
 			),
 
-			ParseEvent: e.DoneReturnParser,
+			ParseEvent: e.Ledger.OnceWel("Returned", e.DoneReturnParser),
 		},
 		{
 			Address: e.ImportContractAddr,
@@ -99,7 +105,7 @@ func (e *WelConsumer) GetConsumer() ([]*welListener.EventConsumer, error) {
 				[]byte(e.importAbi.Events["Imported"].Sig),
 			),
 
-			ParseEvent: e.DoneImportedParser,
+			ParseEvent: e.Ledger.OnceWel("Imported", e.DoneImportedParser),
 		},
 		{
 			Address: e.ImportContractAddr,
@@ -107,17 +113,15 @@ func (e *WelConsumer) GetConsumer() ([]*welListener.EventConsumer, error) {
 				[]byte(e.importAbi.Events["Withdraw"].Sig),
 			),
 
-			ParseEvent: e.DoneIWithdrawParser,
+			ParseEvent: e.Ledger.OnceWel("IWithdraw", e.DoneIWithdrawParser),
 		},
 	}, nil
 }
 
 func (e *WelConsumer) DoneIWithdrawParser(t *welListener.Transaction, logpos int) error {
 	logger.Get().Info().Msgf("[DoneIWithdrawEV] IWithdraw event caught at block %d", t.BlockNumber)
-	if t.Status != "confirmed" {
-		logger.Get().Info().Msg("[DoneIWithdraw] unconfirmed transaction, skipped")
-		return nil
-	}
+	// recorded as soon as seen, the confirmation tracker sends it to BatchDisperse once
+	// it's buried under enough blocks
 	data := make(map[string]interface{})
 	e.importAbi.UnpackIntoMap(
 		data,
@@ -136,32 +140,63 @@ func (e *WelConsumer) DoneIWithdrawParser(t *welListener.Transaction, logpos int
 	tx.Total = _total.String()
 
 	tx.WelTokenAddr, _ = libs.HexToB58("0x41" + GotronCommon.Bytes2Hex(t.Log[logpos].Topics[1][12:]))
-	tx.EthTokenAddr = model.EthTokenFromWel[tx.WelTokenAddr]
+	tx.EthTokenAddr = e.Tokens.EthFromWel(tx.WelTokenAddr)
+
+	// the bridge's fee schedule, if any, overrides the fee charged by the contract
+	if err := e.applyFeeSchedule(&tx); err != nil {
+		logger.Get().Err(err).Msgf("[DoneIWithdraw] can't apply fee schedule to W2E cashout %s", t.Hash)
+		return err
+	}
+	// dispersed in eth token units, the rounding dust is charged as commission fee
+	n, err := e.Tokens.Normalize(tx.WelTokenAddr, model.FeeWelToEth, tx.Amount)
+	if err != nil {
+		logger.Get().Err(err).Msgf("[DoneIWithdraw] can't convert W2E cashout %s amount to eth token units", t.Hash)
+		return err
+	}
+	quote := model.FeeQuote{Total: tx.Total, Amount: tx.Amount, CommissionFee: tx.CommissionFee}.Settle(n)
+	tx.Amount, tx.CommissionFee, tx.DestAmount, tx.Dust = quote.Amount, quote.CommissionFee, quote.DestAmount, quote.Dust
 
 	tx.NetworkID = (&big.Int{}).SetBytes(t.Log[logpos].Topics[3]).String()
 
-	tx.CashoutStatus = model.WelCashoutEthConfirmed
+	tx.CashoutStatus = model.WelCashoutEthPendingConfirmation
 	tx.DisperseStatus = model.WelCashoutEthUnconfirmed
+	tx.WithdrawBlockNumber = t.BlockNumber
+	tx.Confirmations = t.NumOfBlocks + 1
 
 	tx.WelWithdrawTxHash = t.Hash
+	tx.LogIndex = int64(logpos)
 	logger.Get().Info().Msgf("IWithdraw transaction to be created: %+v", tx)
 
 	// save tx
-	id, err := e.WelCashoutEthTransDAO.CreateWelCashoutEthTrans(&tx)
+	_, err = e.WelCashoutEthTransDAO.CreateWelCashoutEthTrans(&tx)
+	if err == model.ErrAlreadyRecorded {
+		logger.Get().Info().Msg("[DoneIWithdraw] transaction already recorded: " + t.Hash)
+		return nil
+	}
 	if err != nil {
 		logger.Get().Err(err).Msgf("[DoneIWithdraw] can't create W2E cashout transaction %s", t.Hash)
 		return err
 	}
-	tx.ID = id
 
-	// send signal to batch tx
-	logger.Get().Info().Msgf("IWithdraw transaction to be sent to BatchDisperse: %+v", tx)
-	err = e.tempCli.SignalWorkflow(context.Background(), coreEthService.BatchDisperseID, "", coreEthService.BatchDisperseSignal, tx)
+	return nil
+}
+
+// applyFeeSchedule charges the W2E cashout the wel token's commission on its total, the
+// on-chain fee is kept when no schedule is set for the token
+func (e *WelConsumer) applyFeeSchedule(tx *model.WelCashoutEthTrans) error {
+	if e.FeeRuleDAO == nil {
+		return nil
+	}
+	schedule, err := e.FeeRuleDAO.SelectFeeSchedule(tx.WelTokenAddr, model.FeeWelToEth)
+	if err != nil || len(schedule) == 0 {
+		return err
+	}
+	quote, err := schedule.Quote(tx.Total)
 	if err != nil {
-		logger.Get().Err(err).Msgf("[DoneIWithdraw] Error sending BatchDisperseWF tx %+v", tx)
 		return err
 	}
-
+	tx.Amount = quote.Amount
+	tx.CommissionFee = quote.CommissionFee
 	return nil
 }
 
@@ -222,16 +257,14 @@ func (e *WelConsumer) DoneImportedParser(t *welListener.Transaction, logpos int)
 		if welTokenAddr != tran.WelTokenAddr {
 			tran.WelTokenAddr = welTokenAddr
 		}
+		// the issued amount is in wel token units, the commission fee was settled in eth
+		// token units when the cashin got recorded
 		amount := &big.Int{}
 		amount.SetString(amountOfReceiver[tran.WelWalletAddr], 10)
-		tran.Amount = amount.String()
-
-		total := big.NewInt(0)
-		total.SetString(tran.Total, 10)
-
-		fee := &big.Int{}
-		fee = fee.Sub(total, amount)
-		tran.CommissionFee = fee.String()
+		if amount.String() != tran.DestAmount {
+			logger.Get().Warn().Msgf("[ImportedEV] issued %s to %s, %s expected", amount.String(), tran.WelWalletAddr, tran.DestAmount)
+		}
+		tran.DestAmount = amount.String()
 
 		tran.Status = confirmStatus
 		tran.IssuedAt = sql.NullTime{Time: time.Now(), Valid: true}
@@ -267,7 +300,13 @@ func (e *WelConsumer) DoneReturnParser(t *welListener.Transaction, logpos int) e
 	if err != nil {
 		return err
 	}
-	if total.String() != tran.Amount { // if amount + fee != originally cashed out amount
+	// amount + fee must be the originally cashed out amount in wel token units, which the
+	// claim itself replaces by the amount delivered
+	expected := tran.DestAmount
+	if tran.ClaimTxHash == t.Hash {
+		expected = addAmounts(tran.DestAmount, tran.Fee)
+	}
+	if total.String() != expected {
 		return fmt.Errorf("Claim wrong amount")
 	}
 	if tran.WelWalletAddr != welWalletAddr {
@@ -321,11 +360,7 @@ func (e *WelConsumer) DoneDepositParser(t *welListener.Transaction, logpos int)
 
 	var networkID = &big.Int{}
 
-	mkEventRecord := func(status string) (*model.WelEthEvent, error) {
-		if status != model.StatusSuccess && status != model.StatusUnknown {
-			return nil, fmt.Errorf(`Event status is neither "confirmed" nor "unconfirmed"`)
-		}
-
+	mkEventRecord := func() *model.WelEthEvent {
 		welTokenAddr, _ := libs.HexToB58("0x41" + GotronCommon.Bytes2Hex(t.Log[logpos].Topics[1][12:]))
 		event := &model.WelEthEvent{
 			WelTokenAddr:  welTokenAddr,
@@ -340,72 +375,32 @@ func (e *WelConsumer) DoneDepositParser(t *welListener.Transaction, logpos int)
 		//event.ID = crypto.Keccak256Hash(m).Big().String()
 
 		event.DepositTxHash = t.Hash
+		event.LogIndex = int64(logpos)
 		event.WelWalletAddr, _ = libs.HexToB58("0x41" + GotronCommon.Bytes2Hex(t.Log[logpos].Topics[2][12:]))
 		event.Amount = amount
-		event.EthTokenAddr = model.EthTokenFromWel[welTokenAddr]
-		event.DepositStatus = status
+		event.EthTokenAddr = e.Tokens.EthFromWel(welTokenAddr)
 		event.Fee = fee
+		// claimed in eth token units, amount comes from the chain thus is always valid
+		n, _ := e.Tokens.Normalize(welTokenAddr, model.FeeWelToEth, amount)
+		event.DestAmount, event.Dust = n.DestAmount, n.Dust
 
-		return event, nil
-	}
-
-	switch t.Status {
-	case "unconfirmed":
-		// NOTE: if front end can't get txHash then we will need to fix this
-		_, err := e.WelCashinEthTransDAO.SelectTransByDepositTxHash(t.Hash)
-		if err != nil && err != sql.ErrNoRows {
-			return err
-		}
-
-		if err == sql.ErrNoRows {
-			// somehow, we did not save this deposit to db before
-			event, err := mkEventRecord(model.StatusUnknown)
-			if err != nil {
-				logger.Get().Err(err).Msg("[DoneDeposit] can't create new event record")
-				return err
-			}
-
-			err = e.WelCashinEthTransDAO.CreateWelCashinEthTrans(event)
-			if err != nil {
-				logger.Get().Err(err).Msg("[DoneDeposit] can't create new transaction")
-				return err
-			}
-		}
-		logger.Get().Info().Msg("[DoneDeposit] unconfirmed cashin transaction: " + t.Hash)
+		// recorded as soon as seen, the confirmation tracker confirms it once it's buried
+		// under enough blocks
+		event.DepositStatus = model.StatusPendingConfirmation
+		event.DepositBlockNumber = t.BlockNumber
+		event.Confirmations = t.NumOfBlocks + 1
 
-	case "confirmed":
-		tran, err := e.WelCashinEthTransDAO.SelectTransByDepositTxHash(t.Hash)
-		if err != nil && err != sql.ErrNoRows {
-			return err
-		}
-		if err == sql.ErrNoRows {
-			// somehow, we did not save this deposit to db before
-			event, err := mkEventRecord(model.StatusSuccess)
-			if err != nil {
-				logger.Get().Err(err).Msg("[DoneDeposit] can't create new event record")
-				return err
-			}
-
-			err = e.WelCashinEthTransDAO.CreateWelCashinEthTrans(event)
-			if err != nil {
-				logger.Get().Err(err).Msg("[DoneDeposit] can't create new transaction")
-				return err
-			}
-		} else {
-			if tran.DepositStatus != model.StatusSuccess {
-				welWalletAddr, _ := libs.HexToB58("0x41" + GotronCommon.Bytes2Hex(t.Log[logpos].Topics[2][12:]))
-				logger.Get().Info().Msg("[DoneDeposit] Update confirmed cashin transaction...")
-				err := e.WelCashinEthTransDAO.UpdateDepositWelCashinEthConfirmed(t.Hash, welWalletAddr, amount, fee)
-				if err != nil {
-					return err
-				}
-			}
-		}
+		return event
+	}
 
-	// emit done deposit event, save to db
-	default:
-		return fmt.Errorf("unknown status")
+	// NOTE: if front end can't get txHash then we will need to fix this
+	// a deposit already recorded is left as is
+	err := e.WelCashinEthTransDAO.CreateWelCashinEthTrans(mkEventRecord())
+	if err != nil {
+		logger.Get().Err(err).Msg("[DoneDeposit] can't create new transaction")
+		return err
 	}
+	logger.Get().Info().Msg("[DoneDeposit] cashin transaction pending confirmation: " + t.Hash)
 
 	return nil
 }
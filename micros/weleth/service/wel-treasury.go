@@ -0,0 +1,55 @@
+package service
+
+import (
+	"bridge/micros/weleth/dao"
+	"bridge/micros/weleth/model"
+	welListener "bridge/service-managers/listener/wel"
+	"bridge/service-managers/logger"
+	"strconv"
+	"time"
+)
+
+// WelTreasuryMonitor records the WEL and TRC20 transfers to the Welups treasury, to be
+// requested as W2E cashouts
+type WelTreasuryMonitor struct {
+	treasury_address  string
+	WelTx2TreasuryDAO dao.IWelTx2TreasuryDAO
+}
+
+func MkWelTreasuryMonitor(address string, daos *dao.DAOs) welListener.ITxMonitor {
+	return &WelTreasuryMonitor{
+		treasury_address:  address,
+		WelTx2TreasuryDAO: daos.WelTx2TreasuryDAO,
+	}
+}
+
+func (tm *WelTreasuryMonitor) MonitoredAddress() string {
+	return tm.treasury_address
+}
+
+func (tm *WelTreasuryMonitor) TxParse(t *welListener.Transaction, logpos int, from, to, tokenAddr, amount string) error {
+	logger.Get().Info().Msgf("transaction to wel treasury: %s:%d", t.Hash, logpos)
+	tx2treasury := &model.TxToTreasury{}
+
+	tx2treasury.TxID = t.Hash
+	tx2treasury.LogIndex = int64(logpos)
+	tx2treasury.FromAddress = from
+	tx2treasury.TreasuryAddr = to
+	tx2treasury.TokenAddr = tokenAddr
+	tx2treasury.Amount = amount
+	tx2treasury.TxFee = strconv.FormatInt(t.Fee, 10)
+
+	// becomes eligible for cashout once the confirmation tracker sees it buried under
+	// enough blocks
+	tx2treasury.Status = model.Tx2TrPendingConfirmation
+	tx2treasury.BlockNumber = t.BlockNumber
+	tx2treasury.Confirmations = t.NumOfBlocks + 1
+	tx2treasury.CreatedAt = time.Now()
+	logger.Get().Info().Msgf("record wel tx to treasury: %+v\n", tx2treasury)
+	if err := tm.WelTx2TreasuryDAO.CreateWelTx2Treasury(tx2treasury); err != nil {
+		logger.Get().Err(err).Msg("Unable to record transaction to wel treasury")
+		return err
+	}
+	logger.Get().Info().Msg("Recorded transaction to wel treasury")
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"bridge/micros/weleth/config"
 	"bridge/micros/weleth/dao"
 	"bridge/micros/weleth/model"
+	"bridge/service-managers/leader"
 	"bridge/service-managers/logger"
 	"context"
 	"fmt"
@@ -54,6 +55,7 @@ const (
 	GetTx2TreasuryBySender  = "GetTx2TreasuryBySender"
 	CreateEthCashinWelTrans = "CreateEthCashinWelTrans"
 	UpdateEthCashinWelTrans = "UpdateEthCashinWelTrans"
+	SettleEthCashinWelIssue = "SettleEthCashinWelIssue"
 
 	CreateWelCashoutEthTrans = "CreateWelCashoutEthTrans"
 	UpdateWelCashoutEthTrans = "UpdateWelCashoutEthTrans"
@@ -85,8 +87,21 @@ type WelethBridgeService struct {
 	Eth2WelCashoutTransDAO dao.IEthCashoutWelTransDAO
 	Eth2WelCashinTransDAO  dao.IEthCashinWelTransDAO
 	Wel2EthCashoutTransDAO dao.IWelCashoutEthTransDAO
+	WelTx2TreasuryDAO      dao.IWelTx2TreasuryDAO
+	FeeRuleDAO             dao.IFeeRuleDAO
+	BridgeLimitDAO         dao.IBridgeLimitDAO
+	BridgeTokenDAO         dao.IBridgeTokenDAO
+	Tokens                 *dao.TokenCache
+	EventOutboxDAO         dao.IEventOutboxDAO
+	RefundDAO              dao.IRefundDAO
+	DisperseRetryDAO       dao.IDisperseRetryDAO
+	OutgoingTxDAO          dao.IOutgoingTxDAO
+	ListenerLeaseDAO       leader.ILeaseRepo
 	tempCli                client.Client
 	worker                 worker.Worker
+
+	// optional, the reconciliation workflow is only served when set
+	Reconciler IReconciler
 }
 
 // Service implementation
@@ -96,22 +111,32 @@ func MkWelethBridgeService(cli client.Client, daos *dao.DAOs) *WelethBridgeServi
 		Eth2WelCashoutTransDAO: daos.EthCashoutWelTransDAO,
 		Eth2WelCashinTransDAO:  daos.EthCashinWelTransDAO,
 		Wel2EthCashoutTransDAO: daos.WelCashoutEthTransDAO,
+		WelTx2TreasuryDAO:      daos.WelTx2TreasuryDAO,
+		FeeRuleDAO:             daos.FeeRuleDAO,
+		BridgeLimitDAO:         daos.BridgeLimitDAO,
+		BridgeTokenDAO:         daos.BridgeTokenDAO,
+		Tokens:                 daos.Tokens,
+		EventOutboxDAO:         daos.EventOutboxDAO,
+		RefundDAO:              daos.RefundDAO,
+		DisperseRetryDAO:       daos.DisperseRetryDAO,
+		OutgoingTxDAO:          daos.OutgoingTxDAO,
+		ListenerLeaseDAO:       daos.ListenerLeaseDAO,
 		tempCli:                cli,
 	}
 }
 
 func (s *WelethBridgeService) MapWelTokenToEth(ctx context.Context, welTk string) (string, error) {
-	ethTk, ok := model.EthTokenFromWel[welTk]
-	if !ok {
-		return "", fmt.Errorf("corresponding token not found")
+	ethTk := s.Tokens.EthFromWel(welTk)
+	if ethTk == "" {
+		return "", model.ErrTokenNotFound
 	}
 	return ethTk, nil
 }
 
 func (s *WelethBridgeService) MapEthTokenToWel(ctx context.Context, ethTk string) (string, error) {
-	welTk, ok := model.WelTokenFromEth[ethTk]
-	if !ok {
-		return "", fmt.Errorf("corresponding token not found")
+	welTk := s.Tokens.WelFromEth(ethTk)
+	if welTk == "" {
+		return "", model.ErrTokenNotFound
 	}
 	return welTk, nil
 }
@@ -127,25 +152,30 @@ func (s *WelethBridgeService) GetWelToEthCashin(ctx context.Context, sender, rec
 	return txs, nil
 }
 
-func (s *WelethBridgeService) GetWelToEthCashinByTxHash(ctx context.Context, txhash string) (tx model.WelCashinEthTrans, err error) {
+func (s *WelethBridgeService) GetWelToEthCashinByTxHash(ctx context.Context, txhash string, logIndex int64) (tx model.WelCashinEthTrans, err error) {
 	log := logger.Get()
 	log.Info().Msgf("[W2E transaction get] getting cashin transaction")
-	ct, err := s.Wel2EthCashinTransDAO.SelectTransByDepositTxHash(txhash)
+	ct, err := s.Wel2EthCashinTransDAO.SelectTransByDepositEvent(txhash, logIndex)
 	if err != nil {
-		log.Err(err).Msg("[W2E transaction get] failed to get cashin transaction: " + txhash)
+		log.Err(err).Msgf("[W2E transaction get] failed to get cashin transaction: %s:%d", txhash, logIndex)
 		return
 	}
 	return *ct, nil
 }
 
-func (s *WelethBridgeService) CreateW2ECashinClaimRequest(ctx context.Context, cashinTxHash string, userAddr string) (tx model.WelCashinEthTrans, err error) {
+func (s *WelethBridgeService) CreateW2ECashinClaimRequest(ctx context.Context, cashinTxHash string, userAddr string, logIndex int64) (tx model.WelCashinEthTrans, err error) {
 	log := logger.Get()
 	log.Info().Msgf("[W2E claim request] getting cashin transaction")
-	ct, err := s.Wel2EthCashinTransDAO.SelectTransByDepositTxHash(cashinTxHash)
+	ct, err := s.Wel2EthCashinTransDAO.SelectTransByDepositEvent(cashinTxHash, logIndex)
 	if err != nil {
 		log.Err(err).Msg("[W2E claim request] failed to get cashin transaction: " + cashinTxHash)
 		return
 	}
+	if ct.DepositStatus != model.StatusSuccess {
+		err = model.ErrNotConfirmed
+		log.Err(err).Msgf("[W2E claim request] %s not claimable yet, %d confirmations", cashinTxHash, ct.Confirmations)
+		return
+	}
 	switch ct.ClaimStatus {
 	case model.StatusSuccess:
 		err = model.ErrAlreadyClaimed
@@ -164,6 +194,11 @@ func (s *WelethBridgeService) CreateW2ECashinClaimRequest(ctx context.Context, c
 			log.Err(err).Msg("[W2E claim request] Inconsistent request")
 			return model.WelCashinEthTrans{}, err
 		}
+		limited := model.BridgeTransfer{Kind: model.TransferW2ECashinClaim, Ref: tx.DepositTxHash, LogIndex: tx.LogIndex, TokenAddr: tx.WelTokenAddr, Address: tx.WelWalletAddr, Amount: tx.Amount}
+		if err := s.checkLimit(limited); err != nil {
+			log.Err(err).Msgf("[W2E claim request] %s not claimable", cashinTxHash)
+			return model.WelCashinEthTrans{}, err
+		}
 		//if tx.EthTokenAddr != inTokenAddr {
 		//	err = fmt.Errorf("Inconsistent receiver address: %s != %s", inTokenAddr, tx.EthTokenAddr)
 		//	log.Err(err).Msg("[W2E claim request] Inconsistent request")
@@ -221,25 +256,30 @@ func (s *WelethBridgeService) GetEthToWelCashout(ctx context.Context, sender, re
 	return txs, nil
 }
 
-func (s *WelethBridgeService) GetEthToWelCashoutByTxHash(ctx context.Context, txhash string) (tx model.EthCashoutWelTrans, err error) {
+func (s *WelethBridgeService) GetEthToWelCashoutByTxHash(ctx context.Context, txhash string, logIndex int64) (tx model.EthCashoutWelTrans, err error) {
 	log := logger.Get()
 	log.Info().Msgf("[E2W transaction get] getting cashout transaction")
-	ct, err := s.Eth2WelCashoutTransDAO.SelectTransByDepositTxHash(txhash)
+	ct, err := s.Eth2WelCashoutTransDAO.SelectTransByDepositEvent(txhash, logIndex)
 	if err != nil {
-		log.Err(err).Msg("[E2W transaction get] failed to get cashout transaction: " + txhash)
+		log.Err(err).Msgf("[E2W transaction get] failed to get cashout transaction: %s:%d", txhash, logIndex)
 		return
 	}
 	return *ct, nil
 }
 
-func (s *WelethBridgeService) CreateE2WCashoutClaimRequest(ctx context.Context, cashoutTxHash string, userAddr string) (tx model.EthCashoutWelTrans, err error) {
+func (s *WelethBridgeService) CreateE2WCashoutClaimRequest(ctx context.Context, cashoutTxHash string, userAddr string, logIndex int64) (tx model.EthCashoutWelTrans, err error) {
 	log := logger.Get()
 	log.Info().Msgf("[E2W claim request] getting cashout transaction")
-	ct, err := s.Eth2WelCashoutTransDAO.SelectTransByDepositTxHash(cashoutTxHash)
+	ct, err := s.Eth2WelCashoutTransDAO.SelectTransByDepositEvent(cashoutTxHash, logIndex)
 	if err != nil {
 		log.Err(err).Msg("[E2W claim request] failed to get cashout transaction: " + cashoutTxHash)
 		return
 	}
+	if ct.DepositStatus != model.StatusSuccess {
+		err = model.ErrNotConfirmed
+		log.Err(err).Msgf("[E2W claim request] %s not claimable yet, %d confirmations", cashoutTxHash, ct.Confirmations)
+		return
+	}
 	switch ct.ClaimStatus {
 	case model.StatusSuccess:
 		err = model.ErrAlreadyClaimed
@@ -258,6 +298,11 @@ func (s *WelethBridgeService) CreateE2WCashoutClaimRequest(ctx context.Context,
 			log.Err(err).Msg("[E2W claim request] Inconsistent request")
 			return model.EthCashoutWelTrans{}, err
 		}
+		limited := model.BridgeTransfer{Kind: model.TransferE2WCashoutClaim, Ref: tx.DepositTxHash, LogIndex: tx.LogIndex, TokenAddr: tx.EthTokenAddr, Address: tx.EthWalletAddr, Amount: tx.Amount}
+		if err := s.checkLimit(limited); err != nil {
+			log.Err(err).Msgf("[E2W claim request] %s not claimable", cashoutTxHash)
+			return model.EthCashoutWelTrans{}, err
+		}
 		//if tx.WelTokenAddr != outTokenAddr {
 		//	err = fmt.Errorf("Inconsistent receiver address: %s != %s", outTokenAddr, tx.WelTokenAddr)
 		//	log.Err(err).Msg("[E2W claim request] Inconsistent request")
@@ -324,7 +369,7 @@ func (s *WelethBridgeService) GetEthToWelCashinWithTx2Treasury(ctx context.Conte
 		return
 	}
 	for _, _tx := range _txs {
-		tx2tr, err := s.Eth2WelCashinTransDAO.GetTx2TreasuryByTxHash(_tx.EthTxHash)
+		tx2tr, err := s.Eth2WelCashinTransDAO.GetTx2TreasuryByTxHash(_tx.EthTxHash, _tx.LogIndex)
 		if err != nil {
 			log.Err(err).Msg("[E2W transaction get] failed to get transaction to treasury for cashin transactions")
 			return nil, err
@@ -357,10 +402,10 @@ func (s *WelethBridgeService) GetWelToEthCashout(ctx context.Context, sender, re
 	return txs, nil
 }
 
-func (s *WelethBridgeService) GetWelToEthCashoutByTxHash(ctx context.Context, txhash string) (model.WelCashoutEthTrans, error) {
+func (s *WelethBridgeService) GetWelToEthCashoutByTxHash(ctx context.Context, txhash string, logIndex int64) (model.WelCashoutEthTrans, error) {
 	log := logger.Get()
 	log.Info().Msgf("[W2E get cashout tx] getting cashout transaction with eth tx hash %s", txhash)
-	tx, err := s.Wel2EthCashoutTransDAO.SelectTransByWithdrawTxHash(txhash)
+	tx, err := s.Wel2EthCashoutTransDAO.SelectTransByWithdrawEvent(txhash, logIndex)
 	if err != nil {
 		log.Err(err).Msgf("[W2E get cashout tx] failed to get cashout transaction with eth txhash %s", txhash)
 		return model.WelCashoutEthTrans{}, err
@@ -398,10 +443,12 @@ func (s *WelethBridgeService) GetUnconfirmedTx2Treasury(ctx context.Context, fro
 	return *t, nil
 }
 
-func (s *WelethBridgeService) GetUnconfirmedTx2TreasuryByTxHash(ctx context.Context, txhash string) (model.TxToTreasury, error) {
+// GetUnconfirmedTx2TreasuryByTxHash gets the deposit of txhash at logIndex, logIndex may be
+// left out for txs holding a single deposit
+func (s *WelethBridgeService) GetUnconfirmedTx2TreasuryByTxHash(ctx context.Context, txhash string, logIndex *int64) (model.TxToTreasury, error) {
 	log := logger.Get()
 	log.Info().Msgf("[E2W tx2treasury get] getting tx2treasury transaction with txhash " + txhash)
-	t, err := s.Eth2WelCashinTransDAO.GetUnconfirmedTx2TreasuryByTxHash(txhash)
+	t, err := s.Eth2WelCashinTransDAO.GetUnconfirmedTx2TreasuryByTxHash(txhash, logIndex)
 	if err != nil {
 		log.Err(err).Msg("[E2W tx2treasury get] failed to get tx2treasury transaction with txhash " + txhash)
 		return model.TxToTreasury{}, err
@@ -431,9 +478,41 @@ func (s *WelethBridgeService) UpdateEthCashinWelTrans(ctx context.Context, tx mo
 		log.Err(err).Msg("[E2W tx2treasury get] failed to update E2W cashin transaction")
 		return err
 	}
+	if tx.Status == model.EthCashinWelFailed {
+		refund := model.RefundOfCashin(tx)
+		if err := s.RefundDAO.CreateRefund(&refund); err != nil {
+			log.Err(err).Msgf("[E2W tx2treasury get] failed to create refund of failed E2W cashin %s", tx.EthTxHash)
+			return err
+		}
+		log.Info().Msgf("[E2W tx2treasury get] E2W cashin %s failed, refund pending approval", tx.EthTxHash)
+	}
 	return nil
 }
 
+// SettleEthCashinWelIssue records the outcome of issueTxHash on a cashin it issued, leaving
+// the cashin alone when it's no longer waiting on it. It tells whether the cashin was.
+func (s *WelethBridgeService) SettleEthCashinWelIssue(ctx context.Context, tx model.EthCashinWelTrans, issueTxHash string) (bool, error) {
+	log := logger.Get()
+	settled, err := s.Eth2WelCashinTransDAO.SettleEthCashinWelIssue(&tx, issueTxHash)
+	if err != nil {
+		log.Err(err).Msgf("[E2W issue] failed to settle issue %s of E2W cashin %s", issueTxHash, tx.EthTxHash)
+		return false, err
+	}
+	if !settled {
+		log.Warn().Msgf("[E2W issue] E2W cashin %s no longer waiting on issue %s", tx.EthTxHash, issueTxHash)
+		return false, nil
+	}
+	if tx.Status == model.EthCashinWelFailed {
+		refund := model.RefundOfCashin(tx)
+		if err := s.RefundDAO.CreateRefund(&refund); err != nil {
+			log.Err(err).Msgf("[E2W issue] failed to create refund of failed E2W cashin %s", tx.EthTxHash)
+			return true, err
+		}
+		log.Info().Msgf("[E2W issue] E2W cashin %s failed, refund pending approval", tx.EthTxHash)
+	}
+	return true, nil
+}
+
 func (s *WelethBridgeService) CreateWelCashoutEthTrans(ctx context.Context, tx model.WelCashoutEthTrans) (int64, error) {
 	log := logger.Get()
 	log.Info().Msgf("[E2W tx2treasury get] creating W2E cashout transaction")
@@ -453,6 +532,12 @@ func (s *WelethBridgeService) UpdateWelCashoutEthTrans(ctx context.Context, tx m
 		log.Err(err).Msg("[E2W tx2treasury get] failed to update W2E cashout transaction")
 		return err
 	}
+	if tx.EthDisperseTxHash != "" {
+		if err := s.DisperseRetryDAO.RecordAttemptTx(tx.ID, tx.EthDisperseTxHash); err != nil {
+			log.Err(err).Msgf("[E2W tx2treasury get] failed to record disperse attempt tx of W2E cashout %d", tx.ID)
+			return err
+		}
+	}
 	return nil
 }
 
@@ -481,12 +566,27 @@ func (s *WelethBridgeService) registerService(w worker.Worker) {
 	w.RegisterActivityWithOptions(s.GetUnconfirmedTx2TreasuryByTxHash, activity.RegisterOptions{Name: GetTx2TreasuryByTxHash})
 	w.RegisterActivityWithOptions(s.CreateEthCashinWelTrans, activity.RegisterOptions{Name: CreateEthCashinWelTrans})
 	w.RegisterActivityWithOptions(s.UpdateEthCashinWelTrans, activity.RegisterOptions{Name: UpdateEthCashinWelTrans})
+	w.RegisterActivityWithOptions(s.SettleEthCashinWelIssue, activity.RegisterOptions{Name: SettleEthCashinWelIssue})
 
 	w.RegisterActivityWithOptions(s.CreateWelCashoutEthTrans, activity.RegisterOptions{Name: CreateWelCashoutEthTrans})
 	w.RegisterActivityWithOptions(s.UpdateWelCashoutEthTrans, activity.RegisterOptions{Name: UpdateWelCashoutEthTrans})
 
 	w.RegisterActivityWithOptions(s.MapEthTokenToWel, activity.RegisterOptions{Name: MapEthTokenToWel})
 	w.RegisterActivityWithOptions(s.MapWelTokenToEth, activity.RegisterOptions{Name: MapWelTokenToEth})
+
+	s.registerFeeSchedule(w)
+	s.registerBridgeLimits(w)
+	s.registerBridgeTokens(w)
+	s.registerEventOutbox(w)
+	s.registerRefunds(w)
+	s.registerDisperseRetries(w)
+	s.registerOutgoingTxs(w)
+	s.registerHealth(w)
+	s.registerWelTreasury(w)
+
+	if s.Reconciler != nil {
+		s.registerReconciler(w)
+	}
 }
 
 func (s *WelethBridgeService) StartService() error {
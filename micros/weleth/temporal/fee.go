@@ -0,0 +1,103 @@
+package welethService
+
+import (
+	"bridge/micros/weleth/model"
+	"bridge/service-managers/logger"
+	"context"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/worker"
+)
+
+const (
+	ComputeCommissionFee = "ComputeCommissionFee"
+
+	GetFeeRules   = "GetFeeRules"
+	CreateFeeRule = "CreateFeeRule"
+	UpdateFeeRule = "UpdateFeeRule"
+	DeleteFeeRule = "DeleteFeeRule"
+)
+
+type FeeRule = model.FeeRule
+type FeeQuote = model.FeeQuote
+
+// ComputeCommissionFee quotes the commission fee of a total of token, token being on the
+// source chain of direction. The quote is settled in the destination token units, the
+// rounding dust being charged as commission fee.
+func (s *WelethBridgeService) ComputeCommissionFee(ctx context.Context, token, direction, total string) (model.FeeQuote, error) {
+	log := logger.Get()
+	schedule, err := s.FeeRuleDAO.SelectFeeSchedule(token, direction)
+	if err != nil {
+		log.Err(err).Msgf("[Fee schedule] failed to get fee schedule of token %s, direction %s", token, direction)
+		return model.FeeQuote{}, err
+	}
+	quote, err := schedule.Quote(total)
+	if err != nil {
+		log.Err(err).Msgf("[Fee schedule] failed to quote commission fee of %s token %s", total, token)
+		return model.FeeQuote{}, err
+	}
+	n, err := s.Tokens.Normalize(token, direction, quote.Amount)
+	if err != nil {
+		log.Err(err).Msgf("[Fee schedule] failed to convert %s token %s to destination units", quote.Amount, token)
+		return model.FeeQuote{}, err
+	}
+	quote = quote.Settle(n)
+	log.Info().Msgf("[Fee schedule] commission fee of %s token %s, direction %s: %s", total, token, direction, quote.CommissionFee)
+	return quote, nil
+}
+
+func (s *WelethBridgeService) GetFeeRules(ctx context.Context, token, direction string) ([]model.FeeRule, error) {
+	log := logger.Get()
+	rules, err := s.FeeRuleDAO.SelectFeeRules(token, direction)
+	if err != nil {
+		log.Err(err).Msg("[Fee schedule] failed to get fee rules")
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (s *WelethBridgeService) CreateFeeRule(ctx context.Context, rule model.FeeRule) (int64, error) {
+	log := logger.Get()
+	if err := rule.Validate(); err != nil {
+		return -1, err
+	}
+	id, err := s.FeeRuleDAO.CreateFeeRule(&rule)
+	if err != nil {
+		log.Err(err).Msg("[Fee schedule] failed to create fee rule")
+		return -1, err
+	}
+	log.Info().Msgf("[Fee schedule] created fee rule %d: %+v", id, rule)
+	return id, nil
+}
+
+func (s *WelethBridgeService) UpdateFeeRule(ctx context.Context, rule model.FeeRule) error {
+	log := logger.Get()
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	if err := s.FeeRuleDAO.UpdateFeeRule(&rule); err != nil {
+		log.Err(err).Msgf("[Fee schedule] failed to update fee rule %d", rule.ID)
+		return err
+	}
+	log.Info().Msgf("[Fee schedule] updated fee rule %d: %+v", rule.ID, rule)
+	return nil
+}
+
+func (s *WelethBridgeService) DeleteFeeRule(ctx context.Context, id int64) error {
+	log := logger.Get()
+	if err := s.FeeRuleDAO.DeleteFeeRule(id); err != nil {
+		log.Err(err).Msgf("[Fee schedule] failed to delete fee rule %d", id)
+		return err
+	}
+	log.Info().Msgf("[Fee schedule] deleted fee rule %d", id)
+	return nil
+}
+
+func (s *WelethBridgeService) registerFeeSchedule(w worker.Worker) {
+	w.RegisterActivityWithOptions(s.ComputeCommissionFee, activity.RegisterOptions{Name: ComputeCommissionFee})
+
+	w.RegisterActivityWithOptions(s.GetFeeRules, activity.RegisterOptions{Name: GetFeeRules})
+	w.RegisterActivityWithOptions(s.CreateFeeRule, activity.RegisterOptions{Name: CreateFeeRule})
+	w.RegisterActivityWithOptions(s.UpdateFeeRule, activity.RegisterOptions{Name: UpdateFeeRule})
+	w.RegisterActivityWithOptions(s.DeleteFeeRule, activity.RegisterOptions{Name: DeleteFeeRule})
+}
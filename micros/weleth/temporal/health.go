@@ -0,0 +1,42 @@
+package welethService
+
+import (
+	"bridge/service-managers/daemon"
+	"bridge/service-managers/leader"
+	"bridge/service-managers/logger"
+	"context"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/worker"
+)
+
+const (
+	GetListenerLeases = "GetListenerLeases"
+	GetDaemonStates   = "GetDaemonStates"
+)
+
+type (
+	ListenerLease = leader.Lease
+	DaemonState   = daemon.State
+)
+
+// GetListenerLeases tells which replica leads each chain's listener, an inactive lease
+// means no replica does
+func (s *WelethBridgeService) GetListenerLeases(ctx context.Context) ([]leader.Lease, error) {
+	leases, err := s.ListenerLeaseDAO.GetLeases()
+	if err != nil {
+		logger.Get().Err(err).Msg("[Health] failed to get listener leases")
+		return nil, err
+	}
+	return leases, nil
+}
+
+// GetDaemonStates tells how the daemons of this replica are faring
+func (s *WelethBridgeService) GetDaemonStates(ctx context.Context) ([]daemon.State, error) {
+	return daemon.States(), nil
+}
+
+func (s *WelethBridgeService) registerHealth(w worker.Worker) {
+	w.RegisterActivityWithOptions(s.GetListenerLeases, activity.RegisterOptions{Name: GetListenerLeases})
+	w.RegisterActivityWithOptions(s.GetDaemonStates, activity.RegisterOptions{Name: GetDaemonStates})
+}
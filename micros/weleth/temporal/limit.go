@@ -0,0 +1,140 @@
+package welethService
+
+import (
+	coreEthService "bridge/micros/core/service/eth"
+	coreWelService "bridge/micros/core/service/wel"
+	"bridge/micros/weleth/model"
+	"bridge/service-managers/logger"
+	"context"
+	"math/big"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/worker"
+)
+
+const (
+	CheckBridgeLimit    = "CheckBridgeLimit"
+	GetHeldTransfers    = "GetHeldTransfers"
+	ReleaseHeldTransfer = "ReleaseHeldTransfer"
+)
+
+type BridgeTransfer = model.BridgeTransfer
+type HeldTransfer = model.HeldTransfer
+
+// checkLimit lets a transfer through and counts it in the daily volumes, or holds it for
+// review and returns ErrTransferHeld. Transfers of paused tokens are held too, a released
+// transfer skips the limits.
+func (s *WelethBridgeService) checkLimit(t model.BridgeTransfer) error {
+	log := logger.Get()
+	limits, paused := s.Tokens.Limits(t.TokenAddr)
+	if limits.IsZero() && !paused {
+		return nil
+	}
+
+	held, err := s.BridgeLimitDAO.SelectHeldTransfer(t.Kind, t.Ref, t.LogIndex)
+	if err != nil && err != model.ErrHeldTransferNotFound {
+		log.Err(err).Msgf("[Bridge limits] failed to get held %s transfer %s", t.Kind, t.Ref)
+		return err
+	}
+	if held != nil && held.Status == model.HeldForReview {
+		return model.ErrTransferHeld
+	}
+	released := held != nil
+
+	amount, ok := (&big.Int{}).SetString(t.Amount, 10)
+	if !ok {
+		return model.ErrInvalidAmount
+	}
+	err = s.BridgeLimitDAO.ReserveVolume(&t, func(addressVolume, tokenVolume *big.Int) error {
+		if released {
+			return nil
+		}
+		if paused {
+			return model.ErrTokenPaused
+		}
+		return limits.Check(amount, addressVolume, tokenVolume)
+	})
+	if !model.IsLimitViolation(err) {
+		return err
+	}
+
+	log.Warn().Msgf("[Bridge limits] %s transfer %s of %s token %s from %s held for review: %s", t.Kind, t.Ref, t.Amount, t.TokenAddr, t.Address, err.Error())
+	if err := s.BridgeLimitDAO.HoldTransfer(&t, err.Error()); err != nil {
+		return err
+	}
+	return model.ErrTransferHeld
+}
+
+// CheckBridgeLimit tells whether the transfer got held for review instead of being let through
+func (s *WelethBridgeService) CheckBridgeLimit(ctx context.Context, t model.BridgeTransfer) (bool, error) {
+	err := s.checkLimit(t)
+	if err == model.ErrTransferHeld {
+		return true, nil
+	}
+	if err != nil {
+		logger.Get().Err(err).Msgf("[Bridge limits] failed to check %s transfer %s", t.Kind, t.Ref)
+		return false, err
+	}
+	return false, nil
+}
+
+func (s *WelethBridgeService) GetHeldTransfers(ctx context.Context, status string, offset, size uint64) ([]model.HeldTransfer, error) {
+	ts, err := s.BridgeLimitDAO.SelectHeldTransfers(status, offset, size)
+	if err != nil {
+		logger.Get().Err(err).Msg("[Bridge limits] failed to get held transfers")
+		return nil, err
+	}
+	return ts, nil
+}
+
+// ReleaseHeldTransfer lets a held transfer through: issues and disperses are sent back to
+// their batch workflow, claims can be requested again
+func (s *WelethBridgeService) ReleaseHeldTransfer(ctx context.Context, id int64) (model.HeldTransfer, error) {
+	log := logger.Get()
+	t, err := s.BridgeLimitDAO.SelectHeldTransferById(id)
+	if err != nil {
+		log.Err(err).Msgf("[Bridge limits] failed to get held transfer %d", id)
+		return model.HeldTransfer{}, err
+	}
+	if t.Status != model.HeldForReview {
+		return *t, model.ErrNotHeld
+	}
+
+	// released before being resent so that the batch workflow lets it through
+	if err := s.BridgeLimitDAO.UpdateHeldTransferStatus(id, model.HeldReleased); err != nil {
+		return *t, err
+	}
+	if err := s.resendReleased(ctx, t); err != nil {
+		log.Err(err).Msgf("[Bridge limits] failed to resend released %s transfer %s", t.Kind, t.Ref)
+		s.BridgeLimitDAO.UpdateHeldTransferStatus(id, model.HeldForReview)
+		return *t, err
+	}
+	t.Status = model.HeldReleased
+	log.Info().Msgf("[Bridge limits] %s transfer %s released", t.Kind, t.Ref)
+	return *t, nil
+}
+
+func (s *WelethBridgeService) resendReleased(ctx context.Context, t *model.HeldTransfer) error {
+	switch t.Kind {
+	case model.TransferE2WCashinIssue:
+		tx, err := s.Eth2WelCashinTransDAO.SelectTransByDepositEvent(t.Ref, t.LogIndex)
+		if err != nil {
+			return err
+		}
+		return s.tempCli.SignalWorkflow(ctx, coreWelService.BatchIssueID, "", coreWelService.BatchIssueSignal, *tx)
+
+	case model.TransferW2ECashoutDisperse:
+		tx, err := s.Wel2EthCashoutTransDAO.SelectTransByWithdrawEvent(t.Ref, t.LogIndex)
+		if err != nil {
+			return err
+		}
+		return s.tempCli.SignalWorkflow(ctx, coreEthService.BatchDisperseID, "", coreEthService.BatchDisperseSignal, *tx)
+	}
+	return nil
+}
+
+func (s *WelethBridgeService) registerBridgeLimits(w worker.Worker) {
+	w.RegisterActivityWithOptions(s.CheckBridgeLimit, activity.RegisterOptions{Name: CheckBridgeLimit})
+	w.RegisterActivityWithOptions(s.GetHeldTransfers, activity.RegisterOptions{Name: GetHeldTransfers})
+	w.RegisterActivityWithOptions(s.ReleaseHeldTransfer, activity.RegisterOptions{Name: ReleaseHeldTransfer})
+}
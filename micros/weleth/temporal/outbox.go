@@ -0,0 +1,44 @@
+package welethService
+
+import (
+	"bridge/micros/weleth/model"
+	"bridge/service-managers/logger"
+	"context"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/worker"
+)
+
+const (
+	GetDeadLetters    = "GetDeadLetters"
+	RequeueDeadLetter = "RequeueDeadLetter"
+)
+
+type OutboxEvent = model.OutboxEvent
+
+// GetDeadLetters lists the events which failed to be consumed, "" lists both chains
+func (s *WelethBridgeService) GetDeadLetters(ctx context.Context, chain string, offset, size uint64) ([]model.OutboxEvent, error) {
+	es, err := s.EventOutboxDAO.SelectDeadLetters(chain, offset, size)
+	if err != nil {
+		logger.Get().Err(err).Msg("[Event outbox] failed to get dead letters")
+		return nil, err
+	}
+	return es, nil
+}
+
+// RequeueDeadLetter sends a dead letter back to its listener, usually once what made it
+// fail got fixed
+func (s *WelethBridgeService) RequeueDeadLetter(ctx context.Context, id int64) error {
+	log := logger.Get()
+	if err := s.EventOutboxDAO.RequeueDeadLetter(id); err != nil {
+		log.Err(err).Msgf("[Event outbox] failed to requeue dead letter %d", id)
+		return err
+	}
+	log.Info().Msgf("[Event outbox] dead letter %d requeued", id)
+	return nil
+}
+
+func (s *WelethBridgeService) registerEventOutbox(w worker.Worker) {
+	w.RegisterActivityWithOptions(s.GetDeadLetters, activity.RegisterOptions{Name: GetDeadLetters})
+	w.RegisterActivityWithOptions(s.RequeueDeadLetter, activity.RegisterOptions{Name: RequeueDeadLetter})
+}
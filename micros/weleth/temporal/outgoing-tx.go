@@ -0,0 +1,51 @@
+package welethService
+
+import (
+	"bridge/service-managers/logger"
+	"context"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/worker"
+)
+
+const (
+	ReplaceEthTxHash = "ReplaceEthTxHash"
+	ConfirmEthTx     = "ConfirmEthTx"
+	AbandonEthTx     = "AbandonEthTx"
+)
+
+// ReplaceEthTxHash is called once the core re-broadcasts a transaction, or once an
+// earlier broadcast of it gets mined
+func (s *WelethBridgeService) ReplaceEthTxHash(ctx context.Context, oldHash, newHash string) error {
+	if err := s.OutgoingTxDAO.ReplaceTxHash(oldHash, newHash); err != nil {
+		logger.Get().Err(err).Msgf("[Outgoing tx] failed to replace tx %s by %s", oldHash, newHash)
+		return err
+	}
+	logger.Get().Info().Msgf("[Outgoing tx] tx %s replaced by %s", oldHash, newHash)
+	return nil
+}
+
+// ConfirmEthTx is called once a transaction is mined
+func (s *WelethBridgeService) ConfirmEthTx(ctx context.Context, txHash string) error {
+	if err := s.OutgoingTxDAO.ConfirmTx(txHash); err != nil {
+		logger.Get().Err(err).Msgf("[Outgoing tx] failed to confirm tx %s", txHash)
+		return err
+	}
+	return nil
+}
+
+// AbandonEthTx is called once a transaction is cancelled, reverted or dropped
+func (s *WelethBridgeService) AbandonEthTx(ctx context.Context, txHash, note string) error {
+	if err := s.OutgoingTxDAO.AbandonTx(txHash, note); err != nil {
+		logger.Get().Err(err).Msgf("[Outgoing tx] failed to abandon tx %s", txHash)
+		return err
+	}
+	logger.Get().Warn().Msgf("[Outgoing tx] tx %s abandoned: %s", txHash, note)
+	return nil
+}
+
+func (s *WelethBridgeService) registerOutgoingTxs(w worker.Worker) {
+	w.RegisterActivityWithOptions(s.ReplaceEthTxHash, activity.RegisterOptions{Name: ReplaceEthTxHash})
+	w.RegisterActivityWithOptions(s.ConfirmEthTx, activity.RegisterOptions{Name: ConfirmEthTx})
+	w.RegisterActivityWithOptions(s.AbandonEthTx, activity.RegisterOptions{Name: AbandonEthTx})
+}
@@ -0,0 +1,75 @@
+package welethService
+
+import (
+	"bridge/micros/weleth/model"
+	"context"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	ReconcileWF = "ReconcileWF"
+
+	ReconcileEth = "ReconcileEth"
+	ReconcileWel = "ReconcileWel"
+)
+
+type ReconcileReport = model.ReconcileReport
+
+// IReconciler diffs the bridge contracts' events in a block range against the bridge
+// records of one chain
+type IReconciler interface {
+	ReconcileEth(ctx context.Context, from, to int64) (model.ReconcileChainReport, error)
+	ReconcileWel(ctx context.Context, from, to int64) (model.ReconcileChainReport, error)
+}
+
+// ReconcileWorkflow reconciles the given block ranges of both chains, a chain is skipped
+// when its range is empty
+func (s *WelethBridgeService) ReconcileWorkflow(ctx workflow.Context, ethFrom, ethTo, welFrom, welTo int64) (model.ReconcileReport, error) {
+	log := workflow.GetLogger(ctx)
+	ao := workflow.ActivityOptions{
+		TaskQueue:              WelethServiceQueue,
+		ScheduleToCloseTimeout: time.Hour,
+		StartToCloseTimeout:    time.Hour,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    time.Minute,
+			MaximumAttempts:    3,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	report := model.ReconcileReport{GeneratedAt: workflow.Now(ctx)}
+	var ethFuture, welFuture workflow.Future
+	if ethFrom <= ethTo {
+		ethFuture = workflow.ExecuteActivity(ctx, ReconcileEth, ethFrom, ethTo)
+	}
+	if welFrom <= welTo {
+		welFuture = workflow.ExecuteActivity(ctx, ReconcileWel, welFrom, welTo)
+	}
+
+	if ethFuture != nil {
+		if err := ethFuture.Get(ctx, &report.Eth); err != nil {
+			log.Error("Failed to reconcile eth blocks", "from", ethFrom, "to", ethTo, "error", err)
+			return report, err
+		}
+	}
+	if welFuture != nil {
+		if err := welFuture.Get(ctx, &report.Wel); err != nil {
+			log.Error("Failed to reconcile wel blocks", "from", welFrom, "to", welTo, "error", err)
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+func (s *WelethBridgeService) registerReconciler(w worker.Worker) {
+	w.RegisterWorkflowWithOptions(s.ReconcileWorkflow, workflow.RegisterOptions{Name: ReconcileWF})
+	w.RegisterActivityWithOptions(s.Reconciler.ReconcileEth, activity.RegisterOptions{Name: ReconcileEth})
+	w.RegisterActivityWithOptions(s.Reconciler.ReconcileWel, activity.RegisterOptions{Name: ReconcileWel})
+}
@@ -0,0 +1,70 @@
+package welethService
+
+import (
+	"bridge/micros/weleth/model"
+	"bridge/service-managers/logger"
+	"context"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/worker"
+)
+
+const (
+	GetRefunds   = "GetRefunds"
+	StartRefund  = "StartRefund"
+	RejectRefund = "RejectRefund"
+	UpdateRefund = "UpdateRefund"
+)
+
+type Refund = model.Refund
+
+// refunds are approved or rejected while pending approval, failed ones can be approved
+// again or given up
+var refundReviewable = []string{model.RefundPendingApproval, model.RefundFailed}
+
+func (s *WelethBridgeService) GetRefunds(ctx context.Context, status string, offset, size uint64) ([]model.Refund, error) {
+	rs, err := s.RefundDAO.SelectRefunds(status, offset, size)
+	if err != nil {
+		logger.Get().Err(err).Msg("[Refunds] failed to get refunds")
+		return nil, err
+	}
+	return rs, nil
+}
+
+// StartRefund marks an approved refund as being sent, a refund is started once at most
+func (s *WelethBridgeService) StartRefund(ctx context.Context, id int64) (model.Refund, error) {
+	log := logger.Get()
+	r, err := s.RefundDAO.TransitionRefund(id, refundReviewable, model.RefundRefunding, "")
+	if err != nil {
+		log.Err(err).Msgf("[Refunds] failed to start refund %d", id)
+		return model.Refund{}, err
+	}
+	log.Info().Msgf("[Refunds] refund %d of tx to treasury %s approved", id, r.TxID)
+	return *r, nil
+}
+
+func (s *WelethBridgeService) RejectRefund(ctx context.Context, id int64, note string) (model.Refund, error) {
+	log := logger.Get()
+	r, err := s.RefundDAO.TransitionRefund(id, refundReviewable, model.RefundRejected, note)
+	if err != nil {
+		log.Err(err).Msgf("[Refunds] failed to reject refund %d", id)
+		return model.Refund{}, err
+	}
+	log.Info().Msgf("[Refunds] refund %d of tx to treasury %s rejected", id, r.TxID)
+	return *r, nil
+}
+
+func (s *WelethBridgeService) UpdateRefund(ctx context.Context, r model.Refund) error {
+	if err := s.RefundDAO.UpdateRefund(&r); err != nil {
+		logger.Get().Err(err).Msgf("[Refunds] failed to update refund %d", r.ID)
+		return err
+	}
+	return nil
+}
+
+func (s *WelethBridgeService) registerRefunds(w worker.Worker) {
+	w.RegisterActivityWithOptions(s.GetRefunds, activity.RegisterOptions{Name: GetRefunds})
+	w.RegisterActivityWithOptions(s.StartRefund, activity.RegisterOptions{Name: StartRefund})
+	w.RegisterActivityWithOptions(s.RejectRefund, activity.RegisterOptions{Name: RejectRefund})
+	w.RegisterActivityWithOptions(s.UpdateRefund, activity.RegisterOptions{Name: UpdateRefund})
+}
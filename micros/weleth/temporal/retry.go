@@ -0,0 +1,75 @@
+package welethService
+
+import (
+	"bridge/micros/weleth/model"
+	"bridge/service-managers/logger"
+	"context"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/worker"
+)
+
+const (
+	GetDueDisperseRetries = "GetDueDisperseRetries"
+	StartDisperseRetry    = "StartDisperseRetry"
+	GiveUpDisperseRetry   = "GiveUpDisperseRetry"
+)
+
+type DisperseRetry = model.DisperseRetry
+type DisperseAttempt = model.DisperseAttempt
+
+// GetDueDisperseRetries lists the declined cashouts whose backoff elapsed, exhausted ones
+// included so that they get given up. Attempts that never made it to a disperse tx are
+// retried as well.
+func (s *WelethBridgeService) GetDueDisperseRetries(ctx context.Context) ([]model.DisperseRetry, error) {
+	stalled, err := s.DisperseRetryDAO.RequeueStalledAttempts(time.Now().Add(-model.DisperseAttemptStallTimeout))
+	if err != nil {
+		logger.Get().Err(err).Msg("[Disperse retry] failed to re-queue stalled disperse attempts")
+		return nil, err
+	}
+	if stalled > 0 {
+		logger.Get().Warn().Msgf("[Disperse retry] %d stalled disperse attempts re-queued", stalled)
+	}
+
+	rs, err := s.DisperseRetryDAO.SelectDisperseRetries()
+	if err != nil {
+		logger.Get().Err(err).Msg("[Disperse retry] failed to get disperse retries")
+		return nil, err
+	}
+	now := time.Now()
+	due := []model.DisperseRetry{}
+	for _, r := range rs {
+		if r.Exhausted() || r.Due(now) {
+			due = append(due, r)
+		}
+	}
+	return due, nil
+}
+
+func (s *WelethBridgeService) StartDisperseRetry(ctx context.Context, cashoutID int64) (model.DisperseAttempt, error) {
+	log := logger.Get()
+	a, err := s.DisperseRetryDAO.StartDisperseAttempt(cashoutID)
+	if err != nil {
+		log.Err(err).Msgf("[Disperse retry] failed to start disperse attempt of W2E cashout %d", cashoutID)
+		return model.DisperseAttempt{}, err
+	}
+	log.Info().Msgf("[Disperse retry] W2E cashout %d disperse attempt %d started", cashoutID, a.Attempt)
+	return *a, nil
+}
+
+func (s *WelethBridgeService) GiveUpDisperseRetry(ctx context.Context, cashoutID int64) error {
+	log := logger.Get()
+	if err := s.DisperseRetryDAO.GiveUpDisperse(cashoutID); err != nil {
+		log.Err(err).Msgf("[Disperse retry] failed to give up disperse of W2E cashout %d", cashoutID)
+		return err
+	}
+	log.Warn().Msgf("[Disperse retry] W2E cashout %d disperse given up", cashoutID)
+	return nil
+}
+
+func (s *WelethBridgeService) registerDisperseRetries(w worker.Worker) {
+	w.RegisterActivityWithOptions(s.GetDueDisperseRetries, activity.RegisterOptions{Name: GetDueDisperseRetries})
+	w.RegisterActivityWithOptions(s.StartDisperseRetry, activity.RegisterOptions{Name: StartDisperseRetry})
+	w.RegisterActivityWithOptions(s.GiveUpDisperseRetry, activity.RegisterOptions{Name: GiveUpDisperseRetry})
+}
@@ -0,0 +1,83 @@
+package welethService
+
+import (
+	"bridge/micros/weleth/model"
+	"bridge/service-managers/logger"
+	"context"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/worker"
+)
+
+const (
+	GetBridgeTokens   = "GetBridgeTokens"
+	CreateBridgeToken = "CreateBridgeToken"
+	UpdateBridgeToken = "UpdateBridgeToken"
+	DeleteBridgeToken = "DeleteBridgeToken"
+)
+
+type BridgeToken = model.BridgeToken
+
+// reloadTokens applies a registry change right away in this process, other processes pick
+// it up on their next refresh
+func (s *WelethBridgeService) reloadTokens() {
+	if err := s.Tokens.Reload(); err != nil {
+		logger.Get().Err(err).Msg("[Token registry] failed to reload bridge tokens, change will apply on next refresh")
+	}
+}
+
+func (s *WelethBridgeService) GetBridgeTokens(ctx context.Context) ([]model.BridgeToken, error) {
+	tokens, err := s.BridgeTokenDAO.SelectBridgeTokens()
+	if err != nil {
+		logger.Get().Err(err).Msg("[Token registry] failed to get bridge tokens")
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (s *WelethBridgeService) CreateBridgeToken(ctx context.Context, token model.BridgeToken) (int64, error) {
+	log := logger.Get()
+	if err := token.Validate(); err != nil {
+		return -1, err
+	}
+	id, err := s.BridgeTokenDAO.CreateBridgeToken(&token)
+	if err != nil {
+		log.Err(err).Msgf("[Token registry] failed to create bridge token %s/%s", token.EthAddr, token.WelAddr)
+		return -1, err
+	}
+	log.Info().Msgf("[Token registry] created bridge token %d: %+v", id, token)
+	s.reloadTokens()
+	return id, nil
+}
+
+func (s *WelethBridgeService) UpdateBridgeToken(ctx context.Context, token model.BridgeToken) error {
+	log := logger.Get()
+	if err := token.Validate(); err != nil {
+		return err
+	}
+	if err := s.BridgeTokenDAO.UpdateBridgeToken(&token); err != nil {
+		log.Err(err).Msgf("[Token registry] failed to update bridge token %d", token.ID)
+		return err
+	}
+	log.Info().Msgf("[Token registry] updated bridge token %d: %+v", token.ID, token)
+	s.reloadTokens()
+	return nil
+}
+
+func (s *WelethBridgeService) DeleteBridgeToken(ctx context.Context, id int64) error {
+	log := logger.Get()
+	if err := s.BridgeTokenDAO.DeleteBridgeToken(id); err != nil {
+		log.Err(err).Msgf("[Token registry] failed to delete bridge token %d", id)
+		return err
+	}
+	log.Info().Msgf("[Token registry] deleted bridge token %d", id)
+	s.reloadTokens()
+	return nil
+}
+
+func (s *WelethBridgeService) registerBridgeTokens(w worker.Worker) {
+	w.RegisterActivityWithOptions(s.GetBridgeTokens, activity.RegisterOptions{Name: GetBridgeTokens})
+	w.RegisterActivityWithOptions(s.CreateBridgeToken, activity.RegisterOptions{Name: CreateBridgeToken})
+	w.RegisterActivityWithOptions(s.UpdateBridgeToken, activity.RegisterOptions{Name: UpdateBridgeToken})
+	w.RegisterActivityWithOptions(s.DeleteBridgeToken, activity.RegisterOptions{Name: DeleteBridgeToken})
+}
@@ -0,0 +1,68 @@
+package welethService
+
+import (
+	"bridge/micros/weleth/config"
+	"bridge/micros/weleth/model"
+	"bridge/service-managers/logger"
+	"context"
+	"fmt"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/worker"
+)
+
+const (
+	GetWelTx2Treasury         = "GetUnconfirmedWelTx2Treasury"
+	GetWelTx2TreasuryByTxHash = "GetUnconfirmedWelTx2TreasuryByTxHash"
+	GetWelTx2TreasuryBySender = "GetWelTx2TreasuryBySender"
+)
+
+func (s *WelethBridgeService) GetWelTx2TreasuryBySender(ctx context.Context, sender string) ([]model.TxToTreasury, error) {
+	log := logger.Get()
+	log.Info().Msgf("[W2E tx2treasury get] getting wel tx2treasury transaction")
+	txs, err := s.WelTx2TreasuryDAO.GetWelTx2TreasuryFromSender(sender)
+	if err != nil {
+		log.Err(err).Msg("[W2E tx2treasury get] failed to get wel tx2treasury transaction")
+		return nil, err
+	}
+	return txs, nil
+}
+
+func (s *WelethBridgeService) GetUnconfirmedWelTx2Treasury(ctx context.Context, from, treasury, token, amount string) (model.TxToTreasury, error) {
+	log := logger.Get()
+	log.Info().Msgf("[W2E tx2treasury get] getting wel tx2treasury transaction")
+	if treasury == "" || treasury != config.Get().WelTreasuryAddress {
+		err := fmt.Errorf("Wrong treasury address")
+		log.Err(err).Msg("[W2E tx2treasury get] Wrong treasury address: " + treasury)
+		return model.TxToTreasury{}, err
+	}
+	t, err := s.WelTx2TreasuryDAO.GetUnconfirmedWelTx2Treasury(from, treasury, token, amount)
+	if err != nil {
+		log.Err(err).Msg("[W2E tx2treasury get] failed to get wel tx2treasury transaction")
+		return model.TxToTreasury{}, err
+	}
+	if t == nil {
+		return model.TxToTreasury{}, model.ErrTx2TreasuryNotFound
+	}
+	return *t, nil
+}
+
+func (s *WelethBridgeService) GetUnconfirmedWelTx2TreasuryByTxHash(ctx context.Context, txhash string, logIndex int64) (model.TxToTreasury, error) {
+	log := logger.Get()
+	log.Info().Msgf("[W2E tx2treasury get] getting wel tx2treasury transaction with txhash %s:%d", txhash, logIndex)
+	t, err := s.WelTx2TreasuryDAO.GetUnconfirmedWelTx2TreasuryByTxHash(txhash, logIndex)
+	if err != nil {
+		log.Err(err).Msgf("[W2E tx2treasury get] failed to get wel tx2treasury transaction with txhash %s:%d", txhash, logIndex)
+		return model.TxToTreasury{}, err
+	}
+	if t == nil {
+		return model.TxToTreasury{}, model.ErrTx2TreasuryNotFound
+	}
+	return *t, nil
+}
+
+func (s *WelethBridgeService) registerWelTreasury(w worker.Worker) {
+	w.RegisterActivityWithOptions(s.GetWelTx2TreasuryBySender, activity.RegisterOptions{Name: GetWelTx2TreasuryBySender})
+	w.RegisterActivityWithOptions(s.GetUnconfirmedWelTx2Treasury, activity.RegisterOptions{Name: GetWelTx2Treasury})
+	w.RegisterActivityWithOptions(s.GetUnconfirmedWelTx2TreasuryByTxHash, activity.RegisterOptions{Name: GetWelTx2TreasuryByTxHash})
+}
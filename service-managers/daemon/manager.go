@@ -2,71 +2,305 @@ package daemon
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os/signal"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"bridge/common/consts"
+	"bridge/service-managers/logger"
 )
 
+// Policy tells how daemons are restarted when they fail, and how long they're given to stop
+type Policy struct {
+	InitialBackoff time.Duration // wait before the first restart, doubled on every next one
+	MaxBackoff     time.Duration
+	// failures in a row after which a daemon is given up on, never when not positive
+	MaxRestarts int
+	// a run lasting this long ends the failures in a row, and resets the backoff
+	StableAfter time.Duration
+	StopTimeout time.Duration // per daemon, on shutdown
+	// once a daemon is given up on, stop the others and return from Run, for daemons
+	// useless without one another
+	FailFast bool
+}
+
+var DefaultPolicy = Policy{
+	InitialBackoff: time.Second,
+	MaxBackoff:     time.Minute,
+	MaxRestarts:    10,
+	StableAfter:    5 * time.Minute,
+	StopTimeout:    10 * time.Second,
+}
+
+type Status string
+
+const (
+	StatusStarting   Status = "starting"
+	StatusRunning    Status = "running"
+	StatusRestarting Status = "restarting"
+	StatusFailed     Status = "failed" // given up on
+	StatusStopped    Status = "stopped"
+)
+
+// State is how a supervised daemon is faring
+type State struct {
+	Name        string    `json:"name"`
+	Status      Status    `json:"status"`
+	Restarts    int       `json:"restarts"`
+	LastError   string    `json:"last_error,omitempty"`
+	LastErrorAt time.Time `json:"last_error_at,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+}
+
+// a daemon returning while not asked to stop is failing as well
+var errDaemonReturned = errors.New("daemon returned unexpectedly")
+
 // BootstrapDaemons start daemons and handling os signal.
 func BootstrapDaemons(ctx context.Context, daemonGenerators ...consts.DaemonGenerator) {
+	BootstrapDaemonsWithPolicy(ctx, DefaultPolicy, daemonGenerators...)
+}
+
+// BootstrapDaemonsWithPolicy is BootstrapDaemons supervising daemons with policy
+func BootstrapDaemonsWithPolicy(ctx context.Context, policy Policy, daemonGenerators ...consts.DaemonGenerator) {
 	// os signal handling
 	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGTERM)
+	defer stop()
 
 	// create daemon manager
-	manager := newDaemonManager(ctx)
-	manager.registerDaemons(daemonGenerators...)
+	manager := MkManager(policy)
+	for _, g := range daemonGenerators {
+		manager.Register(daemonName(g), g)
+	}
+
+	// run until os signal, or a daemon given up on with FailFast, then stop daemons
+	manager.Run(ctx)
+}
+
+// Manager supervises daemons: a daemon failing to start, panicking or returning is restarted
+// with exponential backoff until it's given up on. On shutdown daemons are stopped one at a
+// time, the last registered first.
+type Manager struct {
+	policy  Policy
+	mu      sync.RWMutex
+	daemons []*supervised
+
+	failed   chan struct{} // closed once a daemon is given up on
+	failOnce sync.Once
+}
+
+type supervised struct {
+	gen    consts.DaemonGenerator
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	state  State
+}
+
+func MkManager(policy Policy) *Manager {
+	return &Manager{policy: policy, failed: make(chan struct{})}
+}
+
+// Register adds a daemon, to be started by Run
+func (m *Manager) Register(name string, g consts.DaemonGenerator) {
+	if g == nil {
+		return
+	}
+	// daemons get their own contexts, so that they're stopped in order
+	ctx, cancel := context.WithCancel(context.Background())
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.daemons = append(m.daemons, &supervised{
+		gen:    g,
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+		state:  State{Name: name, Status: StatusStarting},
+	})
+}
+
+// Run starts the daemons and supervises them until ctx is done, or with FailFast until a
+// daemon is given up on, then stops them
+func (m *Manager) Run(ctx context.Context) {
+	track(m)
+	defer untrack(m)
+
+	m.mu.RLock()
+	daemons := m.daemons
+	m.mu.RUnlock()
+	for _, d := range daemons {
+		go m.supervise(d)
+	}
 
-	// wait os signal
-	<-manager.ctx.Done()
-	stop()
+	select {
+	case <-ctx.Done():
+	case <-m.failed:
+		logger.Get().Error().Msg("[daemon] a daemon was given up on, stopping the others")
+	}
+	m.stop(daemons)
+}
 
-	// stop daemons
-	manager.stop()
+// States tells how each daemon is faring
+func (m *Manager) States() []State {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	states := make([]State, 0, len(m.daemons))
+	for _, d := range m.daemons {
+		states = append(states, d.state)
+	}
+	return states
 }
 
-type daemonManager struct {
-	ctx          context.Context
-	daemonCtx    []context.Context
-	daemonCancel []context.CancelFunc
-	terminated   chan struct{}
+func (m *Manager) stop(daemons []*supervised) {
+	log := logger.Get()
+	for i := len(daemons) - 1; i >= 0; i-- {
+		d := daemons[i]
+		d.cancel()
+		select {
+		case <-d.done:
+		case <-time.After(m.policy.StopTimeout):
+			log.Warn().Msgf("[daemon] %s didn't stop within %s, leaving it behind", d.state.Name, m.policy.StopTimeout)
+		}
+	}
 }
 
-func newDaemonManager(ctx context.Context) (d *daemonManager) {
-	d = &daemonManager{
-		ctx:        ctx,
-		terminated: make(chan struct{}, 8),
+func (m *Manager) supervise(d *supervised) {
+	defer close(d.done)
+	log := logger.Get()
+	backoff := m.policy.InitialBackoff
+	failures := 0
+	for {
+		started := time.Now()
+		m.update(d, func(s *State) {
+			s.Status = StatusRunning
+			s.StartedAt = started
+		})
+
+		err := run(d.ctx, d.gen)
+		if d.ctx.Err() != nil {
+			m.update(d, func(s *State) { s.Status = StatusStopped })
+			return
+		}
+		if err == nil {
+			err = errDaemonReturned
+		}
+
+		if time.Since(started) >= m.policy.StableAfter {
+			failures = 0
+			backoff = m.policy.InitialBackoff
+		}
+		failures++
+		giveUp := m.policy.MaxRestarts > 0 && failures > m.policy.MaxRestarts
+		m.update(d, func(s *State) {
+			s.LastError = err.Error()
+			s.LastErrorAt = time.Now()
+			s.Status = StatusRestarting
+			if giveUp {
+				s.Status = StatusFailed
+			}
+		})
+		if giveUp {
+			log.Error().Err(err).Msgf("[daemon] %s failed %d times in a row, giving up", d.state.Name, failures)
+			if m.policy.FailFast {
+				m.failOnce.Do(func() { close(m.failed) })
+			}
+			return
+		}
+
+		log.Warn().Err(err).Msgf("[daemon] %s failed, restarting in %s", d.state.Name, backoff)
+		consts.SleepContext(d.ctx, backoff)
+		if d.ctx.Err() != nil {
+			m.update(d, func(s *State) { s.Status = StatusStopped })
+			return
+		}
+		m.update(d, func(s *State) { s.Restarts++ })
+		backoff = nextBackoff(backoff, m.policy)
 	}
-	return
 }
 
-func (d *daemonManager) registerDaemon(g consts.DaemonGenerator) {
-	if g != nil {
-		ctx, cancel := context.WithCancel(context.Background())
-		d.daemonCtx, d.daemonCancel = append(d.daemonCtx, ctx), append(d.daemonCancel, cancel)
+func (m *Manager) update(d *supervised, f func(s *State)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f(&d.state)
+}
 
-		daemon, err := g(ctx)
-		if err != nil {
-			panic(err)
+// run generates and runs a daemon once, recovering its panics
+func run(ctx context.Context, g consts.DaemonGenerator) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Get().Error().Msgf("[daemon] panic: %v\n%s", r, debug.Stack())
+			err = fmt.Errorf("panic: %v", r)
 		}
+	}()
 
-		// start daemon
-		go func() {
-			daemon()
-			d.terminated <- struct{}{}
-		}()
+	daemon, err := g(ctx)
+	if err != nil {
+		return fmt.Errorf("can't start: %w", err)
 	}
+	daemon()
+	return nil
 }
 
-func (d *daemonManager) registerDaemons(gs ...consts.DaemonGenerator) {
-	for _, g := range gs {
-		d.registerDaemon(g)
+func nextBackoff(prev time.Duration, policy Policy) time.Duration {
+	next := prev * 2
+	if next <= 0 {
+		next = policy.InitialBackoff
+	}
+	if policy.MaxBackoff > 0 && next > policy.MaxBackoff {
+		next = policy.MaxBackoff
+	}
+	return next
+}
+
+// daemonName names a daemon after its generator, e.g. eth.(*EthListener).Scan
+func daemonName(g consts.DaemonGenerator) string {
+	if g == nil {
+		return ""
+	}
+	fn := runtime.FuncForPC(reflect.ValueOf(g).Pointer())
+	if fn == nil {
+		return "daemon"
+	}
+	name := fn.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
 	}
+	return strings.TrimSuffix(name, "-fm")
+}
+
+// running managers, for States
+var (
+	managersMu sync.Mutex
+	managers   = make(map[*Manager]struct{})
+)
+
+func track(m *Manager) {
+	managersMu.Lock()
+	defer managersMu.Unlock()
+	managers[m] = struct{}{}
+}
+
+func untrack(m *Manager) {
+	managersMu.Lock()
+	defer managersMu.Unlock()
+	delete(managers, m)
 }
 
-func (d *daemonManager) stop() {
-	for i := len(d.daemonCtx) - 1; i >= 0; i-- {
-		d.daemonCancel[i]()
-		<-d.terminated // wait termination
+// States tells how the daemons of every running manager of the process are faring
+func States() []State {
+	managersMu.Lock()
+	defer managersMu.Unlock()
+	var states []State
+	for m := range managers {
+		states = append(states, m.States()...)
 	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states
 }
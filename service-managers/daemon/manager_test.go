@@ -0,0 +1,230 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"bridge/common/consts"
+	"bridge/service-managers/logger"
+)
+
+var testPolicy = Policy{
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     4 * time.Millisecond,
+	MaxRestarts:    3,
+	StableAfter:    time.Hour,
+	StopTimeout:    time.Second,
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func stateOf(m *Manager, name string) State {
+	for _, s := range m.States() {
+		if s.Name == name {
+			return s
+		}
+	}
+	return State{}
+}
+
+func TestManagerRestartsUntilGivenUp(t *testing.T) {
+	logger.Get()
+	m := MkManager(testPolicy)
+	m.Register("panicking", func(ctx context.Context) (consts.Daemon, error) {
+		return func() { panic("boom") }, nil
+	})
+	m.Register("unstartable", func(ctx context.Context) (consts.Daemon, error) {
+		return nil, errors.New("no rpc")
+	})
+	m.Register("returning", func(ctx context.Context) (consts.Daemon, error) {
+		return func() {}, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		m.Run(ctx)
+		close(done)
+	}()
+
+	for _, name := range []string{"panicking", "unstartable", "returning"} {
+		waitFor(t, func() bool { return stateOf(m, name).Status == StatusFailed })
+		s := stateOf(m, name)
+		if s.Restarts != testPolicy.MaxRestarts {
+			t.Errorf("%s: %d restarts, want %d", name, s.Restarts, testPolicy.MaxRestarts)
+		}
+		if s.LastError == "" {
+			t.Errorf("%s: no last error", name)
+		}
+	}
+	if s := stateOf(m, "returning"); s.LastError != errDaemonReturned.Error() {
+		t.Errorf("returning: last error %q", s.LastError)
+	}
+
+	cancel()
+	<-done
+}
+
+func TestManagerRecoversAndStopsInReverseOrder(t *testing.T) {
+	logger.Get()
+	m := MkManager(testPolicy)
+
+	var mu sync.Mutex
+	var stopped []string
+	runs := 0
+	blocking := func(name string) consts.DaemonGenerator {
+		return func(ctx context.Context) (consts.Daemon, error) {
+			return func() {
+				<-ctx.Done()
+				mu.Lock()
+				stopped = append(stopped, name)
+				mu.Unlock()
+			}, nil
+		}
+	}
+	m.Register("first", blocking("first"))
+	m.Register("flaky", func(ctx context.Context) (consts.Daemon, error) {
+		return func() {
+			mu.Lock()
+			runs++
+			crash := runs == 1
+			mu.Unlock()
+			if crash {
+				panic("once")
+			}
+			daemon, _ := blocking("flaky")(ctx)
+			daemon()
+		}, nil
+	})
+	m.Register("last", blocking("last"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		m.Run(ctx)
+		close(done)
+	}()
+
+	waitFor(t, func() bool {
+		s := stateOf(m, "flaky")
+		return s.Status == StatusRunning && s.Restarts == 1
+	})
+	if s := stateOf(m, "flaky"); s.LastError != "panic: once" {
+		t.Errorf("flaky: last error %q", s.LastError)
+	}
+	if len(States()) != 3 {
+		t.Errorf("running managers report %d daemons, want 3", len(States()))
+	}
+
+	cancel()
+	<-done
+	want := []string{"last", "flaky", "first"}
+	if len(stopped) != len(want) {
+		t.Fatalf("stopped %v, want %v", stopped, want)
+	}
+	for i := range want {
+		if stopped[i] != want[i] {
+			t.Fatalf("stopped %v, want %v", stopped, want)
+		}
+	}
+	for _, s := range m.States() {
+		if s.Status != StatusStopped {
+			t.Errorf("%s: status %s after shutdown", s.Name, s.Status)
+		}
+	}
+	if len(States()) != 0 {
+		t.Error("stopped manager still reported")
+	}
+}
+
+func TestManagerFailFast(t *testing.T) {
+	logger.Get()
+	policy := testPolicy
+	policy.FailFast = true
+	m := MkManager(policy)
+	m.Register("healthy", func(ctx context.Context) (consts.Daemon, error) {
+		return func() { <-ctx.Done() }, nil
+	})
+	m.Register("unstartable", func(ctx context.Context) (consts.Daemon, error) {
+		return nil, errors.New("no rpc")
+	})
+
+	done := make(chan struct{})
+	go func() {
+		m.Run(context.Background())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run still running with a daemon given up on")
+	}
+	if s := stateOf(m, "unstartable"); s.Status != StatusFailed {
+		t.Errorf("unstartable: status %s", s.Status)
+	}
+	if s := stateOf(m, "healthy"); s.Status != StatusStopped {
+		t.Errorf("healthy: status %s", s.Status)
+	}
+}
+
+func TestManagerLeavesBehindDaemonNotStopping(t *testing.T) {
+	logger.Get()
+	policy := testPolicy
+	policy.StopTimeout = 10 * time.Millisecond
+	m := MkManager(policy)
+	stuck := make(chan struct{})
+	defer close(stuck)
+	m.Register("stuck", func(ctx context.Context) (consts.Daemon, error) {
+		return func() { <-stuck }, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		m.Run(ctx)
+		close(done)
+	}()
+	waitFor(t, func() bool { return stateOf(m, "stuck").Status == StatusRunning })
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("shutdown held up by a daemon not stopping")
+	}
+}
+
+func TestNextBackoff(t *testing.T) {
+	policy := Policy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	backoff := policy.InitialBackoff
+	for _, want := range []time.Duration{2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		backoff = nextBackoff(backoff, policy)
+		if backoff != want {
+			t.Fatalf("backoff %s, want %s", backoff, want)
+		}
+	}
+}
+
+func TestDaemonName(t *testing.T) {
+	l := &fakeListener{}
+	if name := daemonName(l.Scan); name != "daemon.(*fakeListener).Scan" {
+		t.Errorf("name %q", name)
+	}
+}
+
+type fakeListener struct{}
+
+func (l *fakeListener) Scan(ctx context.Context) (consts.Daemon, error) {
+	return func() {}, nil
+}
@@ -0,0 +1,144 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"bridge/common/consts"
+
+	"github.com/rs/zerolog"
+)
+
+// Lease is the right of one instance, its holder, to run a singleton job such as a chain
+// listener. It's held until it expires unless renewed.
+type Lease struct {
+	Name       string    `json:"name" db:"name"`
+	Holder     string    `json:"holder" db:"holder"`
+	AcquiredAt time.Time `json:"acquired_at" db:"acquired_at"`
+	RenewedAt  time.Time `json:"renewed_at" db:"renewed_at"`
+	ExpiresAt  time.Time `json:"expires_at" db:"expires_at"`
+	Active     bool      `json:"active" db:"active"` // not expired yet
+}
+
+type ILeaseRepo interface {
+	// takes the lease for holder when it's free, expired or held by holder already, which
+	// renews it for ttl; returns the lease as it is afterwards, held by holder or not
+	AcquireLease(name, holder string, ttl time.Duration) (*Lease, error)
+	// lets the lease expire right away if holder holds it
+	ReleaseLease(name, holder string) error
+	GetLeases() ([]Lease, error)
+}
+
+// InstanceID identifies this process among the replicas competing for leases
+func InstanceID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// Elector runs a job on the single instance holding its lease. Standbys try to take the
+// lease over every ttl/3, so a dead leader is replaced within ttl and a third; a leader
+// unable to renew steps down before its lease could be taken over.
+type Elector struct {
+	repo   ILeaseRepo
+	name   string
+	id     string
+	ttl    time.Duration
+	logger *zerolog.Logger
+}
+
+func MkElector(repo ILeaseRepo, name, id string, ttl time.Duration, logger *zerolog.Logger) *Elector {
+	return &Elector{repo: repo, name: name, id: id, ttl: ttl, logger: logger}
+}
+
+// Run runs lead, until its context is done, for every term this instance is the leader;
+// standing by in between. A lead returning on its own ends the term: the lease is released
+// and left to standbys for a ttl. It returns once ctx is done.
+func (e *Elector) Run(ctx context.Context, lead func(ctx context.Context)) {
+	interval := e.ttl / 3
+	for ctx.Err() == nil {
+		if !e.acquire() {
+			consts.SleepContext(ctx, interval)
+			continue
+		}
+
+		e.logger.Info().Msgf("[leader] %s leading %s", e.id, e.name)
+		lctx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			lead(lctx)
+			close(done)
+		}()
+		e.hold(lctx, done, interval)
+		quit := false
+		select {
+		case <-done:
+			quit = lctx.Err() == nil
+		default:
+		}
+		cancel()
+		<-done
+
+		if ctx.Err() != nil {
+			// hand over right away on shutdown
+			if err := e.repo.ReleaseLease(e.name, e.id); err != nil {
+				e.logger.Err(err).Msgf("[leader] can't release lease %s", e.name)
+			}
+			return
+		}
+		if quit {
+			// the job gave up, a standby may fare better
+			e.logger.Warn().Msgf("[leader] %s ended on %s, handing the lease over", e.name, e.id)
+			if err := e.repo.ReleaseLease(e.name, e.id); err != nil {
+				e.logger.Err(err).Msgf("[leader] can't release lease %s", e.name)
+			}
+			consts.SleepContext(ctx, e.ttl)
+			continue
+		}
+		e.logger.Warn().Msgf("[leader] %s stepped down from %s", e.id, e.name)
+	}
+}
+
+// acquire tells whether this instance holds the lease, taking or renewing it
+func (e *Elector) acquire() bool {
+	lease, err := e.repo.AcquireLease(e.name, e.id, e.ttl)
+	if err != nil {
+		e.logger.Err(err).Msgf("[leader] can't acquire lease %s", e.name)
+		return false
+	}
+	return lease.Holder == e.id
+}
+
+// hold renews the lease until ctx is done, the job is, the lease is lost, or it couldn't be
+// renewed for long enough that it might expire
+func (e *Elector) hold(ctx context.Context, done <-chan struct{}, interval time.Duration) {
+	renewed := time.Now()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-done:
+			return
+		case <-ticker.C:
+			lease, err := e.repo.AcquireLease(e.name, e.id, e.ttl)
+			switch {
+			case err == nil && lease.Holder == e.id:
+				renewed = time.Now()
+			case err == nil:
+				e.logger.Warn().Msgf("[leader] lease %s taken over by %s", e.name, lease.Holder)
+				return
+			case time.Since(renewed) >= e.ttl-interval:
+				e.logger.Err(err).Msgf("[leader] can't renew lease %s, stepping down before it expires", e.name)
+				return
+			default:
+				e.logger.Err(err).Msgf("[leader] can't renew lease %s", e.name)
+			}
+		}
+	}
+}
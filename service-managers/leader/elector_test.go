@@ -0,0 +1,166 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"bridge/service-managers/logger"
+)
+
+type fakeLeases struct {
+	mu     sync.Mutex
+	leases map[string]*Lease
+	down   bool
+}
+
+func (r *fakeLeases) AcquireLease(name, holder string, ttl time.Duration) (*Lease, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.down {
+		return nil, errors.New("db down")
+	}
+	now := time.Now()
+	l, ok := r.leases[name]
+	if !ok || l.Holder == holder || now.After(l.ExpiresAt) {
+		if !ok || l.Holder != holder {
+			l = &Lease{Name: name, Holder: holder, AcquiredAt: now}
+			r.leases[name] = l
+		}
+		l.RenewedAt, l.ExpiresAt = now, now.Add(ttl)
+	}
+	cp := *l
+	return &cp, nil
+}
+
+func (r *fakeLeases) ReleaseLease(name, holder string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if l, ok := r.leases[name]; ok && l.Holder == holder {
+		l.ExpiresAt = time.Now()
+	}
+	return nil
+}
+
+func (r *fakeLeases) GetLeases() ([]Lease, error) { return nil, nil }
+
+func (r *fakeLeases) setDown(down bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.down = down
+}
+
+// leaders counts the instances leading at once
+type leaders struct {
+	mu      sync.Mutex
+	current []string
+	max     int
+}
+
+func (l *leaders) lead(id string) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		l.mu.Lock()
+		l.current = append(l.current, id)
+		if len(l.current) > l.max {
+			l.max = len(l.current)
+		}
+		l.mu.Unlock()
+
+		<-ctx.Done()
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		for i, c := range l.current {
+			if c == id {
+				l.current = append(l.current[:i], l.current[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func (l *leaders) get() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string{}, l.current...)
+}
+
+func waitFor(t *testing.T, timeout time.Duration, cond func() bool) {
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met within %s", timeout)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestElectorFailover(t *testing.T) {
+	repo := &fakeLeases{leases: make(map[string]*Lease)}
+	ttl := 150 * time.Millisecond
+	l := &leaders{}
+
+	ctxA, stopA := context.WithCancel(context.Background())
+	ctxB, stopB := context.WithCancel(context.Background())
+	defer stopB()
+	wg := sync.WaitGroup{}
+	for _, e := range []struct {
+		ctx context.Context
+		id  string
+	}{{ctxA, "a"}, {ctxB, "b"}} {
+		wg.Add(1)
+		go func(ctx context.Context, id string) {
+			defer wg.Done()
+			MkElector(repo, "eth-listener", id, ttl, logger.Get()).Run(ctx, l.lead(id))
+		}(e.ctx, e.id)
+		// a gets there first
+		waitFor(t, time.Second, func() bool { return len(l.get()) == 1 })
+	}
+
+	time.Sleep(2 * ttl)
+	if current := l.get(); len(current) != 1 || current[0] != "a" {
+		t.Fatalf("a should keep leading while renewing, leaders: %v", current)
+	}
+
+	// shutting down hands over
+	stopA()
+	waitFor(t, ttl, func() bool {
+		current := l.get()
+		return len(current) == 1 && current[0] == "b"
+	})
+
+	// a leader unable to renew steps down before its lease expires
+	repo.setDown(true)
+	waitFor(t, ttl, func() bool { return len(l.get()) == 0 })
+	repo.setDown(false)
+	waitFor(t, 2*ttl, func() bool { return len(l.get()) == 1 })
+
+	stopB()
+	wg.Wait()
+	if l.max != 1 {
+		t.Fatalf("%d instances led at once", l.max)
+	}
+}
+
+func TestElectorHandsOverEndedJob(t *testing.T) {
+	repo := &fakeLeases{leases: make(map[string]*Lease)}
+	ttl := 150 * time.Millisecond
+	l := &leaders{}
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	ended := make(chan struct{})
+	go MkElector(repo, "eth-listener", "a", ttl, logger.Get()).Run(ctx, func(context.Context) {
+		// e.g. a listener whose daemons were given up on
+		close(ended)
+	})
+	<-ended
+
+	go MkElector(repo, "eth-listener", "b", ttl, logger.Get()).Run(ctx, l.lead("b"))
+	waitFor(t, ttl, func() bool {
+		current := l.get()
+		return len(current) == 1 && current[0] == "b"
+	})
+}
@@ -0,0 +1,188 @@
+package cursor
+
+import (
+	"fmt"
+	"sync"
+
+	"bridge/common/consts"
+)
+
+// DefaultCatchUpSpan is the number of blocks a consumer behind catches up per scanning round,
+// so that it never holds back the round of the others for long
+var DefaultCatchUpSpan int64 = 5000
+
+// Lag is a consumer scanning behind its listener, From being the next block it's to scan
+type Lag struct {
+	Consumer string
+	From     int64
+}
+
+// Cursors tracks the last block each consumer of a listener has scanned. Consumers caught up
+// with the listener's own cursor are joined: its scan feeds them and moves their cursors
+// along. The others, newly added ones catching up from their start block or ones whose
+// scan failed, catch up on their own until they join.
+type Cursors struct {
+	mu     sync.RWMutex
+	chain  string
+	repo   consts.IScanCursorRepo
+	names  []string
+	start  map[string]int64
+	last   map[string]int64
+	joined map[string]bool
+}
+
+func MkCursors(chain string) *Cursors {
+	return &Cursors{
+		chain:  chain,
+		start:  make(map[string]int64),
+		last:   make(map[string]int64),
+		joined: make(map[string]bool),
+	}
+}
+
+// Register adds a consumer, scanning from start on when it has no cursor yet; from the
+// listener's cursor when start isn't positive
+func (c *Cursors) Register(name string, start int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.start[name]; ok {
+		return fmt.Errorf("consumer %s already registered", name)
+	}
+	c.names = append(c.names, name)
+	c.start[name] = start
+	c.joined[name] = true
+	return nil
+}
+
+// Enabled tells whether consumers have cursors of their own, they're all joined otherwise
+func (c *Cursors) Enabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.repo != nil
+}
+
+// Load reads the cursors from repo, creating the missing ones, main being the listener's
+// cursor
+func (c *Cursors) Load(repo consts.IScanCursorRepo, main int64) error {
+	stored, err := repo.GetCursors(c.chain)
+	if err != nil {
+		return err
+	}
+	known := make(map[string]int64, len(stored))
+	for _, s := range stored {
+		known[s.Consumer] = s.LastScannedBlock
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, name := range c.names {
+		last, ok := known[name]
+		if !ok {
+			last = main
+			if start := c.start[name]; start > 0 && start-1 < main {
+				last = start - 1
+			}
+			if err := repo.CreateCursor(&consts.ScanCursor{Chain: c.chain, Consumer: name, LastScannedBlock: last}); err != nil {
+				return err
+			}
+		}
+		c.last[name] = last
+		c.joined[name] = last >= main
+	}
+	c.repo = repo
+	return nil
+}
+
+// Joined tells whether the consumer is fed by the listener's scan
+func (c *Cursors) Joined(name string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.joined[name]
+}
+
+// Behind lists the consumers catching up, joining the ones which caught up with main
+func (c *Cursors) Behind(main int64) []Lag {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.repo == nil {
+		return nil
+	}
+	var lags []Lag
+	for _, name := range c.names {
+		if c.joined[name] {
+			continue
+		}
+		if c.last[name] >= main {
+			c.joined[name] = true
+			continue
+		}
+		lags = append(lags, Lag{Consumer: name, From: c.last[name] + 1})
+	}
+	return lags
+}
+
+// Leave makes a joined consumer whose scan failed catch up on its own from its cursor
+func (c *Cursors) Leave(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.repo != nil {
+		c.joined[name] = false
+	}
+}
+
+// Advance moves the cursor of a consumer to block
+func (c *Cursors) Advance(name string, block int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.repo == nil || block <= c.last[name] {
+		return nil
+	}
+	if err := c.repo.UpdateCursor(&consts.ScanCursor{Chain: c.chain, Consumer: name, LastScannedBlock: block}); err != nil {
+		return err
+	}
+	c.last[name] = block
+	return nil
+}
+
+// AdvanceJoined moves the cursors of the joined consumers along with the listener's
+func (c *Cursors) AdvanceJoined(block int64) error {
+	for _, name := range c.names {
+		if !c.Joined(name) {
+			continue
+		}
+		if err := c.Advance(name, block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rollback moves the cursors past block back to it, after a reorg
+func (c *Cursors) Rollback(block int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.repo == nil {
+		return nil
+	}
+	if err := c.repo.RollbackCursors(c.chain, block); err != nil {
+		return err
+	}
+	for name, last := range c.last {
+		if last > block {
+			c.last[name] = block
+		}
+	}
+	return nil
+}
+
+// CatchUpRange is the range of blocks a consumer behind is to scan next, towards main
+func CatchUpRange(lag Lag, main, span int64) (from, to int64, ok bool) {
+	if lag.From > main {
+		return 0, 0, false
+	}
+	to = lag.From + span - 1
+	if to > main {
+		to = main
+	}
+	return lag.From, to, true
+}
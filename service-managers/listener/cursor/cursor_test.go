@@ -0,0 +1,120 @@
+package cursor
+
+import (
+	"testing"
+
+	"bridge/common/consts"
+)
+
+type fakeRepo struct {
+	cursors map[string]int64
+}
+
+func (r *fakeRepo) GetCursors(chain string) ([]consts.ScanCursor, error) {
+	var res []consts.ScanCursor
+	for name, last := range r.cursors {
+		res = append(res, consts.ScanCursor{Chain: chain, Consumer: name, LastScannedBlock: last})
+	}
+	return res, nil
+}
+
+func (r *fakeRepo) CreateCursor(c *consts.ScanCursor) error {
+	if _, ok := r.cursors[c.Consumer]; !ok {
+		r.cursors[c.Consumer] = c.LastScannedBlock
+	}
+	return nil
+}
+
+func (r *fakeRepo) UpdateCursor(c *consts.ScanCursor) error {
+	r.cursors[c.Consumer] = c.LastScannedBlock
+	return nil
+}
+
+func (r *fakeRepo) RollbackCursors(chain string, block int64) error {
+	for name, last := range r.cursors {
+		if last > block {
+			r.cursors[name] = block
+		}
+	}
+	return nil
+}
+
+func TestCursors(t *testing.T) {
+	c := MkCursors("eth")
+	if err := c.Register("bridge", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Register("new", 500); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Register("new", 0); err == nil {
+		t.Fatalf("registering a consumer twice should fail")
+	}
+	if c.Enabled() || !c.Joined("new") || c.Behind(1000) != nil {
+		t.Fatalf("consumers should share the listener's cursor until cursors are loaded")
+	}
+
+	repo := &fakeRepo{cursors: map[string]int64{"old": 10}}
+	if err := c.Load(repo, 1000); err != nil {
+		t.Fatal(err)
+	}
+	if repo.cursors["bridge"] != 1000 || repo.cursors["new"] != 499 {
+		t.Fatalf("wrong cursors created: %v", repo.cursors)
+	}
+	if !c.Joined("bridge") || c.Joined("new") {
+		t.Fatalf("only the consumer starting from the listener's cursor should be joined")
+	}
+	lags := c.Behind(1000)
+	if len(lags) != 1 || lags[0] != (Lag{Consumer: "new", From: 500}) {
+		t.Fatalf("wrong lags: %+v", lags)
+	}
+
+	if err := c.AdvanceJoined(1010); err != nil {
+		t.Fatal(err)
+	}
+	if repo.cursors["bridge"] != 1010 || repo.cursors["new"] != 499 {
+		t.Fatalf("only joined cursors should move along: %v", repo.cursors)
+	}
+	if err := c.Advance("new", 1010); err != nil {
+		t.Fatal(err)
+	}
+	if lags := c.Behind(1010); len(lags) != 0 || !c.Joined("new") {
+		t.Fatalf("caught up consumer should join, lags: %+v", lags)
+	}
+
+	c.Leave("bridge")
+	if c.Joined("bridge") {
+		t.Fatalf("failed consumer should catch up on its own")
+	}
+	if err := c.Rollback(1005); err != nil {
+		t.Fatal(err)
+	}
+	if repo.cursors["bridge"] != 1005 || repo.cursors["old"] != 10 {
+		t.Fatalf("wrong cursors after rollback: %v", repo.cursors)
+	}
+	if lags := c.Behind(1008); len(lags) != 1 || lags[0].From != 1006 {
+		t.Fatalf("wrong lags after rollback: %+v", lags)
+	}
+
+	// an existing cursor isn't reset by the start block
+	c = MkCursors("eth")
+	c.Register("new", 1)
+	if err := c.Load(repo, 2000); err != nil {
+		t.Fatal(err)
+	}
+	if lags := c.Behind(2000); len(lags) != 1 || lags[0].From != 1006 {
+		t.Fatalf("stored cursor should be resumed, lags: %+v", lags)
+	}
+}
+
+func TestCatchUpRange(t *testing.T) {
+	if from, to, ok := CatchUpRange(Lag{From: 100}, 10000, 5000); !ok || from != 100 || to != 5099 {
+		t.Fatalf("wrong range [%d, %d]", from, to)
+	}
+	if from, to, ok := CatchUpRange(Lag{From: 9000}, 10000, 5000); !ok || from != 9000 || to != 10000 {
+		t.Fatalf("range should stop at the listener's cursor, got [%d, %d]", from, to)
+	}
+	if _, _, ok := CatchUpRange(Lag{From: 10001}, 10000, 5000); ok {
+		t.Fatalf("caught up consumer has nothing to scan")
+	}
+}
@@ -0,0 +1,164 @@
+package endpoint
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Stat is a snapshot of an endpoint's health
+type Stat struct {
+	Address   string        `json:"address"`
+	Healthy   bool          `json:"healthy"`
+	Latency   time.Duration `json:"latency"`
+	Head      int64         `json:"head"`
+	LastError string        `json:"last_error,omitempty"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+// Set keeps the health of a chain's endpoints, given in order of priority, and which of
+// them calls go to. It only does the bookkeeping, the clients are kept by the caller and
+// indexed the same way.
+type Set struct {
+	mu        sync.RWMutex
+	addresses []string
+	stats     []Stat
+	current   int
+
+	tag    string
+	maxLag int64
+	logger *zerolog.Logger
+}
+
+// MkSet starts every endpoint healthy; an endpoint whose head is more than maxLag blocks
+// behind the highest one is considered unhealthy. tag prefixes the log messages
+func MkSet(tag string, addresses []string, maxLag int64, logger *zerolog.Logger) *Set {
+	s := &Set{
+		addresses: addresses,
+		stats:     make([]Stat, len(addresses)),
+		tag:       tag,
+		maxLag:    maxLag,
+		logger:    logger,
+	}
+	for i, addr := range addresses {
+		s.stats[i] = Stat{Address: addr, Healthy: true}
+	}
+	return s
+}
+
+func (s *Set) Len() int {
+	return len(s.addresses)
+}
+
+func (s *Set) Address(idx int) string {
+	return s.addresses[idx]
+}
+
+func (s *Set) Stats() []Stat {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res := make([]Stat, len(s.stats))
+	copy(res, s.stats)
+	return res
+}
+
+// Check probes the head of every endpoint concurrently, marks the failing and lagging ones
+// unhealthy and switches back to the highest priority healthy one
+func (s *Set) Check(probe func(idx int) (int64, error)) {
+	var wg sync.WaitGroup
+	results := make([]Stat, len(s.addresses))
+	for i := range s.addresses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			start := time.Now()
+			head, err := probe(i)
+			results[i] = Stat{Healthy: err == nil, Latency: time.Since(start), Head: head, CheckedAt: time.Now()}
+			if err != nil {
+				results[i].LastError = err.Error()
+			}
+		}(i)
+	}
+	wg.Wait()
+	s.update(results)
+}
+
+func (s *Set) update(results []Stat) {
+	var maxHead int64
+	for _, r := range results {
+		if r.Healthy && r.Head > maxHead {
+			maxHead = r.Head
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, r := range results {
+		if r.Healthy && r.Head+s.maxLag < maxHead {
+			r.Healthy = false
+			r.LastError = fmt.Sprintf("lagging %d blocks behind", maxHead-r.Head)
+		}
+		r.Address = s.addresses[i]
+		s.stats[i] = r
+	}
+	for i, st := range s.stats {
+		if st.Healthy {
+			if i != s.current {
+				s.logger.Info().Msgf("%s Switching to %s", s.tag, st.Address)
+			}
+			s.current = i
+			return
+		}
+	}
+	s.logger.Warn().Msgf("%s No healthy endpoint", s.tag)
+}
+
+// Pick returns the current endpoint, or the first healthy one after it when the current
+// one was marked unhealthy since the last check
+func (s *Set) Pick() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	n := len(s.stats)
+	for i := 0; i < n; i++ {
+		idx := (s.current + i) % n
+		if s.stats[idx].Healthy {
+			return idx
+		}
+	}
+	return s.current
+}
+
+// MarkFailed takes an endpoint out of rotation until the next check finds it healthy
+func (s *Set) MarkFailed(idx int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := &s.stats[idx]
+	st.Healthy = false
+	st.LastError = err.Error()
+	st.CheckedAt = time.Now()
+	if idx == s.current {
+		s.current = (idx + 1) % len(s.stats)
+		s.logger.Warn().Msgf("%s %s failed, rotating to %s", s.tag, st.Address, s.stats[s.current].Address)
+	}
+}
+
+// Call runs f on the picked endpoint, moving on to the next one as long as isFailure tells
+// the error is the endpoint's fault, at most once per endpoint
+func Call[T any](s *Set, method string, isFailure func(err error) bool, f func(idx int) (T, error)) (T, error) {
+	var (
+		res T
+		err error
+	)
+	for attempt := 0; attempt < s.Len(); attempt++ {
+		idx := s.Pick()
+		res, err = f(idx)
+		if !isFailure(err) {
+			return res, err
+		}
+		s.logger.Err(err).Msgf("%s %s failed on %s", s.tag, method, s.Address(idx))
+		s.MarkFailed(idx, err)
+	}
+	return res, err
+}
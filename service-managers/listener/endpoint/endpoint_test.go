@@ -0,0 +1,77 @@
+package endpoint
+
+import (
+	"fmt"
+	"testing"
+
+	"bridge/service-managers/logger"
+)
+
+func TestSetCheck(t *testing.T) {
+	s := MkSet("[test]", []string{"node0", "node1", "node2"}, 5, logger.Get())
+	heads := []int64{100, 110, 0}
+	s.Check(func(idx int) (int64, error) {
+		if idx == 2 {
+			return 0, fmt.Errorf("down")
+		}
+		return heads[idx], nil
+	})
+
+	stats := s.Stats()
+	if stats[0].Healthy || stats[0].LastError != "lagging 10 blocks behind" {
+		t.Errorf("lagging endpoint should be unhealthy: %+v", stats[0])
+	}
+	if !stats[1].Healthy || stats[1].Head != 110 {
+		t.Errorf("endpoint at the highest head should be healthy: %+v", stats[1])
+	}
+	if stats[2].Healthy || stats[2].LastError != "down" || stats[2].Address != "node2" {
+		t.Errorf("failing endpoint should be unhealthy: %+v", stats[2])
+	}
+	if idx := s.Pick(); idx != 1 {
+		t.Errorf("expected to switch to node1, picked %d", idx)
+	}
+
+	// back in sync, the highest priority endpoint is preferred again
+	heads[0] = 110
+	s.Check(func(idx int) (int64, error) { return heads[idx], nil })
+	if idx := s.Pick(); idx != 0 {
+		t.Errorf("expected to switch back to node0, picked %d", idx)
+	}
+}
+
+func TestCallRotation(t *testing.T) {
+	s := MkSet("[test]", []string{"node0", "node1", "node2"}, 5, logger.Get())
+	down := fmt.Errorf("down")
+	isFailure := func(err error) bool { return err == down }
+
+	calls := []int{}
+	res, err := Call(s, "Test", isFailure, func(idx int) (int, error) {
+		calls = append(calls, idx)
+		if idx != 2 {
+			return 0, down
+		}
+		return 42, nil
+	})
+	if err != nil || res != 42 || len(calls) != 3 {
+		t.Fatalf("expected to end up on node2, got %v, %d, %v", calls, res, err)
+	}
+	if idx := s.Pick(); idx != 2 {
+		t.Fatalf("failed endpoints should be skipped, picked %d", idx)
+	}
+
+	// errors any endpoint would return aren't retried
+	calls = calls[:0]
+	_, err = Call(s, "Test", isFailure, func(idx int) (int, error) {
+		calls = append(calls, idx)
+		return 0, fmt.Errorf("not found")
+	})
+	if err == nil || len(calls) != 1 {
+		t.Fatalf("expected a single attempt, got %v, %v", calls, err)
+	}
+
+	// every endpoint failing returns the last error
+	_, err = Call(s, "Test", isFailure, func(idx int) (int, error) { return 0, down })
+	if err != down {
+		t.Fatalf("expected the endpoint failure, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultBackfillChunk is the number of blocks fetched at once while backfilling
+var DefaultBackfillChunk int64 = 100
+
+// Backfill replays the events of blocks [from, to] through the registered consumers, one
+// at a time in chain order; the last scanned block is left as is. Consumer errors are
+// logged, only failures to fetch logs abort the backfill
+func (s *EthListener) Backfill(ctx context.Context, from, to int64) (replayed int, err error) {
+	for begin := from; begin <= to; begin += DefaultBackfillChunk {
+		end := begin + DefaultBackfillChunk - 1
+		if end > to {
+			end = to
+		}
+		s.Logger.Info().Msgf("[eth_listener] backfilling blocks %d to %d", begin, end)
+
+		var logs []types.Log
+		for _, query := range s.EventFilters {
+			query.FromBlock = big.NewInt(begin)
+			query.ToBlock = big.NewInt(end)
+			events, err := s.EthClient.FilterLogs(ctx, query)
+			if err != nil {
+				s.Logger.Err(err).Msgf("[eth_listener] can't get logs of blocks %d to %d", begin, end)
+				return replayed, err
+			}
+			logs = append(logs, events...)
+		}
+		sortLogs(logs)
+
+		for _, vLog := range logs {
+			if vLog.Removed || len(vLog.Topics) == 0 {
+				continue
+			}
+			consumer, ok := s.matchEvent(vLog)
+			if !ok {
+				continue
+			}
+			replayed++
+			if err := consumer.ParseEvent(vLog); err != nil {
+				s.Logger.Warn().Err(err).Msgf("[eth_listener] backfill: event %d of tx %s not consumed", vLog.Index, vLog.TxHash.Hex())
+			}
+		}
+	}
+	return replayed, nil
+}
+
+// sortLogs orders logs gathered by several queries as they were emitted
+func sortLogs(logs []types.Log) {
+	sort.SliceStable(logs, func(i, j int) bool {
+		if logs[i].BlockNumber != logs[j].BlockNumber {
+			return logs[i].BlockNumber < logs[j].BlockNumber
+		}
+		return logs[i].Index < logs[j].Index
+	})
+}
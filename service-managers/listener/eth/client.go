@@ -0,0 +1,264 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"bridge/common/consts"
+	"bridge/service-managers/listener/endpoint"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/rs/zerolog"
+)
+
+// IEthClient is the subset of ethclient.Client used across the project, satisfied by both
+// *ethclient.Client and *MultiClient
+type IEthClient interface {
+	bind.ContractBackend
+	bind.DeployBackend
+
+	BlockNumber(ctx context.Context) (uint64, error)
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error)
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+}
+
+var (
+	_ IEthClient = (*ethclient.Client)(nil)
+	_ IEthClient = (*MultiClient)(nil)
+)
+
+var (
+	ErrNoRPCEndpoint      = fmt.Errorf("No ethereum RPC endpoint configured")
+	DefaultHealthInterval = 15 * time.Second
+	DefaultRPCTimeout     = 10 * time.Second
+	// ethereum heads move every few seconds, an endpoint more than a minute behind the
+	// others is considered unhealthy
+	DefaultMaxHeadLag int64 = 5
+)
+
+type EndpointStat = endpoint.Stat
+
+// MultiClient wraps several ethereum RPC endpoints, periodically comparing their block
+// numbers, and fails over to the next healthy endpoint, in configured order, whenever a
+// call fails for a reason another endpoint might not share
+type MultiClient struct {
+	clients []*ethclient.Client
+	health  *endpoint.Set
+
+	healthInterval time.Duration
+	logger         *zerolog.Logger
+}
+
+func NewMultiClient(urls []string, logger *zerolog.Logger) (*MultiClient, error) {
+	m := &MultiClient{
+		healthInterval: DefaultHealthInterval,
+		logger:         logger,
+	}
+	var dialed []string
+	seen := make(map[string]bool)
+	for _, url := range urls {
+		if url == "" || seen[url] {
+			continue
+		}
+		seen[url] = true
+
+		cli, err := ethclient.Dial(url)
+		if err != nil {
+			logger.Err(err).Msgf("[eth client] Unable to dial RPC endpoint %s", url)
+			continue
+		}
+		m.clients = append(m.clients, cli)
+		dialed = append(dialed, url)
+	}
+	if len(m.clients) == 0 {
+		return nil, ErrNoRPCEndpoint
+	}
+	m.health = endpoint.MkSet("[eth client]", dialed, DefaultMaxHeadLag, logger)
+	m.checkHealth(context.Background())
+	return m, nil
+}
+
+func (m *MultiClient) Close() {
+	for _, cli := range m.clients {
+		cli.Close()
+	}
+}
+
+// HealthCheck is a daemon generator periodically checking all endpoints
+func (m *MultiClient) HealthCheck(ctx context.Context) (consts.Daemon, error) {
+	return func() {
+		m.logger.Info().Msg("[eth client] Start health checking RPC endpoints")
+		for {
+			consts.SleepContext(ctx, m.healthInterval)
+			select {
+			case <-ctx.Done():
+				m.logger.Info().Msg("[eth client] Stop health checking RPC endpoints")
+				return
+			default:
+				m.checkHealth(ctx)
+			}
+		}
+	}, nil
+}
+
+func (m *MultiClient) Stats() []EndpointStat {
+	return m.health.Stats()
+}
+
+func (m *MultiClient) checkHealth(ctx context.Context) {
+	m.health.Check(func(idx int) (int64, error) {
+		cctx, cancel := context.WithTimeout(ctx, DefaultRPCTimeout)
+		defer cancel()
+		head, err := m.clients[idx].BlockNumber(cctx)
+		return int64(head), err
+	})
+}
+
+// isEndpointFailure tells apart node/transport failures, which warrant trying another
+// endpoint, from JSON-RPC errors and missing results that any endpoint would return
+func isEndpointFailure(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil || errors.Is(err, ethereum.NotFound) {
+		return false
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		// limit exceeded
+		return rpcErr.ErrorCode() == -32005
+	}
+	return true
+}
+
+func call[T any](ctx context.Context, m *MultiClient, method string, f func(cli *ethclient.Client) (T, error)) (T, error) {
+	isFailure := func(err error) bool { return isEndpointFailure(ctx, err) }
+	return endpoint.Call(m.health, method, isFailure, func(idx int) (T, error) {
+		return f(m.clients[idx])
+	})
+}
+
+func (m *MultiClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return call(ctx, m, "HeaderByNumber", func(cli *ethclient.Client) (*types.Header, error) {
+		return cli.HeaderByNumber(ctx, number)
+	})
+}
+
+func (m *MultiClient) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	return call(ctx, m, "BlockByNumber", func(cli *ethclient.Client) (*types.Block, error) {
+		return cli.BlockByNumber(ctx, number)
+	})
+}
+
+func (m *MultiClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return call(ctx, m, "BlockNumber", func(cli *ethclient.Client) (uint64, error) {
+		return cli.BlockNumber(ctx)
+	})
+}
+
+func (m *MultiClient) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	return call(ctx, m, "FilterLogs", func(cli *ethclient.Client) ([]types.Log, error) {
+		return cli.FilterLogs(ctx, query)
+	})
+}
+
+func (m *MultiClient) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return call(ctx, m, "SubscribeFilterLogs", func(cli *ethclient.Client) (ethereum.Subscription, error) {
+		return cli.SubscribeFilterLogs(ctx, query, ch)
+	})
+}
+
+func (m *MultiClient) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	var isPending bool
+	tx, err := call(ctx, m, "TransactionByHash", func(cli *ethclient.Client) (*types.Transaction, error) {
+		tx, pending, err := cli.TransactionByHash(ctx, hash)
+		isPending = pending
+		return tx, err
+	})
+	return tx, isPending, err
+}
+
+func (m *MultiClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return call(ctx, m, "TransactionReceipt", func(cli *ethclient.Client) (*types.Receipt, error) {
+		return cli.TransactionReceipt(ctx, txHash)
+	})
+}
+
+func (m *MultiClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return call(ctx, m, "BalanceAt", func(cli *ethclient.Client) (*big.Int, error) {
+		return cli.BalanceAt(ctx, account, blockNumber)
+	})
+}
+
+func (m *MultiClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return call(ctx, m, "NonceAt", func(cli *ethclient.Client) (uint64, error) {
+		return cli.NonceAt(ctx, account, blockNumber)
+	})
+}
+
+func (m *MultiClient) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return call(ctx, m, "CodeAt", func(cli *ethclient.Client) ([]byte, error) {
+		return cli.CodeAt(ctx, contract, blockNumber)
+	})
+}
+
+func (m *MultiClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return call(ctx, m, "CallContract", func(cli *ethclient.Client) ([]byte, error) {
+		return cli.CallContract(ctx, msg, blockNumber)
+	})
+}
+
+func (m *MultiClient) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return call(ctx, m, "PendingCodeAt", func(cli *ethclient.Client) ([]byte, error) {
+		return cli.PendingCodeAt(ctx, account)
+	})
+}
+
+func (m *MultiClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return call(ctx, m, "PendingNonceAt", func(cli *ethclient.Client) (uint64, error) {
+		return cli.PendingNonceAt(ctx, account)
+	})
+}
+
+func (m *MultiClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return call(ctx, m, "SuggestGasPrice", func(cli *ethclient.Client) (*big.Int, error) {
+		return cli.SuggestGasPrice(ctx)
+	})
+}
+
+func (m *MultiClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return call(ctx, m, "SuggestGasTipCap", func(cli *ethclient.Client) (*big.Int, error) {
+		return cli.SuggestGasTipCap(ctx)
+	})
+}
+
+func (m *MultiClient) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return call(ctx, m, "EstimateGas", func(cli *ethclient.Client) (uint64, error) {
+		return cli.EstimateGas(ctx, msg)
+	})
+}
+
+// SendTransaction fails a signed transaction over to the next endpoint like any call. The
+// endpoint that failed may have accepted it before failing, so the next one answering
+// "already known" means it's in the mempool and the send went through.
+func (m *MultiClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	failedOver := false
+	_, err := call(ctx, m, "SendTransaction", func(cli *ethclient.Client) (struct{}, error) {
+		err := cli.SendTransaction(ctx, tx)
+		if failedOver && err != nil && strings.Contains(err.Error(), "already known") {
+			m.logger.Info().Msgf("[eth client] Tx %s already known after failing over", tx.Hash().Hex())
+			return struct{}{}, nil
+		}
+		failedOver = true
+		return struct{}{}, err
+	})
+	return err
+}
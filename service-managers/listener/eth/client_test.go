@@ -0,0 +1,134 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bridge/service-managers/logger"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func rpcServer(head string, fail *bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if *fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result":  head,
+		})
+	}))
+}
+
+func TestMultiClientFailover(t *testing.T) {
+	primaryDown, backupDown := false, false
+	primary := rpcServer("0x10", &primaryDown)
+	defer primary.Close()
+	backup := rpcServer("0x11", &backupDown)
+	defer backup.Close()
+
+	cli, err := NewMultiClient([]string{primary.URL, backup.URL, primary.URL}, logger.Get())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	if len(cli.Stats()) != 2 {
+		t.Fatalf("duplicated endpoints should be ignored, got %+v", cli.Stats())
+	}
+
+	head, err := cli.BlockNumber(context.Background())
+	if err != nil || head != 0x10 {
+		t.Fatalf("expected head 0x10 from primary, got %d, %v", head, err)
+	}
+
+	primaryDown = true
+	head, err = cli.BlockNumber(context.Background())
+	if err != nil || head != 0x11 {
+		t.Fatalf("expected head 0x11 from backup, got %d, %v", head, err)
+	}
+	if stats := cli.Stats(); stats[0].Healthy || !stats[1].Healthy {
+		t.Fatalf("primary should be marked unhealthy: %+v", stats)
+	}
+
+	primaryDown = false
+	cli.checkHealth(context.Background())
+	head, err = cli.BlockNumber(context.Background())
+	if err != nil || head != 0x10 {
+		t.Fatalf("expected to switch back to primary, got %d, %v", head, err)
+	}
+}
+
+func TestMultiClientNoEndpoint(t *testing.T) {
+	if _, err := NewMultiClient([]string{""}, logger.Get()); err != ErrNoRPCEndpoint {
+		t.Fatalf("expected ErrNoRPCEndpoint, got %v", err)
+	}
+}
+
+// knownTxServer answers blockNumber, and refuses every transaction as already known
+func knownTxServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		res := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if req.Method == "eth_sendRawTransaction" {
+			res["error"] = map[string]interface{}{"code": -32000, "message": "already known"}
+		} else {
+			res["result"] = "0x10"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}))
+}
+
+func TestMultiClientSendAlreadyKnown(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	tx, err := types.SignTx(types.NewTransaction(0, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	primaryDown := false
+	primary := rpcServer("0x10", &primaryDown)
+	defer primary.Close()
+	backup := knownTxServer()
+	defer backup.Close()
+
+	cli, err := NewMultiClient([]string{backup.URL, primary.URL}, logger.Get())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	// refused by the endpoint it was first sent to
+	if err := cli.SendTransaction(context.Background(), tx); err == nil {
+		t.Fatalf("already known tx should be refused without a failover")
+	}
+
+	cli, err = NewMultiClient([]string{primary.URL, backup.URL}, logger.Get())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	// the failed endpoint may have taken it before failing
+	primaryDown = true
+	if err := cli.SendTransaction(context.Background(), tx); err != nil {
+		t.Fatalf("already known tx after a failover should be sent, got %v", err)
+	}
+}
@@ -22,5 +22,6 @@ type EventParser func(types.Log) error
 //-----------------------------------------------------------//
 type ITxMonitor interface {
 	MonitoredAddress() common.Address
-	TxParse(t *types.Transaction, from, to, tokenAddr, amount string) error
+	// logIndex is the index of the token's Transfer log, model.EthValueLogIndex for ether
+	TxParse(t *types.Transaction, logIndex int64, from, to, tokenAddr, amount string) error
 }
@@ -0,0 +1,90 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+
+	"bridge/service-managers/listener/cursor"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+// registeredConsumer is a consumer's name along with the filter queries of its events
+type registeredConsumer struct {
+	name    string
+	filters []ethereum.FilterQuery
+}
+
+// eventsScanFn scans the events of the consumers over the blocks, returning the ones it failed for
+type eventsScanFn func(from, to *big.Int, consumers []registeredConsumer) (failed []string)
+
+// joinedConsumers are the consumers fed by the listener's scan
+func (s *EthListener) joinedConsumers() []registeredConsumer {
+	var joined []registeredConsumer
+	for _, c := range s.consumers {
+		if s.cursors.Joined(c.name) {
+			joined = append(joined, c)
+		}
+	}
+	return joined
+}
+
+// leaveFailed makes the consumers the listener's scan failed for catch up on their own,
+// telling whether the listener's cursor can move on. It can't when they all failed, or when
+// consumers share the listener's cursor.
+func (s *EthListener) leaveFailed(failed []string, joined []registeredConsumer) bool {
+	if len(failed) == 0 {
+		return true
+	}
+	if !s.cursors.Enabled() || len(failed) == len(joined) {
+		return false
+	}
+	for _, name := range failed {
+		s.Logger.Warn().Msgf("[eth_listener] consumer %s falling behind, it catches up on its own", name)
+		s.cursors.Leave(name)
+	}
+	return true
+}
+
+func (s *EthListener) advanceCursors(block int64) {
+	if err := s.cursors.AdvanceJoined(block); err != nil {
+		s.Logger.Err(err).Msg("[eth_listener] can't update consumers' scan cursors")
+	}
+}
+
+// catchUp scans a span of blocks for each consumer behind the listener's cursor main,
+// telling whether some are still behind after making progress
+func (s *EthListener) catchUp(ctx context.Context, main int64, scan eventsScanFn) (behind bool) {
+	for _, lag := range s.cursors.Behind(main) {
+		from, to, ok := cursor.CatchUpRange(lag, main, cursor.DefaultCatchUpSpan)
+		if !ok {
+			continue
+		}
+		var consumer []registeredConsumer
+		for _, c := range s.consumers {
+			if c.name == lag.Consumer {
+				consumer = append(consumer, c)
+			}
+		}
+		s.Logger.Info().Msgf("[eth_listener] consumer %s catching up with blocks %d to %d", lag.Consumer, from, to)
+
+		progressed := true
+		for begin := from; begin <= to && ctx.Err() == nil; begin += DefaultBackfillChunk {
+			end := begin + DefaultBackfillChunk - 1
+			if end > to {
+				end = to
+			}
+			if failed := scan(big.NewInt(begin), big.NewInt(end), consumer); len(failed) > 0 {
+				progressed = begin > from
+				break
+			}
+			if err := s.cursors.Advance(lag.Consumer, end); err != nil {
+				s.Logger.Err(err).Msgf("[eth_listener] can't update scan cursor of consumer %s", lag.Consumer)
+				progressed = false
+				break
+			}
+		}
+		behind = behind || progressed
+	}
+	return behind
+}
@@ -0,0 +1,100 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// topic of the ERC20 event Transfer(address indexed from, address indexed to, uint256 value)
+var ERC20TransferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// WatchTokens restricts ERC20 transfers reported to tx monitors to the given token contracts
+func (s *EthListener) WatchTokens(tokens ...common.Address) {
+	s.tokensMu.Lock()
+	defer s.tokensMu.Unlock()
+	for _, token := range tokens {
+		if token == (common.Address{}) { // native ETH, picked up by the block scanner
+			continue
+		}
+		s.Tokens = append(s.Tokens, token)
+	}
+}
+
+// SetWatchedTokens replaces the watched token contracts, it's safe to call while the
+// listener is running and applies from the next scan
+func (s *EthListener) SetWatchedTokens(tokens ...common.Address) {
+	s.tokensMu.Lock()
+	s.Tokens = nil
+	s.tokensMu.Unlock()
+	s.WatchTokens(tokens...)
+}
+
+func (s *EthListener) watchedTokens() []common.Address {
+	s.tokensMu.RLock()
+	defer s.tokensMu.RUnlock()
+	return append([]common.Address{}, s.Tokens...)
+}
+
+// tokenTransferQuery filters Transfer events of tokens to any monitored address, tokens must
+// not be empty or the query matches the transfers of every contract
+func (s *EthListener) tokenTransferQuery(tokens []common.Address, from, to *big.Int) ethereum.FilterQuery {
+	monitored := make([]common.Hash, 0, len(s.TxMonitors))
+	for address := range s.TxMonitors {
+		monitored = append(monitored, common.BytesToHash(address.Bytes()))
+	}
+	return ethereum.FilterQuery{
+		FromBlock: from,
+		ToBlock:   to,
+		Addresses: tokens,
+		Topics:    [][]common.Hash{{ERC20TransferTopic}, nil, monitored},
+	}
+}
+
+// parseTokenTransfer decodes a Transfer log, ERC721 transfers (value indexed as a 4th
+// topic) and removed logs are rejected
+func parseTokenTransfer(vLog types.Log) (from, to common.Address, amount *big.Int, ok bool) {
+	if vLog.Removed || len(vLog.Topics) != 3 || vLog.Topics[0] != ERC20TransferTopic || len(vLog.Data) != 32 {
+		return common.Address{}, common.Address{}, nil, false
+	}
+	from = common.BytesToAddress(vLog.Topics[1].Bytes())
+	to = common.BytesToAddress(vLog.Topics[2].Bytes())
+	amount = new(big.Int).SetBytes(vLog.Data)
+	return from, to, amount, true
+}
+
+// scanTokenTransfers reports ERC20 transfers of tokens to monitored addresses in blocks
+// [from, to], tokens being a snapshot of the watched tokens taken for the whole scan
+func (s *EthListener) scanTokenTransfers(ctx context.Context, tokens []common.Address, from, to *big.Int) {
+	if len(tokens) == 0 {
+		return
+	}
+	logs, err := s.EthClient.FilterLogs(ctx, s.tokenTransferQuery(tokens, from, to))
+	if err != nil {
+		s.Logger.Err(err).Msg("[eth_listener] Ethereum token transfer query err")
+		s.errC <- err
+		return
+	}
+	for _, vLog := range logs {
+		sender, receiver, amount, ok := parseTokenTransfer(vLog)
+		if !ok {
+			continue
+		}
+		monitor, ok := s.TxMonitors[receiver]
+		if !ok {
+			continue
+		}
+		t, _, err := s.EthClient.TransactionByHash(ctx, vLog.TxHash)
+		if err != nil {
+			s.Logger.Err(err).Msgf("[eth_listener] Unable to get token transfer tx %s", vLog.TxHash.Hex())
+			s.errC <- err
+			continue
+		}
+		s.Logger.Info().Msgf("[eth_listener] token %s transfer to %s in tx %s", vLog.Address.Hex(), receiver.Hex(), vLog.TxHash.Hex())
+		monitor.TxParse(t, int64(vLog.Index), sender.Hex(), receiver.Hex(), vLog.Address.Hex(), amount.String())
+	}
+}
@@ -0,0 +1,66 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type nopMonitor common.Address
+
+func (m nopMonitor) MonitoredAddress() common.Address { return common.Address(m) }
+func (m nopMonitor) TxParse(t *types.Transaction, logIndex int64, from, to, tokenAddr, amount string) error {
+	return nil
+}
+
+func TestTokenTransferQuery(t *testing.T) {
+	treasury := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	token := common.HexToAddress("0xb60bd744550b46DBBDc3f17ccea62E619d772502")
+
+	s := &EthListener{TxMonitors: make(map[common.Address]ITxMonitor)}
+	s.TxMonitors[treasury] = nopMonitor(treasury)
+	s.WatchTokens(common.Address{}, token)
+	if len(s.Tokens) != 1 || s.Tokens[0] != token {
+		t.Fatalf("native token should not be watched, got %v", s.Tokens)
+	}
+
+	q := s.tokenTransferQuery(s.watchedTokens(), big.NewInt(1), big.NewInt(2))
+	if len(q.Addresses) != 1 || q.Addresses[0] != token {
+		t.Fatalf("expected the watched token as address, got %v", q.Addresses)
+	}
+	if len(q.Topics) != 3 || q.Topics[0][0] != ERC20TransferTopic || q.Topics[1] != nil {
+		t.Fatalf("unexpected topics %v", q.Topics)
+	}
+	if len(q.Topics[2]) != 1 || common.BytesToAddress(q.Topics[2][0].Bytes()) != treasury {
+		t.Fatalf("expected treasury as indexed recipient, got %v", q.Topics[2])
+	}
+}
+
+func TestParseTokenTransfer(t *testing.T) {
+	sender := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	treasury := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	vLog := types.Log{
+		Topics: []common.Hash{ERC20TransferTopic, common.BytesToHash(sender.Bytes()), common.BytesToHash(treasury.Bytes())},
+		Data:   common.BigToHash(big.NewInt(12345)).Bytes(),
+	}
+
+	from, to, amount, ok := parseTokenTransfer(vLog)
+	if !ok || from != sender || to != treasury || amount.Int64() != 12345 {
+		t.Fatalf("wrong transfer decoded: %s -> %s, %v", from.Hex(), to.Hex(), amount)
+	}
+
+	vLog.Removed = true
+	if _, _, _, ok := parseTokenTransfer(vLog); ok {
+		t.Fatalf("removed logs must be rejected")
+	}
+
+	// ERC721 Transfer shares the signature but indexes the token id
+	vLog.Removed = false
+	vLog.Topics = append(vLog.Topics, common.BigToHash(big.NewInt(1)))
+	vLog.Data = nil
+	if _, _, _, ok := parseTokenTransfer(vLog); ok {
+		t.Fatalf("ERC721 transfers must be rejected")
+	}
+}
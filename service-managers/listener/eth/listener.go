@@ -5,35 +5,46 @@ import (
 	"fmt"
 	"math/big"
 	"sync"
+	"time"
 
 	"bridge/common/consts"
 	"bridge/micros/weleth/model"
 	"bridge/service-managers/daemon"
+	"bridge/service-managers/listener/cursor"
 	"bridge/service-managers/logger"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/rs/zerolog"
 )
 
 type EthListener struct {
 	EthInfo          consts.IEthInfoRepo
 	Log              chan types.Log
-	EthClient        *ethclient.Client
+	EthClient        IEthClient
 	EventFilters     []ethereum.FilterQuery
 	EventConsumerMap map[string]*EventConsumer
+	consumers        []registeredConsumer
+	consumerNames    map[*EventConsumer]string
 	TxMonitors       map[common.Address]ITxMonitor
+	Tokens           []common.Address
+	tokensMu         sync.RWMutex
+	ReorgConsumers   []IReorgConsumer
+	Queue            IEventQueue            // optional, matched logs go through it instead of Log
+	Subscriber       IEthSubscriber         // optional, rounds are driven by pushed heads instead of polling
+	ScanCursors      consts.IScanCursorRepo // optional, consumers keep a cursor of their own in it
+	cursors          *cursor.Cursors
 	Logger           *zerolog.Logger
 	errC             chan error
 	blockTime        uint64
 	blockOffset      int64
+	blockHashes      *blockWindow
 }
 
 func NewEthListener(
 	ethInfo consts.IEthInfoRepo,
-	ethClient *ethclient.Client,
+	ethClient IEthClient,
 	blockTime uint64,
 	blockOffset int64,
 	logger *zerolog.Logger,
@@ -42,12 +53,15 @@ func NewEthListener(
 		EthInfo:          ethInfo,
 		EthClient:        ethClient,
 		EventConsumerMap: make(map[string]*EventConsumer),
+		consumerNames:    make(map[*EventConsumer]string),
 		TxMonitors:       make(map[common.Address]ITxMonitor),
 		Log:              make(chan types.Log),
 		errC:             make(chan error),
 		Logger:           logger,
 		blockTime:        blockTime,
 		blockOffset:      blockOffset,
+		blockHashes:      newBlockWindow(DefaultReorgWindow),
+		cursors:          cursor.MkCursors("eth"),
 	}
 }
 
@@ -59,21 +73,39 @@ func (s *EthListener) AddFilterQuery(query ethereum.FilterQuery) {
 	s.EventFilters = append(s.EventFilters, query)
 }
 
-func (s *EthListener) RegisterConsumer(consumer IEventConsumer) error {
+// RegisterConsumer adds a consumer under name, it scans from startBlock on when it has no
+// cursor yet, e.g. its contract's deployment block, from the listener's cursor when
+// startBlock isn't positive. Cursors are only kept per consumer with ScanCursors set.
+func (s *EthListener) RegisterConsumer(name string, startBlock int64, consumer IEventConsumer) error {
 	consumerHandler, err := consumer.GetConsumer()
 	if err != nil {
 		s.Logger.Err(err).Msg("[eth listener] Unable to get consumer")
 		return err
 	}
+	if err := s.cursors.Register(name, startBlock); err != nil {
+		s.Logger.Err(err).Msg("[eth listener] Unable to register consumer")
+		return err
+	}
 	for i := 0; i < len(consumerHandler); i++ {
-		logger.Get().Debug().Msgf("Key for comsumer: %+v, +%v", KeyFromBEConsumer(consumerHandler[i].Address.Hex(), consumerHandler[i].Topic.Hex()))
+		logger.Get().Debug().Msgf("Key for comsumer: %+v", KeyFromBEConsumer(consumerHandler[i].Address.Hex(), consumerHandler[i].Topic.Hex()))
 		s.EventConsumerMap[KeyFromBEConsumer(consumerHandler[i].Address.Hex(), consumerHandler[i].Topic.Hex())] = consumerHandler[i]
+		s.consumerNames[consumerHandler[i]] = name
 	}
 
-	s.EventFilters = append(s.EventFilters, consumer.GetFilterQuery()...)
+	filters := consumer.GetFilterQuery()
+	s.consumers = append(s.consumers, registeredConsumer{name: name, filters: filters})
+	s.EventFilters = append(s.EventFilters, filters...)
+
+	if reorgConsumer, ok := consumer.(IReorgConsumer); ok {
+		s.RegisterReorgConsumer(reorgConsumer)
+	}
 	return nil
 }
 
+func (s *EthListener) RegisterReorgConsumer(consumer IReorgConsumer) {
+	s.ReorgConsumers = append(s.ReorgConsumers, consumer)
+}
+
 func (s *EthListener) RegisterTxMonitor(monitor ITxMonitor) error {
 	if monitor == nil {
 		err := fmt.Errorf("Nil monitor")
@@ -89,13 +121,23 @@ func (s *EthListener) RegisterTxMonitor(monitor ITxMonitor) error {
 	return fmt.Errorf("Monitor for " + fmt.Sprintf("0x%x", address) + " already existed")
 }
 
+// Start runs the listener until ctx is done, or until its scan or handling is given up
+// on, so that a listener run under a lease hands the chain over
 func (s *EthListener) Start(ctx context.Context) {
-	daemon.BootstrapDaemons(ctx, s.Handling, s.Scan)
+	policy := daemon.DefaultPolicy
+	policy.FailFast = true
+	daemon.BootstrapDaemonsWithPolicy(ctx, policy, s.Handling, s.Scan)
 }
 
 func (s *EthListener) Handling(parentContext context.Context) (fn consts.Daemon, err error) {
 	fn = func() {
 		s.Logger.Info().Msg("[eth_listener] Start handling")
+		var poll <-chan time.Time
+		if s.Queue != nil {
+			ticker := time.NewTicker(DefaultQueuePollInterval)
+			defer ticker.Stop()
+			poll = ticker.C
+		}
 		for {
 			select {
 			case err := <-s.errC:
@@ -107,6 +149,9 @@ func (s *EthListener) Handling(parentContext context.Context) (fn consts.Daemon,
 					s.consumeEvent(vLog)
 				}(vLog)
 
+			case <-poll:
+				s.drainQueue(parentContext)
+
 			case <-parentContext.Done():
 				s.Logger.Info().Msg("[eth_listener] Blockchain listener stop")
 				return
@@ -122,7 +167,7 @@ func (s *EthListener) Scan(parentContext context.Context) (daemon consts.Daemon,
 	// scanners
 
 	blockTransferScanner := func(from *big.Int, to *big.Int) {
-		for i := from; i.Cmp(to) < 1; i = i.Add(i, big.NewInt(1)) {
+		for i := new(big.Int).Set(from); i.Cmp(to) < 1; i = i.Add(i, big.NewInt(1)) {
 			logger.Get().Info().Msgf("[eth_listener] start scanning transfer in block %s", i.String())
 			currBlock, err := s.EthClient.BlockByNumber(context.Background(), i)
 			if err != nil {
@@ -135,49 +180,12 @@ func (s *EthListener) Scan(parentContext context.Context) (daemon consts.Daemon,
 				if t.To() == nil { // contract deployment, ignore
 					continue
 				}
-				var isContractCall = false
-				// temporary cutoff the ERC20 checking path because go-eth's performance is horrible
+				// ERC20 transfers are picked up from their Transfer logs by scanTokenTransfers
 				if len(t.Data()) > 0 {
-					//isContractCall = true
 					continue
 				}
-				//receipt, err := s.EthClient.TransactionReceipt(context.Background(), t.Hash())
-				//if err != nil {
-				//	s.Logger.Err(err).Msg("[eth_listener]] Ethereum tx receipt retrieval err")
-				//	continue
-				//}
-				//if receipt.Status != 1 {
-				//	//s.Logger.Debug().Msgf("[eth_listener]] Skipping failed Ethereum tx %s...", t.Hash().Hex())
-				//	continue
-				//}
-				// check if this is an ERC20 transfer
-				//if len(t.Data()) > 0 {
-				//	isContractCall = true
-				//	abiJson := strings.NewReader(`[{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"}]`)
-				//	abi, _ := abi.JSON(abiJson)
-				//	topic := crypto.Keccak256Hash([]byte(abi.Events["Transfer"].Sig))
-				//	for _, log := range receipt.Logs {
-				//		if len(log.Topics) < 3 {
-				//			//s.Logger.Debug().Msgf("[eth listener] topics length too short in log: %+v\n", log)
-				//			continue
-				//		}
-				//		if log.Topics[0] == topic {
-				//			//s.Logger.Info().Msgf("[eth listener] ERC20 transfer tx detected: %+v\n", t)
-				//			_to := common.HexToAddress(log.Topics[2].Hex())
-				//			if monitor, ok := s.TxMonitors[_to]; ok {
-				//				data := make(map[string]interface{})
-				//				abi.UnpackIntoMap(data, "Transfer", log.Data)
-				//				from := common.HexToAddress(log.Topics[1].Hex()).Hex()
-				//				to := _to.Hex()
-				//				contract := t.To().Hex()
-				//				amount := data["amount"].(*big.Int).String()
-				//				monitor.TxParse(t, from, to, contract, amount)
-				//			}
-				//		}
-				//	}
-				//}
-
-				if monitor, ok := s.TxMonitors[*t.To()]; ok && !isContractCall {
+
+				if monitor, ok := s.TxMonitors[*t.To()]; ok {
 					logger.Get().Debug().Msgf("tran to: %+v", *t.To())
 					msg, err := t.AsMessage(types.LatestSignerForChainID(t.ChainId()), nil)
 					if err != nil {
@@ -187,13 +195,14 @@ func (s *EthListener) Scan(parentContext context.Context) (daemon consts.Daemon,
 					from := msg.From().Hex()
 					to := msg.To().Hex()
 					amount := t.Value().String()
-					monitor.TxParse(t, from, to, model.EthereumTk, amount)
+					monitor.TxParse(t, model.EthValueLogIndex, from, to, model.EthereumTk, amount)
 				}
 			}
 		}
 	}
 
-	eventScanner := func(query ethereum.FilterQuery, from, to *big.Int) {
+	// logs are returned to be queued when there's a queue, handed to Handling otherwise
+	eventScanner := func(query ethereum.FilterQuery, from, to *big.Int) ([]types.Log, error) {
 		query.FromBlock = from //scannedBlock
 		query.ToBlock = to     //currBlock
 
@@ -204,17 +213,66 @@ func (s *EthListener) Scan(parentContext context.Context) (daemon consts.Daemon,
 		if err != nil {
 			s.Logger.Err(err).Msg("[eth_listener]] Ethereum filter query err")
 			s.errC <- err
+			return nil, err
 		}
 		logger.Get().Debug().Msgf("[eth_listener] events: %+v", events)
+		if s.Queue != nil {
+			return events, nil
+		}
 		for _, event := range events {
 			logger.Get().Debug().Msgf("[eth_listener] queuing event: %+v", event)
 			s.Log <- event
 		}
+		return nil, nil
+	}
+
+	// eventsScanner runs the filter queries of the consumers over the blocks, returning the
+	// consumers a query failed for or whose logs can't be queued, the blocks are then left
+	// for them to scan again
+	var eventsScanner eventsScanFn = func(from, to *big.Int, consumers []registeredConsumer) (failed []string) {
+		mu := sync.Mutex{}
+		logs := make(map[string][]types.Log)
+		errs := make(map[string]error)
+		wg := sync.WaitGroup{}
+		for _, c := range consumers {
+			for _, query := range c.filters {
+				wg.Add(1)
+				go func(name string, query ethereum.FilterQuery) {
+					defer wg.Done()
+					events, err := eventScanner(query, from, to)
+					mu.Lock()
+					defer mu.Unlock()
+					if err != nil {
+						errs[name] = err
+						return
+					}
+					logs[name] = append(logs[name], events...)
+				}(c.name, query)
+			}
+		}
+		wg.Wait()
+		for _, c := range consumers {
+			if errs[c.name] == nil && s.Queue != nil {
+				if err := s.enqueue(logs[c.name]); err != nil {
+					s.Logger.Err(err).Msgf("[eth_listener] can't queue events of consumer %s", c.name)
+					errs[c.name] = err
+				}
+			}
+			if errs[c.name] != nil {
+				failed = append(failed, c.name)
+			}
+		}
+		return failed
 	}
 
 	// main scanning daemon
+	pacer := &scanPacer{s: s}
 	daemon = func() {
 		s.Logger.Info().Msgf("[eth listener] Begin scan...")
+		// loaded on every start, another replica may have moved them while this one stood by
+		cursorsLoaded := false
+		s.blockHashes = newBlockWindow(DefaultReorgWindow)
+		defer pacer.drop()
 		for {
 			select {
 			case <-parentContext.Done():
@@ -226,13 +284,23 @@ func (s *EthListener) Scan(parentContext context.Context) (daemon consts.Daemon,
 					s.Logger.Err(err).Msg("[eth_listener] can't get system info")
 					continue
 				}
+				if s.ScanCursors != nil && !cursorsLoaded {
+					if err := s.cursors.Load(s.ScanCursors, sysInfo.LastScannedBlock); err != nil {
+						s.Logger.Err(err).Msg("[eth_listener] can't load consumers' scan cursors")
+						consts.SleepContext(parentContext, time.Second*time.Duration(s.blockTime))
+						continue
+					}
+					cursorsLoaded = true
+				}
 
 				header, err := s.EthClient.HeaderByNumber(parentContext, nil)
 				if err != nil {
 					s.Logger.Err(err).Msg("[eth_listener] can't get head by number, possibly due to rpc node failure")
+					consts.SleepContext(parentContext, time.Second*time.Duration(s.blockTime))
 					continue
 				}
 				currBlock := header.Number
+				headNum := currBlock.Int64()
 
 				var scannedBlock *big.Int
 				if sysInfo.LastScannedBlock <= 0 {
@@ -264,6 +332,11 @@ func (s *EthListener) Scan(parentContext context.Context) (daemon consts.Daemon,
 						until = until.Add(begin, limit)
 						//s.Logger.Info().Msg(fmt.Sprintf("[eth_listener] scan from block %s to %s", begin.String(), until.String()))
 
+						// make sure the blocks about to be scanned extend what was scanned before
+						if s.rollbackOnReorg(parentContext, sysInfo, begin.Int64(), until.Int64(), headNum) {
+							break
+						}
+
 						// tx scan
 						wg := sync.WaitGroup{}
 						if len(s.TxMonitors) > 0 {
@@ -273,22 +346,37 @@ func (s *EthListener) Scan(parentContext context.Context) (daemon consts.Daemon,
 								wg.Done()
 							}(begin, until)
 						}
-
-						// events scan
-						for _, query := range s.EventFilters {
+						if tokens := s.watchedTokens(); len(s.TxMonitors) > 0 && len(tokens) > 0 {
 							wg.Add(1)
-							go func(query ethereum.FilterQuery) {
-								eventScanner(query, begin, until)
+							go func(from *big.Int, to *big.Int) {
+								s.scanTokenTransfers(parentContext, tokens, from, to)
 								wg.Done()
-							}(query)
+							}(begin, until)
 						}
+
+						// events scan
+						var failed []string
+						joined := s.joinedConsumers()
+						wg.Add(1)
+						go func(from *big.Int, to *big.Int) {
+							failed = eventsScanner(from, to, joined)
+							wg.Done()
+						}(begin, until)
 						wg.Wait()
+						if !s.leaveFailed(failed, joined) {
+							consts.SleepContext(parentContext, time.Second*time.Duration(s.blockTime))
+							break
+						}
 						// update last scan block
 						sysInfo.LastScannedBlock = until.Int64()
 						s.EthInfo.Update(sysInfo)
+						s.advanceCursors(sysInfo.LastScannedBlock)
 					}
 				} else {
 					//s.Logger.Info().Msg(fmt.Sprintf("[eth_listener] scan from block %s to %s", scannedBlock.String(), currBlock.String()))
+					if s.rollbackOnReorg(parentContext, sysInfo, scannedBlock.Int64(), headNum, headNum) {
+						continue
+					}
 					// tx scan
 					wg := sync.WaitGroup{}
 					if len(s.TxMonitors) > 0 {
@@ -298,29 +386,68 @@ func (s *EthListener) Scan(parentContext context.Context) (daemon consts.Daemon,
 							wg.Done()
 						}(scannedBlock, currBlock)
 					}
-
-					// events scan
-					for _, query := range s.EventFilters {
+					if tokens := s.watchedTokens(); len(s.TxMonitors) > 0 && len(tokens) > 0 {
 						wg.Add(1)
-						go func(query ethereum.FilterQuery) {
-							eventScanner(query, scannedBlock, currBlock)
+						go func(from *big.Int, to *big.Int) {
+							s.scanTokenTransfers(parentContext, tokens, from, to)
 							wg.Done()
-						}(query)
+						}(scannedBlock, currBlock)
 					}
+
+					// events scan
+					var failed []string
+					joined := s.joinedConsumers()
+					wg.Add(1)
+					go func(from *big.Int, to *big.Int) {
+						failed = eventsScanner(from, to, joined)
+						wg.Done()
+					}(scannedBlock, currBlock)
 					wg.Wait()
+					if !s.leaveFailed(failed, joined) {
+						consts.SleepContext(parentContext, time.Second*time.Duration(s.blockTime))
+						continue
+					}
 					// update last scan block
 					sysInfo.LastScannedBlock = currBlock.Int64()
 					s.EthInfo.Update(sysInfo)
+					s.advanceCursors(sysInfo.LastScannedBlock)
 				}
 
-				// TODO: either push this to delay message queue to run OR just sleep
-				//consts.SleepContext(parentContext, time.Second*time.Duration(s.blockTime))
+				// consumers behind go on catching up right away
+				if s.catchUp(parentContext, sysInfo.LastScannedBlock, eventsScanner) {
+					continue
+				}
+
+				pacer.wait(parentContext, headNum)
 			}
 		}
 	}
 	return daemon, nil
 }
 
+// rollbackOnReorg checks blocks [from, to] for a reorg; if one happened the consumers are
+// notified and the last scanned block is moved back to the common ancestor
+func (s *EthListener) rollbackOnReorg(ctx context.Context, sysInfo *consts.EthDefaultInfo, from, to, head int64) bool {
+	ev, err := s.verifyBlocks(ctx, from, to, head)
+	if err != nil {
+		s.Logger.Err(err).Msg("[eth_listener] can't verify block hashes, possibly due to rpc node failure")
+		return false
+	}
+	if ev == nil {
+		return false
+	}
+
+	s.notifyReorg(*ev)
+	sysInfo.LastScannedBlock = int64(ev.FromBlock) - 1
+	if err := s.EthInfo.Update(sysInfo); err != nil {
+		s.Logger.Err(err).Msg("[eth_listener] can't roll back last scanned block")
+	}
+	if err := s.cursors.Rollback(sysInfo.LastScannedBlock); err != nil {
+		s.Logger.Err(err).Msg("[eth_listener] can't roll back consumers' scan cursors")
+	}
+	return true
+}
+
 func (s *EthListener) matchEvent(vLog types.Log) (*EventConsumer, bool) {
 	key := KeyFromBEConsumer(vLog.Address.Hex(), vLog.Topics[0].Hex())
 	logger.Get().Debug().Msgf("key for event: %+v", key)
@@ -335,12 +462,14 @@ func (s *EthListener) matchEvent(vLog types.Log) (*EventConsumer, bool) {
 	return consumer, isExisted
 }
 
-func (s *EthListener) consumeEvent(vLog types.Log) {
+func (s *EthListener) consumeEvent(vLog types.Log) error {
 	consumer, isExisted := s.matchEvent(vLog)
 	if isExisted {
 		err := consumer.ParseEvent(vLog)
 		if err != nil {
 			s.Logger.Err(err).Msg("[eth_client] Consume event error")
+			return err
 		}
 	}
+	return nil
 }
@@ -0,0 +1,75 @@
+package eth
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	// DefaultQueuePollInterval is how often queued events are looked up for consumption
+	DefaultQueuePollInterval = time.Second
+	// DefaultQueueBatch is the number of queued events consumed per poll
+	DefaultQueueBatch = 100
+)
+
+// QueuedEvent is a log held by an event queue until its consumer acknowledges it
+type QueuedEvent struct {
+	ID       int64
+	Attempts int
+	Log      types.Log
+}
+
+// IEventQueue durably holds the logs matched by the scanner: they are enqueued before the
+// last scanned block moves past them, and only leave the queue once consumed. Failed
+// events are retried with backoff, the queue dead-letters those failing for too long.
+type IEventQueue interface {
+	Enqueue(events []QueuedEvent) error
+	// Due returns the events ready to be consumed, oldest first
+	Due(limit int) ([]QueuedEvent, error)
+	Ack(ev QueuedEvent) error
+	Fail(ev QueuedEvent, err error) error
+}
+
+// enqueue queues the logs some consumer is registered for
+func (s *EthListener) enqueue(logs []types.Log) error {
+	sortLogs(logs)
+	var events []QueuedEvent
+	for _, vLog := range logs {
+		if vLog.Removed || len(vLog.Topics) == 0 {
+			continue
+		}
+		if _, ok := s.matchEvent(vLog); ok {
+			events = append(events, QueuedEvent{Log: vLog})
+		}
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	return s.Queue.Enqueue(events)
+}
+
+// drainQueue consumes the due events in order, acknowledging them or reporting their failure
+func (s *EthListener) drainQueue(ctx context.Context) {
+	events, err := s.Queue.Due(DefaultQueueBatch)
+	if err != nil {
+		s.Logger.Err(err).Msg("[eth_listener] can't get queued events")
+		return
+	}
+	for _, ev := range events {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.consumeEvent(ev.Log); err != nil {
+			s.Logger.Err(err).Msgf("[eth_listener] queued event %d of tx %s failed, attempt %d", ev.Log.Index, ev.Log.TxHash.Hex(), ev.Attempts+1)
+			if err := s.Queue.Fail(ev, err); err != nil {
+				s.Logger.Err(err).Msgf("[eth_listener] can't report failure of queued event %d", ev.ID)
+			}
+			continue
+		}
+		if err := s.Queue.Ack(ev); err != nil {
+			s.Logger.Err(err).Msgf("[eth_listener] can't acknowledge queued event %d", ev.ID)
+		}
+	}
+}
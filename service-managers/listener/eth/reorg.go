@@ -0,0 +1,142 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// number of most recent block hashes kept around to detect chain reorganizations
+const DefaultReorgWindow = 128
+
+// ReorgEvent describes a range of blocks [FromBlock, ToBlock] that used to be part of
+// the canonical chain and got orphaned by a reorganization
+type ReorgEvent struct {
+	FromBlock      uint64
+	ToBlock        uint64
+	OrphanedHashes []common.Hash
+}
+
+// consumers interested in chain reorganizations, an IEventConsumer implementing this
+// interface is registered automatically by RegisterConsumer
+type IReorgConsumer interface {
+	HandleReorg(ev ReorgEvent) error
+}
+
+// blockWindow keeps the hashes of a contiguous range of the most recently scanned blocks
+type blockWindow struct {
+	size   int
+	oldest int64
+	hashes []common.Hash
+}
+
+func newBlockWindow(size int) *blockWindow {
+	return &blockWindow{size: size, oldest: -1}
+}
+
+// tip returns the number of the newest recorded block, -1 if the window is empty
+func (w *blockWindow) tip() int64 {
+	if len(w.hashes) == 0 {
+		return -1
+	}
+	return w.oldest + int64(len(w.hashes)) - 1
+}
+
+func (w *blockWindow) get(number int64) (common.Hash, bool) {
+	if len(w.hashes) == 0 || number < w.oldest || number > w.tip() {
+		return common.Hash{}, false
+	}
+	return w.hashes[number-w.oldest], true
+}
+
+// add appends the hash of block `number`, resetting the window if the block is not
+// contiguous with the current tip
+func (w *blockWindow) add(number int64, hash common.Hash) {
+	if len(w.hashes) == 0 || number != w.tip()+1 {
+		w.oldest = number
+		w.hashes = w.hashes[:0]
+	}
+	w.hashes = append(w.hashes, hash)
+	if len(w.hashes) > w.size {
+		w.hashes = w.hashes[len(w.hashes)-w.size:]
+		w.oldest = number - int64(w.size) + 1
+	}
+}
+
+// rollback drops every block after `number` and returns the dropped hashes
+func (w *blockWindow) rollback(number int64) []common.Hash {
+	if len(w.hashes) == 0 || number >= w.tip() {
+		return nil
+	}
+	if number < w.oldest {
+		dropped := append([]common.Hash{}, w.hashes...)
+		w.hashes = w.hashes[:0]
+		w.oldest = -1
+		return dropped
+	}
+	keep := number - w.oldest + 1
+	dropped := append([]common.Hash{}, w.hashes[keep:]...)
+	w.hashes = w.hashes[:keep]
+	return dropped
+}
+
+// verifyBlocks records headers of blocks in [from, to] that fall within the reorg
+// window of head, checking each block's parent hash against the recorded one.
+// On mismatch it walks back the window to find the common ancestor and returns the
+// orphaned range.
+func (s *EthListener) verifyBlocks(ctx context.Context, from, to, head int64) (*ReorgEvent, error) {
+	if tip := s.blockHashes.tip(); tip >= from {
+		from = tip + 1
+	}
+	if lowest := head - int64(s.blockHashes.size) + 1; lowest > from {
+		from = lowest
+	}
+
+	for b := from; b <= to; b++ {
+		header, err := s.EthClient.HeaderByNumber(ctx, big.NewInt(b))
+		if err != nil {
+			return nil, err
+		}
+		if parentHash, ok := s.blockHashes.get(b - 1); ok && parentHash != header.ParentHash {
+			s.Logger.Warn().Msgf("[eth_listener] parent hash mismatch at block %d: expected %s, got %s", b, parentHash.Hex(), header.ParentHash.Hex())
+			return s.findReorg(ctx, b-1)
+		}
+		s.blockHashes.add(b, header.Hash())
+	}
+	return nil, nil
+}
+
+// findReorg walks back from `from` until a recorded block still matches the chain
+func (s *EthListener) findReorg(ctx context.Context, from int64) (*ReorgEvent, error) {
+	tip := s.blockHashes.tip()
+	ancestor := s.blockHashes.oldest - 1
+	for b := from; b >= s.blockHashes.oldest; b-- {
+		header, err := s.EthClient.HeaderByNumber(ctx, big.NewInt(b))
+		if err != nil {
+			return nil, err
+		}
+		if recorded, _ := s.blockHashes.get(b); recorded == header.Hash() {
+			ancestor = b
+			break
+		}
+	}
+	if ancestor < s.blockHashes.oldest {
+		s.Logger.Warn().Msgf("[eth_listener] reorg deeper than the %d-block window, rolling back the whole window", s.blockHashes.size)
+	}
+
+	return &ReorgEvent{
+		FromBlock:      uint64(ancestor + 1),
+		ToBlock:        uint64(tip),
+		OrphanedHashes: s.blockHashes.rollback(ancestor),
+	}, nil
+}
+
+func (s *EthListener) notifyReorg(ev ReorgEvent) {
+	s.Logger.Warn().Msgf("[eth_listener] chain reorganization detected, blocks %d to %d orphaned", ev.FromBlock, ev.ToBlock)
+	for _, consumer := range s.ReorgConsumers {
+		if err := consumer.HandleReorg(ev); err != nil {
+			s.Logger.Err(err).Msg("[eth_listener] Consume reorg error")
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestBlockWindow(t *testing.T) {
+	w := newBlockWindow(4)
+	if w.tip() != -1 {
+		t.Fatalf("empty window should have no tip, got %d", w.tip())
+	}
+
+	for i := int64(10); i <= 15; i++ {
+		w.add(i, common.BigToHash(big.NewInt(i)))
+	}
+	if w.tip() != 15 || w.oldest != 12 {
+		t.Fatalf("expected window [12, 15], got [%d, %d]", w.oldest, w.tip())
+	}
+	if _, ok := w.get(11); ok {
+		t.Fatalf("block 11 should have been evicted")
+	}
+	if h, ok := w.get(13); !ok || h != common.BigToHash(big.NewInt(13)) {
+		t.Fatalf("wrong hash for block 13: %s", h.Hex())
+	}
+
+	dropped := w.rollback(13)
+	if len(dropped) != 2 || w.tip() != 13 {
+		t.Fatalf("expected 2 dropped blocks and tip 13, got %d and %d", len(dropped), w.tip())
+	}
+
+	// non contiguous block resets the window
+	w.add(20, common.Hash{})
+	if w.tip() != 20 || w.oldest != 20 {
+		t.Fatalf("expected window [20, 20], got [%d, %d]", w.oldest, w.tip())
+	}
+
+	dropped = w.rollback(5)
+	if len(dropped) != 1 || w.tip() != -1 {
+		t.Fatalf("rolling back past the window should empty it")
+	}
+}
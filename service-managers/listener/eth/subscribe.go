@@ -0,0 +1,165 @@
+package eth
+
+import (
+	"context"
+	"time"
+
+	"bridge/common/consts"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// IEthSubscriber is the push side of a ws endpoint, satisfied by a *ethclient.Client dialed
+// over ws
+type IEthSubscriber interface {
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
+var _ IEthSubscriber = (*ethclient.Client)(nil)
+
+var (
+	// DefaultResubscribeInterval is how long the listener polls after losing its
+	// subscription before trying to subscribe again
+	DefaultResubscribeInterval = 30 * time.Second
+	// a subscription silent for this many block times is presumed dead
+	SubscriptionSilenceBlocks = 4
+)
+
+// subscription pushes new heads, which only wake the scan: events are dispatched by the
+// scan alone, once their block is blockOffset deep and within the reorg window, so that
+// nothing is handed to consumers that the reorg detection couldn't roll back
+type subscription struct {
+	heads  chan *types.Header
+	errC   chan error
+	sub    ethereum.Subscription
+	cancel context.CancelFunc
+}
+
+func (sub *subscription) close() {
+	sub.sub.Unsubscribe()
+	sub.cancel()
+}
+
+func (s *EthListener) subscribe(ctx context.Context) (*subscription, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	sub := &subscription{
+		heads:  make(chan *types.Header, 16),
+		errC:   make(chan error, 1),
+		cancel: cancel,
+	}
+
+	headSub, err := s.Subscriber.SubscribeNewHead(ctx, sub.heads)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	sub.sub = headSub
+
+	go func() {
+		select {
+		case err := <-headSub.Err():
+			if err != nil {
+				sub.errC <- err
+			}
+		case <-ctx.Done():
+		}
+	}()
+	return sub, nil
+}
+
+// scanPacer paces the scanning rounds: on every new head while subscribed, on an adaptive
+// interval otherwise
+type scanPacer struct {
+	s             *EthListener
+	sub           *subscription
+	lastSubscribe time.Time
+	interval      time.Duration
+	lastHead      int64
+}
+
+// wait blocks until the next round is due, head being the one just scanned up to. A
+// dropped subscription ends the wait right away: the round scans from the last scanned
+// block, which backfills whatever was missed.
+func (p *scanPacer) wait(ctx context.Context, head int64) {
+	s := p.s
+	newBlocks := head - p.lastHead
+	if p.lastHead == 0 {
+		newBlocks = 1
+	}
+	p.lastHead = head
+
+	if s.Subscriber != nil && p.sub == nil && time.Since(p.lastSubscribe) >= DefaultResubscribeInterval {
+		p.lastSubscribe = time.Now()
+		sub, err := s.subscribe(ctx)
+		if err != nil {
+			s.Logger.Err(err).Msg("[eth_listener] can't subscribe, polling instead")
+		} else {
+			s.Logger.Info().Msg("[eth_listener] subscribed to new heads")
+			p.sub = sub
+		}
+	}
+
+	if p.sub == nil {
+		p.interval = nextPollInterval(p.interval, newBlocks, s.blockTime)
+		consts.SleepContext(ctx, p.interval)
+		return
+	}
+
+	silence := time.NewTimer(time.Duration(SubscriptionSilenceBlocks) * blockDuration(s.blockTime))
+	defer silence.Stop()
+	select {
+	case <-p.sub.heads:
+	case err := <-p.sub.errC:
+		s.Logger.Err(err).Msg("[eth_listener] subscription dropped, backfilling by polling")
+		p.drop()
+	case <-silence.C:
+		s.Logger.Warn().Msg("[eth_listener] no new head pushed for a while, backfilling by polling")
+		p.drop()
+	case <-ctx.Done():
+		p.drop()
+	}
+}
+
+func (p *scanPacer) drop() {
+	if p.sub != nil {
+		p.sub.close()
+		p.sub = nil
+	}
+}
+
+// nextPollInterval adapts the polling interval to how many blocks the last round found:
+// backing off while none come, catching up when several did, between half and four block
+// times
+func nextPollInterval(prev time.Duration, newBlocks int64, blockTime uint64) time.Duration {
+	bt := blockDuration(blockTime)
+	min, max := bt/2, 4*bt
+	if min < time.Second {
+		min = time.Second
+	}
+
+	next := prev
+	switch {
+	case prev == 0:
+		next = bt
+	case newBlocks == 0:
+		next = prev * 3 / 2
+	case newBlocks > 1:
+		next = prev / 2
+	}
+	if next < min {
+		next = min
+	}
+	if next > max {
+		next = max
+	}
+	return next
+}
+
+func blockDuration(blockTime uint64) time.Duration {
+	if blockTime == 0 {
+		return time.Second
+	}
+	return time.Duration(blockTime) * time.Second
+}
@@ -0,0 +1,41 @@
+package eth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextPollInterval(t *testing.T) {
+	bt := uint64(12)
+	block := 12 * time.Second
+
+	if got := nextPollInterval(0, 0, bt); got != block {
+		t.Fatalf("first interval should be a block time, got %s", got)
+	}
+	if got := nextPollInterval(block, 1, bt); got != block {
+		t.Fatalf("a block per round should keep the interval, got %s", got)
+	}
+	if got := nextPollInterval(block, 0, bt); got != 18*time.Second {
+		t.Fatalf("no new block should back off, got %s", got)
+	}
+	if got := nextPollInterval(block, 3, bt); got != 6*time.Second {
+		t.Fatalf("several new blocks should speed up, got %s", got)
+	}
+
+	interval := block
+	for i := 0; i < 10; i++ {
+		interval = nextPollInterval(interval, 0, bt)
+	}
+	if interval != 4*block {
+		t.Fatalf("interval should be capped at four block times, got %s", interval)
+	}
+	for i := 0; i < 10; i++ {
+		interval = nextPollInterval(interval, 5, bt)
+	}
+	if interval != block/2 {
+		t.Fatalf("interval should be floored at half a block time, got %s", interval)
+	}
+	if got := nextPollInterval(time.Second, 5, 1); got != time.Second {
+		t.Fatalf("interval should never go under a second, got %s", got)
+	}
+}
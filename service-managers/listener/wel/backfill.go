@@ -0,0 +1,45 @@
+package wel
+
+import (
+	"context"
+	"sort"
+)
+
+// DefaultBackfillChunk is the number of blocks fetched at once while backfilling
+var DefaultBackfillChunk int64 = 100
+
+// Backfill replays the events of blocks [from, to] through the registered consumers, one
+// at a time in chain order; the last scanned block is left as is. Consumer errors are
+// logged, only failures to fetch blocks abort the backfill
+func (s *WelListener) Backfill(ctx context.Context, from, to int64) (replayed int, err error) {
+	for begin := from; begin <= to; begin += DefaultBackfillChunk {
+		if err := ctx.Err(); err != nil {
+			return replayed, err
+		}
+		end := begin + DefaultBackfillChunk - 1
+		if end > to {
+			end = to
+		}
+		s.Logger.Info().Msgf("[wel_listener] backfilling blocks %d to %d", begin, end)
+
+		trans, err := s.TransHandler.CollectRange(begin, end)
+		if err != nil {
+			s.Logger.Err(err).Msgf("[wel_listener] can't get transactions of blocks %d to %d", begin, end)
+			return replayed, err
+		}
+		// workers deliver blocks out of order
+		sort.SliceStable(trans, func(i, j int) bool {
+			return trans[i].BlockNumber < trans[j].BlockNumber
+		})
+
+		for _, t := range trans {
+			for _, m := range s.matchEvents(t) {
+				replayed++
+				if err := m.consumer.ParseEvent(t, m.position); err != nil {
+					s.Logger.Warn().Err(err).Msgf("[wel_listener] backfill: event %d of tx %s not consumed", m.position, t.Hash)
+				}
+			}
+		}
+	}
+	return replayed, nil
+}
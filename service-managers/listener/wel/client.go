@@ -1,9 +1,11 @@
 package wel
 
 import (
+	"context"
 	"time"
 
 	gotron "github.com/Paven-Org/gotron-sdk/pkg/client"
+	"github.com/Paven-Org/gotron-sdk/pkg/proto/api"
 )
 
 //CNodeClient is the wrapper of the nodeClient
@@ -37,3 +39,12 @@ func NewExtNodeClientFromCli(cli *gotron.GrpcClient, timeout time.Duration) *Ext
 	}
 	return client
 }
+
+// GetTransactionInfoByBlockNum returns the infos (logs, receipts, results) of every
+// transaction in a block in a single call
+func (c *ExtNodeClient) GetTransactionInfoByBlockNum(num int64) (*api.TransactionInfoList, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.grpcTimeout)
+	defer cancel()
+
+	return c.Client.GetTransactionInfoByBlockNum(ctx, &api.NumberMessage{Num: num})
+}
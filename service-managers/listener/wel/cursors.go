@@ -0,0 +1,101 @@
+package wel
+
+import (
+	"context"
+	"sort"
+
+	"bridge/service-managers/listener/cursor"
+)
+
+// joinedMatches are the matches of the consumers fed by the listener's scan, the others
+// get their events once they catch up
+func (s *WelListener) joinedMatches(t *Transaction) []eventMatch {
+	var matches []eventMatch
+	for _, m := range s.matchEvents(t) {
+		if s.cursors.Joined(s.consumerNames[m.consumer]) {
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
+
+// consumerMatches are the matches of a single consumer
+func (s *WelListener) consumerMatches(name string) func(t *Transaction) []eventMatch {
+	return func(t *Transaction) []eventMatch {
+		var matches []eventMatch
+		for _, m := range s.matchEvents(t) {
+			if s.consumerNames[m.consumer] == name {
+				matches = append(matches, m)
+			}
+		}
+		return matches
+	}
+}
+
+func (s *WelListener) advanceCursors(block int64) {
+	if err := s.cursors.AdvanceJoined(block); err != nil {
+		s.Logger.Err(err).Msg("[wel_listener] can't update consumers' scan cursors")
+	}
+}
+
+// catchUp scans a span of blocks for each consumer behind the listener's cursor main,
+// telling whether some are still behind after making progress
+func (s *WelListener) catchUp(ctx context.Context, main int64) (behind bool) {
+	for _, lag := range s.cursors.Behind(main) {
+		from, to, ok := cursor.CatchUpRange(lag, main, cursor.DefaultCatchUpSpan)
+		if !ok {
+			continue
+		}
+		s.Logger.Info().Msgf("[wel_listener] consumer %s catching up with blocks %d to %d", lag.Consumer, from, to)
+
+		progressed := true
+		for begin := from; begin <= to && ctx.Err() == nil; begin += DefaultBackfillChunk {
+			end := begin + DefaultBackfillChunk - 1
+			if end > to {
+				end = to
+			}
+			if err := s.catchUpRange(lag.Consumer, begin, end); err != nil {
+				progressed = begin > from
+				break
+			}
+			if err := s.cursors.Advance(lag.Consumer, end); err != nil {
+				s.Logger.Err(err).Msgf("[wel_listener] can't update scan cursor of consumer %s", lag.Consumer)
+				progressed = false
+				break
+			}
+		}
+		behind = behind || progressed
+	}
+	return behind
+}
+
+// catchUpRange hands the consumer its events of blocks [from, to] in chain order, through
+// the queue when there's one
+func (s *WelListener) catchUpRange(name string, from, to int64) error {
+	trans, err := s.TransHandler.CollectRange(from, to)
+	if err != nil {
+		s.Logger.Err(err).Msgf("[wel_listener] can't get transactions of blocks %d to %d", from, to)
+		return err
+	}
+	// workers deliver blocks out of order
+	sort.SliceStable(trans, func(i, j int) bool {
+		return trans[i].BlockNumber < trans[j].BlockNumber
+	})
+
+	match := s.consumerMatches(name)
+	if s.Queue != nil {
+		if err := s.enqueue(trans, match); err != nil {
+			s.Logger.Err(err).Msgf("[wel_listener] can't queue events of consumer %s", name)
+			return err
+		}
+		return nil
+	}
+	for _, t := range trans {
+		for _, m := range match(t) {
+			if err := m.consumer.ParseEvent(t, m.position); err != nil {
+				s.Logger.Err(err).Msgf("[wel_listener] Consume event error, tx with event: %v", t)
+			}
+		}
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 
 	"bridge/common/consts"
 	"bridge/service-managers/daemon"
+	"bridge/service-managers/listener/cursor"
 
 	GotronCommon "github.com/Paven-Org/gotron-sdk/pkg/common"
 	"github.com/rs/zerolog"
@@ -16,8 +17,13 @@ type WelListener struct {
 	TransHandler     *TransHandler
 	WelInfo          consts.IWelInfoRepo
 	EventConsumerMap map[string]*EventConsumer
+	consumerNames    map[*EventConsumer]string
+	TxMonitors       map[string]ITxMonitor
 	Logger           *zerolog.Logger
 	Trans            chan *Transaction
+	Queue            IEventQueue            // optional, matched events go through it instead of Trans
+	ScanCursors      consts.IScanCursorRepo // optional, consumers keep a cursor of their own in it
+	cursors          *cursor.Cursors
 	errC             chan error
 	blockTime        uint64
 	blockOffset      int64
@@ -34,24 +40,36 @@ func NewWelListener(
 		WelInfo:          welInfo,
 		TransHandler:     transHandler,
 		EventConsumerMap: make(map[string]*EventConsumer),
+		consumerNames:    make(map[*EventConsumer]string),
+		TxMonitors:       make(map[string]ITxMonitor),
 		Trans:            make(chan *Transaction),
 		errC:             make(chan error),
 		Logger:           logger,
 		blockTime:        blockTime,
 		blockOffset:      blockOffset,
+		cursors:          cursor.MkCursors("wel"),
 	}
 }
 
-func (s *WelListener) RegisterConsumer(consumer IEventConsumer) error {
+// RegisterConsumer adds a consumer under name, it scans from startBlock on when it has no
+// cursor yet, e.g. its contract's deployment block, from the listener's cursor when
+// startBlock isn't positive. Cursors are only kept per consumer with ScanCursors set.
+func (s *WelListener) RegisterConsumer(name string, startBlock int64, consumer IEventConsumer) error {
 	consumerHandler, err := consumer.GetConsumer()
 	if err != nil {
 		s.Logger.Err(err).Msg("[wel listener] Unable to get consumer")
 		return err
 	}
+	if err := s.cursors.Register(name, startBlock); err != nil {
+		s.Logger.Err(err).Msg("[wel listener] Unable to register consumer")
+		return err
+	}
 
 	for i := 0; i < len(consumerHandler); i++ {
 		// remove 0x from the topic
 		s.EventConsumerMap[KeyFromBEConsumer(consumerHandler[i].Address, consumerHandler[i].Topic.Hex()[2:])] = consumerHandler[i]
+		s.consumerNames[consumerHandler[i]] = name
+		s.TransHandler.WatchAddress(consumerHandler[i].Address)
 	}
 	return nil
 }
@@ -60,13 +78,23 @@ func KeyFromBEConsumer(address string, topic string) string {
 	return fmt.Sprintf("%s:%s", address, topic)
 }
 
+// Start runs the listener until ctx is done, or until its scan or handling is given up
+// on, so that a listener run under a lease hands the chain over
 func (s *WelListener) Start(ctx context.Context) {
-	daemon.BootstrapDaemons(ctx, s.Handling, s.Scan)
+	policy := daemon.DefaultPolicy
+	policy.FailFast = true
+	daemon.BootstrapDaemonsWithPolicy(ctx, policy, s.Handling, s.Scan)
 }
 
 func (s *WelListener) Handling(parentContext context.Context) (fn consts.Daemon, err error) {
 	fn = func() {
 		s.Logger.Info().Msg("[wel_listener] Start handling")
+		var poll <-chan time.Time
+		if s.Queue != nil {
+			ticker := time.NewTicker(DefaultQueuePollInterval)
+			defer ticker.Stop()
+			poll = ticker.C
+		}
 		for {
 			select {
 			case err := <-s.errC:
@@ -77,6 +105,9 @@ func (s *WelListener) Handling(parentContext context.Context) (fn consts.Daemon,
 					s.consumeEvent(vLog)
 				}(vLog)
 
+			case <-poll:
+				s.drainQueue(parentContext)
+
 			case <-parentContext.Done():
 				s.Logger.Info().Msg("[wel_listener] Blockchain listener stop")
 				return
@@ -91,6 +122,8 @@ func (s *WelListener) Handling(parentContext context.Context) (fn consts.Daemon,
 func (s *WelListener) Scan(parentContext context.Context) (fn consts.Daemon, err error) {
 	fn = func() {
 		s.Logger.Info().Msgf("[wel listener] Begin scan...")
+		// loaded on every start, another replica may have moved them while this one stood by
+		cursorsLoaded := false
 		for {
 			select {
 			case <-parentContext.Done():
@@ -102,6 +135,14 @@ func (s *WelListener) Scan(parentContext context.Context) (fn consts.Daemon, err
 					s.Logger.Err(err).Msg("[wel_listener] can't get system info")
 					continue
 				}
+				if s.ScanCursors != nil && !cursorsLoaded {
+					if err := s.cursors.Load(s.ScanCursors, sysInfo.LastScannedBlock); err != nil {
+						s.Logger.Err(err).Msg("[wel_listener] can't load consumers' scan cursors")
+						consts.SleepContext(parentContext, time.Second*time.Duration(s.blockTime))
+						continue
+					}
+					cursorsLoaded = true
+				}
 				lastScanned := sysInfo.LastScannedBlock
 				//s.Logger.Info().Msgf("[wel listener] sysinfo: %d", lastScanned)
 
@@ -134,15 +175,25 @@ func (s *WelListener) Scan(parentContext context.Context) (fn consts.Daemon, err
 							limit = 99
 						}
 						//fmt.Println("from: ", begin, " to: ", begin+limit)
-						s.TransHandler.GetInfoListTransactionRange(begin+limit, limit+1, "", s.Trans, s.errC)
+						if err := s.scanRange(begin, begin+limit); err != nil {
+							break
+						}
 						sysInfo.LastScannedBlock = begin + 99
 						s.WelInfo.Update(sysInfo)
+						s.advanceCursors(sysInfo.LastScannedBlock)
 					}
 				} else {
 					//fmt.Println("from: ", headNum-brange, " to: ", headNum)
-					s.TransHandler.GetInfoListTransactionRange(headNum, brange, "", s.Trans, s.errC)
-					sysInfo.LastScannedBlock = headNum
-					s.WelInfo.Update(sysInfo)
+					if err := s.scanRange(headNum-brange+1, headNum); err == nil {
+						sysInfo.LastScannedBlock = headNum
+						s.WelInfo.Update(sysInfo)
+						s.advanceCursors(sysInfo.LastScannedBlock)
+					}
+				}
+
+				// consumers behind go on catching up right away
+				if s.catchUp(parentContext, sysInfo.LastScannedBlock) {
+					continue
 				}
 
 				// TODO: either push this to delay message queue to run OR just sleep
@@ -153,30 +204,64 @@ func (s *WelListener) Scan(parentContext context.Context) (fn consts.Daemon, err
 	return fn, nil
 }
 
-func (s *WelListener) matchEvent(tran *Transaction) (consumer *EventConsumer, position int) {
-	//TODO: add topic
-	position = -1
-	ctrAddress := tran.Contract.Parameter.Raw["ContractAddress"]
-	if ctrAddress == nil {
-		return nil, -1
+// scanRange streams the transactions of blocks [from, to] to Handling, or queues their
+// events when there's a queue; the blocks are to be scanned again when it fails
+func (s *WelListener) scanRange(from, to int64) error {
+	if s.Queue == nil {
+		s.TransHandler.GetInfoListTransactionRange(to, to-from+1, "", s.Trans, s.errC)
+		return nil
 	}
-	for i, log := range tran.Log {
-		//fmt.Println("[matchEvent] at log ", i)
-		key := KeyFromBEConsumer(ctrAddress.(string), GotronCommon.Bytes2Hex(log.Topics[0]))
-		//fmt.Println("[matchEvent] key: ", key)
-		consumer, isExisted := s.EventConsumerMap[key]
-		if isExisted {
-			return consumer, i
+	trans, err := s.TransHandler.CollectRange(from, to)
+	if err != nil {
+		s.Logger.Err(err).Msgf("[wel_listener] can't get transactions of blocks %d to %d", from, to)
+		return err
+	}
+	// recording them is idempotent, the blocks are scanned again when it fails
+	for _, t := range trans {
+		if err := s.monitorTransfers(t); err != nil {
+			s.Logger.Err(err).Msgf("[wel_listener] can't report transfers of tx %s", t.Hash)
+			return err
 		}
 	}
+	if err := s.enqueue(trans, s.joinedMatches); err != nil {
+		s.Logger.Err(err).Msg("[wel_listener] can't queue events")
+		return err
+	}
+	return nil
+}
+
+// eventMatch is a log of a transaction some consumer is interested in
+type eventMatch struct {
+	consumer *EventConsumer
+	position int
+}
 
-	return nil, -1
+// matchEvents returns every log of the transaction a consumer is registered for, a
+// transaction may emit several events of interest
+func (s *WelListener) matchEvents(tran *Transaction) []eventMatch {
+	ctrAddress, ok := tran.Contract.Parameter.Raw["ContractAddress"].(string)
+	if !ok {
+		return nil
+	}
+	var matches []eventMatch
+	for i, log := range tran.Log {
+		if len(log.Topics) == 0 {
+			continue
+		}
+		key := KeyFromBEConsumer(ctrAddress, GotronCommon.Bytes2Hex(log.Topics[0]))
+		if consumer, isExisted := s.EventConsumerMap[key]; isExisted {
+			matches = append(matches, eventMatch{consumer: consumer, position: i})
+		}
+	}
+	return matches
 }
 
 func (s *WelListener) consumeEvent(t *Transaction) {
-	consumer, position := s.matchEvent(t)
-	if position >= 0 {
-		err := consumer.ParseEvent(t, position)
+	if err := s.monitorTransfers(t); err != nil {
+		s.Logger.Err(err).Msgf("[wel_listener] can't report transfers of tx %s", t.Hash)
+	}
+	for _, m := range s.joinedMatches(t) {
+		err := m.consumer.ParseEvent(t, m.position)
 		if err != nil {
 			s.Logger.Err(err).Msgf("[wel_listener] Consume event error, tx with event: %v", t)
 		}
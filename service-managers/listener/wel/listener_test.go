@@ -0,0 +1,36 @@
+package wel
+
+import (
+	"testing"
+
+	CoreProto "github.com/Paven-Org/gotron-sdk/pkg/proto/core"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestMatchEvents(t *testing.T) {
+	withdraw := common.HexToHash("0x01")
+	other := common.HexToHash("0x02")
+	consumer := &EventConsumer{Address: "WContract", Topic: withdraw}
+	s := &WelListener{EventConsumerMap: map[string]*EventConsumer{
+		KeyFromBEConsumer(consumer.Address, withdraw.Hex()[2:]): consumer,
+	}}
+
+	tran := &Transaction{Log: []*CoreProto.TransactionInfo_Log{
+		{Topics: [][]byte{withdraw.Bytes()}},
+		{Topics: [][]byte{other.Bytes()}},
+		{},
+		{Topics: [][]byte{withdraw.Bytes()}},
+	}}
+	if matches := s.matchEvents(tran); len(matches) != 0 {
+		t.Fatalf("transaction without contract address matched %v", matches)
+	}
+
+	tran.Contract.Parameter.Raw = map[string]interface{}{"ContractAddress": "WContract"}
+	matches := s.matchEvents(tran)
+	if len(matches) != 2 || matches[0].position != 0 || matches[1].position != 3 {
+		t.Fatalf("expected both withdraw logs to match, got %+v", matches)
+	}
+	if matches[0].consumer != consumer {
+		t.Errorf("unexpected consumer %+v", matches[0].consumer)
+	}
+}
@@ -0,0 +1,152 @@
+package wel
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"bridge/micros/weleth/model"
+
+	GotronCommon "github.com/Paven-Org/gotron-sdk/pkg/common"
+	CoreProto "github.com/Paven-Org/gotron-sdk/pkg/proto/core"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// topic of the TRC20 event Transfer(address indexed from, address indexed to, uint256 value)
+var TRC20TransferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// ITxMonitor is told of the transfers to its address: WEL sent by TransferContract, and
+// TRC20 tokens sent by a Transfer event of a watched token. logpos is the position of the
+// Transfer log in the transaction, 0 for WEL transfers.
+type ITxMonitor interface {
+	MonitoredAddress() string
+	TxParse(t *Transaction, logpos int, from, to, tokenAddr, amount string) error
+}
+
+func (s *WelListener) RegisterTxMonitor(monitor ITxMonitor) error {
+	if monitor == nil {
+		err := fmt.Errorf("Nil monitor")
+		s.Logger.Err(err).Msg("[wel listener] Register nil monitor")
+		return err
+	}
+	address := monitor.MonitoredAddress()
+	if _, ok := s.TxMonitors[address]; ok {
+		return fmt.Errorf("Monitor for " + address + " already existed")
+	}
+	s.TxMonitors[address] = monitor
+	s.TransHandler.WatchTransfersTo(address)
+	s.Logger.Info().Msg("Monitor for " + address + " Added")
+	return nil
+}
+
+// SetWatchedTokens replaces the TRC20 tokens whose transfers are reported to tx monitors,
+// it's safe to call while the listener is running and applies from the next scan
+func (s *WelListener) SetWatchedTokens(tokens ...string) {
+	s.TransHandler.SetTokens(tokens...)
+}
+
+// WatchTransfersTo makes range scans keep the transfers to address
+func (t *TransHandler) WatchTransfersTo(address string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.receivers[address] = true
+}
+
+// SetTokens replaces the TRC20 tokens whose calls range scans keep, native WEL is always
+// kept
+func (t *TransHandler) SetTokens(tokens ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tokens = make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		if token == "" || token == model.WelupsTk {
+			continue
+		}
+		t.tokens[token] = true
+	}
+}
+
+func (t *TransHandler) watchesToken(token string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tokens[token]
+}
+
+// transfer is a transfer to a monitored address
+type transfer struct {
+	monitor  ITxMonitor
+	logpos   int
+	from, to string
+	token    string
+	amount   string
+}
+
+// transfers lists the transfers of a transaction to monitored addresses, failed
+// transactions transfer nothing
+func (s *WelListener) transfers(t *Transaction) []transfer {
+	if len(s.TxMonitors) == 0 || t.Result == CoreProto.TransactionInfo_FAILED.String() {
+		return nil
+	}
+	raw := t.Contract.Parameter.Raw
+	switch t.Contract.Type {
+	case CoreProto.Transaction_Contract_TransferContract.String():
+		to, _ := raw["ToAddress"].(string)
+		monitor, ok := s.TxMonitors[to]
+		if !ok {
+			return nil
+		}
+		from, _ := raw["OwnerAddress"].(string)
+		amount, _ := raw["Amount"].(int64)
+		return []transfer{{monitor: monitor, from: from, to: to, token: model.WelupsTk, amount: strconv.FormatInt(amount, 10)}}
+
+	case CoreProto.Transaction_Contract_TriggerSmartContract.String():
+		var res []transfer
+		for logpos, log := range t.Log {
+			from, to, token, amount, ok := parseTokenTransfer(log)
+			if !ok || !s.TransHandler.watchesToken(token) {
+				continue
+			}
+			monitor, ok := s.TxMonitors[to]
+			if !ok {
+				continue
+			}
+			res = append(res, transfer{monitor: monitor, logpos: logpos, from: from, to: to, token: token, amount: amount.String()})
+		}
+		return res
+	}
+	return nil
+}
+
+// parseTokenTransfer decodes a Transfer log, TRC721 transfers (value indexed as a 4th
+// topic) are rejected
+func parseTokenTransfer(log *CoreProto.TransactionInfo_Log) (from, to, token string, amount *big.Int, ok bool) {
+	topics := log.GetTopics()
+	if len(topics) != 3 || common.BytesToHash(topics[0]) != TRC20TransferTopic || len(log.GetData()) != 32 {
+		return "", "", "", nil, false
+	}
+	from = welAddress(topics[1][12:])
+	to = welAddress(topics[2][12:])
+	token = welAddress(log.GetAddress())
+	amount = new(big.Int).SetBytes(log.GetData())
+	return from, to, token, amount, true
+}
+
+// welAddress encodes a 20 bytes address, as found in logs, in base58
+func welAddress(b []byte) string {
+	if len(b) == 20 {
+		b = append([]byte{0x41}, b...)
+	}
+	return GotronCommon.EncodeCheck(b)
+}
+
+// monitorTransfers tells the monitors of the transfers of t to their address
+func (s *WelListener) monitorTransfers(t *Transaction) error {
+	for _, tr := range s.transfers(t) {
+		s.Logger.Info().Msgf("[wel_listener] token %s transfer to %s in tx %s:%d", tr.token, tr.to, t.Hash, tr.logpos)
+		if err := tr.monitor.TxParse(t, tr.logpos, tr.from, tr.to, tr.token, tr.amount); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package wel
+
+import (
+	"math/big"
+	"testing"
+
+	"bridge/micros/weleth/model"
+
+	GotronCommon "github.com/Paven-Org/gotron-sdk/pkg/common"
+	CoreProto "github.com/Paven-Org/gotron-sdk/pkg/proto/core"
+	proto "github.com/golang/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+type nopMonitor string
+
+func (m nopMonitor) MonitoredAddress() string { return string(m) }
+
+func (m nopMonitor) TxParse(t *Transaction, logpos int, from, to, tokenAddr, amount string) error {
+	return nil
+}
+
+func addressBytes(b byte) []byte {
+	return append([]byte{0x41}, bytesOf(b, 20)...)
+}
+
+func topicOf(address []byte) []byte {
+	return append(make([]byte, 12), address[1:]...)
+}
+
+func TestTransfers(t *testing.T) {
+	treasury, sender, token, other := addressBytes(0x01), addressBytes(0x02), addressBytes(0x03), addressBytes(0x04)
+	treasuryB58 := GotronCommon.EncodeCheck(treasury)
+	s := &WelListener{
+		TransHandler: NewTransHandler(nil, 20),
+		TxMonitors:   map[string]ITxMonitor{treasuryB58: nopMonitor(treasuryB58)},
+	}
+	s.SetWatchedTokens(GotronCommon.EncodeCheck(token))
+
+	native := &Transaction{}
+	native.Contract.Type = CoreProto.Transaction_Contract_TransferContract.String()
+	native.Contract.Parameter.Raw = map[string]interface{}{
+		"OwnerAddress": GotronCommon.EncodeCheck(sender),
+		"ToAddress":    treasuryB58,
+		"Amount":       int64(1000),
+	}
+	trs := s.transfers(native)
+	if len(trs) != 1 || trs[0].token != model.WelupsTk || trs[0].amount != "1000" || trs[0].from != GotronCommon.EncodeCheck(sender) {
+		t.Fatalf("unexpected native transfers %+v", trs)
+	}
+	native.Result = CoreProto.TransactionInfo_FAILED.String()
+	if trs := s.transfers(native); len(trs) != 0 {
+		t.Fatalf("failed transfer reported %+v", trs)
+	}
+
+	value := new(big.Int).SetUint64(5e6).FillBytes(make([]byte, 32))
+	transferLog := func(contract, to []byte) *CoreProto.TransactionInfo_Log {
+		return &CoreProto.TransactionInfo_Log{
+			Address: contract[1:],
+			Topics:  [][]byte{TRC20TransferTopic.Bytes(), topicOf(sender), topicOf(to)},
+			Data:    value,
+		}
+	}
+	trc20 := &Transaction{Log: []*CoreProto.TransactionInfo_Log{
+		transferLog(token, treasury),
+		transferLog(other, treasury), // token not watched
+		transferLog(token, other),    // not to the treasury
+		transferLog(token, treasury),
+	}}
+	trc20.Contract.Type = CoreProto.Transaction_Contract_TriggerSmartContract.String()
+	trs = s.transfers(trc20)
+	if len(trs) != 2 || trs[0].token != GotronCommon.EncodeCheck(token) || trs[0].to != treasuryB58 || trs[0].amount != "5000000" {
+		t.Fatalf("unexpected token transfers %+v", trs)
+	}
+	// transfers of the same tx are told apart by their log
+	if trs[0].logpos != 0 || trs[1].logpos != 3 {
+		t.Errorf("unexpected transfer logs %d and %d", trs[0].logpos, trs[1].logpos)
+	}
+}
+
+func TestIsWatchedTransfers(t *testing.T) {
+	treasury, contract := addressBytes(0x01), addressBytes(0x05)
+	h := NewTransHandler(nil, 20)
+	h.WatchAddress(GotronCommon.EncodeCheck(contract))
+	h.WatchTransfersTo(GotronCommon.EncodeCheck(treasury))
+
+	transferTo := func(to []byte) *CoreProto.Transaction {
+		value, _ := proto.Marshal(&CoreProto.TransferContract{ToAddress: to, Amount: 1})
+		return &CoreProto.Transaction{RawData: &CoreProto.TransactionRaw{Contract: []*CoreProto.Transaction_Contract{{
+			Type:      CoreProto.Transaction_Contract_TransferContract,
+			Parameter: &anypb.Any{TypeUrl: "type.googleapis.com/protocol.TransferContract", Value: value},
+		}}}}
+	}
+	if !h.isWatched(transferTo(treasury)) {
+		t.Error("transfer to the treasury filtered out")
+	}
+	if h.isWatched(transferTo(contract)) {
+		t.Error("transfer elsewhere kept")
+	}
+}
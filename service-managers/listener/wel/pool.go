@@ -0,0 +1,217 @@
+package wel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bridge/common/consts"
+	"bridge/service-managers/listener/endpoint"
+
+	"github.com/Paven-Org/gotron-sdk/pkg/account"
+	"github.com/Paven-Org/gotron-sdk/pkg/proto/api"
+	"github.com/Paven-Org/gotron-sdk/pkg/proto/core"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// IWelClient is the subset of the gotron GrpcClient used across the project, satisfied by
+// both *ExtNodeClient and *NodePool
+type IWelClient interface {
+	GetNowBlock() (*api.BlockExtention, error)
+	GetBlockByLimitNext(start, end int64) (*api.BlockListExtention, error)
+	GetTransactionInfoByID(id string) (*core.TransactionInfo, error)
+	GetTransactionByID(id string) (*core.Transaction, error)
+	GetAssetIssueByName(name string) (*core.AssetIssueContract, error)
+
+	GetAccount(addr string) (*core.Account, error)
+	GetAccountDetailed(addr string) (*account.Account, error)
+
+	Transfer(from, toAddress string, amount int64) (*api.TransactionExtention, error)
+	TriggerConstantContract(from, contractAddress, method, jsonString string) (*api.TransactionExtention, error)
+	TriggerContract(from, contractAddress, method, jsonString string, feeLimit, tAmount int64, tTokenID string, tTokenAmount int64) (*api.TransactionExtention, error)
+	Broadcast(tx *core.Transaction) (*api.Return, error)
+}
+
+// IWelBlockClient adds the block-level calls used for scanning, which the plain gotron
+// GrpcClient lacks
+type IWelBlockClient interface {
+	IWelClient
+	GetTransactionInfoByBlockNum(num int64) (*api.TransactionInfoList, error)
+}
+
+var (
+	_ IWelBlockClient = (*ExtNodeClient)(nil)
+	_ IWelBlockClient = (*NodePool)(nil)
+)
+
+var (
+	ErrNoWelNode          = fmt.Errorf("No welups node could be started")
+	DefaultPoolHealthTick = 15 * time.Second
+	// welups produces a block every few seconds, a node lagging this many blocks behind the
+	// highest known head is considered out of sync
+	DefaultMaxBlockLag int64 = 20
+)
+
+type NodeStat = endpoint.Stat
+
+// NodePool keeps a gRPC connection to every configured welups node, rotating away from
+// nodes that become unavailable or fall out of sync
+type NodePool struct {
+	nodes  []*ExtNodeClient
+	health *endpoint.Set
+
+	healthTick time.Duration
+	logger     *zerolog.Logger
+}
+
+func NewNodePool(addresses []string, timeout time.Duration, logger *zerolog.Logger) (*NodePool, error) {
+	p := &NodePool{
+		healthTick: DefaultPoolHealthTick,
+		logger:     logger,
+	}
+	var started []string
+	for _, addr := range addresses {
+		cli := NewExtNodeClientWithTimeout(addr, timeout)
+		if err := cli.Start(); err != nil {
+			logger.Err(err).Msgf("[wel pool] Unable to start node %s", addr)
+			continue
+		}
+		p.nodes = append(p.nodes, cli)
+		started = append(started, addr)
+	}
+	if len(p.nodes) == 0 {
+		return nil, ErrNoWelNode
+	}
+	p.health = endpoint.MkSet("[wel pool]", started, DefaultMaxBlockLag, logger)
+	p.checkHealth()
+	return p, nil
+}
+
+func (p *NodePool) Stop() {
+	for _, cli := range p.nodes {
+		cli.Stop()
+	}
+}
+
+// HealthCheck is a daemon generator periodically comparing nodes' heads
+func (p *NodePool) HealthCheck(ctx context.Context) (consts.Daemon, error) {
+	return func() {
+		p.logger.Info().Msg("[wel pool] Start health checking welups nodes")
+		for {
+			consts.SleepContext(ctx, p.healthTick)
+			select {
+			case <-ctx.Done():
+				p.logger.Info().Msg("[wel pool] Stop health checking welups nodes")
+				return
+			default:
+				p.checkHealth()
+			}
+		}
+	}, nil
+}
+
+func (p *NodePool) Stats() []NodeStat {
+	return p.health.Stats()
+}
+
+func (p *NodePool) checkHealth() {
+	p.health.Check(func(idx int) (int64, error) {
+		block, err := p.nodes[idx].GetNowBlock()
+		if err != nil {
+			return 0, err
+		}
+		return block.GetBlockHeader().GetRawData().GetNumber(), nil
+	})
+}
+
+// isNodeFailure tells apart the gRPC codes of an unreachable or overloaded node, which
+// warrant retrying on another node, from errors that any node would return
+func isNodeFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	}
+	return false
+}
+
+func withNode[T any](p *NodePool, method string, f func(cli *ExtNodeClient) (T, error)) (T, error) {
+	return endpoint.Call(p.health, method, isNodeFailure, func(idx int) (T, error) {
+		return f(p.nodes[idx])
+	})
+}
+
+func (p *NodePool) GetNowBlock() (*api.BlockExtention, error) {
+	return withNode(p, "GetNowBlock", func(cli *ExtNodeClient) (*api.BlockExtention, error) {
+		return cli.GetNowBlock()
+	})
+}
+
+func (p *NodePool) GetBlockByLimitNext(start, end int64) (*api.BlockListExtention, error) {
+	return withNode(p, "GetBlockByLimitNext", func(cli *ExtNodeClient) (*api.BlockListExtention, error) {
+		return cli.GetBlockByLimitNext(start, end)
+	})
+}
+
+func (p *NodePool) GetTransactionInfoByID(id string) (*core.TransactionInfo, error) {
+	return withNode(p, "GetTransactionInfoByID", func(cli *ExtNodeClient) (*core.TransactionInfo, error) {
+		return cli.GetTransactionInfoByID(id)
+	})
+}
+
+func (p *NodePool) GetTransactionByID(id string) (*core.Transaction, error) {
+	return withNode(p, "GetTransactionByID", func(cli *ExtNodeClient) (*core.Transaction, error) {
+		return cli.GetTransactionByID(id)
+	})
+}
+
+func (p *NodePool) GetTransactionInfoByBlockNum(num int64) (*api.TransactionInfoList, error) {
+	return withNode(p, "GetTransactionInfoByBlockNum", func(cli *ExtNodeClient) (*api.TransactionInfoList, error) {
+		return cli.GetTransactionInfoByBlockNum(num)
+	})
+}
+
+func (p *NodePool) GetAssetIssueByName(name string) (*core.AssetIssueContract, error) {
+	return withNode(p, "GetAssetIssueByName", func(cli *ExtNodeClient) (*core.AssetIssueContract, error) {
+		return cli.GetAssetIssueByName(name)
+	})
+}
+
+func (p *NodePool) GetAccount(addr string) (*core.Account, error) {
+	return withNode(p, "GetAccount", func(cli *ExtNodeClient) (*core.Account, error) {
+		return cli.GetAccount(addr)
+	})
+}
+
+func (p *NodePool) GetAccountDetailed(addr string) (*account.Account, error) {
+	return withNode(p, "GetAccountDetailed", func(cli *ExtNodeClient) (*account.Account, error) {
+		return cli.GetAccountDetailed(addr)
+	})
+}
+
+func (p *NodePool) Transfer(from, toAddress string, amount int64) (*api.TransactionExtention, error) {
+	return withNode(p, "Transfer", func(cli *ExtNodeClient) (*api.TransactionExtention, error) {
+		return cli.Transfer(from, toAddress, amount)
+	})
+}
+
+func (p *NodePool) TriggerConstantContract(from, contractAddress, method, jsonString string) (*api.TransactionExtention, error) {
+	return withNode(p, "TriggerConstantContract", func(cli *ExtNodeClient) (*api.TransactionExtention, error) {
+		return cli.TriggerConstantContract(from, contractAddress, method, jsonString)
+	})
+}
+
+func (p *NodePool) TriggerContract(from, contractAddress, method, jsonString string, feeLimit, tAmount int64, tTokenID string, tTokenAmount int64) (*api.TransactionExtention, error) {
+	return withNode(p, "TriggerContract", func(cli *ExtNodeClient) (*api.TransactionExtention, error) {
+		return cli.TriggerContract(from, contractAddress, method, jsonString, feeLimit, tAmount, tTokenID, tTokenAmount)
+	})
+}
+
+// rebroadcasting a signed transaction to another node is safe, the worst case is a
+// duplicated transaction error
+func (p *NodePool) Broadcast(tx *core.Transaction) (*api.Return, error) {
+	return withNode(p, "Broadcast", func(cli *ExtNodeClient) (*api.Return, error) {
+		return cli.Broadcast(tx)
+	})
+}
@@ -0,0 +1,50 @@
+package wel
+
+import (
+	"fmt"
+	"testing"
+
+	"bridge/service-managers/listener/endpoint"
+	"bridge/service-managers/logger"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsNodeFailure(t *testing.T) {
+	cases := map[error]bool{
+		nil:                                      false,
+		fmt.Errorf("transaction info not found"): false,
+		status.Error(codes.Unavailable, "down"):  true,
+		status.Error(codes.DeadlineExceeded, ""): true,
+		status.Error(codes.InvalidArgument, ""):  false,
+	}
+	for err, expected := range cases {
+		if isNodeFailure(err) != expected {
+			t.Errorf("isNodeFailure(%v) should be %v", err, expected)
+		}
+	}
+}
+
+func TestNodePoolRotation(t *testing.T) {
+	addresses := []string{"node0", "node1", "node2"}
+	p := &NodePool{health: endpoint.MkSet("[wel pool]", addresses, DefaultMaxBlockLag, logger.Get()), logger: logger.Get()}
+	for _, addr := range addresses {
+		p.nodes = append(p.nodes, NewExtNodeClient(addr))
+	}
+
+	calls := []string{}
+	_, err := withNode(p, "Test", func(cli *ExtNodeClient) (struct{}, error) {
+		calls = append(calls, cli.Address)
+		if cli.Address != "node2" {
+			return struct{}{}, status.Error(codes.Unavailable, "down")
+		}
+		return struct{}{}, nil
+	})
+	if err != nil || len(calls) != 3 || calls[2] != "node2" {
+		t.Fatalf("expected to end up on node2, got %v, %v", calls, err)
+	}
+	if idx := p.health.Pick(); idx != 2 {
+		t.Fatalf("failed nodes should be skipped, picked %d", idx)
+	}
+}
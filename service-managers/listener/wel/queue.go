@@ -0,0 +1,82 @@
+package wel
+
+import (
+	"context"
+	"time"
+)
+
+var (
+	// DefaultQueuePollInterval is how often queued events are looked up for consumption
+	DefaultQueuePollInterval = time.Second
+	// DefaultQueueBatch is the number of queued events consumed per poll
+	DefaultQueueBatch = 100
+)
+
+// QueuedEvent is the log at LogPos of a transaction, held by an event queue until its
+// consumer acknowledges it
+type QueuedEvent struct {
+	ID       int64
+	Attempts int
+	Tx       *Transaction
+	LogPos   int
+}
+
+// IEventQueue durably holds the events matched by the scanner: they are enqueued before
+// the last scanned block moves past them, and only leave the queue once consumed. Failed
+// events are retried with backoff, the queue dead-letters those failing for too long.
+type IEventQueue interface {
+	Enqueue(events []QueuedEvent) error
+	// Due returns the events ready to be consumed, oldest first
+	Due(limit int) ([]QueuedEvent, error)
+	Ack(ev QueuedEvent) error
+	Fail(ev QueuedEvent, err error) error
+}
+
+// enqueue queues the events of the transactions match finds a consumer for
+func (s *WelListener) enqueue(trans []*Transaction, match func(*Transaction) []eventMatch) error {
+	var events []QueuedEvent
+	for _, t := range trans {
+		for _, m := range match(t) {
+			events = append(events, QueuedEvent{Tx: t, LogPos: m.position})
+		}
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	return s.Queue.Enqueue(events)
+}
+
+// drainQueue consumes the due events in order, acknowledging them or reporting their failure
+func (s *WelListener) drainQueue(ctx context.Context) {
+	events, err := s.Queue.Due(DefaultQueueBatch)
+	if err != nil {
+		s.Logger.Err(err).Msg("[wel_listener] can't get queued events")
+		return
+	}
+	for _, ev := range events {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.consumeQueued(ev); err != nil {
+			s.Logger.Err(err).Msgf("[wel_listener] queued event %d of tx %s failed, attempt %d", ev.LogPos, ev.Tx.Hash, ev.Attempts+1)
+			if err := s.Queue.Fail(ev, err); err != nil {
+				s.Logger.Err(err).Msgf("[wel_listener] can't report failure of queued event %d", ev.ID)
+			}
+			continue
+		}
+		if err := s.Queue.Ack(ev); err != nil {
+			s.Logger.Err(err).Msgf("[wel_listener] can't acknowledge queued event %d", ev.ID)
+		}
+	}
+}
+
+// consumeQueued hands a queued event to its consumer, events no consumer is registered
+// for anymore are dropped
+func (s *WelListener) consumeQueued(ev QueuedEvent) error {
+	for _, m := range s.matchEvents(ev.Tx) {
+		if m.position == ev.LogPos {
+			return m.consumer.ParseEvent(ev.Tx, ev.LogPos)
+		}
+	}
+	return nil
+}
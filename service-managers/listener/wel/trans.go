@@ -3,6 +3,7 @@ package wel
 import (
 	"fmt"
 	"log"
+	"sync"
 
 	GotronCommon "github.com/Paven-Org/gotron-sdk/pkg/common"
 	"github.com/Paven-Org/gotron-sdk/pkg/proto/api"
@@ -11,16 +12,66 @@ import (
 	proto "github.com/golang/protobuf/proto"
 )
 
-func NewTransHandler(client *ExtNodeClient, threshold int64) *TransHandler {
+// number of blocks processed concurrently by default while scanning a range
+const DefaultScanWorkers = 8
+
+func NewTransHandler(client IWelBlockClient, threshold int64) *TransHandler {
 	return &TransHandler{
 		Client:                  client,
 		ConfirmedBlockThreshold: threshold,
+		Workers:                 DefaultScanWorkers,
+		watched:                 make(map[string]bool),
+		receivers:               make(map[string]bool),
+		tokens:                  make(map[string]bool),
 	}
 }
 
 type TransHandler struct {
-	Client                  *ExtNodeClient
+	Client                  IWelBlockClient
 	ConfirmedBlockThreshold int64 // = 20
+	Workers                 int
+
+	mu sync.RWMutex
+	// contract addresses having registered consumers, no filtering while empty
+	watched map[string]bool
+	// addresses having tx monitors, and the TRC20 tokens transferred to them
+	receivers map[string]bool
+	tokens    map[string]bool
+}
+
+// WatchAddress restricts range scans to transactions calling the watched contracts
+func (t *TransHandler) WatchAddress(address string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.watched[address] = true
+}
+
+func (t *TransHandler) isWatched(tx *CoreProto.Transaction) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if len(t.watched) == 0 && len(t.receivers) == 0 {
+		return true
+	}
+	contracts := tx.GetRawData().GetContract()
+	if len(contracts) == 0 {
+		return false
+	}
+	switch contracts[0].GetType() {
+	case CoreProto.Transaction_Contract_TriggerSmartContract:
+		var ctDetails CoreProto.TriggerSmartContract
+		if err := proto.Unmarshal(contracts[0].GetParameter().GetValue(), &ctDetails); err != nil {
+			return false
+		}
+		address := GotronCommon.EncodeCheck(ctDetails.GetContractAddress())
+		return t.watched[address] || t.tokens[address]
+	case CoreProto.Transaction_Contract_TransferContract:
+		var ctDetails CoreProto.TransferContract
+		if err := proto.Unmarshal(contracts[0].GetParameter().GetValue(), &ctDetails); err != nil {
+			return false
+		}
+		return t.receivers[GotronCommon.EncodeCheck(ctDetails.GetToAddress())]
+	}
+	return false
 }
 
 //Transaction defines the transaction data
@@ -55,6 +106,7 @@ type Transaction struct {
 	BlockNumber int64  `json:"blockNumber"`
 	NumOfBlocks int64  `json:"num_of_blocks,omitempty"`
 	Result      string `json:"result,omitempty"`
+	Fee         int64  `json:"fee,omitempty"`
 }
 
 //GetTransactionDetails return transaction info details
@@ -75,66 +127,143 @@ func (t *TransHandler) GetTransactionDetails(n string) *Transaction {
 		return tranInfo
 	}
 
-	blockId := nowBlock.BlockHeader.GetRawData().GetNumber()
+	tranInfo = t.ToTransaction(tranDetails2, nowBlock)
+	t.fillInfo(tranInfo, tranDetails, nowBlock.BlockHeader.GetRawData().GetNumber())
+	return tranInfo
+}
 
+// fillInfo completes a transaction with its receipt and confirmation status
+func (t *TransHandler) fillInfo(tranInfo *Transaction, tranDetails *CoreProto.TransactionInfo, head int64) {
 	resultStatus := "unconfirmed"
-	diffBlock := blockId - tranDetails.GetBlockNumber()
+	diffBlock := head - tranDetails.GetBlockNumber()
 	if diffBlock >= t.ConfirmedBlockThreshold {
 		resultStatus = "confirmed"
 	}
 
-	tranInfo = t.ToTransaction(tranDetails2, nowBlock)
 	tranInfo.Hash = GotronCommon.Bytes2Hex(tranDetails.Id)
 	tranInfo.ContractAddress = GotronCommon.EncodeCheck(tranDetails.GetContractAddress())
 	tranInfo.Log = tranDetails.Log
 	tranInfo.Result = tranDetails.GetResult().String()
-	tranInfo.EnergyUsage = tranDetails.Receipt.EnergyUsage
-	tranInfo.OriginEnergyUsage = tranDetails.Receipt.OriginEnergyUsage
-	tranInfo.EnergyUsageTotal = tranDetails.Receipt.EnergyUsageTotal
-	tranInfo.NetUsage = tranDetails.Receipt.NetUsage
+	tranInfo.Fee = tranDetails.GetFee()
+	tranInfo.EnergyUsage = tranDetails.GetReceipt().GetEnergyUsage()
+	tranInfo.OriginEnergyUsage = tranDetails.GetReceipt().GetOriginEnergyUsage()
+	tranInfo.EnergyUsageTotal = tranDetails.GetReceipt().GetEnergyUsageTotal()
+	tranInfo.NetUsage = tranDetails.GetReceipt().GetNetUsage()
 	tranInfo.Status = resultStatus
 	tranInfo.NumOfBlocks = diffBlock
-	return tranInfo
 }
 
-//GetInfoTransactionRange ....
+//GetInfoTransactionRange fetches blocks in range with a bounded pool of workers, only
+//fetching infos of blocks containing transactions to watched contracts
 func (t *TransHandler) GetInfoListTransactionRange(blocknum int64, limit int64, sortString string, output chan *Transaction, errChan chan error) {
 	//logger.Get().Info().Msg(fmt.Sprintf("[wel_listener] scan from block %v to %v", blocknum-limit+1, blocknum))
+	var start, end int64
 	if sortString == "inc" {
-		blocksList, err := t.Client.GetBlockByLimitNext(blocknum+1, blocknum+limit+1)
-		if err != nil {
-			errChan <- err
-			return
+		start, end = blocknum+1, blocknum+limit+1
+	} else {
+		start = blocknum - limit + 1
+		if start < 0 {
+			start = 0
 		}
-		for _, b := range blocksList.Block {
-			tempTrans := b.GetTransactions()
-			for _, tx := range tempTrans {
-				aTran := t.GetTransactionDetails(GotronCommon.Bytes2Hex(tx.GetTxid()))
-				output <- aTran
+		end = blocknum + 1
+	}
+
+	// head is fetched once for the whole range
+	nowBlock, err := t.Client.GetNowBlock()
+	if err != nil {
+		errChan <- err
+		return
+	}
+	head := nowBlock.GetBlockHeader().GetRawData().GetNumber()
+
+	blocksList, err := t.Client.GetBlockByLimitNext(start, end)
+	if err != nil {
+		errChan <- err
+		return
+	}
+
+	workers := t.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	blocks := make(chan *api.BlockExtention)
+	wg := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range blocks {
+				t.processBlock(b, head, output, errChan)
 			}
+		}()
+	}
+	for _, b := range blocksList.GetBlock() {
+		blocks <- b
+	}
+	close(blocks)
+	wg.Wait()
+}
+
+// CollectRange gathers the watched transactions of blocks [from, to] instead of streaming
+// them, the first error met is returned along with what could be collected
+func (t *TransHandler) CollectRange(from, to int64) ([]*Transaction, error) {
+	output := make(chan *Transaction)
+	errChan := make(chan error)
+	done := make(chan struct{})
+	go func() {
+		t.GetInfoListTransactionRange(to, to-from+1, "", output, errChan)
+		close(done)
+	}()
+
+	var trans []*Transaction
+	var firstErr error
+	for {
+		select {
+		case tran := <-output:
+			trans = append(trans, tran)
+		case err := <-errChan:
+			if firstErr == nil {
+				firstErr = err
+			}
+		case <-done:
+			return trans, firstErr
 		}
-	} else {
-		temp := blocknum - limit + 1
-		if temp < 0 {
-			temp = 0
-		}
+	}
+}
 
-		//fmt.Println("[trans handler] temp: ", temp)
-		//fmt.Println("[trans handler] limit: ", blocknum)
-		blocksList, err := t.Client.GetBlockByLimitNext(temp, blocknum+1)
-		if err != nil {
-			errChan <- err
-			return
+func (t *TransHandler) processBlock(b *api.BlockExtention, head int64, output chan *Transaction, errChan chan error) {
+	var watched []*api.TransactionExtention
+	for _, tx := range b.GetTransactions() {
+		if t.isWatched(tx.GetTransaction()) {
+			watched = append(watched, tx)
 		}
-		for _, b := range blocksList.GetBlock() {
-			tempTrans := b.GetTransactions()
-			for _, tx := range tempTrans {
-				aTran := t.GetTransactionDetails(GotronCommon.Bytes2Hex(tx.GetTxid()))
-				output <- aTran
-			}
+	}
+	if len(watched) == 0 {
+		return
+	}
+
+	blockNum := b.GetBlockHeader().GetRawData().GetNumber()
+	infoList, err := t.Client.GetTransactionInfoByBlockNum(blockNum)
+	if err != nil {
+		errChan <- err
+		return
+	}
+	infos := make(map[string]*CoreProto.TransactionInfo)
+	for _, info := range infoList.GetTransactionInfo() {
+		infos[GotronCommon.Bytes2Hex(info.GetId())] = info
+	}
+
+	for _, tx := range watched {
+		txid := GotronCommon.Bytes2Hex(tx.GetTxid())
+		info, ok := infos[txid]
+		if !ok {
+			errChan <- fmt.Errorf("Transaction info of %s not found in block %d", txid, blockNum)
+			continue
 		}
+		tranInfo := t.ToTransaction(tx.GetTransaction(), b)
+		t.fillInfo(tranInfo, info, head)
+		output <- tranInfo
 	}
-	return
 }
 
 func (tr *TransHandler) ToTransaction(tx *CoreProto.Transaction, b *api.BlockExtention) *Transaction {
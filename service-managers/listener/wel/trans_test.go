@@ -0,0 +1,196 @@
+package wel
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	GotronCommon "github.com/Paven-Org/gotron-sdk/pkg/common"
+	"github.com/Paven-Org/gotron-sdk/pkg/proto/api"
+	CoreProto "github.com/Paven-Org/gotron-sdk/pkg/proto/core"
+	proto "github.com/golang/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const (
+	fakeBlocks      = 20
+	fakeTxsPerBlock = 50
+	fakeRPCLatency  = 100 * time.Microsecond
+)
+
+var (
+	watchedContract = append([]byte{0x41}, make([]byte, 20)...)
+	otherContract   = append([]byte{0x41}, bytesOf(0xff, 20)...)
+)
+
+func bytesOf(b byte, n int) []byte {
+	res := make([]byte, n)
+	for i := range res {
+		res[i] = b
+	}
+	return res
+}
+
+// fakeNodeClient serves a deterministic chain from memory, sleeping fakeRPCLatency on
+// every call to simulate a remote node
+type fakeNodeClient struct {
+	IWelClient
+	head   int64
+	blocks map[int64]*api.BlockExtention
+	infos  map[string]*CoreProto.TransactionInfo
+	txs    map[string]*CoreProto.Transaction
+
+	mu    sync.Mutex
+	calls int
+}
+
+func newFakeNodeClient() *fakeNodeClient {
+	f := &fakeNodeClient{
+		head:   fakeBlocks + 100,
+		blocks: make(map[int64]*api.BlockExtention),
+		infos:  make(map[string]*CoreProto.TransactionInfo),
+		txs:    make(map[string]*CoreProto.Transaction),
+	}
+	for n := int64(0); n < fakeBlocks; n++ {
+		b := &api.BlockExtention{BlockHeader: &CoreProto.BlockHeader{RawData: &CoreProto.BlockHeaderRaw{Number: n}}}
+		for i := 0; i < fakeTxsPerBlock; i++ {
+			contract := otherContract
+			// 1 in 10 transactions calls the watched contract
+			if i%10 == 0 {
+				contract = watchedContract
+			}
+			value, _ := proto.Marshal(&CoreProto.TriggerSmartContract{ContractAddress: contract})
+			param := &anypb.Any{TypeUrl: "type.googleapis.com/protocol.TriggerSmartContract", Value: value}
+			tx := &CoreProto.Transaction{RawData: &CoreProto.TransactionRaw{Contract: []*CoreProto.Transaction_Contract{{
+				Type:      CoreProto.Transaction_Contract_TriggerSmartContract,
+				Parameter: param,
+			}}}}
+			txid := []byte(fmt.Sprintf("%08d%08d", n, i))
+			b.Transactions = append(b.Transactions, &api.TransactionExtention{Transaction: tx, Txid: txid})
+
+			hexid := GotronCommon.Bytes2Hex(txid)
+			f.txs[hexid] = tx
+			f.infos[hexid] = &CoreProto.TransactionInfo{Id: txid, BlockNumber: n, ContractAddress: contract}
+		}
+		f.blocks[n] = b
+	}
+	return f
+}
+
+func (f *fakeNodeClient) rpc() {
+	f.mu.Lock()
+	f.calls++
+	f.mu.Unlock()
+	time.Sleep(fakeRPCLatency)
+}
+
+func (f *fakeNodeClient) GetNowBlock() (*api.BlockExtention, error) {
+	f.rpc()
+	return &api.BlockExtention{BlockHeader: &CoreProto.BlockHeader{RawData: &CoreProto.BlockHeaderRaw{Number: f.head}}}, nil
+}
+
+func (f *fakeNodeClient) GetBlockByLimitNext(start, end int64) (*api.BlockListExtention, error) {
+	f.rpc()
+	res := &api.BlockListExtention{}
+	for n := start; n < end; n++ {
+		if b, ok := f.blocks[n]; ok {
+			res.Block = append(res.Block, b)
+		}
+	}
+	return res, nil
+}
+
+func (f *fakeNodeClient) GetTransactionInfoByID(id string) (*CoreProto.TransactionInfo, error) {
+	f.rpc()
+	return f.infos[id], nil
+}
+
+func (f *fakeNodeClient) GetTransactionByID(id string) (*CoreProto.Transaction, error) {
+	f.rpc()
+	return f.txs[id], nil
+}
+
+func (f *fakeNodeClient) GetTransactionInfoByBlockNum(num int64) (*api.TransactionInfoList, error) {
+	f.rpc()
+	res := &api.TransactionInfoList{}
+	for _, tx := range f.blocks[num].GetTransactions() {
+		res.TransactionInfo = append(res.TransactionInfo, f.infos[GotronCommon.Bytes2Hex(tx.GetTxid())])
+	}
+	return res, nil
+}
+
+func scanRange(th *TransHandler) []*Transaction {
+	output := make(chan *Transaction)
+	errC := make(chan error, fakeBlocks)
+	done := make(chan []*Transaction)
+	go func() {
+		var res []*Transaction
+		for t := range output {
+			res = append(res, t)
+		}
+		done <- res
+	}()
+	th.GetInfoListTransactionRange(fakeBlocks-1, fakeBlocks, "", output, errC)
+	close(output)
+	return <-done
+}
+
+func TestGetInfoListTransactionRange(t *testing.T) {
+	client := newFakeNodeClient()
+	th := NewTransHandler(client, 20)
+	th.WatchAddress(GotronCommon.EncodeCheck(watchedContract))
+
+	trans := scanRange(th)
+	if len(trans) != fakeBlocks*fakeTxsPerBlock/10 {
+		t.Fatalf("expected %d watched transactions, got %d", fakeBlocks*fakeTxsPerBlock/10, len(trans))
+	}
+	for _, tran := range trans {
+		if tran.Status != "confirmed" || tran.ContractAddress != GotronCommon.EncodeCheck(watchedContract) {
+			t.Fatalf("unexpected transaction %+v", tran)
+		}
+		if tran.Contract.Parameter.Raw["ContractAddress"] != tran.ContractAddress {
+			t.Fatalf("contract address not decoded: %+v", tran.Contract.Parameter.Raw)
+		}
+	}
+	// head + block list + one info call per block
+	if client.calls != 2+fakeBlocks {
+		t.Fatalf("expected %d RPCs, got %d", 2+fakeBlocks, client.calls)
+	}
+}
+
+func TestGetInfoListTransactionRangeUnfiltered(t *testing.T) {
+	th := NewTransHandler(newFakeNodeClient(), 20)
+	if trans := scanRange(th); len(trans) != fakeBlocks*fakeTxsPerBlock {
+		t.Fatalf("expected every transaction without watched addresses, got %d", len(trans))
+	}
+}
+
+// BenchmarkPerTxDetails measures the former strategy of 3 RPCs per transaction
+func BenchmarkPerTxDetails(b *testing.B) {
+	client := newFakeNodeClient()
+	th := NewTransHandler(client, 20)
+	for i := 0; i < b.N; i++ {
+		blocks, _ := client.GetBlockByLimitNext(0, fakeBlocks)
+		for _, block := range blocks.GetBlock() {
+			for _, tx := range block.GetTransactions() {
+				th.GetTransactionDetails(GotronCommon.Bytes2Hex(tx.GetTxid()))
+			}
+		}
+	}
+}
+
+func BenchmarkBatchedRange(b *testing.B) {
+	th := NewTransHandler(newFakeNodeClient(), 20)
+	th.WatchAddress(GotronCommon.EncodeCheck(watchedContract))
+	for i := 0; i < b.N; i++ {
+		scanRange(th)
+	}
+}
+
+func BenchmarkBatchedRangeUnfiltered(b *testing.B) {
+	th := NewTransHandler(newFakeNodeClient(), 20)
+	for i := 0; i < b.N; i++ {
+		scanRange(th)
+	}
+}